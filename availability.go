@@ -0,0 +1,99 @@
+package reserv
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxCalendarDays is the maximum number of days that can be requested in a single availability calendar.
+const MaxCalendarDays = 366
+
+// ErrInvalidDateRange is returned when the end of a date range is before its start or the range is too long.
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// DayStatus is the availability of a single day of a property.
+type DayStatus string
+
+const (
+	// DayAvailable is a day that can be booked.
+	DayAvailable DayStatus = "available"
+	// DayBooked is a day that belongs to an active booking.
+	DayBooked DayStatus = "booked"
+	// DayBlocked is a day that can't be booked for any reason other than a booking. Example: a day in the past or a calendar block.
+	DayBlocked DayStatus = "blocked"
+)
+
+// OccupancyKind is the reason why a date range of a property is occupied.
+type OccupancyKind string
+
+const (
+	// OccupancyBooking is a range occupied by an active booking.
+	OccupancyBooking OccupancyKind = "booking"
+	// OccupancyBlock is a range blocked by the host.
+	OccupancyBlock OccupancyKind = "block"
+	// OccupancyHold is a range held by a guest that is completing the checkout.
+	OccupancyHold OccupancyKind = "hold"
+)
+
+// Occupancy is a date range where the property can't be booked. It never carries who is occupying the property,
+// so it is safe to expose it to anonymous users.
+type Occupancy struct {
+	// Kind is the reason why the range is occupied.
+	Kind OccupancyKind `json:"kind" db:"kind"`
+	// StartDate and EndDate are the dates of the range. Both are inclusive, following the same semantics of the
+	// overlap check of the bookings: the check-out day of a booking can't be the check-in day of another one.
+	StartDate time.Time `json:"start_date" db:"start_date"`
+	EndDate   time.Time `json:"end_date" db:"end_date"`
+}
+
+// CalendarDay is the availability and price of a single day of a property.
+type CalendarDay struct {
+	// Date is the day. Format: 2025-01-01T00:00:00Z
+	Date time.Time `json:"date"`
+	// Status is the availability of the day.
+	Status DayStatus `json:"status"`
+	// PricePerNightCents is the price of the night that starts at the day, in cents.
+	PricePerNightCents int64 `json:"price_per_night_cents"`
+}
+
+// ValidateDateRange checks that from and to describe a valid calendar window.
+func ValidateDateRange(from, to time.Time) error {
+	days := Nights(from, to) + 1
+	if days < 1 || days > MaxCalendarDays {
+		return ErrInvalidDateRange
+	}
+	return nil
+}
+
+// Calendar builds the day by day availability of a property from the date from to the date to, both inclusive.
+// Days before today are blocked, days inside a calendar block are blocked and days inside a booking are booked.
+// Prices follow the pricing rules of the property.
+func Calendar(property Property, rules []PricingRule, from, to, today time.Time, occupied []Occupancy) []CalendarDay {
+	days := make([]CalendarDay, 0, Nights(from, to)+1)
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		price, _ := NightlyRate(property, rules, date)
+		day := CalendarDay{
+			Date:               date,
+			Status:             DayAvailable,
+			PricePerNightCents: price,
+		}
+
+		if date.Before(today) {
+			day.Status = DayBlocked
+		}
+
+		for _, o := range occupied {
+			if date.Before(o.StartDate) || date.After(o.EndDate) {
+				continue
+			}
+			if o.Kind == OccupancyBooking {
+				day.Status = DayBooked
+				break
+			}
+			day.Status = DayBlocked
+		}
+
+		days = append(days, day)
+	}
+	return days
+}
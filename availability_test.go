@@ -0,0 +1,45 @@
+package reserv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDateRange(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, ValidateDateRange(from, from))
+	require.NoError(t, ValidateDateRange(from, from.AddDate(0, 0, MaxCalendarDays-1)))
+	require.ErrorIs(t, ValidateDateRange(from, from.AddDate(0, 0, MaxCalendarDays)), ErrInvalidDateRange)
+	require.ErrorIs(t, ValidateDateRange(from, from.AddDate(0, 0, -1)), ErrInvalidDateRange)
+}
+
+func TestCalendar(t *testing.T) {
+	property := Property{PricePerNightCents: 10000, Currency: "USD"}
+	day := func(d int) time.Time {
+		return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	occupied := []Occupancy{
+		{Kind: OccupancyBooking, StartDate: day(4), EndDate: day(6)},
+		{Kind: OccupancyBlock, StartDate: day(8), EndDate: day(9)},
+	}
+
+	calendar := Calendar(property, nil, day(1), day(10), day(3), occupied)
+	require.Len(t, calendar, 10)
+
+	want := []DayStatus{
+		DayBlocked, DayBlocked, // before today
+		DayAvailable,
+		DayBooked, DayBooked, DayBooked, // check-in and check-out days are both booked
+		DayAvailable,
+		DayBlocked, DayBlocked, // calendar block
+		DayAvailable,
+	}
+	for i, d := range calendar {
+		require.Equal(t, day(i+1), d.Date)
+		require.Equal(t, want[i], d.Status, "day %d", i+1)
+		require.Equal(t, int64(10000), d.PricePerNightCents)
+	}
+}
@@ -0,0 +1,36 @@
+package reserv
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCalendarBlockNotFound is returned when a calendar block doesn't exist for the property.
+var ErrCalendarBlockNotFound = errors.New("calendar block not found")
+
+// ErrImportedCalendarBlock is returned when the host changes a block created by a calendar import. The next sync would
+// overwrite the change, so the block must be changed in the external calendar.
+var ErrImportedCalendarBlock = errors.New("calendar block was imported")
+
+// CalendarBlock is a date range that the host marked as unavailable. Example: personal use or maintenance.
+// Blocks are treated exactly like bookings by the overlap check.
+type CalendarBlock struct {
+	// ID is the unique identifier for the block. It is generated by the database.
+	ID string `json:"id" db:"id"`
+	// PropertyID is the id of the blocked property. Required.
+	PropertyID string `json:"property_id" db:"property_id"`
+	// StartDate and EndDate are the blocked dates. Both are inclusive. Format: 2025-01-01T00:00:00Z
+	StartDate time.Time `json:"start_date" db:"start_date"`
+	EndDate   time.Time `json:"end_date" db:"end_date"`
+	// Reason is an optional note of the host about the block. Example: "maintenance".
+	Reason string `json:"reason" db:"reason"`
+	// ImportID is the id of the calendar import that created the block. It is nil for blocks created by the host.
+	// Imported blocks are replaced on every sync of the import.
+	ImportID *string `json:"import_id,omitempty" db:"import_id"`
+	// ExternalUID is the identifier of the event of the external calendar that created the block.
+	ExternalUID string `json:"external_uid,omitempty" db:"external_uid"`
+	// CreatedAt is the timestamp when the block was created.
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// UpdatedAt is the timestamp when the block was updated.
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -1,6 +1,108 @@
 package reserv
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrBookingOverlap is returned when a booking overlaps with another booking of the same property.
+var ErrBookingOverlap = errors.New("booking overlaps")
+
+// ErrInvalidBookingTransition is returned when a booking can't move from its current status to the requested one.
+var ErrInvalidBookingTransition = errors.New("invalid booking status transition")
+
+// ErrBookingNotModifiable is returned when the dates of a booking can't be changed anymore, because the booking
+// was cancelled or the guest already arrived.
+var ErrBookingNotModifiable = errors.New("booking dates can't be changed")
+
+// ErrBookingRequestExpired is returned when the host answers a booking request after its deadline.
+var ErrBookingRequestExpired = errors.New("booking request expired")
+
+const (
+	// DefaultBookingRequestDeadline is how long the host has to answer a booking request when no deadline is configured.
+	DefaultBookingRequestDeadline = 24 * time.Hour
+	// MinBookingRequestDeadline and MaxBookingRequestDeadline bound the configured deadline of the booking requests.
+	MinBookingRequestDeadline = time.Hour
+	MaxBookingRequestDeadline = 7 * 24 * time.Hour
+)
+
+// BookingStatus is the status of a booking in its lifecycle.
+type BookingStatus string
+
+const (
+	// BookingStatusPending is a booking waiting for the host confirmation. Properties without instant book create
+	// their bookings as pending requests.
+	BookingStatusPending BookingStatus = "pending"
+	// BookingStatusConfirmed is a booking confirmed by the host. It is the default status for new bookings.
+	BookingStatusConfirmed BookingStatus = "confirmed"
+	// BookingStatusCancelledByGuest is a booking cancelled by the guest.
+	BookingStatusCancelledByGuest BookingStatus = "cancelled_by_guest"
+	// BookingStatusCancelledByHost is a booking cancelled by the host.
+	BookingStatusCancelledByHost BookingStatus = "cancelled_by_host"
+	// BookingStatusCheckedIn is a booking where the guest already arrived at the property.
+	BookingStatusCheckedIn BookingStatus = "checked_in"
+	// BookingStatusCompleted is a booking where the guest already left the property.
+	BookingStatusCompleted BookingStatus = "completed"
+	// BookingStatusNoShow is a confirmed booking where the guest never arrived.
+	BookingStatusNoShow BookingStatus = "no_show"
+	// BookingStatusDeclined is a booking request declined by the host or not answered before its deadline.
+	BookingStatusDeclined BookingStatus = "declined"
+)
+
+// bookingTransitions maps each status to the statuses it can move to. Statuses without entries are final.
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingStatusPending: {
+		BookingStatusConfirmed,
+		BookingStatusCancelledByGuest,
+		BookingStatusCancelledByHost,
+		BookingStatusDeclined,
+	},
+	BookingStatusConfirmed: {
+		BookingStatusCancelledByGuest,
+		BookingStatusCancelledByHost,
+		BookingStatusCheckedIn,
+		BookingStatusNoShow,
+	},
+	BookingStatusCheckedIn: {
+		BookingStatusCompleted,
+	},
+}
+
+// CanTransitionTo reports whether a booking with the status s can move to the status to.
+func (s BookingStatus) CanTransitionTo(to BookingStatus) bool {
+	for _, allowed := range bookingTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanChangeDates reports whether the dates of a booking with the status s can still be changed.
+func (s BookingStatus) CanChangeDates() bool {
+	return s == BookingStatusPending || s == BookingStatusConfirmed
+}
+
+// Valid reports whether s is one of the known statuses.
+func (s BookingStatus) Valid() bool {
+	switch s {
+	case BookingStatusPending, BookingStatusConfirmed, BookingStatusCancelledByGuest, BookingStatusCancelledByHost,
+		BookingStatusCheckedIn, BookingStatusCompleted, BookingStatusNoShow, BookingStatusDeclined:
+		return true
+	}
+	return false
+}
+
+// IsCancelled reports whether the status is one of the cancelled statuses. Cancelled bookings don't block the property dates.
+func (s BookingStatus) IsCancelled() bool {
+	return s == BookingStatusCancelledByGuest || s == BookingStatusCancelledByHost
+}
+
+// BlocksDates reports whether a booking with the status s blocks the property dates. Cancelled bookings and declined
+// requests don't.
+func (s BookingStatus) BlocksDates() bool {
+	return !s.IsCancelled() && s != BookingStatusDeclined
+}
 
 // BookingFilter is the filter for the bookings.
 type BookingFilter struct {
@@ -10,18 +112,78 @@ type BookingFilter struct {
 	GuestID string
 }
 
+// HostBookingFilter is the filter for the bookings of all the properties of a host.
+type HostBookingFilter struct {
+	// HostID is the id of the host of the properties. Required.
+	HostID string
+	// PropertyID limits the bookings to a single property of the host. Optional.
+	PropertyID string
+	// Statuses limits the bookings to the given statuses. Optional.
+	Statuses []BookingStatus
+	// From and To limit the bookings to the stays that overlap the range, both inclusive. Optional.
+	From time.Time
+	To   time.Time
+}
+
 // Booking is the entity that represents a booking of a property by a guest(user).
 type Booking struct {
 	ID         string `json:"id" db:"id"`
 	PropertyID string `json:"property_id" db:"property_id"`
 	GuestID    string `json:"guest_id" db:"guest_id"`
+	// Status is the current status of the booking in its lifecycle.
+	Status BookingStatus `json:"status" db:"status"`
 	// CheckInDate and CheckOutDate are the dates of the booking.
 	// They must be stored in UTC timezone. and must be in the format YYYY-MM-DD.
 	// Format: 2025-01-01T00:00:00Z
+	CheckInDate  time.Time `json:"check_in_date" db:"check_in_date"`
+	CheckOutDate time.Time `json:"check_out_date" db:"check_out_date"`
+	// Guests is who is coming to the stay.
+	Guests
+	// TotalPriceCents is the price of the stay in the minor unit of the currency. See Total.
+	TotalPriceCents int64  `json:"total_price_cents" db:"total_price_cents"`
+	Currency        string `json:"currency" db:"currency"`
+	// LineItems are the lines of the quote used to price the booking: the stay, its discounts, fees and taxes. Their
+	// sum is TotalPriceCents.
+	LineItems []QuoteLine `json:"line_items,omitempty" db:"-"`
+	// PromotionID is the id of the promotion redeemed by the booking. It is nil when the guest didn't use a promo code.
+	PromotionID *string `json:"promotion_id,omitempty" db:"promotion_id"`
+	// RefundCents is the amount given back to the guest when the booking was cancelled. It follows the cancellation
+	// policy of the property at the moment of the cancellation.
+	RefundCents int64 `json:"refund_cents" db:"refund_cents"`
+	// CancelledAt is the timestamp when the booking was cancelled. It is nil for bookings that were never cancelled.
+	CancelledAt *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	// RespondBy is the deadline for the host to answer a booking request. Requests not answered until then are
+	// declined automatically. It is nil for instantly booked stays.
+	RespondBy *time.Time `json:"respond_by,omitempty" db:"respond_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Total returns the price of the stay.
+func (b Booking) Total() Money {
+	return Money{Amount: b.TotalPriceCents, Currency: b.Currency}
+}
+
+// RequestExpired reports whether b is a booking request whose deadline already passed at now.
+func (b Booking) RequestExpired(now time.Time) bool {
+	return b.Status == BookingStatusPending && b.RespondBy != nil && !now.Before(*b.RespondBy)
+}
+
+// BookingChange is an entry of the history of date changes of a booking. It keeps the dates and price before and after the change.
+type BookingChange struct {
+	ID        string `json:"id" db:"id"`
+	BookingID string `json:"booking_id" db:"booking_id"`
+	// ChangedBy is the id of the user who changed the booking.
+	ChangedBy string `json:"changed_by" db:"changed_by"`
+	// PreviousCheckInDate, PreviousCheckOutDate and PreviousTotalPriceCents are the stay before the change.
+	PreviousCheckInDate     time.Time `json:"previous_check_in_date" db:"previous_check_in_date"`
+	PreviousCheckOutDate    time.Time `json:"previous_check_out_date" db:"previous_check_out_date"`
+	PreviousTotalPriceCents int64     `json:"previous_total_price_cents" db:"previous_total_price_cents"`
+	// CheckInDate, CheckOutDate and TotalPriceCents are the stay after the change.
 	CheckInDate     time.Time `json:"check_in_date" db:"check_in_date"`
 	CheckOutDate    time.Time `json:"check_out_date" db:"check_out_date"`
-	TotalPriceCents int       `json:"total_price_cents" db:"total_price_cents"`
-	Currency        string    `json:"currency" db:"currency"`
+	TotalPriceCents int64     `json:"total_price_cents" db:"total_price_cents"`
 	CreatedAt       time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+	// LineItems are the lines of the quote of the new dates. They replace the line items of the booking.
+	LineItems []QuoteLine `json:"-" db:"-"`
 }
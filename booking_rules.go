@@ -0,0 +1,128 @@
+package reserv
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidBookingRules is returned when the booking rules of a property have invalid fields.
+	ErrInvalidBookingRules = errors.New("invalid booking rules")
+	// ErrStayTooShort is returned when a stay has fewer nights than the minimum of the property.
+	ErrStayTooShort = errors.New("stay is too short")
+	// ErrStayTooLong is returned when a stay has more nights than the maximum of the property.
+	ErrStayTooLong = errors.New("stay is too long")
+	// ErrCheckInTooSoon is returned when the check-in date is in the past or inside the advance notice of the property.
+	ErrCheckInTooSoon = errors.New("check-in date is too soon")
+	// ErrCheckInTooFar is returned when the check-in date is beyond the booking horizon of the property.
+	ErrCheckInTooFar = errors.New("check-in date is too far in the future")
+	// ErrCheckInWeekday is returned when the property doesn't accept check-ins on the weekday of the check-in date.
+	ErrCheckInWeekday = errors.New("check-in is not allowed on this weekday")
+	// ErrPreparationTime is returned when a stay doesn't leave the preparation days of the property free between stays.
+	ErrPreparationTime = errors.New("stay is too close to another booking")
+)
+
+const (
+	// MaxStayNights is the highest maximum stay a property can configure.
+	MaxStayNights = 365
+	// MaxBookingHorizonDays is the highest booking horizon a property can configure.
+	MaxBookingHorizonDays = 730
+	// DefaultBookingHorizonDays is the booking horizon of the properties whose host never configured it.
+	DefaultBookingHorizonDays = 365
+	// MaxPreparationDays is the highest number of preparation days a property can configure.
+	MaxPreparationDays = 30
+)
+
+// BookingRules are the restrictions of a property on the stays that guests can book.
+type BookingRules struct {
+	// PropertyID is the id of the property.
+	PropertyID string `json:"property_id" db:"property_id"`
+	// MinNights is the minimum number of nights of a stay.
+	MinNights int `json:"min_nights" db:"min_nights"`
+	// MaxNights is the maximum number of nights of a stay.
+	MaxNights int `json:"max_nights" db:"max_nights"`
+	// AdvanceNoticeDays is how many days before the check-in date a booking must be made. 0 accepts check-ins for today.
+	AdvanceNoticeDays int `json:"advance_notice_days" db:"advance_notice_days"`
+	// BookingHorizonDays is how many days in the future a check-in date can be.
+	BookingHorizonDays int `json:"booking_horizon_days" db:"booking_horizon_days"`
+	// CheckInWeekdays are the days of the week that accept check-ins, where Sunday is 0 and Saturday is 6. Empty means every day.
+	CheckInWeekdays Weekdays `json:"check_in_weekdays" db:"check_in_weekdays"`
+	// PreparationDays is the number of free days required between the check-out of a stay and the check-in of the next one,
+	// on top of the check-out day itself.
+	PreparationDays int `json:"preparation_days" db:"preparation_days"`
+	// UpdatedAt is the timestamp when the rules were updated. It is zero for properties using the default rules.
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultBookingRules returns the rules of a property whose host never configured them.
+func DefaultBookingRules(propertyID string) BookingRules {
+	return BookingRules{
+		PropertyID:         propertyID,
+		MinNights:          1,
+		MaxNights:          MaxStayNights,
+		AdvanceNoticeDays:  0,
+		BookingHorizonDays: DefaultBookingHorizonDays,
+		CheckInWeekdays:    Weekdays{},
+	}
+}
+
+// Validate checks the fields of the rules. The returned error wraps ErrInvalidBookingRules.
+func (r BookingRules) Validate() error {
+	if r.MinNights < 1 {
+		return fmt.Errorf("%w: min_nights must be at least 1", ErrInvalidBookingRules)
+	}
+
+	if r.MaxNights < r.MinNights || r.MaxNights > MaxStayNights {
+		return fmt.Errorf("%w: max_nights must be between min_nights and %d", ErrInvalidBookingRules, MaxStayNights)
+	}
+
+	if r.AdvanceNoticeDays < 0 {
+		return fmt.Errorf("%w: advance_notice_days must not be negative", ErrInvalidBookingRules)
+	}
+
+	if r.BookingHorizonDays <= r.AdvanceNoticeDays || r.BookingHorizonDays > MaxBookingHorizonDays {
+		return fmt.Errorf("%w: booking_horizon_days must be greater than advance_notice_days and at most %d", ErrInvalidBookingRules, MaxBookingHorizonDays)
+	}
+
+	if r.PreparationDays < 0 || r.PreparationDays > MaxPreparationDays {
+		return fmt.Errorf("%w: preparation_days must be between 0 and %d", ErrInvalidBookingRules, MaxPreparationDays)
+	}
+
+	if err := r.CheckInWeekdays.Validate(); err != nil {
+		return fmt.Errorf("%w: check_in_weekdays %v", ErrInvalidBookingRules, err)
+	}
+
+	return nil
+}
+
+// Check validates a stay against the rules. The preparation days are not checked here, since they depend on the other
+// bookings of the property. Today must be in UTC at midnight, like the stay dates.
+func (r BookingRules) Check(checkIn, checkOut, today time.Time) error {
+	nights := Nights(checkIn, checkOut)
+	if nights < 1 {
+		return ErrInvalidStay
+	}
+
+	if nights < r.MinNights {
+		return fmt.Errorf("%w: the minimum stay is %d nights", ErrStayTooShort, r.MinNights)
+	}
+
+	if nights > r.MaxNights {
+		return fmt.Errorf("%w: the maximum stay is %d nights", ErrStayTooLong, r.MaxNights)
+	}
+
+	if checkIn.Before(today.AddDate(0, 0, r.AdvanceNoticeDays)) {
+		return fmt.Errorf("%w: the check-in date must be at least %d days from today", ErrCheckInTooSoon, r.AdvanceNoticeDays)
+	}
+
+	if checkIn.After(today.AddDate(0, 0, r.BookingHorizonDays)) {
+		return fmt.Errorf("%w: the check-in date must be at most %d days from today", ErrCheckInTooFar, r.BookingHorizonDays)
+	}
+
+	if !r.CheckInWeekdays.Contains(checkIn.Weekday()) {
+		return fmt.Errorf("%w: %s", ErrCheckInWeekday, checkIn.Weekday())
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package reserv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookingRules_Validate(t *testing.T) {
+	require.NoError(t, DefaultBookingRules("property-id").Validate())
+
+	tests := []struct {
+		name   string
+		modify func(r *BookingRules)
+	}{
+		{name: "zero min nights", modify: func(r *BookingRules) { r.MinNights = 0 }},
+		{name: "max below min", modify: func(r *BookingRules) { r.MinNights, r.MaxNights = 5, 4 }},
+		{name: "max too long", modify: func(r *BookingRules) { r.MaxNights = MaxStayNights + 1 }},
+		{name: "negative notice", modify: func(r *BookingRules) { r.AdvanceNoticeDays = -1 }},
+		{name: "horizon inside notice", modify: func(r *BookingRules) { r.AdvanceNoticeDays, r.BookingHorizonDays = 10, 10 }},
+		{name: "horizon too far", modify: func(r *BookingRules) { r.BookingHorizonDays = MaxBookingHorizonDays + 1 }},
+		{name: "too many preparation days", modify: func(r *BookingRules) { r.PreparationDays = MaxPreparationDays + 1 }},
+		{name: "invalid weekday", modify: func(r *BookingRules) { r.CheckInWeekdays = Weekdays{-1} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := DefaultBookingRules("property-id")
+			tt.modify(&rules)
+			require.ErrorIs(t, rules.Validate(), ErrInvalidBookingRules)
+		})
+	}
+}
+
+func TestBookingRules_Check(t *testing.T) {
+	// 2025-01-01 is a Wednesday.
+	today := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time {
+		return today.AddDate(0, 0, d)
+	}
+
+	rules := BookingRules{
+		MinNights:          2,
+		MaxNights:          14,
+		AdvanceNoticeDays:  1,
+		BookingHorizonDays: 90,
+		CheckInWeekdays:    Weekdays{time.Thursday, time.Friday, time.Saturday},
+	}
+
+	tests := []struct {
+		name     string
+		checkIn  time.Time
+		checkOut time.Time
+		wantErr  error
+	}{
+		{name: "valid stay", checkIn: day(1), checkOut: day(3)},
+		{name: "no nights", checkIn: day(1), checkOut: day(1), wantErr: ErrInvalidStay},
+		{name: "too short", checkIn: day(1), checkOut: day(2), wantErr: ErrStayTooShort},
+		{name: "too long", checkIn: day(1), checkOut: day(16), wantErr: ErrStayTooLong},
+		{name: "in the past", checkIn: day(-6), checkOut: day(-3), wantErr: ErrCheckInTooSoon},
+		{name: "inside the advance notice", checkIn: day(0), checkOut: day(3), wantErr: ErrCheckInTooSoon},
+		{name: "beyond the horizon", checkIn: day(93), checkOut: day(95), wantErr: ErrCheckInTooFar},
+		{name: "last day of the horizon", checkIn: day(86), checkOut: day(88)},
+		{name: "check-in on a sunday", checkIn: day(4), checkOut: day(6), wantErr: ErrCheckInWeekday},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rules.Check(tt.checkIn, tt.checkOut, today)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package reserv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookingStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from BookingStatus
+		to   BookingStatus
+		want bool
+	}{
+		{BookingStatusPending, BookingStatusConfirmed, true},
+		{BookingStatusPending, BookingStatusCancelledByGuest, true},
+		{BookingStatusPending, BookingStatusCheckedIn, false},
+		{BookingStatusPending, BookingStatusDeclined, true},
+		{BookingStatusConfirmed, BookingStatusDeclined, false},
+		{BookingStatusDeclined, BookingStatusConfirmed, false},
+		{BookingStatusConfirmed, BookingStatusCancelledByHost, true},
+		{BookingStatusConfirmed, BookingStatusCheckedIn, true},
+		{BookingStatusConfirmed, BookingStatusNoShow, true},
+		{BookingStatusConfirmed, BookingStatusCompleted, false},
+		{BookingStatusCheckedIn, BookingStatusCompleted, true},
+		{BookingStatusCheckedIn, BookingStatusCancelledByGuest, false},
+		{BookingStatusCancelledByGuest, BookingStatusConfirmed, false},
+		{BookingStatusCompleted, BookingStatusCheckedIn, false},
+		{BookingStatusNoShow, BookingStatusConfirmed, false},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to), "%s -> %s", tt.from, tt.to)
+	}
+}
+
+func TestBookingStatus_IsCancelled(t *testing.T) {
+	require.True(t, BookingStatusCancelledByGuest.IsCancelled())
+	require.True(t, BookingStatusCancelledByHost.IsCancelled())
+	require.False(t, BookingStatusConfirmed.IsCancelled())
+	require.False(t, BookingStatusNoShow.IsCancelled())
+}
+
+func TestBookingStatus_BlocksDates(t *testing.T) {
+	require.True(t, BookingStatusPending.BlocksDates())
+	require.True(t, BookingStatusConfirmed.BlocksDates())
+	require.False(t, BookingStatusCancelledByGuest.BlocksDates())
+	require.False(t, BookingStatusDeclined.BlocksDates())
+}
+
+func TestBooking_RequestExpired(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	respondBy := now.Add(time.Hour)
+
+	require.False(t, Booking{Status: BookingStatusConfirmed}.RequestExpired(now))
+	require.False(t, Booking{Status: BookingStatusPending, RespondBy: &respondBy}.RequestExpired(now))
+	require.True(t, Booking{Status: BookingStatusPending, RespondBy: &respondBy}.RequestExpired(respondBy))
+	require.False(t, Booking{Status: BookingStatusDeclined, RespondBy: &respondBy}.RequestExpired(respondBy.Add(time.Hour)))
+}
@@ -0,0 +1,58 @@
+// Package bookingrequests declines the booking requests that the hosts didn't answer before their deadline.
+package bookingrequests
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+//go:generate mockgen -source bookingrequests.go -destination ../mock/bookingrequests.go -package mock
+
+// RequestRepository gathers the methods needed to decline expired booking requests.
+type RequestRepository interface {
+	// DeclineExpiredBookingRequests declines the pending bookings whose deadline passed at now and returns how many were declined
+	DeclineExpiredBookingRequests(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Worker periodically declines the expired booking requests.
+type Worker struct {
+	repo     RequestRepository
+	interval time.Duration
+}
+
+// NewWorker creates a worker that declines the expired requests every interval.
+func NewWorker(repo RequestRepository, interval time.Duration) *Worker {
+	return &Worker{repo: repo, interval: interval}
+}
+
+// Run declines the expired requests until ctx is done. It is blocking, so it must run on its own goroutine.
+func (w *Worker) Run(ctx context.Context) {
+	slog.Info("starting booking request expiry worker", "interval", w.interval)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.DeclineExpired(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.Info("stopping booking request expiry worker")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeclineExpired declines the requests whose deadline already passed. The dates of the declined requests become free again.
+func (w *Worker) DeclineExpired(ctx context.Context) {
+	declined, err := w.repo.DeclineExpiredBookingRequests(ctx, time.Now().UTC())
+	if err != nil {
+		slog.Error("failed to decline expired booking requests", "error", err)
+		return
+	}
+
+	if declined > 0 {
+		slog.Info("expired booking requests declined", "declined", declined)
+	}
+}
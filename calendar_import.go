@@ -0,0 +1,91 @@
+package reserv
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCalendarImportNotFound is returned when a calendar import doesn't exist for the property.
+var ErrCalendarImportNotFound = errors.New("calendar import not found")
+
+// CalendarImport is an external iCalendar feed, usually from another platform where the property is listed.
+// The events of the feed become calendar blocks of the property, so the dates booked on other platforms can't be booked here.
+type CalendarImport struct {
+	// ID is the unique identifier for the import. It is generated by the database.
+	ID string `json:"id" db:"id"`
+	// PropertyID is the id of the property that receives the blocks. Required.
+	PropertyID string `json:"property_id" db:"property_id"`
+	// Name is a label for the feed. Example: "Airbnb". Required.
+	Name string `json:"name" db:"name"`
+	// URL is the address of the feed. It is empty for imports synced only by uploading files, which are
+	// skipped by the background sync.
+	URL string `json:"url" db:"url"`
+	// LastSyncAt is the timestamp of the last sync attempt, successful or not.
+	LastSyncAt *time.Time `json:"last_sync_at" db:"last_sync_at"`
+	// LastSyncError is the error of the last sync attempt. Empty when it succeeded.
+	LastSyncError string `json:"last_sync_error" db:"last_sync_error"`
+	// Conflicts are the events of the last successful sync that overlap local bookings.
+	Conflicts CalendarConflicts `json:"conflicts" db:"conflicts"`
+	// CreatedAt is the timestamp when the import was created.
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// UpdatedAt is the timestamp when the import was updated.
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CalendarConflict is an event of an external calendar that overlaps an active local booking. The event dates are
+// not blocked, since they are already booked, but the host must solve the double booking with the guests.
+type CalendarConflict struct {
+	// UID is the identifier of the event in the external calendar.
+	UID string `json:"uid"`
+	// StartDate and EndDate are the dates of the event, both inclusive.
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	// BookingID is the id of the local booking overlapped by the event.
+	BookingID string `json:"booking_id"`
+}
+
+// CalendarConflicts is a list of conflicts stored as JSON.
+type CalendarConflicts []CalendarConflict
+
+// Value implements driver.Valuer.
+func (c CalendarConflicts) Value() (driver.Value, error) {
+	if c == nil {
+		c = CalendarConflicts{}
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal calendar conflicts: %v", err)
+	}
+	return b, nil
+}
+
+// Scan implements sql.Scanner.
+func (c *CalendarConflicts) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*c = CalendarConflicts{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for calendar conflicts: %T", src)
+	}
+	if err := json.Unmarshal(b, c); err != nil {
+		return fmt.Errorf("failed to unmarshal calendar conflicts: %v", err)
+	}
+	return nil
+}
+
+// CalendarSyncResult is the outcome of syncing a calendar import.
+type CalendarSyncResult struct {
+	// Blocked is the number of events that became calendar blocks.
+	Blocked int `json:"blocked"`
+	// Conflicts are the events that overlap local bookings.
+	Conflicts CalendarConflicts `json:"conflicts"`
+}
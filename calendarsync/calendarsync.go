@@ -0,0 +1,282 @@
+// Package calendarsync imports external iCalendar feeds as calendar blocks of the properties.
+package calendarsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/ical"
+)
+
+//go:generate mockgen -source calendarsync.go -destination ../mock/calendarsync.go -package mock
+
+// maxFeedSize is the maximum size of a feed, in bytes. Feeds of years of bookings are far smaller.
+const maxFeedSize = 5 << 20
+
+// ErrFetchFeed is returned when the feed of a calendar import can't be downloaded.
+var ErrFetchFeed = errors.New("failed to fetch calendar feed")
+
+// ErrFeedTooLarge is returned when a feed is larger than maxFeedSize. It is rejected instead of truncated, since the
+// blocks of the events cut off would be deleted by the sync.
+var ErrFeedTooLarge = errors.New("calendar feed is too large")
+
+// errForbiddenAddress is returned when a feed URL resolves to an address that is not public.
+var errForbiddenAddress = errors.New("address is not public")
+
+// statusError is the unexpected status code of a feed download.
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", int(e))
+}
+
+// nonPublicPrefixes are the ranges that are not reachable on the internet and that netip doesn't already classify as
+// private, loopback or link-local. 100.64.0.0/10 hosts the metadata endpoints of some cloud providers.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// isPublic reports whether ip is a public unicast address. Loopback, private, link-local, like the metadata endpoint
+// 169.254.169.254, and reserved addresses are not public.
+func isPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// dialPublicOnly is the Control of the dialer of NewClient. It runs after the DNS resolution, so it checks the address
+// actually dialed, also on redirects.
+func dialPublicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublic(ip) {
+		return errForbiddenAddress
+	}
+	return nil
+}
+
+// NewClient returns the client to download the feeds, whose URLs are supplied by the hosts. It only connects to public
+// addresses, so feeds can't reach the internal network of the server. It doesn't use the proxy of the environment, as
+// the address checked would be the one of the proxy.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: dialPublicOnly}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// FailureMessage is the message of a failed sync shown to the host of the import. The details of the failed downloads
+// describe the network of the server, so they are left out, except for the status code.
+func FailureMessage(err error) string {
+	if !errors.Is(err, ErrFetchFeed) {
+		return err.Error()
+	}
+	var status statusError
+	if errors.As(err, &status) {
+		return fmt.Sprintf("%v: %v", ErrFetchFeed, status)
+	}
+	return ErrFetchFeed.Error()
+}
+
+// readFeed reads a feed of at most maxFeedSize bytes. It returns ErrFeedTooLarge for larger feeds.
+func readFeed(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxFeedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFeedSize {
+		return nil, fmt.Errorf("%w: the limit is %d bytes", ErrFeedTooLarge, maxFeedSize)
+	}
+	return data, nil
+}
+
+// ImportRepository gathers the methods needed to sync calendar imports.
+type ImportRepository interface {
+	// CalendarImportsToSync gets the imports with a URL whose last sync attempt happened before syncedBefore
+	CalendarImportsToSync(ctx context.Context, syncedBefore time.Time) ([]reserv.CalendarImport, error)
+	// SyncCalendarImport replaces the blocks of an import and returns the blocks that conflict with bookings
+	SyncCalendarImport(ctx context.Context, imp reserv.CalendarImport, blocks []reserv.CalendarBlock) (reserv.CalendarSyncResult, error)
+	// FailCalendarImport records a failed sync attempt of an import
+	FailCalendarImport(ctx context.Context, id string, syncErr string) error
+}
+
+// Blocks converts the events of an external calendar to calendar blocks. The end of the events is exclusive,
+// so the blocks end the day before. This way the check-out day of the external booking can be the check-in day of a local one.
+func Blocks(imp reserv.CalendarImport, events []ical.Event) []reserv.CalendarBlock {
+	blocks := make([]reserv.CalendarBlock, 0, len(events))
+	for _, event := range events {
+		reason := imp.Name
+		if event.Summary != "" {
+			reason = imp.Name + ": " + event.Summary
+		}
+		blocks = append(blocks, reserv.CalendarBlock{
+			PropertyID:  imp.PropertyID,
+			StartDate:   event.Start,
+			EndDate:     event.End.AddDate(0, 0, -1),
+			Reason:      reason,
+			ExternalUID: event.UID,
+		})
+	}
+	return blocks
+}
+
+// Fetch downloads and parses the feed at rawURL, which must be an http or https address.
+func Fetch(ctx context.Context, client *http.Client, rawURL string) ([]ical.Event, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("%w: url must be an http or https address", ErrFetchFeed)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetchFeed, err)
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetchFeed, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %w", ErrFetchFeed, statusError(resp.StatusCode))
+	}
+
+	data, err := readFeed(resp.Body)
+	if errors.Is(err, ErrFeedTooLarge) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetchFeed, err)
+	}
+	return ical.Parse(bytes.NewReader(data))
+}
+
+// Sync imports the events of a calendar import. If feed is nil, the feed is downloaded from the URL of the import.
+// Failed attempts are recorded on the import, keeping the blocks of the last successful sync.
+func Sync(ctx context.Context, repo ImportRepository, client *http.Client, imp reserv.CalendarImport, feed io.Reader) (reserv.CalendarSyncResult, error) {
+	var (
+		events []ical.Event
+		err    error
+	)
+	if feed != nil {
+		var data []byte
+		data, err = readFeed(feed)
+		if err == nil {
+			events, err = ical.Parse(bytes.NewReader(data))
+		}
+	} else {
+		events, err = Fetch(ctx, client, imp.URL)
+	}
+
+	if err != nil {
+		if failErr := repo.FailCalendarImport(ctx, imp.ID, FailureMessage(err)); failErr != nil {
+			slog.Error("failed to record calendar import failure", "error", failErr, "import_id", imp.ID)
+		}
+		return reserv.CalendarSyncResult{}, err
+	}
+
+	result, err := repo.SyncCalendarImport(ctx, imp, Blocks(imp, events))
+	if err != nil {
+		return reserv.CalendarSyncResult{}, err
+	}
+
+	for _, conflict := range result.Conflicts {
+		slog.Warn("calendar import conflicts with a booking",
+			"import_id", imp.ID,
+			"property_id", imp.PropertyID,
+			"uid", conflict.UID,
+			"booking_id", conflict.BookingID,
+		)
+	}
+	return result, nil
+}
+
+// Worker periodically syncs the calendar imports with a URL.
+type Worker struct {
+	repo     ImportRepository
+	client   *http.Client
+	interval time.Duration
+}
+
+// NewWorker creates a worker that syncs each import every interval.
+func NewWorker(repo ImportRepository, client *http.Client, interval time.Duration) *Worker {
+	return &Worker{repo: repo, client: client, interval: interval}
+}
+
+// Run syncs the imports until ctx is done. It is blocking, so it must run on its own goroutine.
+func (w *Worker) Run(ctx context.Context) {
+	slog.Info("starting calendar sync worker", "interval", w.interval)
+	// Checking for due imports more often than the interval spreads the syncs of imports created at different times.
+	ticker := time.NewTicker(w.interval / 10)
+	defer ticker.Stop()
+
+	for {
+		w.SyncDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.Info("stopping calendar sync worker")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncDue syncs the imports whose last sync attempt is older than the interval of the worker.
+func (w *Worker) SyncDue(ctx context.Context) {
+	imports, err := w.repo.CalendarImportsToSync(ctx, time.Now().UTC().Add(-w.interval))
+	if err != nil {
+		slog.Error("failed to get calendar imports to sync", "error", err)
+		return
+	}
+
+	for _, imp := range imports {
+		if ctx.Err() != nil {
+			return
+		}
+
+		result, err := Sync(ctx, w.repo, w.client, imp, nil)
+		if err != nil {
+			slog.Error("failed to sync calendar import", "error", err, "import_id", imp.ID, "property_id", imp.PropertyID)
+			continue
+		}
+		slog.Info("calendar import synced", "import_id", imp.ID, "blocked", result.Blocked, "conflicts", len(result.Conflicts))
+	}
+}
@@ -0,0 +1,149 @@
+package calendarsync_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/calendarsync"
+	"github.com/perebaj/reserv/ical"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../ical/testdata/airbnb.ics")
+	}))
+	defer srv.Close()
+
+	imp := reserv.CalendarImport{ID: "import-id", PropertyID: "property-id", Name: "Airbnb", URL: srv.URL}
+
+	repo := mock.NewMockImportRepository(ctrl)
+	conflicts := reserv.CalendarConflicts{{UID: "booking-42@other", StartDate: date(2025, 2, 1), EndDate: date(2025, 2, 3), BookingID: "booking-id"}}
+	repo.EXPECT().SyncCalendarImport(gomock.Any(), imp, gomock.Any()).DoAndReturn(func(_ context.Context, _ reserv.CalendarImport, blocks []reserv.CalendarBlock) (reserv.CalendarSyncResult, error) {
+		require.Len(t, blocks, 4)
+		// The check-out day of the external booking is not blocked.
+		require.Equal(t, reserv.CalendarBlock{
+			PropertyID:  "property-id",
+			StartDate:   date(2025, 1, 5),
+			EndDate:     date(2025, 1, 9),
+			Reason:      "Airbnb: Reserved",
+			ExternalUID: "1418fb94e984-5f2e2e07d8a0a1a0e3a8b3a8@airbnb.com",
+		}, blocks[0])
+		require.Equal(t, date(2025, 4, 1), blocks[3].StartDate)
+		require.Equal(t, date(2025, 4, 1), blocks[3].EndDate)
+		require.Equal(t, "Airbnb", blocks[3].Reason)
+		return reserv.CalendarSyncResult{Blocked: 3, Conflicts: conflicts}, nil
+	})
+
+	result, err := calendarsync.Sync(context.Background(), repo, srv.Client(), imp, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Blocked)
+	require.Equal(t, conflicts, result.Conflicts)
+}
+
+func TestSync_Rejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockImportRepository(ctrl)
+	imp := reserv.CalendarImport{ID: "import-id", PropertyID: "property-id", Name: "Airbnb"}
+
+	// Oversized and truncated feeds fail, keeping the blocks of the last sync.
+	repo.EXPECT().FailCalendarImport(gomock.Any(), "import-id", gomock.Any()).Return(nil).Times(2)
+	oversized := strings.NewReader("BEGIN:VCALENDAR\n" + strings.Repeat("X-PADDING:x\n", 1<<19) + "END:VCALENDAR\n")
+	_, err := calendarsync.Sync(context.Background(), repo, nil, imp, oversized)
+	require.ErrorIs(t, err, calendarsync.ErrFeedTooLarge)
+
+	_, err = calendarsync.Sync(context.Background(), repo, nil, imp, strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VEVENT\n"))
+	require.ErrorIs(t, err, ical.ErrInvalidCalendar)
+}
+
+func TestNewClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../ical/testdata/airbnb.ics")
+	}))
+	defer srv.Close()
+
+	// The test server listens on a loopback address, which the client refuses.
+	_, err := calendarsync.Fetch(context.Background(), calendarsync.NewClient(time.Second), srv.URL)
+	require.ErrorIs(t, err, calendarsync.ErrFetchFeed)
+	require.Equal(t, "failed to fetch calendar feed", calendarsync.FailureMessage(err))
+
+	for _, url := range []string{"http://169.254.169.254/latest/meta-data", "http://10.0.0.1/feed.ics", "http://[::1]/feed.ics"} {
+		_, err = calendarsync.Fetch(context.Background(), calendarsync.NewClient(time.Second), url)
+		require.ErrorIs(t, err, calendarsync.ErrFetchFeed, url)
+	}
+
+	_, err = calendarsync.Fetch(context.Background(), calendarsync.NewClient(time.Second), "file:///etc/passwd")
+	require.ErrorIs(t, err, calendarsync.ErrFetchFeed)
+}
+
+func TestWorker_SyncDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok.ics", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../ical/testdata/airbnb.ics")
+	})
+	mux.HandleFunc("/html", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("<html>maintenance</html>"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ok := reserv.CalendarImport{ID: "ok", PropertyID: "property-id", Name: "Airbnb", URL: srv.URL + "/ok.ics"}
+	notFound := reserv.CalendarImport{ID: "not-found", PropertyID: "property-id", Name: "Vrbo", URL: srv.URL + "/missing.ics"}
+	invalid := reserv.CalendarImport{ID: "invalid", PropertyID: "property-id", Name: "Booking", URL: srv.URL + "/html"}
+
+	repo := mock.NewMockImportRepository(ctrl)
+	interval := time.Hour
+	repo.EXPECT().CalendarImportsToSync(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, syncedBefore time.Time) ([]reserv.CalendarImport, error) {
+		require.WithinDuration(t, time.Now().Add(-interval), syncedBefore, time.Minute)
+		return []reserv.CalendarImport{notFound, ok, invalid}, nil
+	})
+	repo.EXPECT().FailCalendarImport(gomock.Any(), "not-found", gomock.Any()).DoAndReturn(func(_ context.Context, _ string, syncErr string) error {
+		require.Contains(t, syncErr, "unexpected status 404")
+		return nil
+	})
+	repo.EXPECT().FailCalendarImport(gomock.Any(), "invalid", gomock.Any()).Return(nil)
+	repo.EXPECT().SyncCalendarImport(gomock.Any(), ok, gomock.Any()).Return(reserv.CalendarSyncResult{Blocked: 4}, nil)
+
+	calendarsync.NewWorker(repo, srv.Client(), interval).SyncDue(context.Background())
+}
+
+func TestWorker_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockImportRepository(ctrl)
+	repo.EXPECT().CalendarImportsToSync(gomock.Any(), gomock.Any()).Return(nil, nil).MinTimes(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		calendarsync.NewWorker(repo, http.DefaultClient, time.Hour).Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker didn't stop after the context was cancelled")
+	}
+}
@@ -0,0 +1,185 @@
+package reserv
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidCancellationPolicy is returned when the cancellation policy of a property has invalid fields.
+var ErrInvalidCancellationPolicy = errors.New("invalid cancellation policy")
+
+// CancellationPolicyKind is the name of a cancellation policy.
+type CancellationPolicyKind string
+
+const (
+	// CancellationFlexible gives a full refund until the day before the check-in.
+	CancellationFlexible CancellationPolicyKind = "flexible"
+	// CancellationModerate gives a full refund until 5 days before the check-in and half of it after that.
+	CancellationModerate CancellationPolicyKind = "moderate"
+	// CancellationStrict gives a full refund until 14 days before the check-in and half of it until 7 days before.
+	CancellationStrict CancellationPolicyKind = "strict"
+	// CancellationCustom is a policy where the host chooses all the deadlines and refund percentages.
+	CancellationCustom CancellationPolicyKind = "custom"
+)
+
+// RefundTier is a deadline of a cancellation policy. Guests cancelling at least DaysBeforeCheckIn days before the
+// check-in date get RefundPercent of the total price back.
+type RefundTier struct {
+	DaysBeforeCheckIn int `json:"days_before_check_in"`
+	RefundPercent     int `json:"refund_percent"`
+}
+
+// RefundTiers is a list of refund tiers stored as JSON.
+type RefundTiers []RefundTier
+
+// Value implements driver.Valuer.
+func (t RefundTiers) Value() (driver.Value, error) {
+	if t == nil {
+		t = RefundTiers{}
+	}
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal refund tiers: %v", err)
+	}
+	return b, nil
+}
+
+// Scan implements sql.Scanner.
+func (t *RefundTiers) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*t = RefundTiers{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for refund tiers: %T", src)
+	}
+	if err := json.Unmarshal(b, t); err != nil {
+		return fmt.Errorf("failed to unmarshal refund tiers: %v", err)
+	}
+	return nil
+}
+
+// cancellationPresets are the tiers of the predefined policies.
+var cancellationPresets = map[CancellationPolicyKind]RefundTiers{
+	CancellationFlexible: {{DaysBeforeCheckIn: 1, RefundPercent: 100}},
+	CancellationModerate: {{DaysBeforeCheckIn: 5, RefundPercent: 100}, {DaysBeforeCheckIn: 0, RefundPercent: 50}},
+	CancellationStrict:   {{DaysBeforeCheckIn: 14, RefundPercent: 100}, {DaysBeforeCheckIn: 7, RefundPercent: 50}},
+}
+
+// PresetRefundTiers returns the tiers of a predefined policy. It returns nil for custom or unknown policies.
+func PresetRefundTiers(kind CancellationPolicyKind) RefundTiers {
+	preset := cancellationPresets[kind]
+	if preset == nil {
+		return nil
+	}
+	return append(RefundTiers{}, preset...)
+}
+
+// CancellationPolicy is the policy of a property that decides how much of the price guests get back when they cancel.
+type CancellationPolicy struct {
+	// PropertyID is the id of the property.
+	PropertyID string `json:"property_id" db:"property_id"`
+	// Kind is the name of the policy. The tiers of predefined policies can still be changed by the host.
+	Kind CancellationPolicyKind `json:"kind" db:"kind"`
+	// Tiers are the deadlines of the policy. Cancellations that don't reach any deadline get no refund.
+	Tiers RefundTiers `json:"tiers" db:"tiers"`
+	// UpdatedAt is the timestamp when the policy was updated. It is zero for properties using the default policy.
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultCancellationPolicy returns the policy of a property whose host never configured it.
+func DefaultCancellationPolicy(propertyID string) CancellationPolicy {
+	return CancellationPolicy{
+		PropertyID: propertyID,
+		Kind:       CancellationFlexible,
+		Tiers:      PresetRefundTiers(CancellationFlexible),
+	}
+}
+
+// Validate checks the fields of the policy. The returned error wraps ErrInvalidCancellationPolicy.
+func (p CancellationPolicy) Validate() error {
+	if _, ok := cancellationPresets[p.Kind]; !ok && p.Kind != CancellationCustom {
+		return fmt.Errorf("%w: kind must be flexible, moderate, strict or custom", ErrInvalidCancellationPolicy)
+	}
+
+	if len(p.Tiers) == 0 {
+		return fmt.Errorf("%w: at least one tier is required", ErrInvalidCancellationPolicy)
+	}
+
+	seen := make(map[int]bool, len(p.Tiers))
+	for _, tier := range p.Tiers {
+		if tier.DaysBeforeCheckIn < 0 || tier.DaysBeforeCheckIn > MaxBookingHorizonDays {
+			return fmt.Errorf("%w: days_before_check_in must be between 0 and %d", ErrInvalidCancellationPolicy, MaxBookingHorizonDays)
+		}
+		if tier.RefundPercent < 0 || tier.RefundPercent > 100 {
+			return fmt.Errorf("%w: refund_percent must be between 0 and 100", ErrInvalidCancellationPolicy)
+		}
+		if seen[tier.DaysBeforeCheckIn] {
+			return fmt.Errorf("%w: duplicated tier for %d days before check-in", ErrInvalidCancellationPolicy, tier.DaysBeforeCheckIn)
+		}
+		seen[tier.DaysBeforeCheckIn] = true
+	}
+
+	return nil
+}
+
+// Refund is the amount a guest gets back when a booking is cancelled.
+type Refund struct {
+	// Policy is the cancellation policy used to compute the refund.
+	Policy CancellationPolicyKind `json:"policy"`
+	// DaysBeforeCheckIn is the number of days between the cancellation and the check-in date.
+	DaysBeforeCheckIn int `json:"days_before_check_in"`
+	// RefundPercent is the share of the total price given back to the guest.
+	RefundPercent int `json:"refund_percent"`
+	// RefundCents is the amount given back to the guest, rounded down to the minor unit of the currency.
+	RefundCents int64 `json:"refund_cents"`
+	// TotalPriceCents and Currency are the price paid for the booking.
+	TotalPriceCents int64  `json:"total_price_cents"`
+	Currency        string `json:"currency"`
+}
+
+// Refund computes how much of the booking price the guest gets back if the booking is cancelled today. Cancellations
+// made by the host and bookings the host never confirmed are always fully refunded. Otherwise, the guest gets the
+// best tier whose deadline is not over yet. Today must be in UTC at midnight, like the stay dates.
+func (p CancellationPolicy) Refund(booking Booking, byHost bool, today time.Time) Refund {
+	refund := Refund{
+		Policy:            p.Kind,
+		DaysBeforeCheckIn: Nights(today, booking.CheckInDate),
+		TotalPriceCents:   booking.TotalPriceCents,
+		Currency:          booking.Currency,
+	}
+
+	if byHost || booking.Status == BookingStatusPending {
+		refund.RefundPercent = 100
+	} else {
+		for _, tier := range p.Tiers {
+			if refund.DaysBeforeCheckIn >= tier.DaysBeforeCheckIn && tier.RefundPercent > refund.RefundPercent {
+				refund.RefundPercent = tier.RefundPercent
+			}
+		}
+	}
+
+	// The percent is between 0 and 100, so the share can't overflow.
+	amount, _ := booking.Total().Percent(refund.RefundPercent)
+	refund.RefundCents = amount.Amount
+	return refund
+}
+
+// AllowsDateChange reports whether the guest can still change the dates of a booking today: cancelling it would still
+// give the best refund of the policy. Later changes are refused, otherwise moving the check-in away would raise the
+// refund of a cancellation whose deadline already passed.
+func (p CancellationPolicy) AllowsDateChange(booking Booking, today time.Time) bool {
+	best := 0
+	for _, tier := range p.Tiers {
+		best = max(best, tier.RefundPercent)
+	}
+	return p.Refund(booking, false, today).RefundPercent >= best
+}
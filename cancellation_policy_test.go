@@ -0,0 +1,109 @@
+package reserv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancellationPolicy_Validate(t *testing.T) {
+	require.NoError(t, DefaultCancellationPolicy("property-id").Validate())
+
+	tests := []struct {
+		name   string
+		policy CancellationPolicy
+	}{
+		{name: "unknown kind", policy: CancellationPolicy{Kind: "lenient", Tiers: RefundTiers{{1, 100}}}},
+		{name: "no tiers", policy: CancellationPolicy{Kind: CancellationCustom}},
+		{name: "negative days", policy: CancellationPolicy{Kind: CancellationCustom, Tiers: RefundTiers{{-1, 100}}}},
+		{name: "percent above 100", policy: CancellationPolicy{Kind: CancellationCustom, Tiers: RefundTiers{{1, 101}}}},
+		{name: "duplicated days", policy: CancellationPolicy{Kind: CancellationCustom, Tiers: RefundTiers{{1, 100}, {1, 50}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, tt.policy.Validate(), ErrInvalidCancellationPolicy)
+		})
+	}
+}
+
+func TestCancellationPolicy_Refund(t *testing.T) {
+	today := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	booking := func(daysAhead int) Booking {
+		return Booking{
+			Status:          BookingStatusConfirmed,
+			CheckInDate:     today.AddDate(0, 0, daysAhead),
+			CheckOutDate:    today.AddDate(0, 0, daysAhead+3),
+			TotalPriceCents: 30001,
+			Currency:        "USD",
+		}
+	}
+
+	tests := []struct {
+		name        string
+		kind        CancellationPolicyKind
+		booking     Booking
+		byHost      bool
+		wantPercent int
+		wantCents   int64
+	}{
+		{name: "flexible before the deadline", kind: CancellationFlexible, booking: booking(1), wantPercent: 100, wantCents: 30001},
+		{name: "flexible on the check-in day", kind: CancellationFlexible, booking: booking(0), wantPercent: 0, wantCents: 0},
+		{name: "moderate before the deadline", kind: CancellationModerate, booking: booking(5), wantPercent: 100, wantCents: 30001},
+		{name: "moderate after the deadline", kind: CancellationModerate, booking: booking(4), wantPercent: 50, wantCents: 15000},
+		{name: "strict half refund", kind: CancellationStrict, booking: booking(10), wantPercent: 50, wantCents: 15000},
+		{name: "strict no refund", kind: CancellationStrict, booking: booking(6), wantPercent: 0, wantCents: 0},
+		{name: "host cancellation", kind: CancellationStrict, booking: booking(1), byHost: true, wantPercent: 100, wantCents: 30001},
+		{
+			name:        "pending booking",
+			kind:        CancellationStrict,
+			booking:     Booking{Status: BookingStatusPending, CheckInDate: today, TotalPriceCents: 100},
+			wantPercent: 100,
+			wantCents:   100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := CancellationPolicy{Kind: tt.kind, Tiers: PresetRefundTiers(tt.kind)}
+			refund := policy.Refund(tt.booking, tt.byHost, today)
+			require.Equal(t, tt.kind, refund.Policy)
+			require.Equal(t, tt.wantPercent, refund.RefundPercent)
+			require.Equal(t, tt.wantCents, refund.RefundCents)
+			require.Equal(t, tt.booking.TotalPriceCents, refund.TotalPriceCents)
+		})
+	}
+}
+
+func TestCancellationPolicy_AllowsDateChange(t *testing.T) {
+	today := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	booking := Booking{Status: BookingStatusConfirmed, CheckInDate: today.AddDate(0, 0, 14), TotalPriceCents: 30000, Currency: "USD"}
+	strict := CancellationPolicy{Kind: CancellationStrict, Tiers: PresetRefundTiers(CancellationStrict)}
+
+	require.True(t, strict.AllowsDateChange(booking, today))
+	// Past the full refund deadline, a change would turn a half refund into a full one.
+	require.False(t, strict.AllowsDateChange(booking, today.AddDate(0, 0, 1)))
+
+	booking.Status = BookingStatusPending
+	require.True(t, strict.AllowsDateChange(booking, today.AddDate(0, 0, 10)))
+
+	// Policies without a full refund allow changes while their best tier applies.
+	custom := CancellationPolicy{Kind: CancellationCustom, Tiers: RefundTiers{{DaysBeforeCheckIn: 7, RefundPercent: 80}}}
+	booking.Status = BookingStatusConfirmed
+	require.True(t, custom.AllowsDateChange(booking, today))
+	require.False(t, custom.AllowsDateChange(booking, today.AddDate(0, 0, 8)))
+}
+
+func TestRefundTiers_ValueScan(t *testing.T) {
+	tiers := RefundTiers{{DaysBeforeCheckIn: 7, RefundPercent: 50}}
+	v, err := tiers.Value()
+	require.NoError(t, err)
+
+	var got RefundTiers
+	require.NoError(t, got.Scan(v))
+	require.Equal(t, tiers, got)
+
+	require.NoError(t, got.Scan(nil))
+	require.Equal(t, RefundTiers{}, got)
+}
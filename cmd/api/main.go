@@ -9,14 +9,22 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/clerk/clerk-sdk-go/v2"
 	"github.com/cloudflare/cloudflare-go"
 	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/bookingrequests"
+	"github.com/perebaj/reserv/calendarsync"
 	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/holds"
+	"github.com/perebaj/reserv/payments"
+	"github.com/perebaj/reserv/payouts"
 	"github.com/perebaj/reserv/postgres"
+	"github.com/perebaj/reserv/settlements"
 )
 
 // Config gathers all the configuration for the application.
@@ -31,6 +39,34 @@ type Config struct {
 	CloudFlareAPIKey string
 	// ClerkAPIKey is the private key for the Clerk API.
 	ClerkAPIKey string
+	// CalendarSyncInterval is how often the external calendars of the properties are synced.
+	CalendarSyncInterval time.Duration
+	// BookingHoldTTL is how long a booking hold keeps the dates reserved.
+	BookingHoldTTL time.Duration
+	// BookingHoldExpiryInterval is how often the expired booking holds are deleted.
+	BookingHoldExpiryInterval time.Duration
+	// BookingRequestDeadline is how long the hosts have to answer a booking request.
+	BookingRequestDeadline time.Duration
+	// BookingRequestExpiryInterval is how often the unanswered booking requests are declined.
+	BookingRequestExpiryInterval time.Duration
+	// PaymentProvider is the payment provider used to charge the guests. Available values are: fake. Empty disables payments.
+	PaymentProvider string
+	// PaymentWebhookSecret is the secret that signs the webhooks of the payment provider.
+	PaymentWebhookSecret string
+	// PaymentSettlementInterval is how often the authorized payments of the decided bookings are captured or voided again.
+	PaymentSettlementInterval time.Duration
+	// PublicURL is the URL where the API is reachable. The fake payment provider sends its webhooks there.
+	PublicURL string
+	// ServiceFeePercent is the fee of the platform added to the price of the stays.
+	ServiceFeePercent int
+	// AdminIDs are the Clerk user ids of the platform admins.
+	AdminIDs []string
+	// PayoutProvider is the payout provider that pays the hosts. Available values are: fake. Empty disables payouts.
+	PayoutProvider string
+	// PayoutDelayDays is how many days after the check-in the hosts are paid.
+	PayoutDelayDays int
+	// PayoutInterval is how often the payouts are scheduled and paid.
+	PayoutInterval time.Duration
 }
 
 func main() {
@@ -42,7 +78,80 @@ func main() {
 		LogFormat:        getEnvWithDefault("LOG_FORMAT", "json"),
 		CloudFlareAPIKey: getEnvWithDefault("CLOUDFLARE_API_KEY", ""),
 		// ClerkAPIKey is the private key for the Clerk API.
-		ClerkAPIKey: getEnvWithDefault("CLERK_API_KEY", ""),
+		ClerkAPIKey:          getEnvWithDefault("CLERK_API_KEY", ""),
+		PaymentProvider:      getEnvWithDefault("PAYMENT_PROVIDER", ""),
+		PaymentWebhookSecret: getEnvWithDefault("PAYMENT_WEBHOOK_SECRET", ""),
+		PublicURL:            getEnvWithDefault("PUBLIC_URL", ""),
+		PayoutProvider:       getEnvWithDefault("PAYOUT_PROVIDER", ""),
+	}
+
+	calendarSyncInterval, err := time.ParseDuration(getEnvWithDefault("CALENDAR_SYNC_INTERVAL", "30m"))
+	if err != nil || calendarSyncInterval < time.Minute {
+		slog.Error("CALENDAR_SYNC_INTERVAL must be a duration of at least 1m", "error", err)
+		os.Exit(1)
+	}
+	cfg.CalendarSyncInterval = calendarSyncInterval
+
+	bookingHoldTTL, err := time.ParseDuration(getEnvWithDefault("BOOKING_HOLD_TTL", reserv.DefaultBookingHoldTTL.String()))
+	if err != nil || bookingHoldTTL < reserv.MinBookingHoldTTL || bookingHoldTTL > reserv.MaxBookingHoldTTL {
+		slog.Error("BOOKING_HOLD_TTL must be a duration between 1m and 1h", "error", err)
+		os.Exit(1)
+	}
+	cfg.BookingHoldTTL = bookingHoldTTL
+
+	bookingHoldExpiryInterval, err := time.ParseDuration(getEnvWithDefault("BOOKING_HOLD_EXPIRY_INTERVAL", "1m"))
+	if err != nil || bookingHoldExpiryInterval < time.Second {
+		slog.Error("BOOKING_HOLD_EXPIRY_INTERVAL must be a duration of at least 1s", "error", err)
+		os.Exit(1)
+	}
+	cfg.BookingHoldExpiryInterval = bookingHoldExpiryInterval
+
+	bookingRequestDeadline, err := time.ParseDuration(getEnvWithDefault("BOOKING_REQUEST_DEADLINE", reserv.DefaultBookingRequestDeadline.String()))
+	if err != nil || bookingRequestDeadline < reserv.MinBookingRequestDeadline || bookingRequestDeadline > reserv.MaxBookingRequestDeadline {
+		slog.Error("BOOKING_REQUEST_DEADLINE must be a duration between 1h and 168h", "error", err)
+		os.Exit(1)
+	}
+	cfg.BookingRequestDeadline = bookingRequestDeadline
+
+	bookingRequestExpiryInterval, err := time.ParseDuration(getEnvWithDefault("BOOKING_REQUEST_EXPIRY_INTERVAL", "5m"))
+	if err != nil || bookingRequestExpiryInterval < time.Second {
+		slog.Error("BOOKING_REQUEST_EXPIRY_INTERVAL must be a duration of at least 1s", "error", err)
+		os.Exit(1)
+	}
+	cfg.BookingRequestExpiryInterval = bookingRequestExpiryInterval
+
+	serviceFeePercent, err := strconv.Atoi(getEnvWithDefault("SERVICE_FEE_PERCENT", "0"))
+	if err != nil || serviceFeePercent < 0 || serviceFeePercent > reserv.MaxServiceFeePercent {
+		slog.Error("SERVICE_FEE_PERCENT must be a number between 0 and 30", "error", err)
+		os.Exit(1)
+	}
+	cfg.ServiceFeePercent = serviceFeePercent
+
+	payoutDelayDays, err := strconv.Atoi(getEnvWithDefault("PAYOUT_DELAY_DAYS", strconv.Itoa(reserv.DefaultPayoutDelayDays)))
+	if err != nil || payoutDelayDays < 0 || payoutDelayDays > reserv.MaxPayoutDelayDays {
+		slog.Error("PAYOUT_DELAY_DAYS must be a number between 0 and 60", "error", err)
+		os.Exit(1)
+	}
+	cfg.PayoutDelayDays = payoutDelayDays
+
+	payoutInterval, err := time.ParseDuration(getEnvWithDefault("PAYOUT_INTERVAL", "1h"))
+	if err != nil || payoutInterval < time.Second {
+		slog.Error("PAYOUT_INTERVAL must be a duration of at least 1s", "error", err)
+		os.Exit(1)
+	}
+	cfg.PayoutInterval = payoutInterval
+
+	paymentSettlementInterval, err := time.ParseDuration(getEnvWithDefault("PAYMENT_SETTLEMENT_INTERVAL", "10m"))
+	if err != nil || paymentSettlementInterval < time.Second {
+		slog.Error("PAYMENT_SETTLEMENT_INTERVAL must be a duration of at least 1s", "error", err)
+		os.Exit(1)
+	}
+	cfg.PaymentSettlementInterval = paymentSettlementInterval
+
+	for _, id := range strings.Split(getEnvWithDefault("ADMIN_USER_IDS", ""), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			cfg.AdminIDs = append(cfg.AdminIDs, id)
+		}
 	}
 
 	if cfg.PostgresURL == "" || cfg.CloudFlareAPIKey == "" || cfg.ClerkAPIKey == "" {
@@ -86,8 +195,47 @@ func main() {
 		os.Exit(1)
 	}
 
+	var paymentProvider handler.PaymentProvider
+	switch cfg.PaymentProvider {
+	case "":
+		slog.Warn("PAYMENT_PROVIDER is not set, payments are disabled")
+	case payments.FakeProviderName:
+		if cfg.PaymentWebhookSecret == "" {
+			slog.Error("PAYMENT_WEBHOOK_SECRET is not set")
+			os.Exit(1)
+		}
+		fake := payments.NewFake(cfg.PaymentWebhookSecret)
+		if cfg.PublicURL != "" {
+			fake.WebhookURL = cfg.PublicURL + "/payments/webhook"
+		}
+		paymentProvider = fake
+	default:
+		slog.Error("unknown PAYMENT_PROVIDER", "payment_provider", cfg.PaymentProvider)
+		os.Exit(1)
+	}
+
+	var payoutProvider payouts.PayoutProvider
+	switch cfg.PayoutProvider {
+	case "":
+		slog.Warn("PAYOUT_PROVIDER is not set, payouts are disabled")
+	case payments.FakeProviderName:
+		// The fake payment provider pays the hosts too, so the cash of the ledger goes in and out of the same provider.
+		if fake, ok := paymentProvider.(*payments.Fake); ok {
+			payoutProvider = fake
+		} else {
+			payoutProvider = payments.NewFake(cfg.PaymentWebhookSecret)
+		}
+	default:
+		slog.Error("unknown PAYOUT_PROVIDER", "payout_provider", cfg.PayoutProvider)
+		os.Exit(1)
+	}
+
 	// TODO(@perebaj): Duplicating the repo object to turn easy on testing. But this is not a ideal solution.
-	handler := handler.NewHandler(repo, cloudFlareClient, repo)
+	handler := handler.NewHandler(repo, cloudFlareClient, repo, paymentProvider)
+	handler.HoldTTL = cfg.BookingHoldTTL
+	handler.RequestDeadline = cfg.BookingRequestDeadline
+	handler.ServiceFeePercent = cfg.ServiceFeePercent
+	handler.AdminIDs = cfg.AdminIDs
 
 	mux := http.NewServeMux()
 	handler.RegisterRoutes(mux)
@@ -109,7 +257,7 @@ func main() {
 	cors := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 			w.Header().Set("Access-Control-Allow-Headers", "X-CSRF-Token, X-Requested-With, Accept, Accept-Version, Content-Length, Content-MD5, Content-Type, Date, X-Api-Version, Authorization")
 
 			if r.Method == "OPTIONS" {
@@ -125,6 +273,50 @@ func main() {
 		Handler: cors(mux),
 	}
 
+	// Background workers run until workersCtx is cancelled on shutdown. The WaitGroup waits for them to finish their
+	// current iteration, so no work is interrupted in the middle of a transaction.
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+
+	calendarSyncWorker := calendarsync.NewWorker(repo, calendarsync.NewClient(30*time.Second), cfg.CalendarSyncInterval)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		calendarSyncWorker.Run(workersCtx)
+	}()
+
+	holdExpiryWorker := holds.NewWorker(repo, cfg.BookingHoldExpiryInterval)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		holdExpiryWorker.Run(workersCtx)
+	}()
+
+	requestExpiryWorker := bookingrequests.NewWorker(repo, cfg.BookingRequestExpiryInterval)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		requestExpiryWorker.Run(workersCtx)
+	}()
+
+	if paymentProvider != nil {
+		settlementWorker := settlements.NewWorker(repo, paymentProvider, cfg.PaymentSettlementInterval)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			settlementWorker.Run(workersCtx)
+		}()
+	}
+
+	if payoutProvider != nil {
+		payoutWorker := payouts.NewWorker(repo, payoutProvider, cfg.PayoutDelayDays, cfg.PayoutInterval)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			payoutWorker.Run(workersCtx)
+		}()
+	}
+
 	slog.Info("starting server", "address", srv.Addr)
 	// serverErrors is a channel to receive errors from the server.
 	// It is buffered to avoid blocking the goroutine that starts the server.
@@ -154,6 +346,10 @@ func main() {
 			}
 		}
 	}
+
+	slog.Info("stopping background workers")
+	stopWorkers()
+	workers.Wait()
 }
 
 func getEnvWithDefault(key, defaultValue string) string {
@@ -0,0 +1,66 @@
+// Package main refreshes the exchange-rate table from a CSV or JSON file.
+//
+// Usage: POSTGRES_URL=... go run ./cmd/exchangerates -file rates.csv
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/perebaj/reserv/exchangerates"
+	"github.com/perebaj/reserv/postgres"
+)
+
+func main() {
+	file := flag.String("file", "", "path of the exchange rates file, .csv or .json")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*file); err != nil {
+		slog.Error("failed to refresh exchange rates", "error", err, "file", *file)
+		os.Exit(1)
+	}
+}
+
+func run(file string) error {
+	postgresURL := os.Getenv("POSTGRES_URL")
+	if postgresURL == "" {
+		return errors.New("POSTGRES_URL is not set")
+	}
+
+	rates, err := exchangerates.Load(file, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	db, err := postgres.OpenDB(postgres.Config{URL: postgresURL})
+	if err != nil {
+		return fmt.Errorf("failed to open db: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	if err := postgres.Migrate(db.DB); err != nil {
+		return fmt.Errorf("failed to migrate db: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := postgres.NewRepository(db).ReplaceExchangeRates(ctx, rates); err != nil {
+		return err
+	}
+
+	slog.Info("exchange rates refreshed", "count", len(rates), "file", file)
+	return nil
+}
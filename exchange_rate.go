@@ -0,0 +1,208 @@
+package reserv
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ErrExchangeRateNotFound is returned when there is no rate to convert between two currencies.
+var ErrExchangeRateNotFound = errors.New("exchange rate not found")
+
+// ErrInvalidExchangeRate is returned when an exchange rate has invalid currencies or a rate that is not a positive number.
+var ErrInvalidExchangeRate = errors.New("invalid exchange rate")
+
+// ExchangeRate is how many units of the Quote currency one unit of the Base currency buys, in major units.
+// Example: {Base: "USD", Quote: "BRL", Rate: "5.25"} means 1 dollar is 5.25 reais.
+type ExchangeRate struct {
+	Base  string `json:"base" db:"base_currency"`
+	Quote string `json:"quote" db:"quote_currency"`
+	// Rate is a positive decimal number. It is kept as text, so no precision is lost to floating point.
+	Rate string `json:"rate" db:"rate"`
+	// UpdatedAt is when the rate was loaded.
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Validate checks the currencies and the rate. The returned error wraps ErrInvalidExchangeRate.
+func (r ExchangeRate) Validate() error {
+	if err := ValidateCurrency(r.Base); err != nil {
+		return fmt.Errorf("%w: base %v", ErrInvalidExchangeRate, err)
+	}
+	if err := ValidateCurrency(r.Quote); err != nil {
+		return fmt.Errorf("%w: quote %v", ErrInvalidExchangeRate, err)
+	}
+	if r.Base == r.Quote {
+		return fmt.Errorf("%w: base and quote are both %s", ErrInvalidExchangeRate, r.Base)
+	}
+	if _, err := r.rat(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r ExchangeRate) rat() (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(r.Rate)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: rate %q of %s/%s must be a positive number", ErrInvalidExchangeRate, r.Rate, r.Base, r.Quote)
+	}
+	return rate, nil
+}
+
+// currencyPair is a base and a quote currency.
+type currencyPair struct {
+	base, quote string
+}
+
+// ExchangeRates is a table of exchange rates used to convert amounts between currencies.
+type ExchangeRates struct {
+	rates map[currencyPair]*big.Rat
+	// UpdatedAt is when the oldest rate of the table was loaded.
+	UpdatedAt time.Time
+}
+
+// NewExchangeRates builds a table from a list of rates. It returns an error wrapping ErrInvalidExchangeRate if a rate is
+// invalid or a pair of currencies is repeated.
+func NewExchangeRates(rates []ExchangeRate) (ExchangeRates, error) {
+	table := ExchangeRates{rates: make(map[currencyPair]*big.Rat, len(rates))}
+	for _, r := range rates {
+		if err := r.Validate(); err != nil {
+			return ExchangeRates{}, err
+		}
+
+		pair := currencyPair{base: r.Base, quote: r.Quote}
+		if _, ok := table.rates[pair]; ok {
+			return ExchangeRates{}, fmt.Errorf("%w: duplicated rate for %s/%s", ErrInvalidExchangeRate, r.Base, r.Quote)
+		}
+
+		table.rates[pair], _ = r.rat()
+		if table.UpdatedAt.IsZero() || r.UpdatedAt.Before(table.UpdatedAt) {
+			table.UpdatedAt = r.UpdatedAt
+		}
+	}
+	return table, nil
+}
+
+// Rate returns how many units of to one unit of from buys, in major units. It uses, in order: the direct rate, the
+// inverse of the opposite rate, or a cross rate through a base currency that has rates to both. Rate files usually
+// have a single base currency, so the cross rate is how two non-base currencies are converted.
+func (e ExchangeRates) Rate(from, to string) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	if rate, ok := e.rates[currencyPair{base: from, quote: to}]; ok {
+		return new(big.Rat).Set(rate), nil
+	}
+
+	if rate, ok := e.rates[currencyPair{base: to, quote: from}]; ok {
+		return new(big.Rat).Inv(rate), nil
+	}
+
+	// When more than one base currency has both rates, the first in alphabetical order is used, so the result
+	// doesn't depend on the order of the map.
+	var base string
+	for pair := range e.rates {
+		if pair.quote != to || (base != "" && pair.base > base) {
+			continue
+		}
+		if _, ok := e.rates[currencyPair{base: pair.base, quote: from}]; ok {
+			base = pair.base
+		}
+	}
+	if base == "" {
+		return nil, fmt.Errorf("%w: %s to %s", ErrExchangeRateNotFound, from, to)
+	}
+	return new(big.Rat).Quo(e.rates[currencyPair{base: base, quote: to}], e.rates[currencyPair{base: base, quote: from}]), nil
+}
+
+// Convert returns m in the currency to. The exact converted value is rounded to the minor unit of to, half away from
+// zero: 0.5 of a minor unit or more goes up, less goes down, and negative amounts round symmetrically.
+// Example: 10.00 USD at 5.12345 BRL is 51.2345 BRL, rounded to 51.23 BRL.
+func (e ExchangeRates) Convert(m Money, to string) (Money, error) {
+	if err := ValidateCurrency(to); err != nil {
+		return Money{}, err
+	}
+
+	rate, err := e.Rate(m.Currency, to)
+	if err != nil {
+		return Money{}, err
+	}
+
+	// The amount is in minor units of m.Currency, so it is scaled by the difference of the minor units of the currencies.
+	exact := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), rate)
+	shift := MinorUnits(to) - MinorUnits(m.Currency)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(shift, -shift))), nil))
+	if shift > 0 {
+		exact.Mul(exact, scale)
+	} else {
+		exact.Quo(exact, scale)
+	}
+
+	amount := roundHalfAwayFromZero(exact)
+	if !amount.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s in %s", ErrAmountOverflow, m, to)
+	}
+	return Money{Amount: amount.Int64(), Currency: to}, nil
+}
+
+func roundHalfAwayFromZero(x *big.Rat) *big.Int {
+	num := new(big.Int).Abs(x.Num())
+	quo, rem := new(big.Int).QuoRem(num, x.Denom(), new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(x.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if x.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	return quo
+}
+
+// DisplayPrice is the price of a property converted to a currency chosen by the guest. It is only informative:
+// quotes and bookings are always in the currency of the property.
+type DisplayPrice struct {
+	// Currency is the currency the prices were converted to.
+	Currency string `json:"currency"`
+	// PricePerNightCents, ExtraGuestFeeCents and CleaningFeeCents are the prices of the property converted to Currency,
+	// in its minor unit.
+	PricePerNightCents int64 `json:"price_per_night_cents"`
+	ExtraGuestFeeCents int64 `json:"extra_guest_fee_cents"`
+	CleaningFeeCents   int64 `json:"cleaning_fee_cents"`
+	// Rate is how many units of Currency one unit of the property currency buys, with 6 decimal places.
+	Rate string `json:"rate"`
+	// RatesUpdatedAt is when the exchange rates were loaded.
+	RatesUpdatedAt time.Time `json:"rates_updated_at"`
+}
+
+// NewDisplayPrice converts the prices of a property to currency. Each price is converted and rounded on its own, see
+// ExchangeRates.Convert.
+func NewDisplayPrice(property Property, rates ExchangeRates, currency string) (DisplayPrice, error) {
+	price, err := rates.Convert(Money{Amount: property.PricePerNightCents, Currency: property.Currency}, currency)
+	if err != nil {
+		return DisplayPrice{}, err
+	}
+
+	fee, err := rates.Convert(Money{Amount: property.ExtraGuestFeeCents, Currency: property.Currency}, currency)
+	if err != nil {
+		return DisplayPrice{}, err
+	}
+
+	cleaning, err := rates.Convert(Money{Amount: property.CleaningFeeCents, Currency: property.Currency}, currency)
+	if err != nil {
+		return DisplayPrice{}, err
+	}
+
+	rate, err := rates.Rate(property.Currency, currency)
+	if err != nil {
+		return DisplayPrice{}, err
+	}
+
+	return DisplayPrice{
+		Currency:           currency,
+		PricePerNightCents: price.Amount,
+		ExtraGuestFeeCents: fee.Amount,
+		CleaningFeeCents:   cleaning.Amount,
+		Rate:               rate.FloatString(6),
+		RatesUpdatedAt:     rates.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package reserv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testExchangeRates(t *testing.T) ExchangeRates {
+	rates, err := NewExchangeRates([]ExchangeRate{
+		{Base: "USD", Quote: "BRL", Rate: "5.12345", UpdatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Base: "USD", Quote: "EUR", Rate: "0.8", UpdatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Base: "USD", Quote: "JPY", Rate: "150.5", UpdatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Base: "USD", Quote: "KWD", Rate: "0.3075", UpdatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+	})
+	require.NoError(t, err)
+	return rates
+}
+
+func TestNewExchangeRates(t *testing.T) {
+	rates := testExchangeRates(t)
+	require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), rates.UpdatedAt)
+
+	invalid := []ExchangeRate{
+		{Base: "usd", Quote: "BRL", Rate: "5"},
+		{Base: "USD", Quote: "dollar", Rate: "5"},
+		{Base: "USD", Quote: "USD", Rate: "1"},
+		{Base: "USD", Quote: "BRL", Rate: "0"},
+		{Base: "USD", Quote: "BRL", Rate: "-5"},
+		{Base: "USD", Quote: "BRL", Rate: "five"},
+	}
+	for _, rate := range invalid {
+		_, err := NewExchangeRates([]ExchangeRate{rate})
+		require.ErrorIs(t, err, ErrInvalidExchangeRate, "%+v", rate)
+	}
+
+	_, err := NewExchangeRates([]ExchangeRate{{Base: "USD", Quote: "BRL", Rate: "5"}, {Base: "USD", Quote: "BRL", Rate: "6"}})
+	require.ErrorIs(t, err, ErrInvalidExchangeRate)
+}
+
+func TestExchangeRates_Rate(t *testing.T) {
+	rates := testExchangeRates(t)
+
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{from: "USD", to: "USD", want: "1.000000"},
+		{from: "USD", to: "BRL", want: "5.123450"},
+		{from: "EUR", to: "USD", want: "1.250000"},
+		{from: "EUR", to: "BRL", want: "6.404313"},
+	}
+
+	for _, tt := range tests {
+		rate, err := rates.Rate(tt.from, tt.to)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, rate.FloatString(6), "%s to %s", tt.from, tt.to)
+	}
+
+	_, err := rates.Rate("USD", "GBP")
+	require.ErrorIs(t, err, ErrExchangeRateNotFound)
+}
+
+func TestExchangeRates_Convert(t *testing.T) {
+	rates := testExchangeRates(t)
+
+	tests := []struct {
+		name  string
+		money Money
+		to    string
+		want  Money
+	}{
+		{name: "rounds down below half a cent", money: Money{Amount: 1000, Currency: "USD"}, to: "BRL", want: Money{Amount: 5123, Currency: "BRL"}},
+		{name: "rounds up above half a cent", money: Money{Amount: 1999, Currency: "USD"}, to: "BRL", want: Money{Amount: 10242, Currency: "BRL"}},
+		{name: "exact half rounds up", money: Money{Amount: 2, Currency: "EUR"}, to: "USD", want: Money{Amount: 3, Currency: "USD"}},
+		{name: "negative half rounds away from zero", money: Money{Amount: -2, Currency: "EUR"}, to: "USD", want: Money{Amount: -3, Currency: "USD"}},
+		{name: "cross rate", money: Money{Amount: 1000, Currency: "EUR"}, to: "BRL", want: Money{Amount: 6404, Currency: "BRL"}},
+		{name: "to a currency without minor unit", money: Money{Amount: 1050, Currency: "USD"}, to: "JPY", want: Money{Amount: 1580, Currency: "JPY"}},
+		{name: "from a currency without minor unit", money: Money{Amount: 1505, Currency: "JPY"}, to: "USD", want: Money{Amount: 1000, Currency: "USD"}},
+		{name: "to a currency with 3 digits", money: Money{Amount: 10000, Currency: "USD"}, to: "KWD", want: Money{Amount: 30750, Currency: "KWD"}},
+		{name: "same currency", money: Money{Amount: 1234, Currency: "USD"}, to: "USD", want: Money{Amount: 1234, Currency: "USD"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rates.Convert(tt.money, tt.to)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := rates.Convert(Money{Amount: 1000, Currency: "USD"}, "brl")
+	require.ErrorIs(t, err, ErrInvalidCurrency)
+
+	_, err = rates.Convert(Money{Amount: 1000, Currency: "USD"}, "GBP")
+	require.ErrorIs(t, err, ErrExchangeRateNotFound)
+}
+
+func TestNewDisplayPrice(t *testing.T) {
+	rates := testExchangeRates(t)
+	property := Property{PricePerNightCents: 10000, ExtraGuestFeeCents: 1999, CleaningFeeCents: 5000, Currency: "USD"}
+
+	got, err := NewDisplayPrice(property, rates, "BRL")
+	require.NoError(t, err)
+	require.Equal(t, DisplayPrice{
+		Currency:           "BRL",
+		PricePerNightCents: 51235,
+		ExtraGuestFeeCents: 10242,
+		CleaningFeeCents:   25617,
+		Rate:               "5.123450",
+		RatesUpdatedAt:     rates.UpdatedAt,
+	}, got)
+
+	_, err = NewDisplayPrice(property, rates, "GBP")
+	require.ErrorIs(t, err, ErrExchangeRateNotFound)
+}
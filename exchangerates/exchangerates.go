@@ -0,0 +1,105 @@
+// Package exchangerates loads the exchange-rate table from CSV and JSON files.
+package exchangerates
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// ErrUnsupportedFormat is returned when a file is neither .csv nor .json.
+var ErrUnsupportedFormat = errors.New("unsupported exchange rates file format, expected .csv or .json")
+
+// Load reads the exchange rates from a CSV or JSON file, chosen by its extension. See ParseCSV and ParseJSON for the
+// formats. The rates are marked as updated at now and validated with reserv.NewExchangeRates.
+func Load(path string, now time.Time) ([]reserv.ExchangeRate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open exchange rates file: %v", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var rates []reserv.ExchangeRate
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		rates, err = ParseCSV(f, now)
+	case ".json":
+		rates, err = ParseJSON(f, now)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := reserv.NewExchangeRates(rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// ParseCSV reads exchange rates with a base,quote,rate header and one rate per line. Example:
+//
+//	base,quote,rate
+//	USD,BRL,5.4321
+//	USD,EUR,0.9187
+func ParseCSV(r io.Reader, now time.Time) ([]reserv.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates header: %v", err)
+	}
+	if strings.Join(header, ",") != "base,quote,rate" {
+		return nil, fmt.Errorf("invalid exchange rates header %q, expected base,quote,rate", strings.Join(header, ","))
+	}
+
+	var rates []reserv.ExchangeRate
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read exchange rates: %v", err)
+		}
+		rates = append(rates, reserv.ExchangeRate{Base: record[0], Quote: record[1], Rate: record[2], UpdatedAt: now})
+	}
+}
+
+// jsonRates is the JSON format of the exchange rates.
+type jsonRates struct {
+	Base  string                 `json:"base"`
+	Rates map[string]json.Number `json:"rates"`
+}
+
+// ParseJSON reads exchange rates from a single base currency to many quote currencies. The rates can be numbers or
+// strings. Example:
+//
+//	{"base": "USD", "rates": {"BRL": 5.4321, "EUR": "0.9187"}}
+func ParseJSON(r io.Reader, now time.Time) ([]reserv.ExchangeRate, error) {
+	var file jsonRates
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode exchange rates: %v", err)
+	}
+
+	rates := make([]reserv.ExchangeRate, 0, len(file.Rates))
+	for quote, rate := range file.Rates {
+		rates = append(rates, reserv.ExchangeRate{Base: file.Base, Quote: quote, Rate: rate.String(), UpdatedAt: now})
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Quote < rates[j].Quote })
+	return rates, nil
+}
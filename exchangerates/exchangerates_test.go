@@ -0,0 +1,72 @@
+package exchangerates_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/exchangerates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rates, err := exchangerates.ParseCSV(strings.NewReader("base,quote,rate\nUSD,BRL,5.4321\nUSD, EUR, 0.9187\n"), now)
+	require.NoError(t, err)
+	require.Equal(t, []reserv.ExchangeRate{
+		{Base: "USD", Quote: "BRL", Rate: "5.4321", UpdatedAt: now},
+		{Base: "USD", Quote: "EUR", Rate: "0.9187", UpdatedAt: now},
+	}, rates)
+
+	_, err = exchangerates.ParseCSV(strings.NewReader("from,to,rate\nUSD,BRL,5.4321\n"), now)
+	require.Error(t, err)
+
+	_, err = exchangerates.ParseCSV(strings.NewReader("base,quote,rate\nUSD,BRL\n"), now)
+	require.Error(t, err)
+}
+
+func TestParseJSON(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rates, err := exchangerates.ParseJSON(strings.NewReader(`{"base": "USD", "rates": {"EUR": "0.9187", "BRL": 5.4321}}`), now)
+	require.NoError(t, err)
+	require.Equal(t, []reserv.ExchangeRate{
+		{Base: "USD", Quote: "BRL", Rate: "5.4321", UpdatedAt: now},
+		{Base: "USD", Quote: "EUR", Rate: "0.9187", UpdatedAt: now},
+	}, rates)
+
+	_, err = exchangerates.ParseJSON(strings.NewReader(`{"base": "USD", "rates": {"BRL": "five"}}`), now)
+	require.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	rates, err := exchangerates.Load(write("rates.csv", "base,quote,rate\nUSD,BRL,5.4321\n"), now)
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+
+	rates, err = exchangerates.Load(write("rates.JSON", `{"base": "USD", "rates": {"BRL": 5.4321}}`), now)
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+
+	_, err = exchangerates.Load(write("rates.txt", "USD BRL 5.4321"), now)
+	require.ErrorIs(t, err, exchangerates.ErrUnsupportedFormat)
+
+	_, err = exchangerates.Load(write("invalid.csv", "base,quote,rate\nusd,BRL,5.4321\n"), now)
+	require.ErrorIs(t, err, reserv.ErrInvalidExchangeRate)
+
+	_, err = exchangerates.Load(filepath.Join(dir, "missing.csv"), now)
+	require.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package reserv
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidFees is returned when the fees of a property are invalid.
+	ErrInvalidFees = errors.New("invalid property fees")
+	// ErrInvalidTaxRule is returned when a tax rule has invalid fields.
+	ErrInvalidTaxRule = errors.New("invalid tax rule")
+	// ErrTaxRuleNotFound is returned when a tax rule doesn't exist.
+	ErrTaxRuleNotFound = errors.New("tax rule not found")
+)
+
+// MaxServiceFeePercent is the highest service fee the platform can charge on top of a stay.
+const MaxServiceFeePercent = 30
+
+// jurisdictionPattern matches codes like "US", "US-NY" or "US-NY-NYC": upper case letters or digits, separated by hyphens.
+var jurisdictionPattern = regexp.MustCompile(`^[A-Z0-9]+(-[A-Z0-9]+)*$`)
+
+// ValidateJurisdiction checks that code is a tax jurisdiction code. Codes start with the ISO 3166-1 country, followed
+// by the subdivisions from the largest to the smallest, like "US-NY-NYC" for New York City.
+func ValidateJurisdiction(code string) error {
+	if !jurisdictionPattern.MatchString(code) {
+		return fmt.Errorf("jurisdiction %q must be upper case codes separated by hyphens, like \"US-NY-NYC\"", code)
+	}
+	return nil
+}
+
+// ValidateFees checks the fee fields of the property. The returned error wraps ErrInvalidFees.
+func (p Property) ValidateFees() error {
+	if p.CleaningFeeCents < 0 {
+		return fmt.Errorf("%w: cleaning_fee_cents must not be negative", ErrInvalidFees)
+	}
+
+	if p.Jurisdiction != "" {
+		if err := ValidateJurisdiction(p.Jurisdiction); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidFees, err)
+		}
+	}
+
+	return nil
+}
+
+// TaxRule is an occupancy tax of a jurisdiction. It is charged on the stay and the cleaning fee, after discounts.
+type TaxRule struct {
+	ID string `json:"id" db:"id"`
+	// Jurisdiction is the code of the place where the tax is charged. Example: "US-NY-NYC". The tax applies to the
+	// properties of the jurisdiction and of its subdivisions.
+	Jurisdiction string `json:"jurisdiction" db:"jurisdiction"`
+	// Name is the name of the tax shown to the guests. Example: "NYC hotel room occupancy tax".
+	Name string `json:"name" db:"name"`
+	// RateBasisPoints is the rate of the tax in hundredths of a percent. Example: 1475 is 14.75%.
+	RateBasisPoints int       `json:"rate_basis_points" db:"rate_basis_points"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Validate checks the fields of the tax rule. The returned error wraps ErrInvalidTaxRule.
+func (t TaxRule) Validate() error {
+	if err := ValidateJurisdiction(t.Jurisdiction); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTaxRule, err)
+	}
+
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTaxRule)
+	}
+
+	if t.RateBasisPoints < 1 || t.RateBasisPoints > 10000 {
+		return fmt.Errorf("%w: rate_basis_points must be between 1 and 10000", ErrInvalidTaxRule)
+	}
+
+	return nil
+}
+
+// AppliesTo reports whether the tax is charged in the jurisdiction. Taxes of a jurisdiction apply to all of its
+// subdivisions, so a rule of "US-NY" applies to "US-NY" and "US-NY-NYC", but not to "US-NYX".
+func (t TaxRule) AppliesTo(jurisdiction string) bool {
+	return jurisdiction != "" && (jurisdiction == t.Jurisdiction || strings.HasPrefix(jurisdiction, t.Jurisdiction+"-"))
+}
+
+// formatBasisPoints formats a rate in basis points as a percentage. Example: "14.75%".
+func formatBasisPoints(bps int) string {
+	if bps%100 == 0 {
+		return fmt.Sprintf("%d%%", bps/100)
+	}
+	return strings.TrimRight(fmt.Sprintf("%d.%02d", bps/100, bps%100), "0") + "%"
+}
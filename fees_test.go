@@ -0,0 +1,61 @@
+package reserv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateJurisdiction(t *testing.T) {
+	for _, code := range []string{"US", "US-NY", "US-NY-NYC", "BR-RJ-3304557"} {
+		require.NoError(t, ValidateJurisdiction(code), code)
+	}
+
+	for _, code := range []string{"", "us-ny", "US-", "-US", "US--NY", "US NY"} {
+		require.Error(t, ValidateJurisdiction(code), code)
+	}
+}
+
+func TestProperty_ValidateFees(t *testing.T) {
+	require.NoError(t, Property{}.ValidateFees())
+	require.NoError(t, Property{CleaningFeeCents: 5000, Jurisdiction: "US-NY-NYC"}.ValidateFees())
+	require.ErrorIs(t, Property{CleaningFeeCents: -1}.ValidateFees(), ErrInvalidFees)
+	require.ErrorIs(t, Property{Jurisdiction: "new york"}.ValidateFees(), ErrInvalidFees)
+}
+
+func TestTaxRule_Validate(t *testing.T) {
+	valid := TaxRule{Jurisdiction: "US-NY", Name: "NY sales tax", RateBasisPoints: 400}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name string
+		rule TaxRule
+	}{
+		{name: "invalid jurisdiction", rule: TaxRule{Jurisdiction: "ny", Name: "NY sales tax", RateBasisPoints: 400}},
+		{name: "missing name", rule: TaxRule{Jurisdiction: "US-NY", Name: " ", RateBasisPoints: 400}},
+		{name: "zero rate", rule: TaxRule{Jurisdiction: "US-NY", Name: "NY sales tax"}},
+		{name: "rate above 100%", rule: TaxRule{Jurisdiction: "US-NY", Name: "NY sales tax", RateBasisPoints: 10001}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, tt.rule.Validate(), ErrInvalidTaxRule)
+		})
+	}
+}
+
+func TestTaxRule_AppliesTo(t *testing.T) {
+	rule := TaxRule{Jurisdiction: "US-NY"}
+	require.True(t, rule.AppliesTo("US-NY"))
+	require.True(t, rule.AppliesTo("US-NY-NYC"))
+	require.False(t, rule.AppliesTo("US"))
+	require.False(t, rule.AppliesTo("US-NYX"))
+	require.False(t, rule.AppliesTo(""))
+}
+
+func TestFormatBasisPoints(t *testing.T) {
+	require.Equal(t, "14.75%", formatBasisPoints(1475))
+	require.Equal(t, "5.5%", formatBasisPoints(550))
+	require.Equal(t, "0.05%", formatBasisPoints(5))
+	require.Equal(t, "4%", formatBasisPoints(400))
+}
@@ -0,0 +1,87 @@
+package reserv
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidCapacity is returned when the capacity fields of a property are invalid.
+	ErrInvalidCapacity = errors.New("invalid property capacity")
+	// ErrInvalidGuests is returned when the guest counts of a stay are invalid. Example: a stay without adults.
+	ErrInvalidGuests = errors.New("invalid guest count")
+	// ErrTooManyGuests is returned when a stay has more guests than the property accepts.
+	ErrTooManyGuests = errors.New("too many guests")
+	// ErrTooManyPets is returned when a stay has more pets than the property accepts.
+	ErrTooManyPets = errors.New("too many pets")
+)
+
+// MaxInfants is the maximum number of infants of a stay. Infants don't count towards the maximum guests of a property.
+const MaxInfants = 5
+
+// Guests is who is coming to a stay.
+type Guests struct {
+	// Adults is the number of guests aged 13 or above. At least one adult is required.
+	Adults int `json:"adults" db:"adults"`
+	// Children is the number of guests aged 2 to 12.
+	Children int `json:"children" db:"children"`
+	// Infants is the number of guests under 2. They don't count towards the maximum guests of a property.
+	Infants int `json:"infants" db:"infants"`
+	// Pets is the number of pets.
+	Pets int `json:"pets" db:"pets"`
+}
+
+// Count returns the number of guests that count towards the maximum guests of a property: adults and children.
+func (g Guests) Count() int {
+	return g.Adults + g.Children
+}
+
+// ValidateCapacity checks the capacity fields of the property. The returned error wraps ErrInvalidCapacity.
+func (p Property) ValidateCapacity() error {
+	if p.MaxGuests < 1 {
+		return fmt.Errorf("%w: max_guests must be at least 1", ErrInvalidCapacity)
+	}
+
+	if p.Bedrooms < 0 || p.Beds < 0 || p.Bathrooms < 0 || p.MaxPets < 0 {
+		return fmt.Errorf("%w: bedrooms, beds, bathrooms and max_pets must not be negative", ErrInvalidCapacity)
+	}
+
+	if p.GuestsIncluded < 1 || p.GuestsIncluded > p.MaxGuests {
+		return fmt.Errorf("%w: guests_included must be between 1 and max_guests", ErrInvalidCapacity)
+	}
+
+	if p.ExtraGuestFeeCents < 0 {
+		return fmt.Errorf("%w: extra_guest_fee_cents must not be negative", ErrInvalidCapacity)
+	}
+
+	return nil
+}
+
+// CheckGuests validates the guests of a stay against the capacity of the property.
+func (p Property) CheckGuests(g Guests) error {
+	if g.Adults < 1 || g.Children < 0 || g.Infants < 0 || g.Pets < 0 {
+		return fmt.Errorf("%w: at least one adult is required and counts must not be negative", ErrInvalidGuests)
+	}
+
+	if g.Count() > p.MaxGuests {
+		return fmt.Errorf("%w: the property accepts at most %d guests", ErrTooManyGuests, p.MaxGuests)
+	}
+
+	if g.Infants > MaxInfants {
+		return fmt.Errorf("%w: at most %d infants are accepted", ErrTooManyGuests, MaxInfants)
+	}
+
+	if g.Pets > p.MaxPets {
+		return fmt.Errorf("%w: the property accepts at most %d pets", ErrTooManyPets, p.MaxPets)
+	}
+
+	return nil
+}
+
+// ExtraGuests returns how many guests of the stay are above the guests included in the nightly price of the property.
+func (p Property) ExtraGuests(g Guests) int {
+	if extra := g.Count() - p.GuestsIncluded; extra > 0 {
+		return extra
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package reserv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProperty_ValidateCapacity(t *testing.T) {
+	valid := Property{MaxGuests: 4, Bedrooms: 2, Beds: 2, Bathrooms: 1, GuestsIncluded: 2, ExtraGuestFeeCents: 1000}
+	require.NoError(t, valid.ValidateCapacity())
+
+	tests := []struct {
+		name   string
+		modify func(p *Property)
+	}{
+		{name: "no guests", modify: func(p *Property) { p.MaxGuests = 0 }},
+		{name: "negative beds", modify: func(p *Property) { p.Beds = -1 }},
+		{name: "negative pets", modify: func(p *Property) { p.MaxPets = -1 }},
+		{name: "no guests included", modify: func(p *Property) { p.GuestsIncluded = 0 }},
+		{name: "guests included above max", modify: func(p *Property) { p.GuestsIncluded = 5 }},
+		{name: "negative fee", modify: func(p *Property) { p.ExtraGuestFeeCents = -1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			property := valid
+			tt.modify(&property)
+			require.ErrorIs(t, property.ValidateCapacity(), ErrInvalidCapacity)
+		})
+	}
+}
+
+func TestProperty_CheckGuests(t *testing.T) {
+	property := Property{MaxGuests: 4, MaxPets: 1}
+
+	tests := []struct {
+		name    string
+		guests  Guests
+		wantErr error
+	}{
+		{name: "full house", guests: Guests{Adults: 2, Children: 2, Infants: 2, Pets: 1}},
+		{name: "no adults", guests: Guests{Children: 2}, wantErr: ErrInvalidGuests},
+		{name: "negative children", guests: Guests{Adults: 1, Children: -1}, wantErr: ErrInvalidGuests},
+		{name: "too many guests", guests: Guests{Adults: 3, Children: 2}, wantErr: ErrTooManyGuests},
+		{name: "too many infants", guests: Guests{Adults: 1, Infants: MaxInfants + 1}, wantErr: ErrTooManyGuests},
+		{name: "too many pets", guests: Guests{Adults: 1, Pets: 2}, wantErr: ErrTooManyPets},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := property.CheckGuests(tt.guests)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// GetAvailabilityHandler returns the day by day availability calendar of a property.
+// The calendar never carries who booked the property, so anonymous users can call it.
+// Usage: GET /properties/{id}/availability?from=2025-01-01&to=2025-01-31
+func (h *Handler) GetAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	propertyID := r.PathValue("id")
+	if propertyID == "" {
+		NewAPIError("missing_property_id", "missing property id", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	slog.Info("get availability", "property_id", propertyID, "from", from, "to", to)
+	if from == "" || to == "" {
+		NewAPIError("missing_required_fields", "from and to are required", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	fromDate, err := time.Parse(dateFormat, from)
+	if err != nil {
+		slog.Warn("invalid date format", "error", err, "date", from)
+		NewAPIError("invalid_date_format", "invalid date format. Expected YYYY-MM-DD", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	toDate, err := time.Parse(dateFormat, to)
+	if err != nil {
+		slog.Warn("invalid date format", "error", err, "date", to)
+		NewAPIError("invalid_date_format", "invalid date format. Expected YYYY-MM-DD", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	if err := reserv.ValidateDateRange(fromDate, toDate); err != nil {
+		NewAPIError("invalid_date_range", "to must not be before from and the range can't be longer than 366 days", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	affected, property, err := h.repo.GetProperty(r.Context(), propertyID)
+	if err != nil {
+		slog.Error("failed to get property", "error", err)
+		NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if affected == 0 {
+		NewAPIError("property_not_found", "property not found", http.StatusNotFound).Write(w)
+		return
+	}
+
+	rules, err := h.repo.PricingRules(r.Context(), propertyID)
+	if err != nil {
+		slog.Error("failed to get pricing rules", "error", err)
+		NewAPIError("get_pricing_rules_error", "failed to get pricing rules", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	occupancy, err := h.bookingRepo.Occupancy(r.Context(), propertyID, fromDate, toDate)
+	if err != nil {
+		slog.Error("failed to get occupancy", "error", err)
+		NewAPIError("get_availability_error", "failed to get availability", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	calendar := reserv.Calendar(property, rules, fromDate, toDate, today(), occupancy)
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(calendar)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetAvailabilityHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+	bookingRepo := mock.NewMockBookingRepository(ctrl)
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year()+1, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 4)
+
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, PricePerNightCents: 10000, Currency: "USD"}, nil)
+	repo.EXPECT().PricingRules(gomock.Any(), propertyID.String()).Return(nil, nil)
+	bookingRepo.EXPECT().Occupancy(gomock.Any(), propertyID.String(), from, to).Return([]reserv.Occupancy{
+		{Kind: reserv.OccupancyBooking, StartDate: from.AddDate(0, 0, 1), EndDate: from.AddDate(0, 0, 2)},
+	}, nil)
+
+	url := "/properties/" + propertyID.String() + "/availability?from=" + from.Format("2006-01-02") + "&to=" + to.Format("2006-01-02")
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	resp := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	handler := handler.NewHandler(repo, nil, bookingRepo, nil)
+	handler.RegisterRoutes(mux)
+	mux.ServeHTTP(resp, req)
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusOK, resp.Code, rBody)
+	require.NotContains(t, rBody, "guest_id")
+
+	var calendar []reserv.CalendarDay
+	err := json.Unmarshal([]byte(rBody), &calendar)
+	require.NoError(t, err)
+	require.Len(t, calendar, 5)
+
+	var statuses []reserv.DayStatus
+	for _, day := range calendar {
+		statuses = append(statuses, day.Status)
+	}
+	require.Equal(t, []reserv.DayStatus{reserv.DayAvailable, reserv.DayBooked, reserv.DayBooked, reserv.DayAvailable, reserv.DayAvailable}, statuses)
+}
+
+func TestGetAvailabilityHandler_InvalidRange(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/properties/"+uuid.New().String()+"/availability?from=2025-01-05&to=2025-01-01", nil)
+	resp := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	handler := handler.NewHandler(nil, nil, nil, nil)
+	handler.RegisterRoutes(mux)
+	mux.ServeHTTP(resp, req)
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusBadRequest, resp.Code, rBody)
+}
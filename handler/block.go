@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// CalendarBlockRequest is the request body for creating or updating a calendar block.
+type CalendarBlockRequest struct {
+	// StartDate and EndDate are the blocked dates, both inclusive. Format: YYYY-MM-DD
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	// Reason is an optional note about the block. Example: "maintenance".
+	Reason string `json:"reason"`
+}
+
+// parse validates the request and returns the block dates.
+func (req CalendarBlockRequest) parse() (time.Time, time.Time, *APIError) {
+	if req.StartDate == "" || req.EndDate == "" {
+		return time.Time{}, time.Time{}, NewAPIError("missing_required_fields", "missing required fields", http.StatusBadRequest)
+	}
+
+	start, err := time.Parse(dateFormat, req.StartDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, NewAPIError("invalid_date_format", "invalid date format. Expected YYYY-MM-DD", http.StatusBadRequest)
+	}
+
+	end, err := time.Parse(dateFormat, req.EndDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, NewAPIError("invalid_date_format", "invalid date format. Expected YYYY-MM-DD", http.StatusBadRequest)
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, NewAPIError("invalid_date_range", "end_date must not be before start_date", http.StatusBadRequest)
+	}
+
+	return start, end, nil
+}
+
+// GetCalendarBlocksHandler lists the calendar blocks of a property. Only the host of the property can call it.
+func (h *Handler) GetCalendarBlocksHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+	slog.Info("get calendar blocks", "property_id", property.ID)
+
+	blocks, err := h.repo.CalendarBlocks(r.Context(), property.ID.String())
+	if err != nil {
+		slog.Error("failed to get calendar blocks", "error", err)
+		NewAPIError("get_calendar_blocks_error", "failed to get calendar blocks", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if blocks == nil {
+		blocks = []reserv.CalendarBlock{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(blocks)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// CreateCalendarBlockHandler blocks a date range of a property. Only the host of the property can call it.
+func (h *Handler) CreateCalendarBlockHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+
+	var req CalendarBlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Warn("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("create calendar block", "property_id", property.ID, "request", req)
+
+	start, end, apiErr := req.parse()
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	now := time.Now()
+	id, err := h.repo.CreateCalendarBlock(r.Context(), reserv.CalendarBlock{
+		PropertyID: property.ID.String(),
+		StartDate:  start,
+		EndDate:    end,
+		Reason:     req.Reason,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if errors.Is(err, reserv.ErrBookingOverlap) {
+		NewAPIError("block_overlaps_booking", "the dates overlap an existing booking", http.StatusConflict).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to create calendar block", "error", err)
+		NewAPIError("create_calendar_block_error", "failed to create calendar block", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(map[string]string{"id": id})
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// UpdateCalendarBlockHandler updates the dates and reason of a calendar block. Only the host of the property can call it.
+func (h *Handler) UpdateCalendarBlockHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+
+	blockID := r.PathValue("block_id")
+	var req CalendarBlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Warn("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("update calendar block", "property_id", property.ID, "block_id", blockID, "request", req)
+
+	start, end, apiErr := req.parse()
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	err := h.repo.UpdateCalendarBlock(r.Context(), reserv.CalendarBlock{
+		ID:         blockID,
+		PropertyID: property.ID.String(),
+		StartDate:  start,
+		EndDate:    end,
+		Reason:     req.Reason,
+		UpdatedAt:  time.Now(),
+	})
+	if errors.Is(err, reserv.ErrCalendarBlockNotFound) {
+		NewAPIError("calendar_block_not_found", "calendar block not found", http.StatusNotFound).Write(w)
+		return
+	}
+	if errors.Is(err, reserv.ErrImportedCalendarBlock) {
+		NewAPIError("imported_calendar_block", "the block was imported from an external calendar, change it there", http.StatusConflict).Write(w)
+		return
+	}
+	if errors.Is(err, reserv.ErrBookingOverlap) {
+		NewAPIError("block_overlaps_booking", "the dates overlap an existing booking", http.StatusConflict).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to update calendar block", "error", err)
+		NewAPIError("update_calendar_block_error", "failed to update calendar block", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteCalendarBlockHandler deletes a calendar block. Only the host of the property can call it.
+func (h *Handler) DeleteCalendarBlockHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+
+	blockID := r.PathValue("block_id")
+	slog.Info("delete calendar block", "property_id", property.ID, "block_id", blockID)
+
+	err := h.repo.DeleteCalendarBlock(r.Context(), property.ID.String(), blockID)
+	if errors.Is(err, reserv.ErrCalendarBlockNotFound) {
+		NewAPIError("calendar_block_not_found", "calendar block not found", http.StatusNotFound).Write(w)
+		return
+	}
+	if errors.Is(err, reserv.ErrImportedCalendarBlock) {
+		NewAPIError("imported_calendar_block", "the block was imported from an external calendar, change it there", http.StatusConflict).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete calendar block", "error", err)
+		NewAPIError("delete_calendar_block_error", "failed to delete calendar block", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
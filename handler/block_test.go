@@ -0,0 +1,138 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateCalendarBlockHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	hostID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, HostID: hostID}, nil).Times(3)
+	repo.EXPECT().CreateCalendarBlock(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, block reserv.CalendarBlock) (string, error) {
+		require.Equal(t, propertyID.String(), block.PropertyID)
+		require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), block.StartDate)
+		require.Equal(t, time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), block.EndDate)
+		require.Equal(t, "maintenance", block.Reason)
+		return "block-id", nil
+	})
+	repo.EXPECT().CreateCalendarBlock(gomock.Any(), gomock.Any()).Return("", reserv.ErrBookingOverlap)
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+
+	tests := []struct {
+		name       string
+		subject    string
+		wantStatus int
+	}{
+		{name: "host creates a block", subject: hostID, wantStatus: http.StatusCreated},
+		{name: "block overlaps a booking", subject: hostID, wantStatus: http.StatusConflict},
+		{name: "only the host can block dates", subject: "another_user", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonBody, err := json.Marshal(handler.CalendarBlockRequest{
+				StartDate: "2025-01-01",
+				EndDate:   "2025-01-03",
+				Reason:    "maintenance",
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/properties/"+propertyID.String()+"/blocks", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: tt.subject,
+				},
+			})
+			req = req.WithContext(ctx)
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+		})
+	}
+}
+
+func TestDeleteCalendarBlockHandler_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	hostID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, HostID: hostID}, nil)
+	repo.EXPECT().DeleteCalendarBlock(gomock.Any(), propertyID.String(), "block-id").Return(reserv.ErrCalendarBlockNotFound)
+
+	req := httptest.NewRequest(http.MethodDelete, "/properties/"+propertyID.String()+"/blocks/block-id", nil)
+	req.Header.Set("Authorization", "Bearer test_token")
+	ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+		RegisteredClaims: clerk.RegisteredClaims{
+			Subject: hostID,
+		},
+	})
+	req = req.WithContext(ctx)
+
+	resp := httptest.NewRecorder()
+	mux := http.NewServeMux()
+	handler := handler.NewHandler(repo, nil, nil, nil)
+	handler.RegisterRoutes(mux)
+	mux.ServeHTTP(resp, req)
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusNotFound, resp.Code, rBody)
+}
+
+func TestDeleteCalendarBlockHandler_Imported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	hostID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, HostID: hostID}, nil)
+	repo.EXPECT().DeleteCalendarBlock(gomock.Any(), propertyID.String(), "block-id").Return(reserv.ErrImportedCalendarBlock)
+
+	req := httptest.NewRequest(http.MethodDelete, "/properties/"+propertyID.String()+"/blocks/block-id", nil)
+	req.Header.Set("Authorization", "Bearer test_token")
+	ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+		RegisteredClaims: clerk.RegisteredClaims{
+			Subject: hostID,
+		},
+	})
+	req = req.WithContext(ctx)
+
+	resp := httptest.NewRecorder()
+	mux := http.NewServeMux()
+	handler := handler.NewHandler(repo, nil, nil, nil)
+	handler.RegisterRoutes(mux)
+	mux.ServeHTTP(resp, req)
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusConflict, resp.Code, rBody)
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
@@ -16,9 +18,78 @@ import (
 // BookingRepository is the repository for the booking. Gathers all the methods to interact with the booking.
 type BookingRepository interface {
 	CreateBooking(ctx context.Context, booking reserv.Booking) (string, error)
-	DeleteBooking(ctx context.Context, id string) error
+	UpdateBookingStatus(ctx context.Context, id string, from, to reserv.BookingStatus) error
+	// CancelBooking moves a booking to a cancelled status and stores the refund given back to the guest. The entry of
+	// the refund owed to the guest, when not nil, is posted to the ledger in the same transaction.
+	CancelBooking(ctx context.Context, id string, from, to reserv.BookingStatus, refundCents int64, entry *reserv.JournalEntry) error
+	// ChangeBookingDates moves a booking to new dates and price and appends the change to its history.
+	ChangeBookingDates(ctx context.Context, change reserv.BookingChange) (reserv.BookingChange, error)
+	// BookingChanges returns the history of date changes of a booking, oldest first.
+	BookingChanges(ctx context.Context, bookingID string) ([]reserv.BookingChange, error)
 	GetBooking(ctx context.Context, id string) (int, reserv.Booking, error)
 	Bookings(ctx context.Context, filter reserv.BookingFilter) ([]reserv.Booking, error)
+	// GetBookingsByHostID returns the bookings of all the properties of a host.
+	GetBookingsByHostID(ctx context.Context, filter reserv.HostBookingFilter) ([]reserv.Booking, error)
+	// Occupancy returns the date ranges where the property can't be booked, without exposing who booked it.
+	Occupancy(ctx context.Context, propertyID string, from, to time.Time) ([]reserv.Occupancy, error)
+	// CreateBookingHold holds the dates of a stay for a guest. It returns reserv.ErrBookingOverlap if the dates are not free.
+	CreateBookingHold(ctx context.Context, hold reserv.BookingHold) (string, error)
+
+	// Payment methods
+	// CreatePayment stores a payment of a booking. It returns reserv.ErrPaymentExists if the booking already has one.
+	CreatePayment(ctx context.Context, payment reserv.Payment) (string, error)
+	// PaymentByBookingID gets the latest payment of a booking. It returns reserv.ErrPaymentNotFound if there is none.
+	PaymentByBookingID(ctx context.Context, bookingID string) (reserv.Payment, error)
+	// PaymentByProviderID gets a payment by its id in the payment provider. It returns reserv.ErrPaymentNotFound if it doesn't exist.
+	PaymentByProviderID(ctx context.Context, provider, providerPaymentID string) (reserv.Payment, error)
+	// UpdatePayment stores the status and refund of a payment still in the status from. The entry of the money moved
+	// by the payment, when not nil, is posted to the ledger in the same transaction.
+	UpdatePayment(ctx context.Context, payment reserv.Payment, from reserv.PaymentStatus, entry *reserv.JournalEntry) error
+
+	// Ledger methods
+	// HostBalances returns what the platform owes a host, one balance per currency.
+	HostBalances(ctx context.Context, hostID string) ([]reserv.HostBalance, error)
+	// Reconciliation builds the reconciliation report of the ledger at now.
+	Reconciliation(ctx context.Context, now time.Time) (reserv.ReconciliationReport, error)
+
+	// Payout methods
+	// HostPayouts returns the payouts of a host with their items, the newest first.
+	HostPayouts(ctx context.Context, hostID string) ([]reserv.Payout, error)
+	// HostStatement returns the bookings of a host paid out in the month that starts at month.
+	HostStatement(ctx context.Context, hostID string, month time.Time) ([]reserv.StatementLine, error)
+
+	// Messaging methods
+	// CreateThread creates a thread and returns it, or returns the thread that the booking or the inquiry already has.
+	// The bool reports whether the thread was created.
+	CreateThread(ctx context.Context, thread reserv.Thread) (reserv.Thread, bool, error)
+	// GetThread returns a thread. It returns reserv.ErrThreadNotFound if the thread doesn't exist.
+	GetThread(ctx context.Context, id string) (reserv.Thread, error)
+	// Threads returns a page of the threads of a user, plus one, the most recent message first.
+	Threads(ctx context.Context, userID string, page reserv.Page) ([]reserv.Thread, error)
+	// CreateMessage stores a message in its thread and returns it with its id.
+	CreateMessage(ctx context.Context, message reserv.Message) (reserv.Message, error)
+	// Messages returns a page of the messages of a thread, plus one, the newest first.
+	Messages(ctx context.Context, threadID string, page reserv.Page) ([]reserv.Message, error)
+	// MarkThreadRead marks the messages of a thread sent to readerID as read and returns how many were unread.
+	MarkThreadRead(ctx context.Context, threadID, readerID string, now time.Time) (int64, error)
+
+	// Review methods
+	// CreateReview stores the review of a booking. It returns reserv.ErrReviewExists if the booking already has one.
+	CreateReview(ctx context.Context, review reserv.Review) (string, error)
+	// PropertyReviews returns a page of the reviews of a property, plus one, the newest first.
+	PropertyReviews(ctx context.Context, propertyID string, page reserv.Page) ([]reserv.Review, error)
+
+	// Promotion methods
+	// CreatePromotion creates a promotion. It returns reserv.ErrPromoCodeExists if another promotion already uses the code.
+	CreatePromotion(ctx context.Context, promotion reserv.Promotion) (string, error)
+	// UpdatePromotion updates a promotion, except its redemptions. It returns reserv.ErrPromotionNotFound if it doesn't exist.
+	UpdatePromotion(ctx context.Context, promotion reserv.Promotion) error
+	// GetPromotion gets a promotion by id. It returns reserv.ErrPromotionNotFound if it doesn't exist.
+	GetPromotion(ctx context.Context, id string) (reserv.Promotion, error)
+	// PromotionByCode gets the promotion of a code, in any case. It returns reserv.ErrPromotionNotFound if it doesn't exist.
+	PromotionByCode(ctx context.Context, code string) (reserv.Promotion, error)
+	// Promotions returns all the promotions, the newest first.
+	Promotions(ctx context.Context) ([]reserv.Promotion, error)
 }
 
 // CreateBooking is the request body for creating a booking.
@@ -30,15 +101,24 @@ type CreateBooking struct {
 	// Format: 2025-01-01T00:00:00Z
 	CheckInDate  string `json:"check_in_date"`
 	CheckOutDate string `json:"check_out_date"`
-	// TotalPriceCents is the total price of the booking in cents.
-	TotalPriceCents int `json:"total_price_cents"`
-	// Currency is the currency of the booking.
+	// TotalPriceCents is the total price of the booking in the minor unit of the currency. It must match the server quote.
+	TotalPriceCents int64 `json:"total_price_cents"`
+	// Currency is the ISO 4217 code of the currency of the booking. It must match the currency of the property.
 	Currency string `json:"currency"`
+	// Adults, Children, Infants and Pets are who is coming to the stay. Adults defaults to 1 when omitted.
+	Adults   int `json:"adults"`
+	Children int `json:"children"`
+	Infants  int `json:"infants"`
+	Pets     int `json:"pets"`
+	// PromoCode is a promo code to discount the stay, in any case. Optional.
+	PromoCode string `json:"promo_code"`
 }
 
 const dateFormat = "2006-01-02" // This is Go's way of specifying YYYY-MM-DD
 
-// CreateBookingHandler is the handler for creating a booking.
+// CreateBookingHandler is the handler for creating a booking. Bookings of properties with instant book are confirmed
+// right away, otherwise they are created as pending requests that the host must answer before the deadline.
+// A promo code is redeemed atomically with the creation of the booking, so its usage limit holds under concurrent bookings.
 func (h *Handler) CreateBookingHandler(w http.ResponseWriter, r *http.Request) {
 	claims, ok := clerk.SessionClaimsFromContext(r.Context())
 	if !ok {
@@ -80,16 +160,82 @@ func (h *Handler) CreateBookingHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	checkInDate = time.Date(checkInDate.Year(), checkInDate.Month(), checkInDate.Day(), 0, 0, 0, 0, time.UTC)
+	checkOutDate = time.Date(checkOutDate.Year(), checkOutDate.Month(), checkOutDate.Day(), 0, 0, 0, 0, time.UTC)
+
+	guests := reserv.Guests{Adults: req.Adults, Children: req.Children, Infants: req.Infants, Pets: req.Pets}
+	if guests.Adults == 0 {
+		guests.Adults = 1
+	}
+
+	promotion, apiErr := h.promotionByCode(r.Context(), req.PromoCode)
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	// The client price is only accepted if it matches the server quote, otherwise the guest could book any property for 0 cents.
+	quote, apiErr := h.quoteStay(r.Context(), req.PropertyID, checkInDate, checkOutDate, guests, promotion)
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	if apiErr := h.checkBookingRules(r.Context(), req.PropertyID, checkInDate, checkOutDate); apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	if err := reserv.ValidateCurrency(req.Currency); err != nil {
+		NewAPIError("invalid_currency", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	if price := (reserv.Money{Amount: req.TotalPriceCents, Currency: req.Currency}); price != quote.Total() {
+		slog.Warn("price mismatch", "price", price, "quote_price", quote.Total())
+		NewAPIError("price_mismatch", fmt.Sprintf("price does not match the quote: expected %d %s (%s)", quote.TotalPriceCents, quote.Currency, quote.Total()), http.StatusUnprocessableEntity).Write(w)
+		return
+	}
+
 	booking := reserv.Booking{
 		PropertyID:      req.PropertyID,
 		GuestID:         req.GuestID,
-		CheckInDate:     time.Date(checkInDate.Year(), checkInDate.Month(), checkInDate.Day(), 0, 0, 0, 0, time.UTC),
-		CheckOutDate:    time.Date(checkOutDate.Year(), checkOutDate.Month(), checkOutDate.Day(), 0, 0, 0, 0, time.UTC),
-		TotalPriceCents: req.TotalPriceCents,
-		Currency:        req.Currency,
+		CheckInDate:     checkInDate,
+		CheckOutDate:    checkOutDate,
+		Guests:          guests,
+		TotalPriceCents: quote.TotalPriceCents,
+		Currency:        quote.Currency,
+		LineItems:       quote.Lines,
+		Status:          reserv.BookingStatusConfirmed,
+	}
+
+	if promotion != nil {
+		booking.PromotionID = &promotion.ID
+	}
+
+	// Without instant book, the booking is a request that holds the dates until the host answers it or the deadline passes.
+	if !quote.InstantBook {
+		respondBy := time.Now().UTC().Add(h.RequestDeadline)
+		booking.Status = reserv.BookingStatusPending
+		booking.RespondBy = &respondBy
 	}
 
 	id, err := h.bookingRepo.CreateBooking(r.Context(), booking)
+	if errors.Is(err, reserv.ErrBookingOverlap) {
+		slog.Warn("booking overlaps", "property_id", booking.PropertyID)
+		NewAPIError("booking_overlaps", "the property is already booked for the requested dates", http.StatusConflict).Write(w)
+		return
+	}
+	if apiErr := bookingRuleError(err); apiErr != nil {
+		slog.Warn("booking breaks the booking rules", "error", err, "property_id", booking.PropertyID)
+		apiErr.Write(w)
+		return
+	}
+	if errors.Is(err, reserv.ErrPromotionUnavailable) {
+		slog.Warn("promotion redeemed concurrently", "error", err, "promotion_id", promotion.ID)
+		NewAPIError("promo_code_unavailable", err.Error(), http.StatusUnprocessableEntity).Write(w)
+		return
+	}
 	if err != nil {
 		slog.Error("failed to create booking", "error", err)
 		NewAPIError("failed_to_create_booking", "failed to create booking", http.StatusInternalServerError).Write(w)
@@ -97,7 +243,7 @@ func (h *Handler) CreateBookingHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	err = json.NewEncoder(w).Encode(map[string]string{"id": id})
+	err = json.NewEncoder(w).Encode(map[string]string{"id": id, "status": string(booking.Status)})
 	if err != nil {
 		slog.Error("failed to encode response", "error", err)
 		NewAPIError("failed_to_encode_response", "failed to encode response", http.StatusInternalServerError).Write(w)
@@ -105,7 +251,8 @@ func (h *Handler) CreateBookingHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// GetBookingHandler is the handler for getting a booking by id.
+// GetBookingHandler is the handler for getting a booking by id. The booking comes with its line items, so the
+// receipts and payouts can be reconciled with the price.
 func (h *Handler) GetBookingHandler(w http.ResponseWriter, r *http.Request) {
 	slog.Info("get booking")
 	_, ok := clerk.SessionClaimsFromContext(r.Context())
@@ -190,28 +337,146 @@ func (h *Handler) BookingsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// DeleteBookingHandler is the handler for deleting a booking by id.
-func (h *Handler) DeleteBookingHandler(w http.ResponseWriter, r *http.Request) {
-	_, ok := clerk.SessionClaimsFromContext(r.Context())
+// bookingAction is a transition that can be requested through the /bookings/{id}/{action} endpoints.
+type bookingAction string
+
+const (
+	bookingActionCancel   bookingAction = "cancel"
+	bookingActionConfirm  bookingAction = "confirm"
+	bookingActionDecline  bookingAction = "decline"
+	bookingActionCheckIn  bookingAction = "check-in"
+	bookingActionComplete bookingAction = "complete"
+	bookingActionNoShow   bookingAction = "no-show"
+)
+
+// targetStatus returns the status that the action moves the booking to, considering who is requesting it.
+// The guest can only cancel the booking, all the other actions belong to the host of the property.
+// It returns false if the user is not allowed to perform the action.
+func (a bookingAction) targetStatus(isGuest, isHost bool) (reserv.BookingStatus, bool) {
+	switch a {
+	case bookingActionCancel:
+		if isHost {
+			return reserv.BookingStatusCancelledByHost, true
+		}
+		if isGuest {
+			return reserv.BookingStatusCancelledByGuest, true
+		}
+	case bookingActionConfirm:
+		return reserv.BookingStatusConfirmed, isHost
+	case bookingActionDecline:
+		return reserv.BookingStatusDeclined, isHost
+	case bookingActionCheckIn:
+		return reserv.BookingStatusCheckedIn, isHost
+	case bookingActionComplete:
+		return reserv.BookingStatusCompleted, isHost
+	case bookingActionNoShow:
+		return reserv.BookingStatusNoShow, isHost
+	}
+	return "", false
+}
+
+// loadBookingTransition loads the booking of the request and its property and checks that the user is a participant of
+// the booking allowed to perform the action and that the transition is allowed. It writes the error response and
+// returns false otherwise.
+func (h *Handler) loadBookingTransition(w http.ResponseWriter, r *http.Request, action bookingAction) (reserv.Booking, reserv.Property, reserv.BookingStatus, bool) {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
 	if !ok {
 		slog.Warn("unauthorized, no claims")
 		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
-		return
+		return reserv.Booking{}, reserv.Property{}, "", false
 	}
 
 	id := r.PathValue("id")
 	if id == "" {
 		NewAPIError("missing_id", "missing id", http.StatusBadRequest).Write(w)
-		return
+		return reserv.Booking{}, reserv.Property{}, "", false
 	}
-	slog.Info("delete booking", "id", id)
+	slog.Info("booking transition", "id", id, "action", action)
 
-	err := h.bookingRepo.DeleteBooking(r.Context(), id)
+	affectedRows, booking, err := h.bookingRepo.GetBooking(r.Context(), id)
 	if err != nil {
-		slog.Error("failed to delete booking", "error", err)
-		NewAPIError("failed_to_delete_booking", "failed to delete booking", http.StatusInternalServerError).Write(w)
-		return
+		slog.Error("failed to get booking", "error", err)
+		NewAPIError("failed_to_get_booking", "failed to get booking", http.StatusInternalServerError).Write(w)
+		return reserv.Booking{}, reserv.Property{}, "", false
+	}
+
+	if affectedRows == 0 {
+		NewAPIError("booking_not_found", "booking not found", http.StatusNotFound).Write(w)
+		return reserv.Booking{}, reserv.Property{}, "", false
+	}
+
+	affectedRows, property, err := h.repo.GetProperty(r.Context(), booking.PropertyID)
+	if err != nil {
+		slog.Error("failed to get property", "error", err)
+		NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError).Write(w)
+		return reserv.Booking{}, reserv.Property{}, "", false
+	}
+
+	if affectedRows == 0 {
+		NewAPIError("property_not_found", "property not found", http.StatusNotFound).Write(w)
+		return reserv.Booking{}, reserv.Property{}, "", false
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	to, allowed := action.targetStatus(claims.Subject == booking.GuestID, claims.Subject == property.HostID)
+	if !allowed {
+		slog.Warn("unauthorized, user can't perform the action", "action", action, "jwt_subject", claims.Subject)
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return reserv.Booking{}, reserv.Property{}, "", false
+	}
+
+	if !booking.Status.CanTransitionTo(to) {
+		slog.Warn("invalid booking transition", "from", booking.Status, "to", to)
+		NewAPIError("invalid_status_transition", "booking can't move from "+string(booking.Status)+" to "+string(to), http.StatusConflict).Write(w)
+		return reserv.Booking{}, reserv.Property{}, "", false
+	}
+
+	return booking, property, to, true
+}
+
+// BookingTransitionHandler returns the handler that moves a booking to the status related to the action.
+// It validates that the user is a participant of the booking and that the transition is allowed. Booking requests
+// are approved with the confirm action and declined with the decline action, both only before their deadline.
+// Cancellations have their own handler, since they also compute the refund. See CancelBookingHandler.
+func (h *Handler) BookingTransitionHandler(action bookingAction) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		booking, property, to, ok := h.loadBookingTransition(w, r, action)
+		if !ok {
+			return
+		}
+
+		if booking.RequestExpired(time.Now()) {
+			slog.Warn("booking request expired", "id", booking.ID, "respond_by", booking.RespondBy)
+			NewAPIError("booking_request_expired", "the deadline to answer the booking request has passed", http.StatusConflict).Write(w)
+			return
+		}
+
+		err := h.bookingRepo.UpdateBookingStatus(r.Context(), booking.ID, booking.Status, to)
+		if errors.Is(err, reserv.ErrInvalidBookingTransition) {
+			slog.Warn("booking status changed concurrently", "id", booking.ID)
+			NewAPIError("invalid_status_transition", "booking status changed, try again", http.StatusConflict).Write(w)
+			return
+		}
+		if err != nil {
+			slog.Error("failed to update booking status", "error", err)
+			NewAPIError("failed_to_update_booking", "failed to update booking", http.StatusInternalServerError).Write(w)
+			return
+		}
+
+		switch to {
+		case reserv.BookingStatusConfirmed:
+			h.capturePayment(r.Context(), booking, property.HostID)
+		case reserv.BookingStatusDeclined:
+			h.refundPayment(r.Context(), booking.ID, booking.TotalPriceCents)
+		}
+
+		booking.Status = to
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(booking)
+		if err != nil {
+			slog.Error("failed to encode response", "error", err)
+			NewAPIError("failed_to_encode_response", "failed to encode response", http.StatusInternalServerError).Write(w)
+			return
+		}
+	}
 }
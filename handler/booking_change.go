@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/perebaj/reserv"
+)
+
+// UpdateBookingDates is the request body for changing the dates of a booking.
+type UpdateBookingDates struct {
+	// CheckInDate and CheckOutDate are the new dates of the booking. Format: YYYY-MM-DD
+	CheckInDate  string `json:"check_in_date"`
+	CheckOutDate string `json:"check_out_date"`
+}
+
+// UpdateBookingDatesHandler changes the dates of a booking atomically, so the guest never loses the old dates before
+// getting the new ones. The stay is re-priced with the server quote. Only the guest of the booking can call it, until
+// the full refund deadline of the cancellation policy passes. Paid bookings can only move to dates of the same price,
+// since the payment holds the old one.
+// Usage: PATCH /bookings/{id}
+func (h *Handler) UpdateBookingDatesHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
+	if !ok {
+		slog.Warn("unauthorized, no claims")
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		NewAPIError("missing_id", "missing id", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	var req UpdateBookingDates
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Warn("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("update booking dates", "id", id, "request", req)
+
+	if req.CheckInDate == "" || req.CheckOutDate == "" {
+		NewAPIError("missing_required_fields", "check_in_date and check_out_date are required", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	checkInDate, err := time.Parse(dateFormat, req.CheckInDate)
+	if err != nil {
+		slog.Warn("invalid date format", "error", err, "date", req.CheckInDate)
+		NewAPIError("invalid_date_format", "invalid date format. Expected YYYY-MM-DD", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	checkOutDate, err := time.Parse(dateFormat, req.CheckOutDate)
+	if err != nil {
+		slog.Warn("invalid date format", "error", err, "date", req.CheckOutDate)
+		NewAPIError("invalid_date_format", "invalid date format. Expected YYYY-MM-DD", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	affectedRows, booking, err := h.bookingRepo.GetBooking(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to get booking", "error", err)
+		NewAPIError("failed_to_get_booking", "failed to get booking", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if affectedRows == 0 {
+		NewAPIError("booking_not_found", "booking not found", http.StatusNotFound).Write(w)
+		return
+	}
+
+	if claims.Subject != booking.GuestID {
+		slog.Warn("unauthorized, only the guest can change the booking dates", "jwt_subject", claims.Subject)
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	if !booking.Status.CanChangeDates() {
+		NewAPIError("booking_not_modifiable", "the dates of a "+string(booking.Status)+" booking can't be changed", http.StatusConflict).Write(w)
+		return
+	}
+
+	policy, err := h.repo.CancellationPolicy(r.Context(), booking.PropertyID)
+	if err != nil {
+		slog.Error("failed to get cancellation policy", "error", err)
+		NewAPIError("get_cancellation_policy_error", "failed to get cancellation policy", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if !policy.AllowsDateChange(booking, today()) {
+		NewAPIError("cancellation_deadline_passed", "the dates can't be changed after the full refund deadline of the cancellation policy", http.StatusConflict).Write(w)
+		return
+	}
+
+	// The promo code was already redeemed, so it is applied again even if it expired since, as long as it applies to the new stay.
+	var promotion *reserv.Promotion
+	if booking.PromotionID != nil {
+		found, err := h.bookingRepo.GetPromotion(r.Context(), *booking.PromotionID)
+		if err != nil {
+			slog.Error("failed to get promotion", "error", err, "promotion_id", *booking.PromotionID)
+			NewAPIError("get_promotion_error", "failed to get promotion", http.StatusInternalServerError).Write(w)
+			return
+		}
+		promotion = &found
+	}
+
+	quote, apiErr := h.quoteStay(r.Context(), booking.PropertyID, checkInDate, checkOutDate, booking.Guests, promotion)
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	if apiErr := h.checkBookingRules(r.Context(), booking.PropertyID, checkInDate, checkOutDate); apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	if h.Payments != nil {
+		payment, err := h.bookingRepo.PaymentByBookingID(r.Context(), booking.ID)
+		if err != nil && !errors.Is(err, reserv.ErrPaymentNotFound) {
+			slog.Error("failed to get payment", "error", err)
+			NewAPIError("failed_to_get_payment", "failed to get payment", http.StatusInternalServerError).Write(w)
+			return
+		}
+		if err == nil && payment.Status.IsActive() && payment.AmountCents != quote.TotalPriceCents {
+			slog.Warn("booking payment doesn't match the new price", "booking_id", booking.ID, "payment_id", payment.ID)
+			NewAPIError("booking_paid", "the booking has a payment of another amount, the new dates must have the same price", http.StatusConflict).Write(w)
+			return
+		}
+	}
+
+	change, err := h.bookingRepo.ChangeBookingDates(r.Context(), reserv.BookingChange{
+		BookingID:       booking.ID,
+		ChangedBy:       claims.Subject,
+		CheckInDate:     checkInDate,
+		CheckOutDate:    checkOutDate,
+		TotalPriceCents: quote.TotalPriceCents,
+		LineItems:       quote.Lines,
+		CreatedAt:       time.Now().UTC(),
+	})
+	if errors.Is(err, reserv.ErrBookingOverlap) {
+		slog.Warn("booking overlaps", "property_id", booking.PropertyID)
+		NewAPIError("booking_overlaps", "the property is already booked for the requested dates", http.StatusConflict).Write(w)
+		return
+	}
+	if errors.Is(err, reserv.ErrBookingNotModifiable) {
+		slog.Warn("booking status changed concurrently", "id", booking.ID)
+		NewAPIError("booking_not_modifiable", "the booking dates can't be changed anymore", http.StatusConflict).Write(w)
+		return
+	}
+	if apiErr := bookingRuleError(err); apiErr != nil {
+		slog.Warn("booking breaks the booking rules", "error", err, "property_id", booking.PropertyID)
+		apiErr.Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to change booking dates", "error", err)
+		NewAPIError("failed_to_update_booking", "failed to update booking", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	booking.CheckInDate = change.CheckInDate
+	booking.CheckOutDate = change.CheckOutDate
+	booking.TotalPriceCents = change.TotalPriceCents
+	booking.LineItems = quote.Lines
+	booking.UpdatedAt = change.CreatedAt
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(booking)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("failed_to_encode_response", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// BookingChangesHandler returns the history of date changes of a booking. Only the guest of the booking and the host
+// of the property can call it.
+// Usage: GET /bookings/{id}/changes
+func (h *Handler) BookingChangesHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
+	if !ok {
+		slog.Warn("unauthorized, no claims")
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		NewAPIError("missing_id", "missing id", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("get booking changes", "id", id)
+
+	affectedRows, booking, err := h.bookingRepo.GetBooking(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to get booking", "error", err)
+		NewAPIError("failed_to_get_booking", "failed to get booking", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if affectedRows == 0 {
+		NewAPIError("booking_not_found", "booking not found", http.StatusNotFound).Write(w)
+		return
+	}
+
+	if claims.Subject != booking.GuestID {
+		affectedRows, property, err := h.repo.GetProperty(r.Context(), booking.PropertyID)
+		if err != nil {
+			slog.Error("failed to get property", "error", err)
+			NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError).Write(w)
+			return
+		}
+
+		if affectedRows == 0 || claims.Subject != property.HostID {
+			slog.Warn("unauthorized, user is not a participant of the booking", "jwt_subject", claims.Subject)
+			NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+			return
+		}
+	}
+
+	changes, err := h.bookingRepo.BookingChanges(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to get booking changes", "error", err)
+		NewAPIError("failed_to_get_booking_changes", "failed to get booking changes", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(changes)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("failed_to_encode_response", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
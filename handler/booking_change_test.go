@@ -0,0 +1,195 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/perebaj/reserv/payments"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateBookingDatesHandler(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	checkIn, checkOut := today.AddDate(0, 0, 20), today.AddDate(0, 0, 23)
+
+	booking := reserv.Booking{
+		ID:              "123",
+		PropertyID:      "789",
+		GuestID:         "guest",
+		Status:          reserv.BookingStatusConfirmed,
+		CheckInDate:     today.AddDate(0, 0, 10),
+		CheckOutDate:    today.AddDate(0, 0, 12),
+		Guests:          reserv.Guests{Adults: 2},
+		TotalPriceCents: 20000,
+		Currency:        "USD",
+	}
+	property := reserv.Property{HostID: "host", PricePerNightCents: 10000, Currency: "USD", MaxGuests: 2, GuestsIncluded: 2}
+
+	tests := []struct {
+		name       string
+		subject    string
+		status     reserv.BookingStatus
+		policy     reserv.CancellationPolicyKind
+		payment    *reserv.Payment
+		repoErr    error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "guest changes the dates", subject: "guest", wantStatus: http.StatusOK},
+		{name: "host can't change the dates", subject: "host", wantStatus: http.StatusUnauthorized, wantCode: "unauthorized"},
+		{name: "cancelled booking", subject: "guest", status: reserv.BookingStatusCancelledByGuest, wantStatus: http.StatusConflict, wantCode: "booking_not_modifiable"},
+		{name: "full refund deadline passed", subject: "guest", policy: reserv.CancellationStrict, wantStatus: http.StatusConflict, wantCode: "cancellation_deadline_passed"},
+		{
+			name:       "paid booking moves to dates of another price",
+			subject:    "guest",
+			payment:    &reserv.Payment{ID: "payment-id", Status: reserv.PaymentStatusAuthorized, AmountCents: 20000},
+			wantStatus: http.StatusConflict,
+			wantCode:   "booking_paid",
+		},
+		{
+			name:       "paid booking moves to dates of the same price",
+			subject:    "guest",
+			payment:    &reserv.Payment{ID: "payment-id", Status: reserv.PaymentStatusCaptured, AmountCents: 30000},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "voided payment doesn't hold the price",
+			subject:    "guest",
+			payment:    &reserv.Payment{ID: "payment-id", Status: reserv.PaymentStatusVoided, AmountCents: 20000},
+			wantStatus: http.StatusOK,
+		},
+		{name: "new dates overlap", subject: "guest", repoErr: reserv.ErrBookingOverlap, wantStatus: http.StatusConflict, wantCode: "booking_overlaps"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			propertyRepo := mock.NewMockPropertyRepository(ctrl)
+
+			got := booking
+			if tt.status != "" {
+				got.Status = tt.status
+			}
+			bookingRepo.EXPECT().GetBooking(gomock.Any(), "123").Return(1, got, nil)
+
+			policy := reserv.DefaultCancellationPolicy("789")
+			if tt.policy != "" {
+				policy = reserv.CancellationPolicy{PropertyID: "789", Kind: tt.policy, Tiers: reserv.PresetRefundTiers(tt.policy)}
+			}
+			if tt.subject == "guest" && got.Status.CanChangeDates() {
+				propertyRepo.EXPECT().CancellationPolicy(gomock.Any(), "789").Return(policy, nil)
+			}
+			if tt.subject == "guest" && got.Status.CanChangeDates() && tt.policy == "" {
+				propertyRepo.EXPECT().GetProperty(gomock.Any(), "789").Return(1, property, nil)
+				propertyRepo.EXPECT().PricingRules(gomock.Any(), "789").Return(nil, nil)
+				propertyRepo.EXPECT().BookingRules(gomock.Any(), "789").Return(reserv.DefaultBookingRules("789"), nil)
+			}
+			if tt.payment != nil {
+				bookingRepo.EXPECT().PaymentByBookingID(gomock.Any(), "123").Return(*tt.payment, nil)
+			}
+			if tt.subject == "guest" && got.Status.CanChangeDates() && tt.policy == "" && tt.wantCode != "booking_paid" {
+				bookingRepo.EXPECT().ChangeBookingDates(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, change reserv.BookingChange) (reserv.BookingChange, error) {
+					require.Equal(t, "123", change.BookingID)
+					require.Equal(t, "guest", change.ChangedBy)
+					require.Equal(t, checkIn, change.CheckInDate)
+					require.Equal(t, checkOut, change.CheckOutDate)
+					require.Equal(t, int64(30000), change.TotalPriceCents)
+					return change, tt.repoErr
+				})
+			}
+
+			mux := http.NewServeMux()
+			h := handler.NewHandler(propertyRepo, nil, bookingRepo, nil)
+			if tt.payment != nil {
+				h.Payments = payments.NewFake("whsec_test")
+			}
+			h.RegisterRoutes(mux)
+
+			body, err := json.Marshal(handler.UpdateBookingDates{
+				CheckInDate:  checkIn.Format("2006-01-02"),
+				CheckOutDate: checkOut.Format("2006-01-02"),
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPatch, "/bookings/123", bytes.NewBuffer(body))
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: tt.subject,
+				},
+			})
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req.WithContext(ctx))
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+			if tt.wantCode != "" {
+				var apiErr handler.APIError
+				require.NoError(t, json.Unmarshal([]byte(rBody), &apiErr))
+				require.Equal(t, tt.wantCode, apiErr.Code)
+				return
+			}
+
+			var updated reserv.Booking
+			require.NoError(t, json.Unmarshal([]byte(rBody), &updated))
+			require.Equal(t, checkIn, updated.CheckInDate)
+			require.Equal(t, checkOut, updated.CheckOutDate)
+			require.Equal(t, int64(30000), updated.TotalPriceCents)
+		})
+	}
+}
+
+func TestBookingChangesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookingRepo := mock.NewMockBookingRepository(ctrl)
+	propertyRepo := mock.NewMockPropertyRepository(ctrl)
+
+	booking := reserv.Booking{ID: "123", PropertyID: "789", GuestID: "guest"}
+	changes := []reserv.BookingChange{{ID: "1", BookingID: "123", ChangedBy: "guest", TotalPriceCents: 30000, PreviousTotalPriceCents: 20000}}
+
+	bookingRepo.EXPECT().GetBooking(gomock.Any(), "123").Return(1, booking, nil).Times(2)
+	propertyRepo.EXPECT().GetProperty(gomock.Any(), "789").Return(1, reserv.Property{HostID: "host"}, nil).Times(2)
+	bookingRepo.EXPECT().BookingChanges(gomock.Any(), "123").Return(changes, nil)
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(propertyRepo, nil, bookingRepo, nil)
+	h.RegisterRoutes(mux)
+
+	do := func(subject string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/bookings/123/changes", nil)
+		req.Header.Set("Authorization", "Bearer test_token")
+		ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+			RegisteredClaims: clerk.RegisteredClaims{
+				Subject: subject,
+			},
+		})
+		resp := httptest.NewRecorder()
+		mux.ServeHTTP(resp, req.WithContext(ctx))
+		return resp
+	}
+
+	resp := do("host")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	var got []reserv.BookingChange
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &got))
+	require.Equal(t, changes, got)
+
+	resp = do("stranger")
+	require.Equal(t, http.StatusUnauthorized, resp.Code, resp.Body.String())
+}
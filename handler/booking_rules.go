@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// bookingRuleErrorCodes maps the errors of the booking rules to the codes of the API errors.
+var bookingRuleErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{reserv.ErrStayTooShort, "stay_too_short"},
+	{reserv.ErrStayTooLong, "stay_too_long"},
+	{reserv.ErrCheckInTooSoon, "check_in_too_soon"},
+	{reserv.ErrCheckInTooFar, "check_in_too_far"},
+	{reserv.ErrCheckInWeekday, "check_in_weekday_not_allowed"},
+	{reserv.ErrPreparationTime, "preparation_time_required"},
+	{reserv.ErrInvalidStay, "invalid_stay"},
+	{reserv.ErrInvalidGuests, "invalid_guests"},
+	{reserv.ErrTooManyGuests, "too_many_guests"},
+	{reserv.ErrTooManyPets, "too_many_pets"},
+}
+
+// bookingRuleError converts an error of the booking rules to an API error with status 422.
+// It returns nil if err is not an error of the booking rules.
+func bookingRuleError(err error) *APIError {
+	for _, e := range bookingRuleErrorCodes {
+		if errors.Is(err, e.err) {
+			return NewAPIError(e.code, err.Error(), http.StatusUnprocessableEntity)
+		}
+	}
+	return nil
+}
+
+// today returns the current day in UTC at midnight, the same representation of the stay dates.
+func today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// checkBookingRules loads the booking rules of the property and checks the stay against them.
+// The returned APIError is ready to be written to the response writer.
+func (h *Handler) checkBookingRules(ctx context.Context, propertyID string, checkIn, checkOut time.Time) *APIError {
+	rules, err := h.repo.BookingRules(ctx, propertyID)
+	if err != nil {
+		slog.Error("failed to get booking rules", "error", err)
+		return NewAPIError("get_booking_rules_error", "failed to get booking rules", http.StatusInternalServerError)
+	}
+
+	if err := rules.Check(checkIn, checkOut, today()); err != nil {
+		slog.Warn("stay breaks the booking rules", "error", err, "property_id", propertyID)
+		return bookingRuleError(err)
+	}
+	return nil
+}
+
+// BookingRulesRequest is the request body for replacing the booking rules of a property.
+type BookingRulesRequest struct {
+	// MinNights is the minimum number of nights of a stay.
+	MinNights int `json:"min_nights"`
+	// MaxNights is the maximum number of nights of a stay.
+	MaxNights int `json:"max_nights"`
+	// AdvanceNoticeDays is how many days before the check-in date a booking must be made.
+	AdvanceNoticeDays int `json:"advance_notice_days"`
+	// BookingHorizonDays is how many days in the future a check-in date can be.
+	BookingHorizonDays int `json:"booking_horizon_days"`
+	// CheckInWeekdays are the days of the week that accept check-ins, where Sunday is 0. Empty means every day.
+	CheckInWeekdays []time.Weekday `json:"check_in_weekdays"`
+	// PreparationDays is the number of free days required between stays.
+	PreparationDays int `json:"preparation_days"`
+}
+
+// GetBookingRulesHandler returns the booking rules of a property. Guests need them to pick valid dates, so anonymous users can call it.
+func (h *Handler) GetBookingRulesHandler(w http.ResponseWriter, r *http.Request) {
+	propertyID := r.PathValue("id")
+	if propertyID == "" {
+		NewAPIError("missing_property_id", "missing property id", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("get booking rules", "property_id", propertyID)
+
+	affected, _, err := h.repo.GetProperty(r.Context(), propertyID)
+	if err != nil {
+		slog.Error("failed to get property", "error", err)
+		NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if affected == 0 {
+		NewAPIError("property_not_found", "property not found", http.StatusNotFound).Write(w)
+		return
+	}
+
+	rules, err := h.repo.BookingRules(r.Context(), propertyID)
+	if err != nil {
+		slog.Error("failed to get booking rules", "error", err)
+		NewAPIError("get_booking_rules_error", "failed to get booking rules", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(rules)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// UpdateBookingRulesHandler replaces the booking rules of a property. Only the host of the property can call it.
+func (h *Handler) UpdateBookingRulesHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+
+	var req BookingRulesRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		slog.Error("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("update booking rules", "property_id", property.ID, "request", req)
+
+	rules := reserv.BookingRules{
+		PropertyID:         property.ID.String(),
+		MinNights:          req.MinNights,
+		MaxNights:          req.MaxNights,
+		AdvanceNoticeDays:  req.AdvanceNoticeDays,
+		BookingHorizonDays: req.BookingHorizonDays,
+		CheckInWeekdays:    reserv.Weekdays(req.CheckInWeekdays),
+		PreparationDays:    req.PreparationDays,
+		UpdatedAt:          time.Now().UTC(),
+	}
+	if rules.CheckInWeekdays == nil {
+		rules.CheckInWeekdays = reserv.Weekdays{}
+	}
+
+	if err := rules.Validate(); err != nil {
+		NewAPIError("invalid_booking_rules", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	err = h.repo.SetBookingRules(r.Context(), rules)
+	if err != nil {
+		slog.Error("failed to set booking rules", "error", err)
+		NewAPIError("update_booking_rules_error", "failed to update booking rules", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(rules)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
@@ -0,0 +1,115 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateBookingRulesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	hostID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, HostID: hostID}, nil).Times(2)
+	repo.EXPECT().SetBookingRules(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, rules reserv.BookingRules) error {
+		require.Equal(t, propertyID.String(), rules.PropertyID)
+		require.Equal(t, 3, rules.MinNights)
+		require.Equal(t, reserv.Weekdays{time.Friday}, rules.CheckInWeekdays)
+		require.Equal(t, 1, rules.PreparationDays)
+		return nil
+	})
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+
+	tests := []struct {
+		name       string
+		body       handler.BookingRulesRequest
+		wantStatus int
+	}{
+		{
+			name: "valid rules",
+			body: handler.BookingRulesRequest{
+				MinNights:          3,
+				MaxNights:          30,
+				AdvanceNoticeDays:  2,
+				BookingHorizonDays: 180,
+				CheckInWeekdays:    []time.Weekday{time.Friday},
+				PreparationDays:    1,
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "max nights below min nights",
+			body:       handler.BookingRulesRequest{MinNights: 3, MaxNights: 2, BookingHorizonDays: 180},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonBody, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPut, "/properties/"+propertyID.String()+"/booking-rules", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: hostID,
+				},
+			})
+			req = req.WithContext(ctx)
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+		})
+	}
+}
+
+func TestGetBookingRulesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID}, nil)
+	repo.EXPECT().BookingRules(gomock.Any(), propertyID.String()).Return(reserv.DefaultBookingRules(propertyID.String()), nil)
+
+	// Anonymous users can read the rules.
+	req := httptest.NewRequest(http.MethodGet, "/properties/"+propertyID.String()+"/booking-rules", nil)
+	resp := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+	mux.ServeHTTP(resp, req)
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusOK, resp.Code, rBody)
+
+	var rules reserv.BookingRules
+	require.NoError(t, json.Unmarshal([]byte(rBody), &rules))
+	require.Equal(t, reserv.DefaultBookingRules(propertyID.String()), rules)
+}
@@ -2,10 +2,13 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/clerk/clerk-sdk-go/v2"
 	"github.com/perebaj/reserv"
@@ -14,20 +17,40 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
+// futureDate returns the date days from today, so the stays of the tests never break the advance notice of the booking rules.
+func futureDate(days int) string {
+	return time.Now().UTC().AddDate(0, 0, days).Format(dateFormat)
+}
+
 func TestCreateBookingHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockBookingRepo := mock.NewMockBookingRepository(ctrl)
-	mockBookingRepo.EXPECT().CreateBooking(gomock.Any(), gomock.Any()).Return("123", nil)
+	mockBookingRepo.EXPECT().CreateBooking(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, booking reserv.Booking) (string, error) {
+		require.Equal(t, int64(10000), booking.TotalPriceCents)
+		require.Equal(t, "USD", booking.Currency)
+		// Adults defaults to 1 when omitted.
+		require.Equal(t, reserv.Guests{Adults: 1}, booking.Guests)
+		require.Equal(t, reserv.BookingStatusConfirmed, booking.Status)
+		require.Nil(t, booking.RespondBy)
+		require.Equal(t, []reserv.QuoteLine{{Kind: reserv.QuoteLineNightly, Description: "1 nights", AmountCents: 10000}}, booking.LineItems)
+		return "123", nil
+	})
+	mockPropertyRepo := mock.NewMockPropertyRepository(ctrl)
+	mockPropertyRepo.EXPECT().GetProperty(gomock.Any(), "123").Return(1, reserv.Property{PricePerNightCents: 10000, Currency: "USD", MaxGuests: 2, GuestsIncluded: 2, InstantBook: true}, nil)
+	mockPropertyRepo.EXPECT().PricingRules(gomock.Any(), "123").Return(nil, nil)
+	mockPropertyRepo.EXPECT().BookingRules(gomock.Any(), "123").Return(reserv.DefaultBookingRules("123"), nil)
 
-	handler := NewHandler(nil, nil, mockBookingRepo)
+	handler := NewHandler(mockPropertyRepo, nil, mockBookingRepo, nil)
 
 	requestBody := CreateBooking{
-		PropertyID:   "123",
-		GuestID:      "456",
-		CheckInDate:  "2024-01-01",
-		CheckOutDate: "2024-01-02",
+		PropertyID:      "123",
+		GuestID:         "456",
+		CheckInDate:     futureDate(30),
+		CheckOutDate:    futureDate(31),
+		TotalPriceCents: 10000,
+		Currency:        "USD",
 	}
 
 	jsonBody, err := json.Marshal(requestBody)
@@ -56,18 +79,86 @@ func TestCreateBookingHandler(t *testing.T) {
 	err = json.Unmarshal([]byte(rBody), &response)
 	require.NoError(t, err)
 	require.Equal(t, "123", response["id"])
+	require.Equal(t, "confirmed", response["status"])
 }
 
-func TestDeleteBookingHandler(t *testing.T) {
+func TestCreateBookingHandler_RequestToBook(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockBookingRepo := mock.NewMockBookingRepository(ctrl)
-	mockBookingRepo.EXPECT().DeleteBooking(gomock.Any(), gomock.Any()).Return(nil)
+	mockBookingRepo.EXPECT().CreateBooking(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, booking reserv.Booking) (string, error) {
+		require.Equal(t, reserv.BookingStatusPending, booking.Status)
+		require.NotNil(t, booking.RespondBy)
+		require.WithinDuration(t, time.Now().Add(2*time.Hour), *booking.RespondBy, time.Minute)
+		return "123", nil
+	})
+	mockPropertyRepo := mock.NewMockPropertyRepository(ctrl)
+	mockPropertyRepo.EXPECT().GetProperty(gomock.Any(), "123").Return(1, reserv.Property{PricePerNightCents: 10000, Currency: "USD", MaxGuests: 2, GuestsIncluded: 2}, nil)
+	mockPropertyRepo.EXPECT().PricingRules(gomock.Any(), "123").Return(nil, nil)
+	mockPropertyRepo.EXPECT().BookingRules(gomock.Any(), "123").Return(reserv.DefaultBookingRules("123"), nil)
+
+	handler := NewHandler(mockPropertyRepo, nil, mockBookingRepo, nil)
+	handler.RequestDeadline = 2 * time.Hour
+
+	jsonBody, err := json.Marshal(CreateBooking{
+		PropertyID:      "123",
+		GuestID:         "456",
+		CheckInDate:     futureDate(30),
+		CheckOutDate:    futureDate(31),
+		TotalPriceCents: 10000,
+		Currency:        "USD",
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/bookings", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Authorization", "Bearer test_token")
+	ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+		RegisteredClaims: clerk.RegisteredClaims{
+			Subject: "456",
+		},
+	})
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	resp := httptest.NewRecorder()
+	mux.ServeHTTP(resp, req.WithContext(ctx))
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusCreated, resp.Code, rBody)
 
-	handler := NewHandler(nil, nil, mockBookingRepo)
+	var response map[string]string
+	require.NoError(t, json.Unmarshal([]byte(rBody), &response))
+	require.Equal(t, "pending", response["status"])
+}
+
+func TestCreateBookingHandler_Overlap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBookingRepo := mock.NewMockBookingRepository(ctrl)
+	mockBookingRepo.EXPECT().CreateBooking(gomock.Any(), gomock.Any()).Return("", reserv.ErrBookingOverlap)
+	mockPropertyRepo := mock.NewMockPropertyRepository(ctrl)
+	mockPropertyRepo.EXPECT().GetProperty(gomock.Any(), "123").Return(1, reserv.Property{PricePerNightCents: 10000, Currency: "USD", MaxGuests: 2, GuestsIncluded: 2}, nil)
+	mockPropertyRepo.EXPECT().PricingRules(gomock.Any(), "123").Return(nil, nil)
+	mockPropertyRepo.EXPECT().BookingRules(gomock.Any(), "123").Return(reserv.DefaultBookingRules("123"), nil)
 
-	req := httptest.NewRequest(http.MethodDelete, "/bookings/123", nil)
+	handler := NewHandler(mockPropertyRepo, nil, mockBookingRepo, nil)
+
+	requestBody := CreateBooking{
+		PropertyID:      "123",
+		GuestID:         "456",
+		CheckInDate:     futureDate(30),
+		CheckOutDate:    futureDate(31),
+		TotalPriceCents: 10000,
+		Currency:        "USD",
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/bookings", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer test_token")
 
 	ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
@@ -76,13 +167,207 @@ func TestDeleteBookingHandler(t *testing.T) {
 		},
 	})
 	req = req.WithContext(ctx)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
 
 	resp := httptest.NewRecorder()
+	mux.ServeHTTP(resp, req)
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusConflict, resp.Code, rBody)
+
+	var apiErr APIError
+	err = json.Unmarshal([]byte(rBody), &apiErr)
+	require.NoError(t, err)
+	require.Equal(t, "booking_overlaps", apiErr.Code)
+}
+
+func TestCreateBookingHandler_PriceMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBookingRepo := mock.NewMockBookingRepository(ctrl)
+	mockPropertyRepo := mock.NewMockPropertyRepository(ctrl)
+	mockPropertyRepo.EXPECT().GetProperty(gomock.Any(), "123").Return(1, reserv.Property{PricePerNightCents: 10000, Currency: "USD", MaxGuests: 2, GuestsIncluded: 2}, nil).Times(2)
+	mockPropertyRepo.EXPECT().PricingRules(gomock.Any(), "123").Return(nil, nil).Times(2)
+	mockPropertyRepo.EXPECT().BookingRules(gomock.Any(), "123").Return(reserv.DefaultBookingRules("123"), nil).Times(2)
+
+	handler := NewHandler(mockPropertyRepo, nil, mockBookingRepo, nil)
 	mux := http.NewServeMux()
 	handler.RegisterRoutes(mux)
-	mux.ServeHTTP(resp, req)
 
-	require.Equal(t, http.StatusNoContent, resp.Code)
+	for _, requestBody := range []CreateBooking{
+		{PropertyID: "123", GuestID: "456", CheckInDate: futureDate(30), CheckOutDate: futureDate(32), TotalPriceCents: 0, Currency: "USD"},
+		{PropertyID: "123", GuestID: "456", CheckInDate: futureDate(30), CheckOutDate: futureDate(32), TotalPriceCents: 20000, Currency: "BRL"},
+	} {
+		jsonBody, err := json.Marshal(requestBody)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/bookings", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test_token")
+
+		ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+			RegisteredClaims: clerk.RegisteredClaims{
+				Subject: "456",
+			},
+		})
+		req = req.WithContext(ctx)
+
+		resp := httptest.NewRecorder()
+		mux.ServeHTTP(resp, req)
+
+		rBody := resp.Body.String()
+		require.Equal(t, http.StatusUnprocessableEntity, resp.Code, rBody)
+
+		var apiErr APIError
+		err = json.Unmarshal([]byte(rBody), &apiErr)
+		require.NoError(t, err)
+		require.Equal(t, "price_mismatch", apiErr.Code)
+	}
+}
+
+func TestCreateBookingHandler_BookingRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rules := reserv.DefaultBookingRules("123")
+	rules.MinNights = 2
+	rules.AdvanceNoticeDays = 2
+
+	mockBookingRepo := mock.NewMockBookingRepository(ctrl)
+	mockBookingRepo.EXPECT().CreateBooking(gomock.Any(), gomock.Any()).Return("", fmt.Errorf("%w: 2 preparation days are required between stays", reserv.ErrPreparationTime))
+	mockPropertyRepo := mock.NewMockPropertyRepository(ctrl)
+	mockPropertyRepo.EXPECT().GetProperty(gomock.Any(), "123").Return(1, reserv.Property{PricePerNightCents: 10000, Currency: "USD", MaxGuests: 2, GuestsIncluded: 2}, nil).Times(4)
+	mockPropertyRepo.EXPECT().PricingRules(gomock.Any(), "123").Return(nil, nil).Times(4)
+	mockPropertyRepo.EXPECT().BookingRules(gomock.Any(), "123").Return(rules, nil).Times(4)
+
+	handler := NewHandler(mockPropertyRepo, nil, mockBookingRepo, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name     string
+		checkIn  string
+		checkOut string
+		wantCode string
+	}{
+		{name: "too short", checkIn: futureDate(30), checkOut: futureDate(31), wantCode: "stay_too_short"},
+		{name: "in the past", checkIn: futureDate(-5), checkOut: futureDate(-2), wantCode: "check_in_too_soon"},
+		{name: "inside the advance notice", checkIn: futureDate(1), checkOut: futureDate(4), wantCode: "check_in_too_soon"},
+		{name: "too close to another booking", checkIn: futureDate(30), checkOut: futureDate(32), wantCode: "preparation_time_required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkIn, err := time.Parse(dateFormat, tt.checkIn)
+			require.NoError(t, err)
+			checkOut, err := time.Parse(dateFormat, tt.checkOut)
+			require.NoError(t, err)
+
+			jsonBody, err := json.Marshal(CreateBooking{
+				PropertyID:      "123",
+				GuestID:         "456",
+				CheckInDate:     tt.checkIn,
+				CheckOutDate:    tt.checkOut,
+				TotalPriceCents: int64(10000 * reserv.Nights(checkIn, checkOut)),
+				Currency:        "USD",
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/bookings", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test_token")
+
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: "456",
+				},
+			})
+			req = req.WithContext(ctx)
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+
+			rBody := resp.Body.String()
+			require.Equal(t, http.StatusUnprocessableEntity, resp.Code, rBody)
+
+			var apiErr APIError
+			err = json.Unmarshal([]byte(rBody), &apiErr)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, apiErr.Code)
+		})
+	}
+}
+
+func TestBookingTransitionHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBookingRepo := mock.NewMockBookingRepository(ctrl)
+	mockPropertyRepo := mock.NewMockPropertyRepository(ctrl)
+
+	handler := NewHandler(mockPropertyRepo, nil, mockBookingRepo, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	booking := reserv.Booking{
+		ID:         "123",
+		PropertyID: "789",
+		GuestID:    "456",
+		Status:     reserv.BookingStatusConfirmed,
+	}
+	property := reserv.Property{HostID: "host"}
+
+	tests := []struct {
+		name       string
+		action     string
+		subject    string
+		wantStatus int
+		wantTo     reserv.BookingStatus
+	}{
+		{name: "guest cancels", action: "cancel", subject: "456", wantStatus: http.StatusOK, wantTo: reserv.BookingStatusCancelledByGuest},
+		{name: "host cancels", action: "cancel", subject: "host", wantStatus: http.StatusOK, wantTo: reserv.BookingStatusCancelledByHost},
+		{name: "host checks in", action: "check-in", subject: "host", wantStatus: http.StatusOK, wantTo: reserv.BookingStatusCheckedIn},
+		{name: "guest can't check in", action: "check-in", subject: "456", wantStatus: http.StatusUnauthorized},
+		{name: "stranger can't cancel", action: "cancel", subject: "stranger", wantStatus: http.StatusUnauthorized},
+		{name: "confirmed can't be confirmed again", action: "confirm", subject: "host", wantStatus: http.StatusConflict},
+		{name: "confirmed can't be completed", action: "complete", subject: "host", wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBookingRepo.EXPECT().GetBooking(gomock.Any(), "123").Return(1, booking, nil)
+			mockPropertyRepo.EXPECT().GetProperty(gomock.Any(), "789").Return(1, property, nil)
+			if tt.wantTo != "" && tt.action == "cancel" {
+				mockPropertyRepo.EXPECT().CancellationPolicy(gomock.Any(), "789").Return(reserv.DefaultCancellationPolicy("789"), nil)
+				mockBookingRepo.EXPECT().CancelBooking(gomock.Any(), "123", reserv.BookingStatusConfirmed, tt.wantTo, gomock.Any(), nil).Return(nil)
+			} else if tt.wantTo != "" {
+				mockBookingRepo.EXPECT().UpdateBookingStatus(gomock.Any(), "123", reserv.BookingStatusConfirmed, tt.wantTo).Return(nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/bookings/123/"+tt.action, nil)
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: tt.subject,
+				},
+			})
+			req = req.WithContext(ctx)
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+			if tt.wantTo != "" {
+				var got reserv.Booking
+				err := json.Unmarshal([]byte(rBody), &got)
+				require.NoError(t, err)
+				require.Equal(t, tt.wantTo, got.Status)
+			}
+		})
+	}
 }
 
 func TestGetBookingHandler(t *testing.T) {
@@ -94,7 +379,7 @@ func TestGetBookingHandler(t *testing.T) {
 		ID: "123",
 	}, nil)
 
-	handler := NewHandler(nil, nil, mockBookingRepo)
+	handler := NewHandler(nil, nil, mockBookingRepo, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/bookings/123", nil)
 	req.Header.Set("Authorization", "Bearer test_token")
@@ -122,7 +407,7 @@ func TestBookingsHandler(t *testing.T) {
 		},
 	}, nil)
 
-	handler := NewHandler(nil, nil, mockBookingRepo)
+	handler := NewHandler(nil, nil, mockBookingRepo, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/bookings?property_id=123&guest_id=456", nil)
 	req.Header.Set("Authorization", "Bearer test_token")
@@ -159,3 +444,137 @@ func TestBookingsHandler(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, resp.Code)
 }
+
+func TestCreateBookingHandler_Guests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	property := reserv.Property{PricePerNightCents: 10000, Currency: "USD", MaxGuests: 4, GuestsIncluded: 2, ExtraGuestFeeCents: 1500, MaxPets: 1}
+
+	mockBookingRepo := mock.NewMockBookingRepository(ctrl)
+	mockBookingRepo.EXPECT().CreateBooking(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, booking reserv.Booking) (string, error) {
+		require.Equal(t, reserv.Guests{Adults: 3, Children: 1, Infants: 1, Pets: 1}, booking.Guests)
+		require.Equal(t, int64(13000), booking.TotalPriceCents)
+		return "123", nil
+	})
+	mockPropertyRepo := mock.NewMockPropertyRepository(ctrl)
+	mockPropertyRepo.EXPECT().GetProperty(gomock.Any(), "123").Return(1, property, nil).Times(2)
+	mockPropertyRepo.EXPECT().PricingRules(gomock.Any(), "123").Return(nil, nil)
+	mockPropertyRepo.EXPECT().BookingRules(gomock.Any(), "123").Return(reserv.DefaultBookingRules("123"), nil)
+
+	handler := NewHandler(mockPropertyRepo, nil, mockBookingRepo, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name       string
+		guests     CreateBooking
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "two extra guests", guests: CreateBooking{Adults: 3, Children: 1, Infants: 1, Pets: 1, TotalPriceCents: 13000}, wantStatus: http.StatusCreated},
+		{name: "above the capacity", guests: CreateBooking{Adults: 4, Children: 1, TotalPriceCents: 14500}, wantStatus: http.StatusUnprocessableEntity, wantCode: "too_many_guests"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestBody := tt.guests
+			requestBody.PropertyID = "123"
+			requestBody.GuestID = "456"
+			requestBody.CheckInDate = futureDate(30)
+			requestBody.CheckOutDate = futureDate(31)
+			requestBody.Currency = "USD"
+
+			jsonBody, err := json.Marshal(requestBody)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/bookings", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: "456",
+				},
+			})
+			req = req.WithContext(ctx)
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+			if tt.wantCode != "" {
+				var apiErr APIError
+				require.NoError(t, json.Unmarshal([]byte(rBody), &apiErr))
+				require.Equal(t, tt.wantCode, apiErr.Code)
+			}
+		})
+	}
+}
+
+func TestBookingTransitionHandler_BookingRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBookingRepo := mock.NewMockBookingRepository(ctrl)
+	mockPropertyRepo := mock.NewMockPropertyRepository(ctrl)
+
+	handler := NewHandler(mockPropertyRepo, nil, mockBookingRepo, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	property := reserv.Property{HostID: "host"}
+	open := time.Now().Add(time.Hour)
+	expired := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name       string
+		action     string
+		subject    string
+		respondBy  time.Time
+		wantStatus int
+		wantCode   string
+		wantTo     reserv.BookingStatus
+	}{
+		{name: "host approves", action: "confirm", subject: "host", respondBy: open, wantStatus: http.StatusOK, wantTo: reserv.BookingStatusConfirmed},
+		{name: "host declines", action: "decline", subject: "host", respondBy: open, wantStatus: http.StatusOK, wantTo: reserv.BookingStatusDeclined},
+		{name: "guest can't decline", action: "decline", subject: "456", respondBy: open, wantStatus: http.StatusUnauthorized, wantCode: "unauthorized"},
+		{name: "host answers after the deadline", action: "confirm", subject: "host", respondBy: expired, wantStatus: http.StatusConflict, wantCode: "booking_request_expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			respondBy := tt.respondBy
+			booking := reserv.Booking{ID: "123", PropertyID: "789", GuestID: "456", Status: reserv.BookingStatusPending, RespondBy: &respondBy}
+			mockBookingRepo.EXPECT().GetBooking(gomock.Any(), "123").Return(1, booking, nil)
+			mockPropertyRepo.EXPECT().GetProperty(gomock.Any(), "789").Return(1, property, nil)
+			if tt.wantTo != "" {
+				mockBookingRepo.EXPECT().UpdateBookingStatus(gomock.Any(), "123", reserv.BookingStatusPending, tt.wantTo).Return(nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/bookings/123/"+tt.action, nil)
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: tt.subject,
+				},
+			})
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req.WithContext(ctx))
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+			if tt.wantCode != "" {
+				var apiErr APIError
+				require.NoError(t, json.Unmarshal([]byte(rBody), &apiErr))
+				require.Equal(t, tt.wantCode, apiErr.Code)
+				return
+			}
+
+			var got reserv.Booking
+			require.NoError(t, json.Unmarshal([]byte(rBody), &got))
+			require.Equal(t, tt.wantTo, got.Status)
+		})
+	}
+}
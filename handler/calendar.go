@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/ical"
+)
+
+// calendarProdID identifies reserv as the creator of the exported calendars.
+const calendarProdID = "-//reserv//calendar//EN"
+
+// CalendarTokenResponse is the response with the secret token of the iCalendar feed of a property.
+type CalendarTokenResponse struct {
+	// Token is the secret token of the feed.
+	Token string `json:"token"`
+	// URL is the path of the feed, with the token. It is the URL that must be registered on other platforms.
+	URL string `json:"url"`
+}
+
+// newCalendarToken generates a random token that can't be guessed.
+func newCalendarToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate calendar token: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func newCalendarTokenResponse(propertyID, token string) CalendarTokenResponse {
+	return CalendarTokenResponse{
+		Token: token,
+		URL:   fmt.Sprintf("/properties/%s/calendar.ics?token=%s", propertyID, token),
+	}
+}
+
+// calendarEvents converts the active bookings and the calendar blocks of a property to all-day events.
+// The events never carry who booked the property. The end of the events is exclusive, so the day after the
+// check-out date is used, following the inclusive ranges of the overlap check.
+func calendarEvents(bookings []reserv.Booking, blocks []reserv.CalendarBlock) []ical.Event {
+	events := make([]ical.Event, 0, len(bookings)+len(blocks))
+	for _, booking := range bookings {
+		if !booking.Status.BlocksDates() {
+			continue
+		}
+		events = append(events, ical.Event{
+			UID:     "booking-" + booking.ID + "@reserv",
+			Start:   booking.CheckInDate,
+			End:     booking.CheckOutDate.AddDate(0, 0, 1),
+			Summary: "Reserved",
+			Stamp:   booking.UpdatedAt,
+		})
+	}
+
+	for _, block := range blocks {
+		events = append(events, ical.Event{
+			UID:     "block-" + block.ID + "@reserv",
+			Start:   block.StartDate,
+			End:     block.EndDate.AddDate(0, 0, 1),
+			Summary: "Not available",
+			Stamp:   block.UpdatedAt,
+		})
+	}
+	return events
+}
+
+// GetCalendarTokenHandler returns the secret token of the iCalendar feed of a property. Only the host of the property can call it.
+func (h *Handler) GetCalendarTokenHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+	slog.Info("get calendar token", "property_id", property.ID)
+
+	token, err := h.repo.CalendarExportToken(r.Context(), property.ID.String())
+	if err != nil {
+		slog.Error("failed to get calendar token", "error", err)
+		NewAPIError("get_calendar_token_error", "failed to get calendar token", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if token == "" {
+		NewAPIError("calendar_token_not_found", "calendar token not found", http.StatusNotFound).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(newCalendarTokenResponse(property.ID.String(), token))
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// CreateCalendarTokenHandler creates a new secret token for the iCalendar feed of a property. The previous token, if any,
+// stops working. Only the host of the property can call it.
+func (h *Handler) CreateCalendarTokenHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+	slog.Info("create calendar token", "property_id", property.ID)
+
+	token, err := newCalendarToken()
+	if err != nil {
+		slog.Error("failed to generate calendar token", "error", err)
+		NewAPIError("create_calendar_token_error", "failed to create calendar token", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	err = h.repo.SetCalendarExportToken(r.Context(), property.ID.String(), token)
+	if err != nil {
+		slog.Error("failed to set calendar token", "error", err)
+		NewAPIError("create_calendar_token_error", "failed to create calendar token", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(newCalendarTokenResponse(property.ID.String(), token))
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// GetCalendarFeedHandler returns the RFC 5545 feed with the bookings and blocks of a property.
+// External calendar fetchers can't send bearer tokens, so the secret token of the property is sent in the URL.
+// Usage: GET /properties/{id}/calendar.ics?token=secret
+func (h *Handler) GetCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	propertyID := r.PathValue("id")
+	if propertyID == "" {
+		NewAPIError("missing_property_id", "missing property id", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("get calendar feed", "property_id", propertyID)
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		NewAPIError("invalid_calendar_token", "invalid calendar token", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	want, err := h.repo.CalendarExportToken(r.Context(), propertyID)
+	if err != nil {
+		slog.Error("failed to get calendar token", "error", err)
+		NewAPIError("get_calendar_feed_error", "failed to get calendar feed", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if want == "" || subtle.ConstantTimeCompare([]byte(token), []byte(want)) != 1 {
+		slog.Warn("invalid calendar token", "property_id", propertyID)
+		NewAPIError("invalid_calendar_token", "invalid calendar token", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	affected, property, err := h.repo.GetProperty(r.Context(), propertyID)
+	if err != nil {
+		slog.Error("failed to get property", "error", err)
+		NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if affected == 0 {
+		NewAPIError("property_not_found", "property not found", http.StatusNotFound).Write(w)
+		return
+	}
+
+	bookings, err := h.bookingRepo.Bookings(r.Context(), reserv.BookingFilter{PropertyID: propertyID})
+	if err != nil {
+		slog.Error("failed to get bookings", "error", err)
+		NewAPIError("get_calendar_feed_error", "failed to get calendar feed", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	blocks, err := h.repo.CalendarBlocks(r.Context(), propertyID)
+	if err != nil {
+		slog.Error("failed to get calendar blocks", "error", err)
+		NewAPIError("get_calendar_feed_error", "failed to get calendar feed", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="calendar.ics"`)
+	err = ical.Encode(w, ical.Calendar{
+		ProdID: calendarProdID,
+		Name:   property.Title,
+		Events: calendarEvents(bookings, blocks),
+	})
+	if err != nil {
+		slog.Error("failed to encode calendar", "error", err)
+		return
+	}
+}
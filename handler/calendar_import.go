@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/calendarsync"
+	"github.com/perebaj/reserv/ical"
+)
+
+// calendarClient is the client used to download the external calendars synced on demand.
+var calendarClient = calendarsync.NewClient(30 * time.Second)
+
+// CalendarImportRequest is the request body for registering an external calendar.
+type CalendarImportRequest struct {
+	// Name is a label for the calendar. Example: "Airbnb". Required.
+	Name string `json:"name"`
+	// URL is the address of the .ics feed. It is synced periodically in background. Optional, imports without URL are
+	// synced only by uploading files.
+	URL string `json:"url"`
+}
+
+// GetCalendarImportsHandler lists the external calendars of a property, with the result of their last sync.
+// Only the host of the property can call it.
+func (h *Handler) GetCalendarImportsHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+	slog.Info("get calendar imports", "property_id", property.ID)
+
+	imports, err := h.repo.CalendarImports(r.Context(), property.ID.String())
+	if err != nil {
+		slog.Error("failed to get calendar imports", "error", err)
+		NewAPIError("get_calendar_imports_error", "failed to get calendar imports", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if imports == nil {
+		imports = []reserv.CalendarImport{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(imports)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// CreateCalendarImportHandler registers an external calendar of a property. Only the host of the property can call it.
+func (h *Handler) CreateCalendarImportHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+
+	var req CalendarImportRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		slog.Error("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("create calendar import", "property_id", property.ID, "name", req.Name, "url", req.URL)
+
+	if req.Name == "" {
+		NewAPIError("missing_required_fields", "name is required", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	if req.URL != "" {
+		u, err := url.Parse(req.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			NewAPIError("invalid_calendar_url", "url must be an http or https address", http.StatusBadRequest).Write(w)
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	id, err := h.repo.CreateCalendarImport(r.Context(), reserv.CalendarImport{
+		PropertyID: property.ID.String(),
+		Name:       req.Name,
+		URL:        req.URL,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		slog.Error("failed to create calendar import", "error", err)
+		NewAPIError("create_calendar_import_error", "failed to create calendar import", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(map[string]string{"id": id})
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// DeleteCalendarImportHandler deletes an external calendar of a property and the blocks created by it.
+// Only the host of the property can call it.
+func (h *Handler) DeleteCalendarImportHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+
+	importID := r.PathValue("import_id")
+	slog.Info("delete calendar import", "property_id", property.ID, "import_id", importID)
+
+	err := h.repo.DeleteCalendarImport(r.Context(), property.ID.String(), importID)
+	if errors.Is(err, reserv.ErrCalendarImportNotFound) {
+		NewAPIError("calendar_import_not_found", "calendar import not found", http.StatusNotFound).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete calendar import", "error", err)
+		NewAPIError("delete_calendar_import_error", "failed to delete calendar import", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SyncCalendarImportHandler syncs an external calendar of a property right away and returns the conflicts with local bookings.
+// When the body is a text/calendar file, the file is imported instead of downloading the URL of the import.
+// Only the host of the property can call it.
+func (h *Handler) SyncCalendarImportHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+
+	importID := r.PathValue("import_id")
+	slog.Info("sync calendar import", "property_id", property.ID, "import_id", importID)
+
+	imp, err := h.repo.GetCalendarImport(r.Context(), property.ID.String(), importID)
+	if errors.Is(err, reserv.ErrCalendarImportNotFound) {
+		NewAPIError("calendar_import_not_found", "calendar import not found", http.StatusNotFound).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get calendar import", "error", err)
+		NewAPIError("get_calendar_import_error", "failed to get calendar import", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	var feed io.Reader
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/calendar" {
+		feed = r.Body
+	} else if imp.URL == "" {
+		NewAPIError("missing_calendar_feed", "the import has no url, upload a text/calendar file", http.StatusUnprocessableEntity).Write(w)
+		return
+	}
+
+	result, err := calendarsync.Sync(r.Context(), h.repo, calendarClient, imp, feed)
+	if errors.Is(err, calendarsync.ErrFetchFeed) {
+		slog.Warn("failed to fetch calendar feed", "error", err, "import_id", imp.ID)
+		NewAPIError("calendar_fetch_error", calendarsync.FailureMessage(err), http.StatusBadGateway).Write(w)
+		return
+	}
+	if errors.Is(err, calendarsync.ErrFeedTooLarge) {
+		NewAPIError("calendar_feed_too_large", err.Error(), http.StatusUnprocessableEntity).Write(w)
+		return
+	}
+	if errors.Is(err, ical.ErrInvalidCalendar) {
+		NewAPIError("invalid_calendar_feed", err.Error(), http.StatusUnprocessableEntity).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to sync calendar import", "error", err)
+		NewAPIError("sync_calendar_import_error", "failed to sync calendar import", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
@@ -0,0 +1,131 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSyncCalendarImportHandler_Upload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	hostID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	propertyID := uuid.New()
+	imp := reserv.CalendarImport{ID: "import-id", PropertyID: propertyID.String(), Name: "Airbnb"}
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, HostID: hostID}, nil).Times(2)
+	repo.EXPECT().GetCalendarImport(gomock.Any(), propertyID.String(), "import-id").Return(imp, nil).Times(2)
+
+	conflict := reserv.CalendarConflict{
+		UID:       "booking-42@other",
+		StartDate: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC),
+		BookingID: "booking-id",
+	}
+	repo.EXPECT().SyncCalendarImport(gomock.Any(), imp, gomock.Any()).DoAndReturn(func(_ context.Context, _ reserv.CalendarImport, blocks []reserv.CalendarBlock) (reserv.CalendarSyncResult, error) {
+		require.Len(t, blocks, 4)
+		return reserv.CalendarSyncResult{Blocked: 3, Conflicts: reserv.CalendarConflicts{conflict}}, nil
+	})
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+
+	newRequest := func(contentType string, body []byte) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/properties/"+propertyID.String()+"/calendar-imports/import-id/sync", bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Authorization", "Bearer test_token")
+		ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+			RegisteredClaims: clerk.RegisteredClaims{
+				Subject: hostID,
+			},
+		})
+		return req.WithContext(ctx)
+	}
+
+	feed, err := os.ReadFile("../ical/testdata/airbnb.ics")
+	require.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	mux.ServeHTTP(resp, newRequest("text/calendar; charset=utf-8", feed))
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	var result reserv.CalendarSyncResult
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	require.Equal(t, 3, result.Blocked)
+	require.Equal(t, reserv.CalendarConflicts{conflict}, result.Conflicts)
+
+	// Without a file, an import without URL has nothing to sync.
+	resp = httptest.NewRecorder()
+	mux.ServeHTTP(resp, newRequest("application/json", nil))
+	require.Equal(t, http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+}
+
+func TestCreateCalendarImportHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	hostID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, HostID: hostID}, nil).Times(3)
+	repo.EXPECT().CreateCalendarImport(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, imp reserv.CalendarImport) (string, error) {
+		require.Equal(t, propertyID.String(), imp.PropertyID)
+		require.Equal(t, "Airbnb", imp.Name)
+		require.Equal(t, "https://www.airbnb.com/calendar/ical/1.ics", imp.URL)
+		return "import-id", nil
+	})
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+
+	tests := []struct {
+		name       string
+		body       handler.CalendarImportRequest
+		wantStatus int
+	}{
+		{name: "valid import", body: handler.CalendarImportRequest{Name: "Airbnb", URL: "https://www.airbnb.com/calendar/ical/1.ics"}, wantStatus: http.StatusCreated},
+		{name: "missing name", body: handler.CalendarImportRequest{URL: "https://www.airbnb.com/calendar/ical/1.ics"}, wantStatus: http.StatusBadRequest},
+		{name: "invalid url", body: handler.CalendarImportRequest{Name: "Airbnb", URL: "file:///etc/passwd"}, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonBody, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/properties/"+propertyID.String()+"/calendar-imports", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: hostID,
+				},
+			})
+			req = req.WithContext(ctx)
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+		})
+	}
+}
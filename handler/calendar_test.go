@@ -0,0 +1,152 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetCalendarFeedHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+	bookingRepo := mock.NewMockBookingRepository(ctrl)
+
+	propertyID := uuid.New()
+	repo.EXPECT().CalendarExportToken(gomock.Any(), propertyID.String()).Return("secret", nil).Times(2)
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, Title: "Beach house"}, nil)
+	bookingRepo.EXPECT().Bookings(gomock.Any(), reserv.BookingFilter{PropertyID: propertyID.String()}).Return([]reserv.Booking{
+		{
+			ID:           "b1",
+			GuestID:      "guest_secret_id",
+			Status:       reserv.BookingStatusConfirmed,
+			CheckInDate:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			CheckOutDate: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:           "b2",
+			Status:       reserv.BookingStatusCancelledByGuest,
+			CheckInDate:  time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			CheckOutDate: time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC),
+		},
+	}, nil)
+	repo.EXPECT().CalendarBlocks(gomock.Any(), propertyID.String()).Return([]reserv.CalendarBlock{
+		{
+			ID:        "k1",
+			StartDate: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+		},
+	}, nil)
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, bookingRepo, nil)
+	h.RegisterRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/properties/"+propertyID.String()+"/calendar.ics?token=wrong", nil)
+	resp := httptest.NewRecorder()
+	mux.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/properties/"+propertyID.String()+"/calendar.ics?token=secret", nil)
+	resp = httptest.NewRecorder()
+	mux.ServeHTTP(resp, req)
+
+	body := resp.Body.String()
+	require.Equal(t, http.StatusOK, resp.Code, body)
+	require.Equal(t, "text/calendar; charset=utf-8", resp.Header().Get("Content-Type"))
+	require.Contains(t, body, "X-WR-CALNAME:Beach house\r\n")
+	require.Contains(t, body, "UID:booking-b1@reserv\r\nDTSTAMP:00010101T000000Z\r\nDTSTART;VALUE=DATE:20250101\r\nDTEND;VALUE=DATE:20250104\r\n")
+	require.Contains(t, body, "UID:block-k1@reserv\r\nDTSTAMP:00010101T000000Z\r\nDTSTART;VALUE=DATE:20250310\r\nDTEND;VALUE=DATE:20250311\r\n")
+	require.NotContains(t, body, "booking-b2")
+	require.NotContains(t, body, "guest_secret_id")
+	require.Equal(t, 2, strings.Count(body, "BEGIN:VEVENT"))
+}
+
+func TestGetCalendarFeedHandler_NoToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+	propertyID := uuid.New()
+	// A property without a token never exposes its feed, even for an empty token.
+	repo.EXPECT().CalendarExportToken(gomock.Any(), propertyID.String()).Return("", nil)
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/properties/"+propertyID.String()+"/calendar.ics?token=anything", nil)
+	resp := httptest.NewRecorder()
+	mux.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestCreateCalendarTokenHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	hostID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, HostID: hostID}, nil).Times(2)
+
+	var saved string
+	repo.EXPECT().SetCalendarExportToken(gomock.Any(), propertyID.String(), gomock.Any()).DoAndReturn(func(_ any, _ string, token string) error {
+		saved = token
+		return nil
+	})
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+
+	tests := []struct {
+		name       string
+		subject    string
+		wantStatus int
+	}{
+		{name: "host creates a token", subject: hostID, wantStatus: http.StatusCreated},
+		{name: "only the host can create a token", subject: "another_user", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/properties/"+propertyID.String()+"/calendar-token", nil)
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: tt.subject,
+				},
+			})
+			req = req.WithContext(ctx)
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+
+			var got handler.CalendarTokenResponse
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &got))
+			require.Len(t, got.Token, 64)
+			require.Equal(t, saved, got.Token)
+			require.Equal(t, "/properties/"+propertyID.String()+"/calendar.ics?token="+got.Token, got.URL)
+		})
+	}
+}
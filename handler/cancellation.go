@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// CancellationResponse is the response of the cancellation of a booking. It is the cancelled booking with the details
+// of the refund.
+type CancellationResponse struct {
+	reserv.Booking
+	Refund reserv.Refund `json:"refund"`
+}
+
+// CancellationPolicyRequest is the request body for replacing the cancellation policy of a property.
+type CancellationPolicyRequest struct {
+	// Kind is the name of the policy: flexible, moderate, strict or custom.
+	Kind reserv.CancellationPolicyKind `json:"kind"`
+	// Tiers are the deadlines of the policy. When empty, the tiers of the predefined policy are used. Required for custom policies.
+	Tiers []reserv.RefundTier `json:"tiers"`
+}
+
+// refund loads the cancellation policy of the property of the booking and computes the refund of cancelling it today.
+// The returned APIError is ready to be written to the response writer.
+func (h *Handler) refund(ctx context.Context, booking reserv.Booking, to reserv.BookingStatus) (reserv.Refund, *APIError) {
+	policy, err := h.repo.CancellationPolicy(ctx, booking.PropertyID)
+	if err != nil {
+		slog.Error("failed to get cancellation policy", "error", err)
+		return reserv.Refund{}, NewAPIError("get_cancellation_policy_error", "failed to get cancellation policy", http.StatusInternalServerError)
+	}
+	return policy.Refund(booking, to == reserv.BookingStatusCancelledByHost, today()), nil
+}
+
+// CancellationPreviewHandler returns the refund the user would get by cancelling the booking now, without cancelling it.
+// Usage: GET /bookings/{id}/cancel
+func (h *Handler) CancellationPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	booking, _, to, ok := h.loadBookingTransition(w, r, bookingActionCancel)
+	if !ok {
+		return
+	}
+
+	refund, apiErr := h.refund(r.Context(), booking, to)
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(refund)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("failed_to_encode_response", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// CancelBookingHandler cancels a booking and stores the refund computed with the cancellation policy of the property.
+// When the payment was captured, the refund owed to the guest is posted to the ledger with the cancellation, and
+// paid back through the payment provider right after.
+// Usage: POST /bookings/{id}/cancel
+func (h *Handler) CancelBookingHandler(w http.ResponseWriter, r *http.Request) {
+	booking, property, to, ok := h.loadBookingTransition(w, r, bookingActionCancel)
+	if !ok {
+		return
+	}
+
+	refund, apiErr := h.refund(r.Context(), booking, to)
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	entry, err := h.cancellationEntry(r.Context(), booking, property.HostID, refund.RefundCents)
+	if err != nil {
+		slog.Error("failed to get payment", "error", err)
+		NewAPIError("failed_to_get_payment", "failed to get payment", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	err = h.bookingRepo.CancelBooking(r.Context(), booking.ID, booking.Status, to, refund.RefundCents, entry)
+	if errors.Is(err, reserv.ErrInvalidBookingTransition) {
+		slog.Warn("booking status changed concurrently", "id", booking.ID)
+		NewAPIError("invalid_status_transition", "booking status changed, try again", http.StatusConflict).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to cancel booking", "error", err)
+		NewAPIError("failed_to_update_booking", "failed to update booking", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	h.refundPayment(r.Context(), booking.ID, refund.RefundCents)
+
+	now := time.Now().UTC()
+	booking.Status = to
+	booking.RefundCents = refund.RefundCents
+	booking.CancelledAt = &now
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(CancellationResponse{Booking: booking, Refund: refund})
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("failed_to_encode_response", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// GetCancellationPolicyHandler returns the cancellation policy of a property. Guests need it before booking, so anonymous users can call it.
+func (h *Handler) GetCancellationPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	propertyID := r.PathValue("id")
+	if propertyID == "" {
+		NewAPIError("missing_property_id", "missing property id", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("get cancellation policy", "property_id", propertyID)
+
+	affected, _, err := h.repo.GetProperty(r.Context(), propertyID)
+	if err != nil {
+		slog.Error("failed to get property", "error", err)
+		NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if affected == 0 {
+		NewAPIError("property_not_found", "property not found", http.StatusNotFound).Write(w)
+		return
+	}
+
+	policy, err := h.repo.CancellationPolicy(r.Context(), propertyID)
+	if err != nil {
+		slog.Error("failed to get cancellation policy", "error", err)
+		NewAPIError("get_cancellation_policy_error", "failed to get cancellation policy", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(policy)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// UpdateCancellationPolicyHandler replaces the cancellation policy of a property. Only the host of the property can call it.
+func (h *Handler) UpdateCancellationPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+
+	var req CancellationPolicyRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		slog.Error("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("update cancellation policy", "property_id", property.ID, "request", req)
+
+	policy := reserv.CancellationPolicy{
+		PropertyID: property.ID.String(),
+		Kind:       req.Kind,
+		Tiers:      reserv.RefundTiers(req.Tiers),
+		UpdatedAt:  time.Now().UTC(),
+	}
+	if len(policy.Tiers) == 0 {
+		policy.Tiers = reserv.PresetRefundTiers(req.Kind)
+	}
+
+	if err := policy.Validate(); err != nil {
+		NewAPIError("invalid_cancellation_policy", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	err = h.repo.SetCancellationPolicy(r.Context(), policy)
+	if err != nil {
+		slog.Error("failed to set cancellation policy", "error", err)
+		NewAPIError("update_cancellation_policy_error", "failed to update cancellation policy", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(policy)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
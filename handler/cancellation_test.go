@@ -0,0 +1,164 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCancelBookingHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookingRepo := mock.NewMockBookingRepository(ctrl)
+	propertyRepo := mock.NewMockPropertyRepository(ctrl)
+
+	now := time.Now().UTC()
+	booking := reserv.Booking{
+		ID:              "123",
+		PropertyID:      "789",
+		GuestID:         "guest",
+		Status:          reserv.BookingStatusConfirmed,
+		CheckInDate:     time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 10),
+		CheckOutDate:    time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 12),
+		TotalPriceCents: 20000,
+		Currency:        "USD",
+	}
+	strict := reserv.CancellationPolicy{PropertyID: "789", Kind: reserv.CancellationStrict, Tiers: reserv.PresetRefundTiers(reserv.CancellationStrict)}
+
+	bookingRepo.EXPECT().GetBooking(gomock.Any(), "123").Return(1, booking, nil).Times(2)
+	propertyRepo.EXPECT().GetProperty(gomock.Any(), "789").Return(1, reserv.Property{HostID: "host"}, nil).Times(2)
+	propertyRepo.EXPECT().CancellationPolicy(gomock.Any(), "789").Return(strict, nil).Times(2)
+	bookingRepo.EXPECT().CancelBooking(gomock.Any(), "123", reserv.BookingStatusConfirmed, reserv.BookingStatusCancelledByGuest, int64(10000), nil).Return(nil)
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(propertyRepo, nil, bookingRepo, nil)
+	h.RegisterRoutes(mux)
+
+	do := func(method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/bookings/123/cancel", nil)
+		req.Header.Set("Authorization", "Bearer test_token")
+		ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+			RegisteredClaims: clerk.RegisteredClaims{
+				Subject: "guest",
+			},
+		})
+		resp := httptest.NewRecorder()
+		mux.ServeHTTP(resp, req.WithContext(ctx))
+		return resp
+	}
+
+	// The preview doesn't cancel the booking.
+	resp := do(http.MethodGet)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	var preview reserv.Refund
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &preview))
+	require.Equal(t, reserv.Refund{
+		Policy:            reserv.CancellationStrict,
+		DaysBeforeCheckIn: 10,
+		RefundPercent:     50,
+		RefundCents:       10000,
+		TotalPriceCents:   20000,
+		Currency:          "USD",
+	}, preview)
+
+	resp = do(http.MethodPost)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	var got handler.CancellationResponse
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &got))
+	require.Equal(t, reserv.BookingStatusCancelledByGuest, got.Status)
+	require.Equal(t, int64(10000), got.RefundCents)
+	require.NotNil(t, got.CancelledAt)
+	require.Equal(t, preview, got.Refund)
+}
+
+func TestUpdateCancellationPolicyHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	hostID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, HostID: hostID}, nil).AnyTimes()
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+
+	tests := []struct {
+		name       string
+		body       handler.CancellationPolicyRequest
+		wantStatus int
+		wantTiers  reserv.RefundTiers
+	}{
+		{
+			name:       "preset tiers",
+			body:       handler.CancellationPolicyRequest{Kind: reserv.CancellationModerate},
+			wantStatus: http.StatusOK,
+			wantTiers:  reserv.PresetRefundTiers(reserv.CancellationModerate),
+		},
+		{
+			name: "custom tiers",
+			body: handler.CancellationPolicyRequest{
+				Kind:  reserv.CancellationCustom,
+				Tiers: []reserv.RefundTier{{DaysBeforeCheckIn: 30, RefundPercent: 100}, {DaysBeforeCheckIn: 3, RefundPercent: 25}},
+			},
+			wantStatus: http.StatusOK,
+			wantTiers:  reserv.RefundTiers{{DaysBeforeCheckIn: 30, RefundPercent: 100}, {DaysBeforeCheckIn: 3, RefundPercent: 25}},
+		},
+		{
+			name:       "custom without tiers",
+			body:       handler.CancellationPolicyRequest{Kind: reserv.CancellationCustom},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantStatus == http.StatusOK {
+				repo.EXPECT().SetCancellationPolicy(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			jsonBody, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPut, "/properties/"+propertyID.String()+"/cancellation-policy", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: hostID,
+				},
+			})
+			req = req.WithContext(ctx)
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var policy reserv.CancellationPolicy
+			require.NoError(t, json.Unmarshal([]byte(rBody), &policy))
+			require.Equal(t, tt.body.Kind, policy.Kind)
+			require.Equal(t, tt.wantTiers, policy.Tiers)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/perebaj/reserv"
+)
+
+// CreateBookingHold is the request body for holding the dates of a stay.
+type CreateBookingHold struct {
+	PropertyID string `json:"property_id"`
+	// CheckInDate and CheckOutDate are the dates to hold. Format: YYYY-MM-DD
+	CheckInDate  string `json:"check_in_date"`
+	CheckOutDate string `json:"check_out_date"`
+}
+
+// CreateBookingHoldHandler holds the dates of a stay for the authenticated guest during h.HoldTTL, so nobody else can book
+// them while the guest completes the checkout. The dates follow the same rules of a new booking.
+// Usage: POST /bookings/holds
+func (h *Handler) CreateBookingHoldHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
+	if !ok {
+		slog.Warn("unauthorized, no claims")
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	var req CreateBookingHold
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Warn("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("create booking hold", "request", req)
+
+	if req.PropertyID == "" || req.CheckInDate == "" || req.CheckOutDate == "" {
+		NewAPIError("missing_required_fields", "missing required fields", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	checkInDate, err := time.Parse(dateFormat, req.CheckInDate)
+	if err != nil {
+		slog.Warn("invalid date format", "error", err, "date", req.CheckInDate)
+		NewAPIError("invalid_date_format", "invalid date format. Expected YYYY-MM-DD", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	checkOutDate, err := time.Parse(dateFormat, req.CheckOutDate)
+	if err != nil {
+		slog.Warn("invalid date format", "error", err, "date", req.CheckOutDate)
+		NewAPIError("invalid_date_format", "invalid date format. Expected YYYY-MM-DD", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	affectedRows, _, err := h.repo.GetProperty(r.Context(), req.PropertyID)
+	if err != nil {
+		slog.Error("failed to get property", "error", err)
+		NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if affectedRows == 0 {
+		NewAPIError("property_not_found", "property not found", http.StatusNotFound).Write(w)
+		return
+	}
+
+	if apiErr := h.checkBookingRules(r.Context(), req.PropertyID, checkInDate, checkOutDate); apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	now := time.Now().UTC()
+	hold := reserv.BookingHold{
+		PropertyID:   req.PropertyID,
+		GuestID:      claims.Subject,
+		CheckInDate:  checkInDate,
+		CheckOutDate: checkOutDate,
+		ExpiresAt:    now.Add(h.HoldTTL),
+		CreatedAt:    now,
+	}
+
+	hold.ID, err = h.bookingRepo.CreateBookingHold(r.Context(), hold)
+	if errors.Is(err, reserv.ErrBookingOverlap) {
+		slog.Warn("booking hold overlaps", "property_id", hold.PropertyID)
+		NewAPIError("booking_overlaps", "the property is already booked or held for the requested dates", http.StatusConflict).Write(w)
+		return
+	}
+	if errors.Is(err, reserv.ErrTooManyHolds) {
+		slog.Warn("guest has too many booking holds", "guest_id", hold.GuestID)
+		NewAPIError("too_many_holds", err.Error(), http.StatusTooManyRequests).Write(w)
+		return
+	}
+	if apiErr := bookingRuleError(err); apiErr != nil {
+		slog.Warn("booking hold breaks the booking rules", "error", err, "property_id", hold.PropertyID)
+		apiErr.Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to create booking hold", "error", err)
+		NewAPIError("failed_to_create_booking_hold", "failed to create booking hold", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(hold)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("failed_to_encode_response", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
@@ -0,0 +1,123 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateBookingHoldHandler(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	checkIn, checkOut := today.AddDate(0, 0, 10), today.AddDate(0, 0, 12)
+
+	tests := []struct {
+		name       string
+		repoErr    error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "holds the dates", wantStatus: http.StatusCreated},
+		{name: "dates already booked or held", repoErr: reserv.ErrBookingOverlap, wantStatus: http.StatusConflict, wantCode: "booking_overlaps"},
+		{name: "preparation time", repoErr: reserv.ErrPreparationTime, wantStatus: http.StatusUnprocessableEntity, wantCode: "preparation_time_required"},
+		{name: "too many holds", repoErr: reserv.ErrTooManyHolds, wantStatus: http.StatusTooManyRequests, wantCode: "too_many_holds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			propertyRepo := mock.NewMockPropertyRepository(ctrl)
+
+			propertyRepo.EXPECT().GetProperty(gomock.Any(), "789").Return(1, reserv.Property{HostID: "host"}, nil)
+			propertyRepo.EXPECT().BookingRules(gomock.Any(), "789").Return(reserv.DefaultBookingRules("789"), nil)
+			bookingRepo.EXPECT().CreateBookingHold(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, hold reserv.BookingHold) (string, error) {
+				require.Equal(t, "789", hold.PropertyID)
+				require.Equal(t, "guest", hold.GuestID)
+				require.Equal(t, checkIn, hold.CheckInDate)
+				require.Equal(t, checkOut, hold.CheckOutDate)
+				require.Equal(t, 5*time.Minute, hold.ExpiresAt.Sub(hold.CreatedAt))
+				return "hold-id", tt.repoErr
+			})
+
+			mux := http.NewServeMux()
+			h := handler.NewHandler(propertyRepo, nil, bookingRepo, nil)
+			h.HoldTTL = 5 * time.Minute
+			h.RegisterRoutes(mux)
+
+			body, err := json.Marshal(handler.CreateBookingHold{
+				PropertyID:   "789",
+				CheckInDate:  checkIn.Format("2006-01-02"),
+				CheckOutDate: checkOut.Format("2006-01-02"),
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/bookings/holds", bytes.NewBuffer(body))
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: "guest",
+				},
+			})
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req.WithContext(ctx))
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+			if tt.wantCode != "" {
+				var apiErr handler.APIError
+				require.NoError(t, json.Unmarshal([]byte(rBody), &apiErr))
+				require.Equal(t, tt.wantCode, apiErr.Code)
+				return
+			}
+
+			var hold reserv.BookingHold
+			require.NoError(t, json.Unmarshal([]byte(rBody), &hold))
+			require.Equal(t, "hold-id", hold.ID)
+			require.Equal(t, "guest", hold.GuestID)
+			require.WithinDuration(t, time.Now().Add(5*time.Minute), hold.ExpiresAt, time.Minute)
+		})
+	}
+}
+
+func TestCreateBookingHoldHandler_InvalidStay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookingRepo := mock.NewMockBookingRepository(ctrl)
+	propertyRepo := mock.NewMockPropertyRepository(ctrl)
+	propertyRepo.EXPECT().GetProperty(gomock.Any(), "789").Return(1, reserv.Property{HostID: "host"}, nil)
+	propertyRepo.EXPECT().BookingRules(gomock.Any(), "789").Return(reserv.DefaultBookingRules("789"), nil)
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(propertyRepo, nil, bookingRepo, nil)
+	h.RegisterRoutes(mux)
+
+	body := `{"property_id":"789","check_in_date":"2030-01-10","check_out_date":"2030-01-08"}`
+	req := httptest.NewRequest(http.MethodPost, "/bookings/holds", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer test_token")
+	ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+		RegisteredClaims: clerk.RegisteredClaims{
+			Subject: "guest",
+		},
+	})
+	resp := httptest.NewRecorder()
+	mux.ServeHTTP(resp, req.WithContext(ctx))
+
+	require.Equal(t, http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+	var apiErr handler.APIError
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &apiErr))
+	require.Equal(t, "invalid_stay", apiErr.Code)
+}
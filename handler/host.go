@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/perebaj/reserv"
+)
+
+// HostBookingsHandler lists the bookings of all the properties of a host. Only the host can call it.
+// Usage: GET /hosts/{id}/bookings?status=confirmed,pending&property_id=123&from=2025-01-01&to=2025-01-31
+// The status accepts a comma separated list and from-to keeps the stays that overlap the range.
+func (h *Handler) HostBookingsHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
+	if !ok {
+		slog.Warn("unauthorized, no claims")
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	hostID := r.PathValue("id")
+	if hostID == "" {
+		NewAPIError("missing_host_id", "missing host id", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	query := r.URL.Query()
+	slog.Info("host bookings", "host_id", hostID, "query", query)
+
+	if claims.Subject != hostID {
+		slog.Warn("unauthorized, different user from hostID and jwt", "host_id", hostID, "jwt_subject", claims.Subject)
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	filter := reserv.HostBookingFilter{
+		HostID:     hostID,
+		PropertyID: query.Get("property_id"),
+	}
+
+	if status := query.Get("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			s := reserv.BookingStatus(strings.TrimSpace(s))
+			if !s.Valid() {
+				NewAPIError("invalid_status", "invalid booking status: "+string(s), http.StatusBadRequest).Write(w)
+				return
+			}
+			filter.Statuses = append(filter.Statuses, s)
+		}
+	}
+
+	for _, param := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(dateFormat, value)
+		if err != nil {
+			slog.Warn("invalid date format", "error", err, "date", value)
+			NewAPIError("invalid_date_format", "invalid date format. Expected YYYY-MM-DD", http.StatusBadRequest).Write(w)
+			return
+		}
+		*param.dst = date
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		NewAPIError("invalid_date_range", "to must not be before from", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	bookings, err := h.bookingRepo.GetBookingsByHostID(r.Context(), filter)
+	if err != nil {
+		slog.Error("failed to get host bookings", "error", err)
+		NewAPIError("failed_to_get_bookings", "failed to get bookings", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(bookings)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("failed_to_encode_response", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
@@ -0,0 +1,77 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHostBookingsHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		subject    string
+		query      string
+		wantStatus int
+		wantFilter *reserv.HostBookingFilter
+	}{
+		{
+			name:       "all bookings",
+			subject:    "host",
+			wantStatus: http.StatusOK,
+			wantFilter: &reserv.HostBookingFilter{HostID: "host"},
+		},
+		{
+			name:       "with filters",
+			subject:    "host",
+			query:      "?status=confirmed,pending&property_id=789&from=2025-01-01&to=2025-01-31",
+			wantStatus: http.StatusOK,
+			wantFilter: &reserv.HostBookingFilter{
+				HostID:     "host",
+				PropertyID: "789",
+				Statuses:   []reserv.BookingStatus{reserv.BookingStatusConfirmed, reserv.BookingStatusPending},
+				From:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				To:         time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{name: "another user", subject: "stranger", wantStatus: http.StatusUnauthorized},
+		{name: "invalid status", subject: "host", query: "?status=booked", wantStatus: http.StatusBadRequest},
+		{name: "invalid date", subject: "host", query: "?from=01-01-2025", wantStatus: http.StatusBadRequest},
+		{name: "to before from", subject: "host", query: "?from=2025-01-31&to=2025-01-01", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			if tt.wantFilter != nil {
+				bookingRepo.EXPECT().GetBookingsByHostID(gomock.Any(), *tt.wantFilter).Return([]reserv.Booking{{ID: "123"}}, nil)
+			}
+
+			mux := http.NewServeMux()
+			h := handler.NewHandler(nil, nil, bookingRepo, nil)
+			h.RegisterRoutes(mux)
+
+			req := httptest.NewRequest(http.MethodGet, "/hosts/host/bookings"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: tt.subject,
+				},
+			})
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req.WithContext(ctx))
+
+			require.Equal(t, tt.wantStatus, resp.Code, resp.Body.String())
+		})
+	}
+}
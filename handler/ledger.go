@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// HostBalanceHandler returns what the platform owes a host, one balance per currency, from the ledger. Only the host
+// and the platform admins can call it.
+// Usage: GET /hosts/{id}/balance
+func (h *Handler) HostBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	hostID, ok := h.authorizeHostOrAdmin(w, r)
+	if !ok {
+		return
+	}
+	slog.Info("host balance", "host_id", hostID)
+
+	balances, err := h.bookingRepo.HostBalances(r.Context(), hostID)
+	if err != nil {
+		slog.Error("failed to get host balances", "error", err)
+		NewAPIError("get_host_balance_error", "failed to get host balance", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if balances == nil {
+		balances = []reserv.HostBalance{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(balances)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// ReconciliationHandler returns the reconciliation report of the ledger: the debits and credits of each currency, the
+// balance of each account and the payments that don't match the ledger. Only the platform admins can call it.
+// Usage: GET /ledger/reconciliation
+func (h *Handler) ReconciliationHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	slog.Info("ledger reconciliation")
+	report, err := h.bookingRepo.Reconciliation(r.Context(), time.Now().UTC())
+	if err != nil {
+		slog.Error("failed to reconcile ledger", "error", err)
+		NewAPIError("reconciliation_error", "failed to reconcile ledger", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(report)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
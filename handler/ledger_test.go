@@ -0,0 +1,103 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHostBalanceHandler(t *testing.T) {
+	adminID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+
+	tests := []struct {
+		name       string
+		subject    string
+		balances   []reserv.HostBalance
+		wantStatus int
+		want       []reserv.HostBalance
+	}{
+		{
+			name:       "host gets their balance",
+			subject:    "host",
+			balances:   []reserv.HostBalance{{HostID: "host", Currency: "BRL", BalanceCents: 50000}, {HostID: "host", Currency: "USD", BalanceCents: 27000}},
+			wantStatus: http.StatusOK,
+			want:       []reserv.HostBalance{{HostID: "host", Currency: "BRL", BalanceCents: 50000}, {HostID: "host", Currency: "USD", BalanceCents: 27000}},
+		},
+		{name: "host without earnings", subject: "host", wantStatus: http.StatusOK, want: []reserv.HostBalance{}},
+		{name: "admin gets the balance of a host", subject: adminID, wantStatus: http.StatusOK, want: []reserv.HostBalance{}},
+		{name: "other users can't get the balance", subject: "guest", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			if tt.wantStatus == http.StatusOK {
+				bookingRepo.EXPECT().HostBalances(gomock.Any(), "host").Return(tt.balances, nil)
+			}
+
+			mux := http.NewServeMux()
+			h := handler.NewHandler(nil, nil, bookingRepo, nil)
+			h.AdminIDs = []string{adminID}
+			h.RegisterRoutes(mux)
+
+			resp := servePayment(mux, http.MethodGet, "/hosts/host/balance", tt.subject)
+			require.Equal(t, tt.wantStatus, resp.Code, resp.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got []reserv.HostBalance
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &got))
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReconciliationHandler(t *testing.T) {
+	adminID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookingRepo := mock.NewMockBookingRepository(ctrl)
+	bookingRepo.EXPECT().Reconciliation(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, now time.Time) (reserv.ReconciliationReport, error) {
+		return reserv.NewReconciliationReport(
+			[]reserv.TrialBalance{{Currency: "USD", DebitsCents: 30000, CreditsCents: 30000}},
+			[]reserv.AccountBalance{
+				{Account: reserv.AccountCash, Currency: "USD", BalanceCents: 30000},
+				{Account: reserv.AccountHostPayable, Currency: "USD", BalanceCents: 27000},
+				{Account: reserv.AccountPlatformRevenue, Currency: "USD", BalanceCents: 3000},
+			},
+			[]reserv.PaymentDiscrepancy{{PaymentID: "payment-id", BookingID: "123", Status: reserv.PaymentStatusCaptured, Currency: "USD", PaymentCents: 20000}},
+			now,
+		), nil
+	})
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(nil, nil, bookingRepo, nil)
+	h.AdminIDs = []string{adminID}
+	h.RegisterRoutes(mux)
+
+	resp := servePayment(mux, http.MethodGet, "/ledger/reconciliation", "host")
+	require.Equal(t, http.StatusUnauthorized, resp.Code, resp.Body.String())
+
+	resp = servePayment(mux, http.MethodGet, "/ledger/reconciliation", adminID)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	var report reserv.ReconciliationReport
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &report))
+	require.False(t, report.Balanced)
+	require.Len(t, report.Balances, 3)
+	require.Equal(t, "payment-id", report.Discrepancies[0].PaymentID)
+}
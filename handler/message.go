@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/perebaj/reserv"
+)
+
+// CreateThread is the request to start a thread. Exactly one of BookingID and PropertyID must be set.
+type CreateThread struct {
+	// BookingID starts the thread of a booking, between its guest and the host of the property.
+	BookingID string `json:"booking_id"`
+	// PropertyID starts an inquiry of the user about a property, before booking it.
+	PropertyID string `json:"property_id"`
+}
+
+// SendMessage is the request to send a message in a thread.
+type SendMessage struct {
+	Body string `json:"body"`
+}
+
+// ThreadsResponse is a page of threads. NextCursor is empty in the last page.
+type ThreadsResponse struct {
+	Threads    []reserv.Thread `json:"threads"`
+	NextCursor string          `json:"next_cursor"`
+}
+
+// MessagesResponse is a page of messages, the newest first. NextCursor is empty in the last page.
+type MessagesResponse struct {
+	Messages   []reserv.Message `json:"messages"`
+	NextCursor string           `json:"next_cursor"`
+}
+
+// parsePage parses the limit and cursor query parameters of a paginated list. If they are invalid, the error is
+// written to the response writer and false is returned.
+func parsePage(w http.ResponseWriter, r *http.Request) (reserv.Page, bool) {
+	query := r.URL.Query()
+
+	var limit int
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			NewAPIError("invalid_limit", "limit must be a number", http.StatusBadRequest).Write(w)
+			return reserv.Page{}, false
+		}
+		limit = n
+	}
+
+	page, err := reserv.NewPage(limit, query.Get("cursor"))
+	if errors.Is(err, reserv.ErrInvalidCursor) {
+		slog.Warn("invalid cursor", "error", err)
+		NewAPIError("invalid_cursor", "invalid cursor", http.StatusBadRequest).Write(w)
+		return reserv.Page{}, false
+	}
+	if err != nil {
+		NewAPIError("invalid_limit", err.Error(), http.StatusBadRequest).Write(w)
+		return reserv.Page{}, false
+	}
+	return page, true
+}
+
+// authorizeThread loads the thread with the id in the path and checks that the user of the request is one of its
+// participants. It returns the thread and the user id. If not, the error is written to the response writer and false
+// is returned.
+func (h *Handler) authorizeThread(w http.ResponseWriter, r *http.Request) (reserv.Thread, string, bool) {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
+	if !ok {
+		slog.Warn("unauthorized, no claims")
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return reserv.Thread{}, "", false
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		NewAPIError("missing_thread_id", "missing thread id", http.StatusBadRequest).Write(w)
+		return reserv.Thread{}, "", false
+	}
+
+	thread, err := h.bookingRepo.GetThread(r.Context(), id)
+	if errors.Is(err, reserv.ErrThreadNotFound) {
+		NewAPIError("thread_not_found", "thread not found", http.StatusNotFound).Write(w)
+		return reserv.Thread{}, "", false
+	}
+	if err != nil {
+		slog.Error("failed to get thread", "error", err)
+		NewAPIError("get_thread_error", "failed to get thread", http.StatusInternalServerError).Write(w)
+		return reserv.Thread{}, "", false
+	}
+
+	if !thread.IsParticipant(claims.Subject) {
+		slog.Warn("unauthorized, user is not a participant of the thread", "thread_id", thread.ID, "jwt_subject", claims.Subject)
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return reserv.Thread{}, "", false
+	}
+
+	return thread, claims.Subject, true
+}
+
+// CreateThreadHandler starts the thread of a booking, which only its guest and the host of the property can call, or
+// an inquiry of the user about a property. A booking has a single thread, and so does the inquiry of a guest about a
+// property: when it already exists, it is returned with 200 instead of 201.
+// Usage: POST /threads
+func (h *Handler) CreateThreadHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
+	if !ok {
+		slog.Warn("unauthorized, no claims")
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	var req CreateThread
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Warn("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("create thread", "request", req)
+
+	if (req.BookingID == "") == (req.PropertyID == "") {
+		NewAPIError("invalid_thread", "either booking_id or property_id is required", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	now := time.Now().UTC()
+	thread := reserv.Thread{PropertyID: req.PropertyID, GuestID: claims.Subject, LastMessageAt: now, CreatedAt: now}
+	if req.BookingID != "" {
+		affected, booking, err := h.bookingRepo.GetBooking(r.Context(), req.BookingID)
+		if err != nil {
+			slog.Error("failed to get booking", "error", err)
+			NewAPIError("failed_to_get_booking", "failed to get booking", http.StatusInternalServerError).Write(w)
+			return
+		}
+
+		if affected == 0 {
+			NewAPIError("booking_not_found", "booking not found", http.StatusNotFound).Write(w)
+			return
+		}
+		thread.PropertyID = booking.PropertyID
+		thread.BookingID = &booking.ID
+		thread.GuestID = booking.GuestID
+	}
+
+	affected, property, err := h.repo.GetProperty(r.Context(), thread.PropertyID)
+	if err != nil {
+		slog.Error("failed to get property", "error", err)
+		NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if affected == 0 {
+		NewAPIError("property_not_found", "property not found", http.StatusNotFound).Write(w)
+		return
+	}
+	thread.HostID = property.HostID
+
+	if thread.BookingID == nil && claims.Subject == property.HostID {
+		NewAPIError("own_property_inquiry", "hosts can't send inquiries about their own properties", http.StatusUnprocessableEntity).Write(w)
+		return
+	}
+
+	if !thread.IsParticipant(claims.Subject) {
+		slog.Warn("unauthorized, user is not the guest or the host of the booking", "booking_id", req.BookingID, "jwt_subject", claims.Subject)
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	thread, created, err := h.bookingRepo.CreateThread(r.Context(), thread)
+	if err != nil {
+		slog.Error("failed to create thread", "error", err)
+		NewAPIError("create_thread_error", "failed to create thread", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	err = json.NewEncoder(w).Encode(thread)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// ThreadsHandler lists the threads of the user, as guest or host, the most recent message first, with how many
+// messages the user didn't read in each.
+// Usage: GET /threads?limit=20&cursor=...
+func (h *Handler) ThreadsHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
+	if !ok {
+		slog.Warn("unauthorized, no claims")
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	page, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+	slog.Info("threads", "user_id", claims.Subject, "limit", page.Limit)
+
+	threads, err := h.bookingRepo.Threads(r.Context(), claims.Subject, page)
+	if err != nil {
+		slog.Error("failed to get threads", "error", err)
+		NewAPIError("get_threads_error", "failed to get threads", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	resp := ThreadsResponse{Threads: []reserv.Thread{}}
+	threads, resp.NextCursor = reserv.NextPage(page, threads, reserv.Thread.Cursor)
+	resp.Threads = append(resp.Threads, threads...)
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// MessagesHandler lists the messages of a thread, the newest first. Only the participants of the thread can call it.
+// Usage: GET /threads/{id}/messages?limit=20&cursor=...
+func (h *Handler) MessagesHandler(w http.ResponseWriter, r *http.Request) {
+	thread, _, ok := h.authorizeThread(w, r)
+	if !ok {
+		return
+	}
+
+	page, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+	slog.Info("messages", "thread_id", thread.ID, "limit", page.Limit)
+
+	messages, err := h.bookingRepo.Messages(r.Context(), thread.ID, page)
+	if err != nil {
+		slog.Error("failed to get messages", "error", err)
+		NewAPIError("get_messages_error", "failed to get messages", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	resp := MessagesResponse{Messages: []reserv.Message{}}
+	messages, resp.NextCursor = reserv.NextPage(page, messages, reserv.Message.Cursor)
+	resp.Messages = append(resp.Messages, messages...)
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// SendMessageHandler sends a message in a thread. Only the participants of the thread can call it.
+// Usage: POST /threads/{id}/messages
+func (h *Handler) SendMessageHandler(w http.ResponseWriter, r *http.Request) {
+	thread, userID, ok := h.authorizeThread(w, r)
+	if !ok {
+		return
+	}
+
+	var req SendMessage
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Warn("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	// The body is not logged, messages are private to the participants.
+	slog.Info("send message", "thread_id", thread.ID, "sender_id", userID)
+
+	body, err := reserv.NormalizeMessageBody(req.Body)
+	if err != nil {
+		NewAPIError("invalid_message", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	message, err := h.bookingRepo.CreateMessage(r.Context(), reserv.Message{
+		ThreadID:  thread.ID,
+		SenderID:  userID,
+		Body:      body,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		slog.Error("failed to create message", "error", err)
+		NewAPIError("create_message_error", "failed to send message", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(message)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// MarkThreadReadHandler marks the messages the user received in a thread as read, and returns how many were unread.
+// Only the participants of the thread can call it.
+// Usage: POST /threads/{id}/read
+func (h *Handler) MarkThreadReadHandler(w http.ResponseWriter, r *http.Request) {
+	thread, userID, ok := h.authorizeThread(w, r)
+	if !ok {
+		return
+	}
+	slog.Info("mark thread read", "thread_id", thread.ID, "reader_id", userID)
+
+	read, err := h.bookingRepo.MarkThreadRead(r.Context(), thread.ID, userID, time.Now().UTC())
+	if err != nil {
+		slog.Error("failed to mark thread read", "error", err)
+		NewAPIError("mark_thread_read_error", "failed to mark thread read", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(map[string]int64{"read": read})
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
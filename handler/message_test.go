@@ -0,0 +1,248 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// serveThread sends a request with the JSON body, when not nil, as the user subject.
+func serveThread(t *testing.T, mux *http.ServeMux, method, target, subject string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+
+	req := httptest.NewRequest(method, target, &buf)
+	req.Header.Set("Authorization", "Bearer test_token")
+	ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+		RegisteredClaims: clerk.RegisteredClaims{
+			Subject: subject,
+		},
+	})
+	resp := httptest.NewRecorder()
+	mux.ServeHTTP(resp, req.WithContext(ctx))
+	return resp
+}
+
+func TestCreateThreadHandler(t *testing.T) {
+	property := reserv.Property{HostID: "host"}
+	booking := reserv.Booking{ID: "booking", PropertyID: "property", GuestID: "guest"}
+
+	tests := []struct {
+		name       string
+		subject    string
+		req        handler.CreateThread
+		exists     bool
+		wantStatus int
+		want       reserv.Thread
+	}{
+		{
+			name:       "guest starts the thread of their booking",
+			subject:    "guest",
+			req:        handler.CreateThread{BookingID: "booking"},
+			wantStatus: http.StatusCreated,
+			want:       reserv.Thread{PropertyID: "property", BookingID: &booking.ID, GuestID: "guest", HostID: "host"},
+		},
+		{
+			name:       "host gets the existing thread of a booking",
+			subject:    "host",
+			req:        handler.CreateThread{BookingID: "booking"},
+			exists:     true,
+			wantStatus: http.StatusOK,
+			want:       reserv.Thread{PropertyID: "property", BookingID: &booking.ID, GuestID: "guest", HostID: "host"},
+		},
+		{
+			name:       "other users can't join the thread of a booking",
+			subject:    "other",
+			req:        handler.CreateThread{BookingID: "booking"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "user sends an inquiry about a property",
+			subject:    "other",
+			req:        handler.CreateThread{PropertyID: "property"},
+			wantStatus: http.StatusCreated,
+			want:       reserv.Thread{PropertyID: "property", GuestID: "other", HostID: "host"},
+		},
+		{
+			name:       "host can't send an inquiry about their property",
+			subject:    "host",
+			req:        handler.CreateThread{PropertyID: "property"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{name: "booking or property is required", subject: "guest", wantStatus: http.StatusBadRequest},
+		{
+			name:       "booking and property are exclusive",
+			subject:    "guest",
+			req:        handler.CreateThread{BookingID: "booking", PropertyID: "property"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			propertyRepo := mock.NewMockPropertyRepository(ctrl)
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			if tt.wantStatus != http.StatusBadRequest {
+				if tt.req.BookingID != "" {
+					bookingRepo.EXPECT().GetBooking(gomock.Any(), "booking").Return(1, booking, nil)
+				}
+				propertyRepo.EXPECT().GetProperty(gomock.Any(), "property").Return(1, property, nil)
+			}
+			if tt.wantStatus == http.StatusOK || tt.wantStatus == http.StatusCreated {
+				bookingRepo.EXPECT().CreateThread(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, thread reserv.Thread) (reserv.Thread, bool, error) {
+					require.False(t, thread.CreatedAt.IsZero())
+					require.Equal(t, thread.CreatedAt, thread.LastMessageAt)
+					thread.ID = "thread"
+					return thread, !tt.exists, nil
+				})
+			}
+
+			mux := http.NewServeMux()
+			h := handler.NewHandler(propertyRepo, nil, bookingRepo, nil)
+			h.RegisterRoutes(mux)
+
+			resp := serveThread(t, mux, http.MethodPost, "/threads", tt.subject, tt.req)
+			require.Equal(t, tt.wantStatus, resp.Code, resp.Body.String())
+			if tt.wantStatus != http.StatusOK && tt.wantStatus != http.StatusCreated {
+				return
+			}
+
+			var got reserv.Thread
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &got))
+			require.Equal(t, "thread", got.ID)
+			got.ID, got.CreatedAt, got.LastMessageAt = "", time.Time{}, time.Time{}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestThreadsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	threads := []reserv.Thread{
+		{ID: "thread-3", GuestID: "guest", HostID: "host", UnreadCount: 2, LastMessageAt: now},
+		{ID: "thread-2", GuestID: "guest", HostID: "host", LastMessageAt: now.Add(-time.Hour)},
+		{ID: "thread-1", GuestID: "guest", HostID: "host", LastMessageAt: now.Add(-2 * time.Hour)},
+	}
+
+	bookingRepo := mock.NewMockBookingRepository(ctrl)
+	bookingRepo.EXPECT().Threads(gomock.Any(), "guest", reserv.Page{Limit: 2}).Return(threads, nil)
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(nil, nil, bookingRepo, nil)
+	h.RegisterRoutes(mux)
+
+	resp := serveThread(t, mux, http.MethodGet, "/threads?limit=2", "guest", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	var got handler.ThreadsResponse
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &got))
+	require.Len(t, got.Threads, 2)
+	require.Equal(t, 2, got.Threads[0].UnreadCount)
+	require.NotEmpty(t, got.NextCursor)
+
+	// The next page starts after the last thread of the page.
+	cursor, err := reserv.ParseCursor(got.NextCursor)
+	require.NoError(t, err)
+	bookingRepo.EXPECT().Threads(gomock.Any(), "guest", reserv.Page{Limit: 2, After: &cursor}).Return(threads[2:], nil)
+
+	resp = serveThread(t, mux, http.MethodGet, "/threads?limit=2&cursor="+got.NextCursor, "guest", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	got = handler.ThreadsResponse{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &got))
+	require.Len(t, got.Threads, 1)
+	require.Equal(t, "thread-1", got.Threads[0].ID)
+	require.Empty(t, got.NextCursor)
+
+	resp = serveThread(t, mux, http.MethodGet, "/threads?limit=1000", "guest", nil)
+	require.Equal(t, http.StatusBadRequest, resp.Code, resp.Body.String())
+
+	resp = serveThread(t, mux, http.MethodGet, "/threads?cursor=invalid", "guest", nil)
+	require.Equal(t, http.StatusBadRequest, resp.Code, resp.Body.String())
+}
+
+func TestMessagesHandlers(t *testing.T) {
+	thread := reserv.Thread{ID: "thread", GuestID: "guest", HostID: "host"}
+	now := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookingRepo := mock.NewMockBookingRepository(ctrl)
+	bookingRepo.EXPECT().GetThread(gomock.Any(), "thread").Return(thread, nil).AnyTimes()
+	bookingRepo.EXPECT().GetThread(gomock.Any(), "unknown").Return(reserv.Thread{}, reserv.ErrThreadNotFound).AnyTimes()
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(nil, nil, bookingRepo, nil)
+	h.RegisterRoutes(mux)
+
+	// Only the participants can read, send and mark messages read.
+	for _, req := range []struct{ method, target string }{
+		{http.MethodGet, "/threads/thread/messages"},
+		{http.MethodPost, "/threads/thread/messages"},
+		{http.MethodPost, "/threads/thread/read"},
+	} {
+		resp := serveThread(t, mux, req.method, req.target, "other", handler.SendMessage{Body: "Hi"})
+		require.Equal(t, http.StatusUnauthorized, resp.Code, req.target)
+	}
+
+	resp := serveThread(t, mux, http.MethodGet, "/threads/unknown/messages", "guest", nil)
+	require.Equal(t, http.StatusNotFound, resp.Code, resp.Body.String())
+
+	bookingRepo.EXPECT().CreateMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, message reserv.Message) (reserv.Message, error) {
+		require.Equal(t, "thread", message.ThreadID)
+		require.Equal(t, "guest", message.SenderID)
+		require.Equal(t, "Is early check-in possible?", message.Body)
+		message.ID = "message"
+		return message, nil
+	})
+
+	resp = serveThread(t, mux, http.MethodPost, "/threads/thread/messages", "guest", handler.SendMessage{Body: " Is early check-in possible? "})
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var sent reserv.Message
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &sent))
+	require.Equal(t, "message", sent.ID)
+	require.Nil(t, sent.ReadAt)
+
+	resp = serveThread(t, mux, http.MethodPost, "/threads/thread/messages", "guest", handler.SendMessage{Body: "  "})
+	require.Equal(t, http.StatusBadRequest, resp.Code, resp.Body.String())
+
+	bookingRepo.EXPECT().Messages(gomock.Any(), "thread", reserv.Page{Limit: reserv.DefaultPageLimit}).Return([]reserv.Message{
+		{ID: "message-2", ThreadID: "thread", SenderID: "host", Body: "Sure", CreatedAt: now},
+		{ID: "message-1", ThreadID: "thread", SenderID: "guest", Body: "Is early check-in possible?", CreatedAt: now.Add(-time.Hour), ReadAt: &now},
+	}, nil)
+
+	resp = serveThread(t, mux, http.MethodGet, "/threads/thread/messages", "host", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var page handler.MessagesResponse
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &page))
+	require.Len(t, page.Messages, 2)
+	require.Equal(t, "message-2", page.Messages[0].ID)
+	require.Empty(t, page.NextCursor)
+
+	bookingRepo.EXPECT().MarkThreadRead(gomock.Any(), "thread", "host", gomock.Any()).Return(int64(1), nil)
+
+	resp = serveThread(t, mux, http.MethodPost, "/threads/thread/read", "host", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	require.JSONEq(t, `{"read": 1}`, resp.Body.String())
+}
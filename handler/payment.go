@@ -0,0 +1,343 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/payments"
+	"github.com/perebaj/reserv/settlements"
+)
+
+//go:generate mockgen -source payment.go -destination ../mock/payment.go -package mock
+
+// maxWebhookSize is the maximum size of a webhook payload, in bytes.
+const maxWebhookSize = 1 << 20
+
+// PaymentProvider is the interface for the payment providers that charge the guests. Failed captures and voids are
+// retried, so capturing or voiding a payment again must not move its money twice.
+type PaymentProvider interface {
+	// CreateIntent starts a payment that the guest completes on the client side
+	CreateIntent(ctx context.Context, req reserv.PaymentIntentRequest) (reserv.PaymentIntent, error)
+	// Capture charges an authorized payment
+	Capture(ctx context.Context, paymentID string, amountCents int64) error
+	// Refund gives amountCents of a captured payment back to the guest, or releases an authorized payment
+	Refund(ctx context.Context, paymentID string, amountCents int64) error
+	// VerifyWebhook checks the signature of a webhook of the provider and decodes its event
+	VerifyWebhook(payload []byte, signature string) (reserv.PaymentEvent, error)
+}
+
+// PaymentResponse is the response of the creation of a payment. The guest completes the payment with the ClientSecret.
+type PaymentResponse struct {
+	reserv.Payment
+	ClientSecret string `json:"client_secret"`
+}
+
+// CreatePaymentHandler starts the payment of a booking in the payment provider. Only the guest of the booking can call it.
+// The payment is authorized asynchronously, when the provider sends the payment.authorized webhook, and captured once
+// the booking is confirmed.
+// Usage: POST /bookings/{id}/payments
+func (h *Handler) CreatePaymentHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
+	if !ok {
+		slog.Warn("unauthorized, no claims")
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		NewAPIError("missing_id", "missing id", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("create payment", "booking_id", id)
+
+	if h.Payments == nil {
+		slog.Error("payment provider not configured")
+		NewAPIError("payments_unavailable", "payments are not available", http.StatusServiceUnavailable).Write(w)
+		return
+	}
+
+	affectedRows, booking, err := h.bookingRepo.GetBooking(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to get booking", "error", err)
+		NewAPIError("failed_to_get_booking", "failed to get booking", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if affectedRows == 0 {
+		NewAPIError("booking_not_found", "booking not found", http.StatusNotFound).Write(w)
+		return
+	}
+
+	if claims.Subject != booking.GuestID {
+		slog.Warn("unauthorized, only the guest can pay the booking", "jwt_subject", claims.Subject)
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	if booking.Status != reserv.BookingStatusPending && booking.Status != reserv.BookingStatusConfirmed {
+		NewAPIError("booking_not_payable", "a "+string(booking.Status)+" booking can't be paid", http.StatusConflict).Write(w)
+		return
+	}
+
+	// Checking before creating the intent avoids leaving intents without a payment in the provider. Concurrent requests
+	// are still caught by CreatePayment.
+	existing, err := h.bookingRepo.PaymentByBookingID(r.Context(), booking.ID)
+	if err != nil && !errors.Is(err, reserv.ErrPaymentNotFound) {
+		slog.Error("failed to get payment", "error", err)
+		NewAPIError("failed_to_get_payment", "failed to get payment", http.StatusInternalServerError).Write(w)
+		return
+	}
+	if err == nil && existing.Status.IsActive() {
+		slog.Warn("booking already has a payment", "booking_id", booking.ID)
+		NewAPIError("payment_exists", "the booking already has a payment", http.StatusConflict).Write(w)
+		return
+	}
+
+	intent, err := h.Payments.CreateIntent(r.Context(), reserv.PaymentIntentRequest{
+		BookingID:   booking.ID,
+		AmountCents: booking.TotalPriceCents,
+		Currency:    booking.Currency,
+	})
+	if err != nil {
+		slog.Error("failed to create payment intent", "error", err)
+		NewAPIError("payment_provider_error", "failed to create payment", http.StatusBadGateway).Write(w)
+		return
+	}
+
+	now := time.Now().UTC()
+	payment := reserv.Payment{
+		BookingID:         booking.ID,
+		Provider:          intent.Provider,
+		ProviderPaymentID: intent.ID,
+		Status:            reserv.PaymentStatusPending,
+		AmountCents:       booking.TotalPriceCents,
+		Currency:          booking.Currency,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	payment.ID, err = h.bookingRepo.CreatePayment(r.Context(), payment)
+	if errors.Is(err, reserv.ErrPaymentExists) {
+		slog.Warn("booking already has a payment", "booking_id", booking.ID)
+		NewAPIError("payment_exists", "the booking already has a payment", http.StatusConflict).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to create payment", "error", err)
+		NewAPIError("failed_to_create_payment", "failed to create payment", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(PaymentResponse{Payment: payment, ClientSecret: intent.ClientSecret})
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("failed_to_encode_response", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// PaymentWebhookHandler receives the events of the payment provider. The provider can't send bearer tokens, so the
+// events are authenticated by their signature. Events are idempotent: an event already applied is acknowledged again.
+// Usage: POST /payments/webhook
+func (h *Handler) PaymentWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if h.Payments == nil {
+		slog.Error("payment provider not configured")
+		NewAPIError("payments_unavailable", "payments are not available", http.StatusServiceUnavailable).Write(w)
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookSize))
+	if err != nil {
+		slog.Warn("failed to read webhook body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	event, err := h.Payments.VerifyWebhook(payload, r.Header.Get(payments.SignatureHeader))
+	if errors.Is(err, reserv.ErrInvalidWebhookSignature) {
+		slog.Warn("invalid webhook signature", "error", err)
+		NewAPIError("invalid_signature", "invalid webhook signature", http.StatusBadRequest).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Warn("invalid webhook event", "error", err)
+		NewAPIError("invalid_request_body", "invalid webhook event", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("payment webhook", "event_id", event.ID, "type", event.Type, "payment_id", event.PaymentID)
+
+	var to reserv.PaymentStatus
+	switch event.Type {
+	case reserv.PaymentEventAuthorized:
+		to = reserv.PaymentStatusAuthorized
+	case reserv.PaymentEventFailed:
+		to = reserv.PaymentStatusFailed
+	default:
+		// Providers send more events than we handle. Acknowledging them avoids retries.
+		slog.Info("ignoring payment event", "type", event.Type)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	payment, err := h.bookingRepo.PaymentByProviderID(r.Context(), event.Provider, event.PaymentID)
+	if errors.Is(err, reserv.ErrPaymentNotFound) {
+		slog.Warn("payment of the webhook not found", "payment_id", event.PaymentID)
+		NewAPIError("payment_not_found", "payment not found", http.StatusNotFound).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get payment", "error", err)
+		NewAPIError("failed_to_get_payment", "failed to get payment", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if !payment.Status.CanTransitionTo(to) {
+		slog.Info("payment event already applied", "payment_id", payment.ID, "status", payment.Status, "type", event.Type)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	from := payment.Status
+	payment.Status = to
+	payment.UpdatedAt = time.Now().UTC()
+	err = h.bookingRepo.UpdatePayment(r.Context(), payment, from, nil)
+	if errors.Is(err, reserv.ErrInvalidPaymentTransition) {
+		slog.Info("payment changed concurrently", "payment_id", payment.ID)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to update payment", "error", err)
+		NewAPIError("failed_to_update_payment", "failed to update payment", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	// Bookings confirmed before the payment was authorized are charged right away and the ones cancelled or declined
+	// meanwhile are released. The others are charged when the host approves them.
+	if to == reserv.PaymentStatusAuthorized {
+		affectedRows, booking, err := h.bookingRepo.GetBooking(r.Context(), payment.BookingID)
+		if err != nil {
+			slog.Error("failed to get booking", "error", err)
+			NewAPIError("failed_to_get_booking", "failed to get booking", http.StatusInternalServerError).Write(w)
+			return
+		}
+
+		if affectedRows == 1 && !booking.Status.BlocksDates() {
+			h.refundPayment(r.Context(), booking.ID, 0)
+		} else if affectedRows == 1 && booking.Status == reserv.BookingStatusConfirmed {
+			affectedRows, property, err := h.repo.GetProperty(r.Context(), booking.PropertyID)
+			if err != nil {
+				slog.Error("failed to get property", "error", err)
+				NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError).Write(w)
+				return
+			}
+
+			if affectedRows == 1 {
+				h.capturePayment(r.Context(), booking, property.HostID)
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// capturePayment charges the authorized payment of a booking, if there is one, and posts the payment to the ledger,
+// crediting the host share to hostID. The booking was already updated when it is called, so failures are logged and
+// the payment stays authorized, to be captured by the settlement worker.
+func (h *Handler) capturePayment(ctx context.Context, booking reserv.Booking, hostID string) {
+	if h.Payments == nil {
+		return
+	}
+
+	payment, err := h.bookingRepo.PaymentByBookingID(ctx, booking.ID)
+	if errors.Is(err, reserv.ErrPaymentNotFound) {
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get payment", "error", err, "booking_id", booking.ID)
+		return
+	}
+
+	if payment.Status != reserv.PaymentStatusAuthorized {
+		return
+	}
+
+	if err := settlements.Capture(ctx, h.bookingRepo, h.Payments, payment, booking, hostID); err != nil {
+		slog.Error("failed to capture payment, the settlement worker retries it", "error", err, "payment_id", payment.ID)
+	}
+}
+
+// cancellationEntry returns the ledger entry of the refund owed to the guest when the booking is cancelled with
+// refundCents, or nil if its payment wasn't captured or nothing is refunded. The host share is taken from hostID.
+func (h *Handler) cancellationEntry(ctx context.Context, booking reserv.Booking, hostID string, refundCents int64) (*reserv.JournalEntry, error) {
+	if h.Payments == nil || refundCents <= 0 {
+		return nil, nil
+	}
+
+	payment, err := h.bookingRepo.PaymentByBookingID(ctx, booking.ID)
+	if errors.Is(err, reserv.ErrPaymentNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Status != reserv.PaymentStatusCaptured {
+		return nil, nil
+	}
+
+	entry := reserv.CancellationEntry(payment, booking, hostID, min(refundCents, payment.AmountCents), time.Now().UTC())
+	return &entry, nil
+}
+
+// refundPayment gives refundCents of the captured payment of a booking back to the guest and posts the refund to the
+// ledger. Authorized payments are released without charging the guest, whatever the refund, so nothing is posted.
+// Like capturePayment, failures are logged. Voids are retried by the settlement worker, refunds are not.
+func (h *Handler) refundPayment(ctx context.Context, bookingID string, refundCents int64) {
+	if h.Payments == nil {
+		return
+	}
+
+	payment, err := h.bookingRepo.PaymentByBookingID(ctx, bookingID)
+	if errors.Is(err, reserv.ErrPaymentNotFound) {
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get payment", "error", err, "booking_id", bookingID)
+		return
+	}
+
+	if payment.Status == reserv.PaymentStatusAuthorized {
+		if err := settlements.Void(ctx, h.bookingRepo, h.Payments, payment); err != nil {
+			slog.Error("failed to void payment, the settlement worker retries it", "error", err, "payment_id", payment.ID)
+		}
+		return
+	}
+
+	if payment.Status != reserv.PaymentStatusCaptured || refundCents <= 0 {
+		return
+	}
+
+	payment.Status = reserv.PaymentStatusRefunded
+	payment.RefundedCents = min(refundCents, payment.AmountCents)
+	if err := h.Payments.Refund(ctx, payment.ProviderPaymentID, payment.RefundedCents); err != nil {
+		slog.Error("failed to refund payment", "error", err, "payment_id", payment.ID)
+		return
+	}
+
+	payment.UpdatedAt = time.Now().UTC()
+	entry := reserv.RefundEntry(payment, payment.RefundedCents, payment.UpdatedAt)
+	if err := h.bookingRepo.UpdatePayment(ctx, payment, reserv.PaymentStatusCaptured, &entry); err != nil {
+		slog.Error("failed to update refunded payment", "error", err, "payment_id", payment.ID)
+	}
+}
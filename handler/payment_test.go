@@ -0,0 +1,460 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/perebaj/reserv/payments"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// paymentStore keeps the payment of a booking and the journal entries posted with it in memory, standing in for the
+// payments and ledger tables in the mocked repository.
+type paymentStore struct {
+	mu      sync.Mutex
+	payment *reserv.Payment
+	entries []reserv.JournalEntry
+}
+
+func (s *paymentStore) expect(bookingRepo *mock.MockBookingRepository) {
+	bookingRepo.EXPECT().CreatePayment(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, payment reserv.Payment) (string, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.payment != nil {
+			return "", reserv.ErrPaymentExists
+		}
+		payment.ID = "payment-id"
+		s.payment = &payment
+		return payment.ID, nil
+	}).AnyTimes()
+
+	get := func() (reserv.Payment, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.payment == nil {
+			return reserv.Payment{}, reserv.ErrPaymentNotFound
+		}
+		return *s.payment, nil
+	}
+	bookingRepo.EXPECT().PaymentByBookingID(gomock.Any(), "123").DoAndReturn(func(context.Context, string) (reserv.Payment, error) {
+		return get()
+	}).AnyTimes()
+	bookingRepo.EXPECT().PaymentByProviderID(gomock.Any(), payments.FakeProviderName, gomock.Any()).DoAndReturn(func(_ context.Context, _, id string) (reserv.Payment, error) {
+		payment, err := get()
+		if err != nil || payment.ProviderPaymentID != id {
+			return reserv.Payment{}, reserv.ErrPaymentNotFound
+		}
+		return payment, nil
+	}).AnyTimes()
+
+	bookingRepo.EXPECT().UpdatePayment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, payment reserv.Payment, from reserv.PaymentStatus, entry *reserv.JournalEntry) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.payment == nil || s.payment.Status != from {
+			return reserv.ErrInvalidPaymentTransition
+		}
+		if entry != nil {
+			if err := entry.Validate(); err != nil {
+				return err
+			}
+			s.entries = append(s.entries, *entry)
+		}
+		s.payment = &payment
+		return nil
+	}).AnyTimes()
+}
+
+// postings returns the postings of the entries of the store by kind.
+func (s *paymentStore) postings() map[reserv.JournalEntryKind][]reserv.Posting {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	postings := map[reserv.JournalEntryKind][]reserv.Posting{}
+	for _, entry := range s.entries {
+		postings[entry.Kind] = entry.Postings
+	}
+	return postings
+}
+
+func (s *paymentStore) get(t *testing.T) reserv.Payment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	require.NotNil(t, s.payment)
+	return *s.payment
+}
+
+func servePayment(mux *http.ServeMux, method, target, subject string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Authorization", "Bearer test_token")
+	ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+		RegisteredClaims: clerk.RegisteredClaims{
+			Subject: subject,
+		},
+	})
+	resp := httptest.NewRecorder()
+	mux.ServeHTTP(resp, req.WithContext(ctx))
+	return resp
+}
+
+// TestPaymentFlow runs the whole payment of a booking request against the fake provider: the guest pays, the fake
+// provider sends the signed payment.authorized webhook to the server and the host answers the request.
+func TestPaymentFlow(t *testing.T) {
+	tests := []struct {
+		name          string
+		action        string
+		wantBooking   reserv.BookingStatus
+		wantPayment   reserv.PaymentStatus
+		wantFakeState reserv.PaymentStatus
+		wantPostings  map[reserv.JournalEntryKind][]reserv.Posting
+	}{
+		{
+			name: "host approves and the payment is captured", action: "confirm", wantBooking: reserv.BookingStatusConfirmed, wantPayment: reserv.PaymentStatusCaptured, wantFakeState: reserv.PaymentStatusCaptured,
+			wantPostings: map[reserv.JournalEntryKind][]reserv.Posting{
+				reserv.JournalEntryPayment: {
+					{Account: reserv.AccountCash, AmountCents: 30000},
+					{Account: reserv.AccountHostPayable, HostID: "host", AmountCents: -27000},
+					{Account: reserv.AccountPlatformRevenue, AmountCents: -3000},
+				},
+			},
+		},
+		{name: "host declines and the payment is voided", action: "decline", wantBooking: reserv.BookingStatusDeclined, wantPayment: reserv.PaymentStatusVoided, wantFakeState: reserv.PaymentStatusVoided, wantPostings: map[reserv.JournalEntryKind][]reserv.Posting{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			propertyRepo := mock.NewMockPropertyRepository(ctrl)
+
+			respondBy := time.Now().Add(time.Hour)
+			booking := reserv.Booking{ID: "123", PropertyID: "789", GuestID: "guest", Status: reserv.BookingStatusPending, TotalPriceCents: 30000, Currency: "USD", RespondBy: &respondBy}
+			booking.LineItems = []reserv.QuoteLine{
+				{Kind: reserv.QuoteLineNightly, Description: "3 nights", AmountCents: 27000},
+				{Kind: reserv.QuoteLineServiceFee, Description: "Service fee (11%)", AmountCents: 3000},
+			}
+			var mu sync.Mutex
+			bookingRepo.EXPECT().GetBooking(gomock.Any(), "123").DoAndReturn(func(context.Context, string) (int, reserv.Booking, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return 1, booking, nil
+			}).AnyTimes()
+			bookingRepo.EXPECT().UpdateBookingStatus(gomock.Any(), "123", reserv.BookingStatusPending, tt.wantBooking).DoAndReturn(func(_ context.Context, _ string, _, to reserv.BookingStatus) error {
+				mu.Lock()
+				defer mu.Unlock()
+				booking.Status = to
+				return nil
+			})
+			propertyRepo.EXPECT().GetProperty(gomock.Any(), "789").Return(1, reserv.Property{HostID: "host"}, nil)
+
+			store := &paymentStore{}
+			store.expect(bookingRepo)
+
+			fake := payments.NewFake("whsec_test")
+			mux := http.NewServeMux()
+			h := handler.NewHandler(propertyRepo, nil, bookingRepo, fake)
+			h.RegisterRoutes(mux)
+
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+			fake.WebhookURL = srv.URL + "/payments/webhook"
+
+			resp := servePayment(mux, http.MethodPost, "/bookings/123/payments", "guest")
+			require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+
+			var created handler.PaymentResponse
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &created))
+			require.Equal(t, "payment-id", created.ID)
+			require.Equal(t, payments.FakeProviderName, created.Provider)
+			require.Equal(t, reserv.PaymentStatusPending, created.Status)
+			require.Equal(t, int64(30000), created.AmountCents)
+			require.Equal(t, "USD", created.Currency)
+			require.NotEmpty(t, created.ClientSecret)
+
+			resp = servePayment(mux, http.MethodPost, "/bookings/123/payments", "guest")
+			require.Equal(t, http.StatusConflict, resp.Code, resp.Body.String())
+
+			require.NoError(t, fake.Authorize(context.Background(), created.ProviderPaymentID))
+			require.Equal(t, reserv.PaymentStatusAuthorized, store.get(t).Status)
+
+			resp = servePayment(mux, http.MethodPost, "/bookings/123/"+tt.action, "host")
+			require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+			payment := store.get(t)
+			require.Equal(t, tt.wantPayment, payment.Status)
+			status, _, err := fake.Status(created.ProviderPaymentID)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantFakeState, status)
+			require.Equal(t, tt.wantPostings, store.postings())
+		})
+	}
+}
+
+// TestPaymentFlow_Cancellation cancels a booking whose payment was captured: the refund owed to the guest is posted
+// with the cancellation and paid when the fake provider refunds it.
+func TestPaymentFlow_Cancellation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookingRepo := mock.NewMockBookingRepository(ctrl)
+	propertyRepo := mock.NewMockPropertyRepository(ctrl)
+
+	booking := reserv.Booking{
+		ID:              "123",
+		PropertyID:      "789",
+		GuestID:         "guest",
+		Status:          reserv.BookingStatusConfirmed,
+		CheckInDate:     time.Now().UTC().AddDate(0, 0, 30),
+		CheckOutDate:    time.Now().UTC().AddDate(0, 0, 33),
+		TotalPriceCents: 33000,
+		Currency:        "USD",
+		LineItems: []reserv.QuoteLine{
+			{Kind: reserv.QuoteLineNightly, Description: "3 nights", AmountCents: 30000},
+			{Kind: reserv.QuoteLineServiceFee, Description: "Service fee (10%)", AmountCents: 3000},
+		},
+	}
+	bookingRepo.EXPECT().GetBooking(gomock.Any(), "123").Return(1, booking, nil)
+	propertyRepo.EXPECT().GetProperty(gomock.Any(), "789").Return(1, reserv.Property{HostID: "host"}, nil)
+	propertyRepo.EXPECT().CancellationPolicy(gomock.Any(), "789").Return(reserv.DefaultCancellationPolicy("789"), nil)
+
+	store := &paymentStore{payment: &reserv.Payment{
+		ID:                "payment-id",
+		BookingID:         "123",
+		Provider:          payments.FakeProviderName,
+		ProviderPaymentID: "fake_pi_1",
+		Status:            reserv.PaymentStatusCaptured,
+		AmountCents:       33000,
+		Currency:          "USD",
+	}}
+	store.expect(bookingRepo)
+
+	bookingRepo.EXPECT().CancelBooking(gomock.Any(), "123", reserv.BookingStatusConfirmed, reserv.BookingStatusCancelledByGuest, int64(33000), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _, _ reserv.BookingStatus, _ int64, entry *reserv.JournalEntry) error {
+			require.NotNil(t, entry)
+			require.NoError(t, entry.Validate())
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			store.entries = append(store.entries, *entry)
+			return nil
+		})
+
+	fake := payments.NewFake("whsec_test")
+	intent, err := fake.CreateIntent(context.Background(), reserv.PaymentIntentRequest{BookingID: "123", AmountCents: 33000, Currency: "USD"})
+	require.NoError(t, err)
+	require.NoError(t, fake.Authorize(context.Background(), intent.ID))
+	require.NoError(t, fake.Capture(context.Background(), intent.ID, 33000))
+	store.payment.ProviderPaymentID = intent.ID
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(propertyRepo, nil, bookingRepo, fake)
+	h.RegisterRoutes(mux)
+
+	resp := servePayment(mux, http.MethodPost, "/bookings/123/cancel", "guest")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	payment := store.get(t)
+	require.Equal(t, reserv.PaymentStatusRefunded, payment.Status)
+	require.Equal(t, int64(33000), payment.RefundedCents)
+	require.Equal(t, map[reserv.JournalEntryKind][]reserv.Posting{
+		reserv.JournalEntryCancellation: {
+			{Account: reserv.AccountHostPayable, HostID: "host", AmountCents: 30000},
+			{Account: reserv.AccountPlatformRevenue, AmountCents: 3000},
+			{Account: reserv.AccountGuestRefundsPayable, AmountCents: -33000},
+		},
+		reserv.JournalEntryRefund: {
+			{Account: reserv.AccountGuestRefundsPayable, AmountCents: 33000},
+			{Account: reserv.AccountCash, AmountCents: -33000},
+		},
+	}, store.postings())
+}
+
+// TestPaymentFlow_LateAuthorization authorizes the payment of a booking declined while the guest was paying: the
+// authorization is released right away, instead of holding the money of the guest.
+func TestPaymentFlow_LateAuthorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookingRepo := mock.NewMockBookingRepository(ctrl)
+	booking := reserv.Booking{ID: "123", PropertyID: "789", GuestID: "guest", Status: reserv.BookingStatusDeclined, TotalPriceCents: 30000, Currency: "USD"}
+	bookingRepo.EXPECT().GetBooking(gomock.Any(), "123").Return(1, booking, nil)
+
+	fake := payments.NewFake("whsec_test")
+	intent, err := fake.CreateIntent(context.Background(), reserv.PaymentIntentRequest{BookingID: "123", AmountCents: 30000, Currency: "USD"})
+	require.NoError(t, err)
+
+	store := &paymentStore{payment: &reserv.Payment{
+		ID:                "payment-id",
+		BookingID:         "123",
+		Provider:          payments.FakeProviderName,
+		ProviderPaymentID: intent.ID,
+		Status:            reserv.PaymentStatusPending,
+		AmountCents:       30000,
+		Currency:          "USD",
+	}}
+	store.expect(bookingRepo)
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(nil, nil, bookingRepo, fake)
+	h.RegisterRoutes(mux)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	fake.WebhookURL = srv.URL + "/payments/webhook"
+
+	require.NoError(t, fake.Authorize(context.Background(), intent.ID))
+
+	require.Equal(t, reserv.PaymentStatusVoided, store.get(t).Status)
+	status, _, err := fake.Status(intent.ID)
+	require.NoError(t, err)
+	require.Equal(t, reserv.PaymentStatusVoided, status)
+	require.Empty(t, store.postings())
+}
+
+func TestPaymentWebhookHandler(t *testing.T) {
+	event := reserv.PaymentEvent{ID: "evt", Type: reserv.PaymentEventFailed, PaymentID: "fake_pi_1", CreatedAt: time.Now().UTC()}
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		signature  string
+		status     reserv.PaymentStatus
+		wantStatus int
+		wantCode   string
+		wantTo     reserv.PaymentStatus
+	}{
+		{name: "fails the payment", signature: payments.Sign([]byte("whsec_test"), payload, time.Now()), status: reserv.PaymentStatusPending, wantStatus: http.StatusNoContent, wantTo: reserv.PaymentStatusFailed},
+		{name: "event already applied", signature: payments.Sign([]byte("whsec_test"), payload, time.Now()), status: reserv.PaymentStatusFailed, wantStatus: http.StatusNoContent},
+		{name: "wrong secret", signature: payments.Sign([]byte("other"), payload, time.Now()), wantStatus: http.StatusBadRequest, wantCode: "invalid_signature"},
+		{name: "replayed webhook", signature: payments.Sign([]byte("whsec_test"), payload, time.Now().Add(-time.Hour)), wantStatus: http.StatusBadRequest, wantCode: "invalid_signature"},
+		{name: "missing signature", wantStatus: http.StatusBadRequest, wantCode: "invalid_signature"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			if tt.status != "" {
+				payment := reserv.Payment{ID: "payment-id", BookingID: "123", Provider: payments.FakeProviderName, ProviderPaymentID: "fake_pi_1", Status: tt.status}
+				bookingRepo.EXPECT().PaymentByProviderID(gomock.Any(), payments.FakeProviderName, "fake_pi_1").Return(payment, nil)
+			}
+			if tt.wantTo != "" {
+				bookingRepo.EXPECT().UpdatePayment(gomock.Any(), gomock.Any(), tt.status, nil).DoAndReturn(func(_ context.Context, payment reserv.Payment, _ reserv.PaymentStatus, _ *reserv.JournalEntry) error {
+					require.Equal(t, tt.wantTo, payment.Status)
+					return nil
+				})
+			}
+
+			mux := http.NewServeMux()
+			h := handler.NewHandler(nil, nil, bookingRepo, payments.NewFake("whsec_test"))
+			h.RegisterRoutes(mux)
+
+			req := httptest.NewRequest(http.MethodPost, "/payments/webhook", bytes.NewReader(payload))
+			if tt.signature != "" {
+				req.Header.Set(payments.SignatureHeader, tt.signature)
+			}
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+
+			require.Equal(t, tt.wantStatus, resp.Code, resp.Body.String())
+			if tt.wantCode != "" {
+				var apiErr handler.APIError
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &apiErr))
+				require.Equal(t, tt.wantCode, apiErr.Code)
+			}
+		})
+	}
+}
+
+func TestCreatePaymentHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		subject    string
+		status     reserv.BookingStatus
+		noProvider bool
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "only the guest pays", subject: "host", status: reserv.BookingStatusConfirmed, wantStatus: http.StatusUnauthorized, wantCode: "unauthorized"},
+		{name: "cancelled booking", subject: "guest", status: reserv.BookingStatusCancelledByGuest, wantStatus: http.StatusConflict, wantCode: "booking_not_payable"},
+		{name: "payments disabled", subject: "guest", noProvider: true, wantStatus: http.StatusServiceUnavailable, wantCode: "payments_unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			var provider handler.PaymentProvider = payments.NewFake("whsec_test")
+			if tt.noProvider {
+				provider = nil
+			} else {
+				booking := reserv.Booking{ID: "123", PropertyID: "789", GuestID: "guest", Status: tt.status, TotalPriceCents: 30000, Currency: "USD"}
+				bookingRepo.EXPECT().GetBooking(gomock.Any(), "123").Return(1, booking, nil)
+			}
+
+			mux := http.NewServeMux()
+			h := handler.NewHandler(nil, nil, bookingRepo, provider)
+			h.RegisterRoutes(mux)
+
+			resp := servePayment(mux, http.MethodPost, "/bookings/123/payments", tt.subject)
+			require.Equal(t, tt.wantStatus, resp.Code, resp.Body.String())
+
+			var apiErr handler.APIError
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &apiErr))
+			require.Equal(t, tt.wantCode, apiErr.Code)
+		})
+	}
+}
+
+func TestCreatePaymentHandler_Exists(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     reserv.PaymentStatus
+		wantStatus int
+	}{
+		{name: "payment in progress", status: reserv.PaymentStatusPending, wantStatus: http.StatusConflict},
+		{name: "payment authorized", status: reserv.PaymentStatusAuthorized, wantStatus: http.StatusConflict},
+		{name: "failed payment is paid again", status: reserv.PaymentStatusFailed, wantStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			booking := reserv.Booking{ID: "123", PropertyID: "789", GuestID: "guest", Status: reserv.BookingStatusConfirmed, TotalPriceCents: 30000, Currency: "USD"}
+			bookingRepo.EXPECT().GetBooking(gomock.Any(), "123").Return(1, booking, nil)
+			bookingRepo.EXPECT().PaymentByBookingID(gomock.Any(), "123").Return(reserv.Payment{ID: "payment-id", BookingID: "123", Status: tt.status}, nil)
+
+			// The intent is only created when the booking can be paid, so duplicated requests don't leave intents behind.
+			provider := mock.NewMockPaymentProvider(ctrl)
+			if tt.wantStatus == http.StatusCreated {
+				provider.EXPECT().CreateIntent(gomock.Any(), gomock.Any()).Return(reserv.PaymentIntent{Provider: "fake", ID: "fake_pi_2", ClientSecret: "secret"}, nil)
+				bookingRepo.EXPECT().CreatePayment(gomock.Any(), gomock.Any()).Return("payment-id-2", nil)
+			}
+
+			mux := http.NewServeMux()
+			h := handler.NewHandler(nil, nil, bookingRepo, provider)
+			h.RegisterRoutes(mux)
+
+			resp := servePayment(mux, http.MethodPost, "/bookings/123/payments", "guest")
+			require.Equal(t, tt.wantStatus, resp.Code, resp.Body.String())
+		})
+	}
+}
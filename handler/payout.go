@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/perebaj/reserv"
+)
+
+// HostPayoutsHandler returns the payout history of a host, the newest first, with the bookings of each payout. Only
+// the host and the platform admins can call it.
+// Usage: GET /hosts/{id}/payouts
+func (h *Handler) HostPayoutsHandler(w http.ResponseWriter, r *http.Request) {
+	hostID, ok := h.authorizeHostOrAdmin(w, r)
+	if !ok {
+		return
+	}
+	slog.Info("host payouts", "host_id", hostID)
+
+	payouts, err := h.bookingRepo.HostPayouts(r.Context(), hostID)
+	if err != nil {
+		slog.Error("failed to get host payouts", "error", err)
+		NewAPIError("get_host_payouts_error", "failed to get host payouts", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if payouts == nil {
+		payouts = []reserv.Payout{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(payouts)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// HostStatementHandler returns the earnings statement of a host for a month, formatted as YYYY-MM, as a CSV file with
+// a line per booking paid out in the month. Only the host and the platform admins can call it.
+// Usage: GET /hosts/{id}/statements/{month}
+func (h *Handler) HostStatementHandler(w http.ResponseWriter, r *http.Request) {
+	hostID, ok := h.authorizeHostOrAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	month, err := reserv.ParseStatementMonth(r.PathValue("month"))
+	if err != nil {
+		NewAPIError("invalid_month", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("host statement", "host_id", hostID, "month", r.PathValue("month"))
+
+	lines, err := h.bookingRepo.HostStatement(r.Context(), hostID, month)
+	if err != nil {
+		slog.Error("failed to get host statement", "error", err)
+		NewAPIError("get_host_statement_error", "failed to get host statement", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	// The CSV is written to a buffer first, so an error can still be returned as JSON.
+	var buf bytes.Buffer
+	if err := reserv.WriteStatementCSV(&buf, lines); err != nil {
+		slog.Error("failed to write statement", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="statement-%s.csv"`, month.Format("2006-01")))
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,111 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHostPayoutsHandler(t *testing.T) {
+	adminID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	paidAt := time.Date(2025, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		subject    string
+		payouts    []reserv.Payout
+		wantStatus int
+		wantLen    int
+	}{
+		{
+			name:    "host gets their payouts",
+			subject: "host",
+			payouts: []reserv.Payout{
+				{ID: "payout-2", HostID: "host", Status: reserv.PayoutStatusPending, AmountCents: 15000, Currency: "USD", Items: []reserv.PayoutItem{{PayoutID: "payout-2", BookingID: "booking-2", AmountCents: 15000}}},
+				{ID: "payout-1", HostID: "host", Status: reserv.PayoutStatusPaid, AmountCents: 27000, Currency: "USD", PaidAt: &paidAt, Items: []reserv.PayoutItem{{PayoutID: "payout-1", BookingID: "booking-1", AmountCents: 27000}}},
+			},
+			wantStatus: http.StatusOK,
+			wantLen:    2,
+		},
+		{name: "host without payouts", subject: "host", wantStatus: http.StatusOK},
+		{name: "admin gets the payouts of a host", subject: adminID, wantStatus: http.StatusOK},
+		{name: "other users can't get the payouts", subject: "guest", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			if tt.wantStatus == http.StatusOK {
+				bookingRepo.EXPECT().HostPayouts(gomock.Any(), "host").Return(tt.payouts, nil)
+			}
+
+			mux := http.NewServeMux()
+			h := handler.NewHandler(nil, nil, bookingRepo, nil)
+			h.AdminIDs = []string{adminID}
+			h.RegisterRoutes(mux)
+
+			resp := servePayment(mux, http.MethodGet, "/hosts/host/payouts", tt.subject)
+			require.Equal(t, tt.wantStatus, resp.Code, resp.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got []reserv.Payout
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &got))
+			require.NotNil(t, got)
+			require.Len(t, got, tt.wantLen)
+			if tt.wantLen > 0 {
+				require.Equal(t, "payout-2", got[0].ID)
+				require.Nil(t, got[0].PaidAt)
+				require.Equal(t, paidAt, *got[1].PaidAt)
+				require.Equal(t, "booking-1", got[1].Items[0].BookingID)
+			}
+		})
+	}
+}
+
+func TestHostStatementHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookingRepo := mock.NewMockBookingRepository(ctrl)
+	bookingRepo.EXPECT().HostStatement(gomock.Any(), "host", time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)).Return([]reserv.StatementLine{{
+		PaidAt: time.Date(2025, 5, 2, 10, 0, 0, 0, time.UTC),
+		PayoutItem: reserv.PayoutItem{
+			PayoutID:     "payout-1",
+			BookingID:    "booking-1",
+			PropertyID:   "property-1",
+			CheckInDate:  time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+			CheckOutDate: time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC),
+			AmountCents:  27000,
+		},
+		Currency: "USD",
+	}}, nil)
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(nil, nil, bookingRepo, nil)
+	h.RegisterRoutes(mux)
+
+	resp := servePayment(mux, http.MethodGet, "/hosts/host/statements/2025-05", "host")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	require.Equal(t, "text/csv; charset=utf-8", resp.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="statement-2025-05.csv"`, resp.Header().Get("Content-Disposition"))
+	require.Equal(t, "paid_at,payout_id,booking_id,property_id,check_in_date,check_out_date,currency,amount\n"+
+		"2025-05-02T10:00:00Z,payout-1,booking-1,property-1,2025-05-01,2025-05-04,USD,270.00\n", resp.Body.String())
+
+	resp = servePayment(mux, http.MethodGet, "/hosts/host/statements/may", "host")
+	require.Equal(t, http.StatusBadRequest, resp.Code, resp.Body.String())
+
+	resp = servePayment(mux, http.MethodGet, "/hosts/host/statements/2025-05", "guest")
+	require.Equal(t, http.StatusUnauthorized, resp.Code, resp.Body.String())
+}
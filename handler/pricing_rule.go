@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// PricingRuleRequest is the request body for creating or updating a pricing rule.
+type PricingRuleRequest struct {
+	// Name is a label for the rule. Example: "Weekends". Required.
+	Name string `json:"name"`
+	// StartDate and EndDate limit the nights where the rule applies. Both are inclusive and optional. Format: YYYY-MM-DD
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	// Weekdays limits the nights where the rule applies, where Sunday is 0 and Saturday is 6. Optional.
+	Weekdays []time.Weekday `json:"weekdays"`
+	// Priority decides which rule is used when more than one applies. The highest wins.
+	Priority int `json:"priority"`
+	// PriceCents replaces the price of the night. Exactly one of PriceCents and AdjustmentPercent is required.
+	PriceCents *int64 `json:"price_cents"`
+	// AdjustmentPercent changes the price of the night by a percentage. Example: 20 for +20%.
+	AdjustmentPercent *int `json:"adjustment_percent"`
+}
+
+// rule converts the request to a validated pricing rule of the property.
+func (req PricingRuleRequest) rule(propertyID string) (reserv.PricingRule, *APIError) {
+	rule := reserv.PricingRule{
+		PropertyID:        propertyID,
+		Name:              req.Name,
+		Weekdays:          reserv.Weekdays(req.Weekdays),
+		Priority:          req.Priority,
+		PriceCents:        req.PriceCents,
+		AdjustmentPercent: req.AdjustmentPercent,
+	}
+
+	for _, d := range []struct {
+		value string
+		dst   **time.Time
+	}{{req.StartDate, &rule.StartDate}, {req.EndDate, &rule.EndDate}} {
+		if d.value == "" {
+			continue
+		}
+		date, err := time.Parse(dateFormat, d.value)
+		if err != nil {
+			return reserv.PricingRule{}, NewAPIError("invalid_date_format", "invalid date format. Expected YYYY-MM-DD", http.StatusBadRequest)
+		}
+		*d.dst = &date
+	}
+
+	if err := rule.Validate(); err != nil {
+		return reserv.PricingRule{}, NewAPIError("invalid_pricing_rule", err.Error(), http.StatusBadRequest)
+	}
+
+	return rule, nil
+}
+
+// GetPricingRulesHandler lists the pricing rules of a property. Only the host of the property can call it.
+func (h *Handler) GetPricingRulesHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+	slog.Info("get pricing rules", "property_id", property.ID)
+
+	rules, err := h.repo.PricingRules(r.Context(), property.ID.String())
+	if err != nil {
+		slog.Error("failed to get pricing rules", "error", err)
+		NewAPIError("get_pricing_rules_error", "failed to get pricing rules", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if rules == nil {
+		rules = []reserv.PricingRule{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(rules)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// CreatePricingRuleHandler creates a pricing rule for a property. Only the host of the property can call it.
+func (h *Handler) CreatePricingRuleHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+
+	var req PricingRuleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		slog.Error("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("create pricing rule", "property_id", property.ID, "name", req.Name)
+
+	rule, apiErr := req.rule(property.ID.String())
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+	rule.CreatedAt = time.Now().UTC()
+	rule.UpdatedAt = rule.CreatedAt
+
+	id, err := h.repo.CreatePricingRule(r.Context(), rule)
+	if err != nil {
+		slog.Error("failed to create pricing rule", "error", err)
+		NewAPIError("create_pricing_rule_error", "failed to create pricing rule", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(map[string]string{"id": id})
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// UpdatePricingRuleHandler replaces a pricing rule of a property. Only the host of the property can call it.
+func (h *Handler) UpdatePricingRuleHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+
+	ruleID := r.PathValue("rule_id")
+	var req PricingRuleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		slog.Error("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("update pricing rule", "property_id", property.ID, "rule_id", ruleID)
+
+	rule, apiErr := req.rule(property.ID.String())
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+	rule.ID = ruleID
+	rule.UpdatedAt = time.Now().UTC()
+
+	err = h.repo.UpdatePricingRule(r.Context(), rule)
+	if errors.Is(err, reserv.ErrPricingRuleNotFound) {
+		NewAPIError("pricing_rule_not_found", "pricing rule not found", http.StatusNotFound).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to update pricing rule", "error", err)
+		NewAPIError("update_pricing_rule_error", "failed to update pricing rule", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeletePricingRuleHandler deletes a pricing rule of a property. Only the host of the property can call it.
+func (h *Handler) DeletePricingRuleHandler(w http.ResponseWriter, r *http.Request) {
+	property, ok := h.authorizeHost(w, r)
+	if !ok {
+		return
+	}
+
+	ruleID := r.PathValue("rule_id")
+	slog.Info("delete pricing rule", "property_id", property.ID, "rule_id", ruleID)
+
+	err := h.repo.DeletePricingRule(r.Context(), property.ID.String(), ruleID)
+	if errors.Is(err, reserv.ErrPricingRuleNotFound) {
+		NewAPIError("pricing_rule_not_found", "pricing rule not found", http.StatusNotFound).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete pricing rule", "error", err)
+		NewAPIError("delete_pricing_rule_error", "failed to delete pricing rule", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
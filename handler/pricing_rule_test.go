@@ -0,0 +1,138 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreatePricingRuleHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	hostID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, HostID: hostID}, nil).Times(4)
+	repo.EXPECT().CreatePricingRule(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, rule reserv.PricingRule) (string, error) {
+		require.Equal(t, propertyID.String(), rule.PropertyID)
+		require.Equal(t, "High season", rule.Name)
+		require.Equal(t, time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC), *rule.StartDate)
+		require.Equal(t, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), *rule.EndDate)
+		require.Equal(t, reserv.Weekdays{time.Friday, time.Saturday}, rule.Weekdays)
+		require.Equal(t, 20, *rule.AdjustmentPercent)
+		require.Nil(t, rule.PriceCents)
+		return "rule-id", nil
+	})
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+
+	adjustment := 20
+	price := int64(15000)
+	tests := []struct {
+		name       string
+		subject    string
+		body       handler.PricingRuleRequest
+		wantStatus int
+	}{
+		{
+			name:    "valid rule",
+			subject: hostID,
+			body: handler.PricingRuleRequest{
+				Name:              "High season",
+				StartDate:         "2025-12-15",
+				EndDate:           "2026-01-15",
+				Weekdays:          []time.Weekday{time.Friday, time.Saturday},
+				AdjustmentPercent: &adjustment,
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "price and adjustment at the same time",
+			subject:    hostID,
+			body:       handler.PricingRuleRequest{Name: "Weekends", PriceCents: &price, AdjustmentPercent: &adjustment},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid date",
+			subject:    hostID,
+			body:       handler.PricingRuleRequest{Name: "Weekends", StartDate: "15/12/2025", PriceCents: &price},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "only the host can create rules",
+			subject:    "another_user",
+			body:       handler.PricingRuleRequest{Name: "Weekends", PriceCents: &price},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonBody, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/properties/"+propertyID.String()+"/pricing-rules", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: tt.subject,
+				},
+			})
+			req = req.WithContext(ctx)
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+		})
+	}
+}
+
+func TestGetQuoteHandler_PricingRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	propertyID := uuid.New()
+	adjustment := 50
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, PricePerNightCents: 10000, Currency: "USD", MaxGuests: 2, GuestsIncluded: 2}, nil)
+	repo.EXPECT().PricingRules(gomock.Any(), propertyID.String()).Return([]reserv.PricingRule{
+		{ID: "thursdays", Name: "Thursdays", Weekdays: reserv.Weekdays{time.Thursday}, AdjustmentPercent: &adjustment},
+	}, nil)
+
+	// 2025-01-01 is a Wednesday.
+	req := httptest.NewRequest(http.MethodGet, "/properties/"+propertyID.String()+"/quote?check_in=2025-01-01&check_out=2025-01-03", nil)
+	resp := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+	mux.ServeHTTP(resp, req)
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusOK, resp.Code, rBody)
+
+	var quote reserv.Quote
+	require.NoError(t, json.Unmarshal([]byte(rBody), &quote))
+	require.Equal(t, int64(25000), quote.TotalPriceCents)
+	require.Equal(t, "thursdays", quote.Nights[1].PricingRuleID)
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// PromotionRequest is the request body for creating or updating a promotion.
+type PromotionRequest struct {
+	// Code is what the guests type to get the discount, in any case. Example: "SUMMER25". Required.
+	Code string `json:"code"`
+	// PercentOff is the discount as a percentage of the stay. Exactly one of PercentOff and AmountOffCents is required.
+	PercentOff *int `json:"percent_off"`
+	// AmountOffCents is a fixed discount in the minor unit of Currency.
+	AmountOffCents *int64 `json:"amount_off_cents"`
+	// Currency is the ISO 4217 code of AmountOffCents. Required with AmountOffCents.
+	Currency string `json:"currency"`
+	// ExpiresAt is when the code stops being accepted. Optional. Format: 2025-01-01T00:00:00Z
+	ExpiresAt *time.Time `json:"expires_at"`
+	// MaxRedemptions is how many bookings can use the code. 0 means there is no limit.
+	MaxRedemptions int `json:"max_redemptions"`
+	// MinNights is the minimum number of nights of the stay. 0 means any stay.
+	MinNights int `json:"min_nights"`
+	// PropertyIDs and HostIDs restrict the code to some properties or to all the properties of some hosts. Optional.
+	PropertyIDs []string `json:"property_ids"`
+	HostIDs     []string `json:"host_ids"`
+}
+
+// promotion converts the request to a validated promotion.
+func (req PromotionRequest) promotion() (reserv.Promotion, *APIError) {
+	promotion := reserv.Promotion{
+		Code:           reserv.NormalizePromoCode(req.Code),
+		PercentOff:     req.PercentOff,
+		AmountOffCents: req.AmountOffCents,
+		Currency:       req.Currency,
+		MaxRedemptions: req.MaxRedemptions,
+		MinNights:      req.MinNights,
+		PropertyIDs:    req.PropertyIDs,
+		HostIDs:        req.HostIDs,
+	}
+
+	if req.ExpiresAt != nil {
+		expiresAt := req.ExpiresAt.UTC()
+		promotion.ExpiresAt = &expiresAt
+	}
+
+	if err := promotion.Validate(); err != nil {
+		return reserv.Promotion{}, NewAPIError("invalid_promotion", err.Error(), http.StatusBadRequest)
+	}
+
+	return promotion, nil
+}
+
+// GetPromotionsHandler lists all the promotions with their redemptions. Only the platform admins can call it.
+// Usage: GET /promotions
+func (h *Handler) GetPromotionsHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	slog.Info("get promotions")
+	promotions, err := h.bookingRepo.Promotions(r.Context())
+	if err != nil {
+		slog.Error("failed to get promotions", "error", err)
+		NewAPIError("get_promotions_error", "failed to get promotions", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(promotions)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// CreatePromotionHandler creates a promotion. Only the platform admins can call it.
+// Usage: POST /promotions
+func (h *Handler) CreatePromotionHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	var req PromotionRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		slog.Error("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("create promotion", "request", req)
+
+	promotion, apiErr := req.promotion()
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	now := time.Now().UTC()
+	promotion.CreatedAt = now
+	promotion.UpdatedAt = now
+
+	id, err := h.bookingRepo.CreatePromotion(r.Context(), promotion)
+	if errors.Is(err, reserv.ErrPromoCodeExists) {
+		NewAPIError("promo_code_exists", "promo code already exists", http.StatusConflict).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to create promotion", "error", err)
+		NewAPIError("create_promotion_error", "failed to create promotion", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(map[string]string{"id": id})
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// GetPromotionHandler returns a promotion with its redemptions. Only the platform admins can call it.
+// Usage: GET /promotions/{id}
+func (h *Handler) GetPromotionHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+	slog.Info("get promotion", "id", id)
+
+	promotion, err := h.bookingRepo.GetPromotion(r.Context(), id)
+	if errors.Is(err, reserv.ErrPromotionNotFound) {
+		NewAPIError("promotion_not_found", "promotion not found", http.StatusNotFound).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get promotion", "error", err)
+		NewAPIError("get_promotion_error", "failed to get promotion", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(promotion)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// UpdatePromotionHandler replaces a promotion. The bookings that already redeemed the code keep their discount. To stop
+// a code, set its expiry. Only the platform admins can call it.
+// Usage: PUT /promotions/{id}
+func (h *Handler) UpdatePromotionHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+	var req PromotionRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		slog.Error("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("update promotion", "id", id, "request", req)
+
+	promotion, apiErr := req.promotion()
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	promotion.ID = id
+	promotion.UpdatedAt = time.Now().UTC()
+
+	err = h.bookingRepo.UpdatePromotion(r.Context(), promotion)
+	if errors.Is(err, reserv.ErrPromotionNotFound) {
+		NewAPIError("promotion_not_found", "promotion not found", http.StatusNotFound).Write(w)
+		return
+	}
+	if errors.Is(err, reserv.ErrPromoCodeExists) {
+		NewAPIError("promo_code_exists", "promo code already exists", http.StatusConflict).Write(w)
+		return
+	}
+	if errors.Is(err, reserv.ErrInvalidPromotion) {
+		NewAPIError("invalid_promotion", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to update promotion", "error", err)
+		NewAPIError("update_promotion_error", "failed to update promotion", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
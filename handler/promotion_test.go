@@ -0,0 +1,185 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCreatePromotionHandler(t *testing.T) {
+	adminID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+
+	tests := []struct {
+		name       string
+		subject    string
+		body       handler.PromotionRequest
+		repoErr    error
+		wantStatus int
+	}{
+		{
+			name:       "percent promotion",
+			subject:    adminID,
+			body:       handler.PromotionRequest{Code: "summer25", PercentOff: ptr(25), MaxRedemptions: 100, MinNights: 2},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "fixed amount promotion",
+			subject:    adminID,
+			body:       handler.PromotionRequest{Code: "WELCOME", AmountOffCents: ptr(int64(2000)), Currency: "USD", HostIDs: []string{"host_1"}},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "code already exists",
+			subject:    adminID,
+			body:       handler.PromotionRequest{Code: "SUMMER25", PercentOff: ptr(25)},
+			repoErr:    reserv.ErrPromoCodeExists,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "no discount",
+			subject:    adminID,
+			body:       handler.PromotionRequest{Code: "SUMMER25"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "only admins can create promotions",
+			subject:    "another_user",
+			body:       handler.PromotionRequest{Code: "SUMMER25", PercentOff: ptr(25)},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			if tt.wantStatus == http.StatusCreated || tt.repoErr != nil {
+				bookingRepo.EXPECT().CreatePromotion(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, promotion reserv.Promotion) (string, error) {
+					require.Equal(t, reserv.NormalizePromoCode(tt.body.Code), promotion.Code)
+					require.False(t, promotion.CreatedAt.IsZero())
+					return "promotion-id", tt.repoErr
+				})
+			}
+
+			mux := http.NewServeMux()
+			h := handler.NewHandler(nil, nil, bookingRepo, nil)
+			h.AdminIDs = []string{adminID}
+			h.RegisterRoutes(mux)
+
+			jsonBody, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/promotions", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: tt.subject,
+				},
+			})
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req.WithContext(ctx))
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+		})
+	}
+}
+
+func TestCreateBookingHandler_PromoCode(t *testing.T) {
+	summer := reserv.Promotion{ID: "summer", Code: "SUMMER25", PercentOff: ptr(25), MinNights: 2}
+	expired := reserv.Promotion{ID: "expired", Code: "SPRING", PercentOff: ptr(25), ExpiresAt: ptr(time.Now().Add(-time.Hour))}
+	checkIn := time.Now().UTC().AddDate(0, 0, 30)
+
+	tests := []struct {
+		name       string
+		promoCode  string
+		promotion  reserv.Promotion
+		findErr    error
+		nights     int
+		totalCents int64
+		createErr  error
+		wantStatus int
+	}{
+		{name: "discounted booking", promoCode: "summer25", promotion: summer, nights: 2, totalCents: 15000, wantStatus: http.StatusCreated},
+		{name: "unknown code", promoCode: "WINTER", findErr: reserv.ErrPromotionNotFound, nights: 2, totalCents: 15000, wantStatus: http.StatusUnprocessableEntity},
+		{name: "expired code", promoCode: "SPRING", promotion: expired, nights: 2, totalCents: 15000, wantStatus: http.StatusUnprocessableEntity},
+		{name: "stay shorter than the minimum nights", promoCode: "SUMMER25", promotion: summer, nights: 1, totalCents: 7500, wantStatus: http.StatusUnprocessableEntity},
+		{
+			name:       "usage limit reached by a concurrent booking",
+			promoCode:  "SUMMER25",
+			promotion:  summer,
+			nights:     2,
+			totalCents: 15000,
+			createErr:  reserv.ErrPromotionUnavailable,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			propertyRepo := mock.NewMockPropertyRepository(ctrl)
+			bookingRepo.EXPECT().PromotionByCode(gomock.Any(), tt.promoCode).Return(tt.promotion, tt.findErr)
+			propertyRepo.EXPECT().GetProperty(gomock.Any(), "123").Return(1, reserv.Property{PricePerNightCents: 10000, Currency: "USD", MaxGuests: 2, GuestsIncluded: 2, InstantBook: true}, nil).AnyTimes()
+			propertyRepo.EXPECT().PricingRules(gomock.Any(), "123").Return(nil, nil).AnyTimes()
+			propertyRepo.EXPECT().BookingRules(gomock.Any(), "123").Return(reserv.DefaultBookingRules("123"), nil).AnyTimes()
+			if tt.wantStatus == http.StatusCreated || tt.createErr != nil {
+				bookingRepo.EXPECT().CreateBooking(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, booking reserv.Booking) (string, error) {
+					require.Equal(t, &tt.promotion.ID, booking.PromotionID)
+					require.Equal(t, tt.totalCents, booking.TotalPriceCents)
+					require.Equal(t, reserv.QuoteLine{Kind: reserv.QuoteLineDiscount, Description: "Promo code SUMMER25 (25%)", AmountCents: -5000, SourceID: "summer"}, booking.LineItems[1])
+					return "booking-id", tt.createErr
+				})
+			}
+
+			mux := http.NewServeMux()
+			handler.NewHandler(propertyRepo, nil, bookingRepo, nil).RegisterRoutes(mux)
+
+			jsonBody, err := json.Marshal(handler.CreateBooking{
+				PropertyID:      "123",
+				GuestID:         "456",
+				CheckInDate:     checkIn.Format("2006-01-02"),
+				CheckOutDate:    checkIn.AddDate(0, 0, tt.nights).Format("2006-01-02"),
+				TotalPriceCents: tt.totalCents,
+				Currency:        "USD",
+				PromoCode:       tt.promoCode,
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/bookings", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: "456",
+				},
+			})
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req.WithContext(ctx))
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+		})
+	}
+}
@@ -4,6 +4,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"time"
@@ -21,7 +22,7 @@ type PropertyRepository interface {
 	CreateProperty(ctx context.Context, property reserv.Property) (string, error)
 	// UpdateProperty updates an existing property
 	UpdateProperty(ctx context.Context, property reserv.Property, id string) error
-	// DeleteProperty deletes a property
+	// DeleteProperty deletes a property. It returns reserv.ErrPropertyHasPayments if its bookings have payments in progress or charged.
 	DeleteProperty(ctx context.Context, id string) error
 	// GetProperty gets a property by id
 	GetProperty(ctx context.Context, id string) (int, reserv.Property, error)
@@ -33,6 +34,16 @@ type PropertyRepository interface {
 	// CreatePropertyAmenities creates amenities for a property
 	CreatePropertyAmenities(ctx context.Context, propertyID string, amenities []string) error
 
+	// Tax rules methods
+	// CreateTaxRule creates a tax rule of a jurisdiction
+	CreateTaxRule(ctx context.Context, rule reserv.TaxRule) (string, error)
+	// UpdateTaxRule updates a tax rule. It returns reserv.ErrTaxRuleNotFound if it doesn't exist.
+	UpdateTaxRule(ctx context.Context, rule reserv.TaxRule) error
+	// DeleteTaxRule deletes a tax rule. It returns reserv.ErrTaxRuleNotFound if it doesn't exist.
+	DeleteTaxRule(ctx context.Context, id string) error
+	// TaxRules gets the tax rules of all the jurisdictions
+	TaxRules(ctx context.Context) ([]reserv.TaxRule, error)
+
 	// Images methods
 	// CreateImage creates an image for a property
 	CreateImage(ctx context.Context, image reserv.PropertyImage) (string, error)
@@ -41,16 +52,191 @@ type PropertyRepository interface {
 
 	// Amenities methods
 	Amenities(ctx context.Context) ([]reserv.Amenity, error)
+
+	// Exchange rates methods
+	// ExchangeRates gets all the exchange rates used to display prices in other currencies
+	ExchangeRates(ctx context.Context) ([]reserv.ExchangeRate, error)
+
+	// Calendar blocks methods
+	// CreateCalendarBlock creates a calendar block for a property. It returns reserv.ErrBookingOverlap if the block overlaps a booking.
+	CreateCalendarBlock(ctx context.Context, block reserv.CalendarBlock) (string, error)
+	// UpdateCalendarBlock updates a calendar block of a property
+	UpdateCalendarBlock(ctx context.Context, block reserv.CalendarBlock) error
+	// DeleteCalendarBlock deletes a calendar block of a property
+	DeleteCalendarBlock(ctx context.Context, propertyID, id string) error
+	// CalendarBlocks gets all calendar blocks of a property
+	CalendarBlocks(ctx context.Context, propertyID string) ([]reserv.CalendarBlock, error)
+
+	// Pricing rules methods
+	// CreatePricingRule creates a pricing rule for a property
+	CreatePricingRule(ctx context.Context, rule reserv.PricingRule) (string, error)
+	// UpdatePricingRule updates a pricing rule of a property. It returns reserv.ErrPricingRuleNotFound if it doesn't exist.
+	UpdatePricingRule(ctx context.Context, rule reserv.PricingRule) error
+	// DeletePricingRule deletes a pricing rule of a property. It returns reserv.ErrPricingRuleNotFound if it doesn't exist.
+	DeletePricingRule(ctx context.Context, propertyID, id string) error
+	// PricingRules gets all pricing rules of a property
+	PricingRules(ctx context.Context, propertyID string) ([]reserv.PricingRule, error)
+
+	// Booking rules methods
+	// BookingRules gets the booking rules of a property. Properties without rules get reserv.DefaultBookingRules.
+	BookingRules(ctx context.Context, propertyID string) (reserv.BookingRules, error)
+	// SetBookingRules creates or replaces the booking rules of a property
+	SetBookingRules(ctx context.Context, rules reserv.BookingRules) error
+
+	// Cancellation policy methods
+	// CancellationPolicy gets the cancellation policy of a property. Properties without a policy get reserv.DefaultCancellationPolicy.
+	CancellationPolicy(ctx context.Context, propertyID string) (reserv.CancellationPolicy, error)
+	// SetCancellationPolicy creates or replaces the cancellation policy of a property
+	SetCancellationPolicy(ctx context.Context, policy reserv.CancellationPolicy) error
+
+	// Calendar export methods
+	// CalendarExportToken gets the secret token of the iCalendar feed of a property. It is empty if the property has no token yet.
+	CalendarExportToken(ctx context.Context, propertyID string) (string, error)
+	// SetCalendarExportToken creates or replaces the secret token of the iCalendar feed of a property
+	SetCalendarExportToken(ctx context.Context, propertyID, token string) error
+
+	// Calendar import methods
+	// CreateCalendarImport registers an external calendar of a property
+	CreateCalendarImport(ctx context.Context, imp reserv.CalendarImport) (string, error)
+	// GetCalendarImport gets a calendar import of a property. It returns reserv.ErrCalendarImportNotFound if it doesn't exist.
+	GetCalendarImport(ctx context.Context, propertyID, id string) (reserv.CalendarImport, error)
+	// CalendarImports gets all calendar imports of a property
+	CalendarImports(ctx context.Context, propertyID string) ([]reserv.CalendarImport, error)
+	// DeleteCalendarImport deletes a calendar import of a property and its blocks
+	DeleteCalendarImport(ctx context.Context, propertyID, id string) error
+	// CalendarImportsToSync gets the imports with a URL whose last sync attempt happened before syncedBefore
+	CalendarImportsToSync(ctx context.Context, syncedBefore time.Time) ([]reserv.CalendarImport, error)
+	// SyncCalendarImport replaces the blocks of an import and returns the blocks that conflict with bookings
+	SyncCalendarImport(ctx context.Context, imp reserv.CalendarImport, blocks []reserv.CalendarBlock) (reserv.CalendarSyncResult, error)
+	// FailCalendarImport records a failed sync attempt of an import
+	FailCalendarImport(ctx context.Context, id string, syncErr string) error
+}
+
+// authorizeHost checks that the user of the request is the host of the property with the id in the path.
+// If not, the error is written to the response writer and false is returned.
+func (h *Handler) authorizeHost(w http.ResponseWriter, r *http.Request) (reserv.Property, bool) {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
+	if !ok {
+		slog.Warn("unauthorized, no claims")
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return reserv.Property{}, false
+	}
+
+	propertyID := r.PathValue("id")
+	if propertyID == "" {
+		NewAPIError("missing_property_id", "missing property id", http.StatusBadRequest).Write(w)
+		return reserv.Property{}, false
+	}
+
+	affected, property, err := h.repo.GetProperty(r.Context(), propertyID)
+	if err != nil {
+		slog.Error("failed to get property", "error", err)
+		NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError).Write(w)
+		return reserv.Property{}, false
+	}
+
+	if affected == 0 {
+		NewAPIError("property_not_found", "property not found", http.StatusNotFound).Write(w)
+		return reserv.Property{}, false
+	}
+
+	if claims.Subject != property.HostID {
+		slog.Warn("unauthorized, different user from hostID and jwt", "host_id", property.HostID, "jwt_subject", claims.Subject)
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return reserv.Property{}, false
+	}
+
+	return property, true
+}
+
+// setIfPresent sets dst to the value of a request field, unless the field was omitted.
+func setIfPresent[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// PropertyCapacity represents the capacity fields of the requests for creating and updating a property. Omitted
+// fields keep the current value of the property on updates.
+type PropertyCapacity struct {
+	// MaxGuests is the maximum number of adults and children. Defaults to 1, like the properties created before it existed.
+	MaxGuests *int `json:"max_guests"`
+	Bedrooms  *int `json:"bedrooms"`
+	Beds      *int `json:"beds"`
+	Bathrooms *int `json:"bathrooms"`
+	// MaxPets is the maximum number of pets. 0 means pets are not allowed.
+	MaxPets *int `json:"max_pets"`
+	// GuestsIncluded is the number of guests covered by the nightly price. Defaults to MaxGuests.
+	GuestsIncluded *int `json:"guests_included"`
+	// ExtraGuestFeeCents is the price per night of each guest above GuestsIncluded.
+	ExtraGuestFeeCents *int64 `json:"extra_guest_fee_cents"`
+}
+
+// apply copies the capacity fields present in the request to the property. When guests_included is omitted, the
+// current value is kept as long as it fits MaxGuests, otherwise the nightly price covers all the guests.
+func (c PropertyCapacity) apply(property *reserv.Property) {
+	setIfPresent(&property.MaxGuests, c.MaxGuests)
+	setIfPresent(&property.Bedrooms, c.Bedrooms)
+	setIfPresent(&property.Beds, c.Beds)
+	setIfPresent(&property.Bathrooms, c.Bathrooms)
+	setIfPresent(&property.MaxPets, c.MaxPets)
+	setIfPresent(&property.ExtraGuestFeeCents, c.ExtraGuestFeeCents)
+	if c.GuestsIncluded != nil {
+		property.GuestsIncluded = *c.GuestsIncluded
+	} else if property.GuestsIncluded == 0 || property.GuestsIncluded > property.MaxGuests {
+		property.GuestsIncluded = property.MaxGuests
+	}
+}
+
+// PropertyFees represents the fee fields of the requests for creating and updating a property. Omitted fields keep
+// the current value of the property on updates.
+type PropertyFees struct {
+	// CleaningFeeCents is the price charged once per stay for cleaning the property.
+	CleaningFeeCents *int64 `json:"cleaning_fee_cents"`
+	// Jurisdiction is the tax jurisdiction of the property. Example: "US-NY-NYC". Empty means the stays are not taxed.
+	Jurisdiction *string `json:"jurisdiction"`
+}
+
+// apply copies the fee fields present in the request to the property.
+func (f PropertyFees) apply(property *reserv.Property) {
+	setIfPresent(&property.CleaningFeeCents, f.CleaningFeeCents)
+	setIfPresent(&property.Jurisdiction, f.Jurisdiction)
+}
+
+// PropertyDiscounts represents the long stay discount fields of the requests for creating and updating a property.
+// Omitted fields keep the current value of the property on updates.
+type PropertyDiscounts struct {
+	// WeeklyDiscountPercent is the discount of the stays of at least 7 nights. Example: 10 for 10% off.
+	WeeklyDiscountPercent *int `json:"weekly_discount_percent"`
+	// MonthlyDiscountPercent is the discount of the stays of at least 28 nights. It replaces the weekly discount.
+	MonthlyDiscountPercent *int `json:"monthly_discount_percent"`
+}
+
+// apply copies the discount fields present in the request to the property.
+func (d PropertyDiscounts) apply(property *reserv.Property) {
+	setIfPresent(&property.WeeklyDiscountPercent, d.WeeklyDiscountPercent)
+	setIfPresent(&property.MonthlyDiscountPercent, d.MonthlyDiscountPercent)
+}
+
+// instantBook returns the instant book flag of a request. Properties are instantly bookable unless the host turns it off.
+func instantBook(v *bool) bool {
+	return v == nil || *v
 }
 
 // CreatePropertyRequest represents the request body for creating a property
 type CreatePropertyRequest struct {
-	Title              string   `json:"title"`
-	Description        string   `json:"description"`
-	PricePerNightCents int64    `json:"price_per_night_cents"`
-	Currency           string   `json:"currency"`
-	HostID             string   `json:"host_id"`
-	Amenities          []string `json:"amenities"`
+	Title              string `json:"title"`
+	Description        string `json:"description"`
+	PricePerNightCents int64  `json:"price_per_night_cents"`
+	// Currency is the ISO 4217 code of the currency of the prices, in upper case. Example: "USD".
+	Currency  string   `json:"currency"`
+	HostID    string   `json:"host_id"`
+	Amenities []string `json:"amenities"`
+	// InstantBook is whether the bookings are confirmed right away. Defaults to true.
+	InstantBook *bool `json:"instant_book"`
+	PropertyCapacity
+	PropertyFees
+	PropertyDiscounts
 }
 
 // CreateProperty creates a new property
@@ -77,6 +263,11 @@ func (h *Handler) CreateProperty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := reserv.ValidateCurrency(req.Currency); err != nil {
+		NewAPIError("invalid_currency", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
 	if claims.Subject != req.HostID {
 		slog.Warn("unauthorized, different user from hostID and jwt", "host_id", req.HostID, "jwt_subject", claims.Subject)
 		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
@@ -90,9 +281,29 @@ func (h *Handler) CreateProperty(w http.ResponseWriter, r *http.Request) {
 		PricePerNightCents: req.PricePerNightCents,
 		Currency:           req.Currency,
 		HostID:             req.HostID,
+		InstantBook:        instantBook(req.InstantBook),
+		MaxGuests:          1,
 		CreatedAt:          now,
 		UpdatedAt:          now,
 	}
+	req.PropertyCapacity.apply(&property)
+	req.PropertyFees.apply(&property)
+	req.PropertyDiscounts.apply(&property)
+
+	if err := property.ValidateCapacity(); err != nil {
+		NewAPIError("invalid_capacity", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	if err := property.ValidateFees(); err != nil {
+		NewAPIError("invalid_fees", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	if err := property.ValidateDiscounts(); err != nil {
+		NewAPIError("invalid_discounts", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
 
 	id, err := h.repo.CreateProperty(r.Context(), property)
 	if err != nil {
@@ -119,12 +330,19 @@ func (h *Handler) CreateProperty(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// UpdatePropertyRequest represents the request body for updating a property
+// UpdatePropertyRequest represents the request body for updating a property. The optional fields that are omitted
+// keep their current value, so clients that don't know a field never reset it.
 type UpdatePropertyRequest struct {
 	Title              string `json:"title"`
 	Description        string `json:"description"`
 	PricePerNightCents int64  `json:"price_per_night_cents"`
-	Currency           string `json:"currency"`
+	// Currency is the ISO 4217 code of the currency of the prices, in upper case. Example: "USD".
+	Currency string `json:"currency"`
+	// InstantBook is whether the bookings are confirmed right away. When omitted, the current setting is kept.
+	InstantBook *bool `json:"instant_book"`
+	PropertyCapacity
+	PropertyFees
+	PropertyDiscounts
 }
 
 // UpdateProperty updates an existing property
@@ -157,12 +375,47 @@ func (h *Handler) UpdateProperty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	property := reserv.Property{
-		Title:              req.Title,
-		Description:        req.Description,
-		PricePerNightCents: req.PricePerNightCents,
-		Currency:           req.Currency,
-		UpdatedAt:          time.Now(),
+	if err := reserv.ValidateCurrency(req.Currency); err != nil {
+		NewAPIError("invalid_currency", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	affected, property, err := h.repo.GetProperty(r.Context(), propertyID)
+	if err != nil {
+		slog.Error("failed to get property", "error", err)
+		NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if affected == 0 {
+		NewAPIError("property_not_found", "property not found", http.StatusNotFound).Write(w)
+		return
+	}
+
+	property.Title = req.Title
+	property.Description = req.Description
+	property.PricePerNightCents = req.PricePerNightCents
+	property.Currency = req.Currency
+	property.UpdatedAt = time.Now()
+	// Requests without instant_book keep the setting of the host, instead of turning instant book back on.
+	setIfPresent(&property.InstantBook, req.InstantBook)
+	req.PropertyCapacity.apply(&property)
+	req.PropertyFees.apply(&property)
+	req.PropertyDiscounts.apply(&property)
+
+	if err := property.ValidateCapacity(); err != nil {
+		NewAPIError("invalid_capacity", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	if err := property.ValidateFees(); err != nil {
+		NewAPIError("invalid_fees", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	if err := property.ValidateDiscounts(); err != nil {
+		NewAPIError("invalid_discounts", err.Error(), http.StatusBadRequest).Write(w)
+		return
 	}
 
 	if err := h.repo.UpdateProperty(r.Context(), property, propertyID); err != nil {
@@ -190,7 +443,13 @@ func (h *Handler) DeleteProperty(w http.ResponseWriter, r *http.Request) {
 	}
 	slog.Info("delete property", "property_id", propertyID)
 
-	if err := h.repo.DeleteProperty(r.Context(), propertyID); err != nil {
+	err := h.repo.DeleteProperty(r.Context(), propertyID)
+	if errors.Is(err, reserv.ErrPropertyHasPayments) {
+		slog.Warn("property has payments", "property_id", propertyID)
+		NewAPIError("property_has_payments", "the property has bookings with payments in progress or charged", http.StatusConflict).Write(w)
+		return
+	}
+	if err != nil {
 		slog.Error("failed to delete property", "error", err)
 		NewAPIError("delete_property_error", "failed to delete property", http.StatusInternalServerError).Write(w)
 		return
@@ -199,7 +458,7 @@ func (h *Handler) DeleteProperty(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// GetProperty gets a property by id
+// GetProperty gets a property by id. With ?currency=, the prices are also converted to the currency in display_price.
 func (h *Handler) GetProperty(w http.ResponseWriter, r *http.Request) {
 	slog.Info("get property")
 	_, ok := clerk.SessionClaimsFromContext(r.Context())
@@ -214,6 +473,13 @@ func (h *Handler) GetProperty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	slog.Info("get property", "property_id", propertyID)
+
+	currency, rates, apiErr := h.displayCurrency(r)
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
 	affected, property, err := h.repo.GetProperty(r.Context(), propertyID)
 	if err != nil {
 		slog.Error("failed to get property", "error", err)
@@ -225,6 +491,21 @@ func (h *Handler) GetProperty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if currency != "" {
+		displayPrice, err := reserv.NewDisplayPrice(property, rates, currency)
+		if errors.Is(err, reserv.ErrExchangeRateNotFound) || errors.Is(err, reserv.ErrAmountOverflow) {
+			slog.Warn("failed to convert property price", "error", err, "property_id", propertyID)
+			NewAPIError("exchange_rate_not_found", err.Error(), http.StatusUnprocessableEntity).Write(w)
+			return
+		}
+		if err != nil {
+			slog.Error("failed to convert property price", "error", err)
+			NewAPIError("exchange_rate_error", "failed to convert property price", http.StatusInternalServerError).Write(w)
+			return
+		}
+		property.DisplayPrice = &displayPrice
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(property)
 	if err != nil {
@@ -234,7 +515,7 @@ func (h *Handler) GetProperty(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// GetProperties gets all properties
+// GetProperties gets all properties. With ?currency=, the prices are also converted to the currency in display_price.
 func (h *Handler) GetProperties(w http.ResponseWriter, r *http.Request) {
 	slog.Info("get properties")
 	claims, ok := clerk.SessionClaimsFromContext(r.Context())
@@ -257,6 +538,12 @@ func (h *Handler) GetProperties(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	currency, rates, apiErr := h.displayCurrency(r)
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
 	properties, err := h.repo.Properties(r.Context(), reserv.PropertyFilter{HostID: hostID})
 	if err != nil {
 		slog.Error("failed to get properties", "error", err)
@@ -265,6 +552,18 @@ func (h *Handler) GetProperties(w http.ResponseWriter, r *http.Request) {
 	}
 	slog.Info("get properties", "host_id", hostID)
 
+	// A listing without a rate to the requested currency is still listed, only without the display price.
+	if currency != "" {
+		for i := range properties {
+			displayPrice, err := reserv.NewDisplayPrice(properties[i], rates, currency)
+			if err != nil {
+				slog.Warn("failed to convert property price", "error", err, "property_id", properties[i].ID)
+				continue
+			}
+			properties[i].DisplayPrice = &displayPrice
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(properties)
 	if err != nil {
@@ -331,3 +630,30 @@ func (h *Handler) GetAmenities(w http.ResponseWriter, r *http.Request) {
 
 	_, _ = w.Write(amenitiesBytes)
 }
+
+// displayCurrency reads the currency query parameter, used to display the prices of the properties in the currency of
+// the guest, and loads the exchange rates. The currency is empty when the parameter is not set.
+func (h *Handler) displayCurrency(r *http.Request) (string, reserv.ExchangeRates, *APIError) {
+	currency := r.URL.Query().Get("currency")
+	if currency == "" {
+		return "", reserv.ExchangeRates{}, nil
+	}
+
+	if err := reserv.ValidateCurrency(currency); err != nil {
+		return "", reserv.ExchangeRates{}, NewAPIError("invalid_currency", err.Error(), http.StatusBadRequest)
+	}
+
+	list, err := h.repo.ExchangeRates(r.Context())
+	if err != nil {
+		slog.Error("failed to get exchange rates", "error", err)
+		return "", reserv.ExchangeRates{}, NewAPIError("get_exchange_rates_error", "failed to get exchange rates", http.StatusInternalServerError)
+	}
+
+	rates, err := reserv.NewExchangeRates(list)
+	if err != nil {
+		slog.Error("invalid exchange rates", "error", err)
+		return "", reserv.ExchangeRates{}, NewAPIError("get_exchange_rates_error", "failed to get exchange rates", http.StatusInternalServerError)
+	}
+
+	return currency, rates, nil
+}
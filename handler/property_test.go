@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -30,8 +31,22 @@ func TestCreateProperty(t *testing.T) {
 		PricePerNightCents: 10000,
 		Currency:           "USD",
 		HostID:             uid,
+		PropertyCapacity: handler.PropertyCapacity{
+			MaxGuests: ptr(4),
+			Bedrooms:  ptr(2),
+			Beds:      ptr(3),
+			Bathrooms: ptr(1),
+		},
+		PropertyDiscounts: handler.PropertyDiscounts{WeeklyDiscountPercent: ptr(10), MonthlyDiscountPercent: ptr(25)},
 	}
-	repo.EXPECT().CreateProperty(gomock.Any(), gomock.Any()).Return(uid, nil)
+	repo.EXPECT().CreateProperty(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, property reserv.Property) (string, error) {
+		require.Equal(t, 4, property.MaxGuests)
+		// The nightly price covers all the guests when guests_included is omitted.
+		require.Equal(t, 4, property.GuestsIncluded)
+		require.Equal(t, 10, property.WeeklyDiscountPercent)
+		require.Equal(t, 25, property.MonthlyDiscountPercent)
+		return uid, nil
+	})
 
 	jsonBody, err := json.Marshal(payload)
 	require.NoError(t, err)
@@ -48,7 +63,7 @@ func TestCreateProperty(t *testing.T) {
 	})
 	req = req.WithContext(ctx)
 	mux := http.NewServeMux()
-	propHandler := handler.NewHandler(repo, nil, nil)
+	propHandler := handler.NewHandler(repo, nil, nil, nil)
 	propHandler.RegisterRoutes(mux)
 	mux.ServeHTTP(resp, req)
 
@@ -62,27 +77,134 @@ func TestCreateProperty(t *testing.T) {
 }
 
 func TestUpdateProperty(t *testing.T) {
+	stored := reserv.Property{
+		Title:                  "Old Title",
+		PricePerNightCents:     8000,
+		Currency:               "USD",
+		InstantBook:            false,
+		MaxGuests:              4,
+		Bedrooms:               2,
+		Beds:                   3,
+		Bathrooms:              1,
+		MaxPets:                1,
+		GuestsIncluded:         3,
+		ExtraGuestFeeCents:     1500,
+		CleaningFeeCents:       5000,
+		Jurisdiction:           "US-NY-NYC",
+		WeeklyDiscountPercent:  10,
+		MonthlyDiscountPercent: 20,
+	}
+
+	tests := []struct {
+		name      string
+		capacity  handler.PropertyCapacity
+		fees      handler.PropertyFees
+		discounts handler.PropertyDiscounts
+		want      func(reserv.Property) reserv.Property
+	}{
+		{
+			// The request has no instant_book, capacity, fees nor discounts, so the host keeps them.
+			name: "omitted fields keep the stored values",
+			want: func(p reserv.Property) reserv.Property { return p },
+		},
+		{
+			name:     "present fields are replaced",
+			capacity: handler.PropertyCapacity{Bedrooms: ptr(3), MaxPets: ptr(0)},
+			want: func(p reserv.Property) reserv.Property {
+				p.Bedrooms, p.MaxPets = 3, 0
+				return p
+			},
+		},
+		{
+			name: "fees are removed explicitly",
+			fees: handler.PropertyFees{CleaningFeeCents: ptr(int64(0)), Jurisdiction: ptr("")},
+			want: func(p reserv.Property) reserv.Property {
+				p.CleaningFeeCents, p.Jurisdiction = 0, ""
+				return p
+			},
+		},
+		{
+			name:      "monthly discount changed alone",
+			discounts: handler.PropertyDiscounts{MonthlyDiscountPercent: ptr(30)},
+			want: func(p reserv.Property) reserv.Property {
+				p.MonthlyDiscountPercent = 30
+				return p
+			},
+		},
+		{
+			name:     "guests included is capped by the new max guests",
+			capacity: handler.PropertyCapacity{MaxGuests: ptr(2)},
+			want: func(p reserv.Property) reserv.Property {
+				p.MaxGuests, p.GuestsIncluded = 2, 2
+				return p
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockPropertyRepository(ctrl)
+
+			payload := handler.UpdatePropertyRequest{
+				Title:              "Test Property",
+				Description:        "Test Description",
+				PricePerNightCents: 10000,
+				Currency:           "USD",
+				PropertyCapacity:   tt.capacity,
+				PropertyFees:       tt.fees,
+				PropertyDiscounts:  tt.discounts,
+			}
+
+			propertyID := uuid.New().String()
+			repo.EXPECT().GetProperty(gomock.Any(), propertyID).Return(1, stored, nil)
+			repo.EXPECT().UpdateProperty(gomock.Any(), gomock.Any(), propertyID).DoAndReturn(func(_ context.Context, property reserv.Property, _ string) error {
+				want := tt.want(stored)
+				want.Title = "Test Property"
+				want.Description = "Test Description"
+				want.PricePerNightCents = 10000
+				want.UpdatedAt = property.UpdatedAt
+				require.Equal(t, want, property)
+				return nil
+			})
+
+			jsonBody, err := json.Marshal(payload)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPut, "/properties/"+propertyID, bytes.NewBuffer(jsonBody))
+			resp := httptest.NewRecorder()
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test_token")
+
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: "user_2x5CiRO5Mf0wBpWO8w469jEJhRq",
+				},
+			})
+			req = req.WithContext(ctx)
+			mux := http.NewServeMux()
+			h := handler.NewHandler(repo, nil, nil, nil)
+			h.RegisterRoutes(mux)
+			mux.ServeHTTP(resp, req)
+
+			rBody := resp.Body.String()
+			require.Equal(t, http.StatusNoContent, resp.Code, rBody)
+		})
+	}
+}
+
+func TestDeleteProperty(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mock.NewMockPropertyRepository(ctrl)
 
-	payload := handler.UpdatePropertyRequest{
-		Title:              "Test Property",
-		Description:        "Test Description",
-		PricePerNightCents: 10000,
-		Currency:           "USD",
-	}
-
 	propertyID := uuid.New().String()
-	repo.EXPECT().UpdateProperty(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-
-	jsonBody, err := json.Marshal(payload)
-	require.NoError(t, err)
+	repo.EXPECT().DeleteProperty(gomock.Any(), gomock.Any()).Return(nil)
 
-	req := httptest.NewRequest(http.MethodPut, "/properties/"+propertyID, bytes.NewBuffer(jsonBody))
-	resp := httptest.NewRecorder()
-	req.Header.Set("Content-Type", "application/json")
+	req := httptest.NewRequest(http.MethodDelete, "/properties/"+propertyID, nil)
 	req.Header.Set("Authorization", "Bearer test_token")
 
 	ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
@@ -91,8 +213,10 @@ func TestUpdateProperty(t *testing.T) {
 		},
 	})
 	req = req.WithContext(ctx)
+	resp := httptest.NewRecorder()
+
 	mux := http.NewServeMux()
-	handler := handler.NewHandler(repo, nil, nil)
+	handler := handler.NewHandler(repo, nil, nil, nil)
 	handler.RegisterRoutes(mux)
 	mux.ServeHTTP(resp, req)
 
@@ -100,14 +224,14 @@ func TestUpdateProperty(t *testing.T) {
 	require.Equal(t, http.StatusNoContent, resp.Code, rBody)
 }
 
-func TestDeleteProperty(t *testing.T) {
+func TestDeleteProperty_HasPayments(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mock.NewMockPropertyRepository(ctrl)
 
 	propertyID := uuid.New().String()
-	repo.EXPECT().DeleteProperty(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().DeleteProperty(gomock.Any(), propertyID).Return(reserv.ErrPropertyHasPayments)
 
 	req := httptest.NewRequest(http.MethodDelete, "/properties/"+propertyID, nil)
 	req.Header.Set("Authorization", "Bearer test_token")
@@ -121,12 +245,15 @@ func TestDeleteProperty(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	mux := http.NewServeMux()
-	handler := handler.NewHandler(repo, nil, nil)
-	handler.RegisterRoutes(mux)
+	handler.NewHandler(repo, nil, nil, nil).RegisterRoutes(mux)
 	mux.ServeHTTP(resp, req)
 
 	rBody := resp.Body.String()
-	require.Equal(t, http.StatusNoContent, resp.Code, rBody)
+	require.Equal(t, http.StatusConflict, resp.Code, rBody)
+
+	var apiErr handler.APIError
+	require.NoError(t, json.Unmarshal([]byte(rBody), &apiErr))
+	require.Equal(t, "property_has_payments", apiErr.Code)
 }
 
 func TestGetProperty(t *testing.T) {
@@ -150,7 +277,7 @@ func TestGetProperty(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	mux := http.NewServeMux()
-	handler := handler.NewHandler(repo, nil, nil)
+	handler := handler.NewHandler(repo, nil, nil, nil)
 	handler.RegisterRoutes(mux)
 	mux.ServeHTTP(resp, req)
 
@@ -186,7 +313,7 @@ func TestGetProperty_NotFound(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	mux := http.NewServeMux()
-	handler := handler.NewHandler(repo, nil, nil)
+	handler := handler.NewHandler(repo, nil, nil, nil)
 	handler.RegisterRoutes(mux)
 	mux.ServeHTTP(resp, req)
 
@@ -208,7 +335,7 @@ func TestGetAmenities(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	mux := http.NewServeMux()
-	handler := handler.NewHandler(repo, nil, nil)
+	handler := handler.NewHandler(repo, nil, nil, nil)
 	handler.RegisterRoutes(mux)
 	mux.ServeHTTP(resp, req)
 
@@ -247,7 +374,7 @@ func TestCreatePropertyAmenity(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	mux := http.NewServeMux()
-	handler := handler.NewHandler(repo, nil, nil)
+	handler := handler.NewHandler(repo, nil, nil, nil)
 	handler.RegisterRoutes(mux)
 	mux.ServeHTTP(resp, req)
 
@@ -278,7 +405,7 @@ func TestGetProperties_WithHostIDFilter(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	mux := http.NewServeMux()
-	handler := handler.NewHandler(repo, nil, nil)
+	handler := handler.NewHandler(repo, nil, nil, nil)
 	handler.RegisterRoutes(mux)
 	mux.ServeHTTP(resp, req)
 
@@ -308,3 +435,320 @@ func TestGetProperties_WithHostIDFilter(t *testing.T) {
 	rBody = resp.Body.String()
 	require.Equal(t, http.StatusUnauthorized, resp.Code, rBody)
 }
+
+func TestCreateProperty_InvalidCapacity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	uid := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	payload := handler.CreatePropertyRequest{
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		HostID:             uid,
+		PropertyCapacity:   handler.PropertyCapacity{MaxGuests: ptr(2), GuestsIncluded: ptr(3)},
+	}
+
+	jsonBody, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/properties", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test_token")
+	ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+		RegisteredClaims: clerk.RegisteredClaims{
+			Subject: uid,
+		},
+	})
+	req = req.WithContext(ctx)
+
+	resp := httptest.NewRecorder()
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+	mux.ServeHTTP(resp, req)
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusBadRequest, resp.Code, rBody)
+
+	var apiErr handler.APIError
+	require.NoError(t, json.Unmarshal([]byte(rBody), &apiErr))
+	require.Equal(t, "invalid_capacity", apiErr.Code)
+}
+
+func TestCreateProperty_DefaultCapacity(t *testing.T) {
+	uid := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+
+	tests := []struct {
+		name       string
+		capacity   handler.PropertyCapacity
+		wantStatus int
+	}{
+		{name: "omitted max_guests defaults to 1", wantStatus: http.StatusCreated},
+		{name: "explicit max_guests must be at least 1", capacity: handler.PropertyCapacity{MaxGuests: ptr(0)}, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockPropertyRepository(ctrl)
+			if tt.wantStatus == http.StatusCreated {
+				repo.EXPECT().CreateProperty(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, property reserv.Property) (string, error) {
+					require.Equal(t, 1, property.MaxGuests)
+					require.Equal(t, 1, property.GuestsIncluded)
+					return "property", nil
+				})
+			}
+
+			jsonBody, err := json.Marshal(handler.CreatePropertyRequest{
+				Title:              "Test Property",
+				Description:        "Test Description",
+				PricePerNightCents: 10000,
+				Currency:           "USD",
+				HostID:             uid,
+				PropertyCapacity:   tt.capacity,
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/properties", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: uid,
+				},
+			})
+			req = req.WithContext(ctx)
+
+			resp := httptest.NewRecorder()
+			mux := http.NewServeMux()
+			h := handler.NewHandler(repo, nil, nil, nil)
+			h.RegisterRoutes(mux)
+			mux.ServeHTTP(resp, req)
+
+			require.Equal(t, tt.wantStatus, resp.Code, resp.Body.String())
+		})
+	}
+}
+
+func TestCreateProperty_InvalidDiscounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	uid := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	payload := handler.CreatePropertyRequest{
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		HostID:             uid,
+		PropertyCapacity:   handler.PropertyCapacity{MaxGuests: ptr(2)},
+		// Staying a month would cost more per night than staying a week.
+		PropertyDiscounts: handler.PropertyDiscounts{WeeklyDiscountPercent: ptr(20), MonthlyDiscountPercent: ptr(10)},
+	}
+
+	jsonBody, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/properties", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test_token")
+	ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+		RegisteredClaims: clerk.RegisteredClaims{
+			Subject: uid,
+		},
+	})
+	req = req.WithContext(ctx)
+
+	resp := httptest.NewRecorder()
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+	mux.ServeHTTP(resp, req)
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusBadRequest, resp.Code, rBody)
+
+	var apiErr handler.APIError
+	require.NoError(t, json.Unmarshal([]byte(rBody), &apiErr))
+	require.Equal(t, "invalid_discounts", apiErr.Code)
+}
+
+func TestPropertyHandlers_InvalidCurrency(t *testing.T) {
+	uid := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	propertyID := uuid.New().String()
+
+	for _, currency := range []string{"usd", "dollar", "XXX"} {
+		create := handler.CreatePropertyRequest{
+			Title:              "Test Property",
+			Description:        "Test Description",
+			PricePerNightCents: 10000,
+			Currency:           currency,
+			HostID:             uid,
+			PropertyCapacity:   handler.PropertyCapacity{MaxGuests: ptr(2)},
+		}
+		update := handler.UpdatePropertyRequest{
+			Title:              "Test Property",
+			Description:        "Test Description",
+			PricePerNightCents: 10000,
+			Currency:           currency,
+			PropertyCapacity:   handler.PropertyCapacity{MaxGuests: ptr(2)},
+		}
+
+		for _, tt := range []struct {
+			method, target string
+			body           any
+		}{
+			{method: http.MethodPost, target: "/properties", body: create},
+			{method: http.MethodPut, target: "/properties/" + propertyID, body: update},
+		} {
+			t.Run(tt.method+" "+currency, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				// The repository must not be called.
+				repo := mock.NewMockPropertyRepository(ctrl)
+
+				jsonBody, err := json.Marshal(tt.body)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(tt.method, tt.target, bytes.NewBuffer(jsonBody))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer test_token")
+				ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+					RegisteredClaims: clerk.RegisteredClaims{
+						Subject: uid,
+					},
+				})
+
+				resp := httptest.NewRecorder()
+				mux := http.NewServeMux()
+				h := handler.NewHandler(repo, nil, nil, nil)
+				h.RegisterRoutes(mux)
+				mux.ServeHTTP(resp, req.WithContext(ctx))
+
+				rBody := resp.Body.String()
+				require.Equal(t, http.StatusBadRequest, resp.Code, rBody)
+
+				var apiErr handler.APIError
+				require.NoError(t, json.Unmarshal([]byte(rBody), &apiErr))
+				require.Equal(t, "invalid_currency", apiErr.Code)
+			})
+		}
+	}
+}
+
+func TestGetProperty_DisplayCurrency(t *testing.T) {
+	uid := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	updatedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	rates := []reserv.ExchangeRate{{Base: "USD", Quote: "BRL", Rate: "5.12345", UpdatedAt: updatedAt}}
+
+	tests := []struct {
+		name       string
+		currency   string
+		wantStatus int
+		wantCode   string
+		wantPrice  *reserv.DisplayPrice
+	}{
+		{name: "converts the prices", currency: "BRL", wantStatus: http.StatusOK, wantPrice: &reserv.DisplayPrice{Currency: "BRL", PricePerNightCents: 51235, ExtraGuestFeeCents: 12809, Rate: "5.123450", RatesUpdatedAt: updatedAt}},
+		{name: "same currency as the property", currency: "USD", wantStatus: http.StatusOK, wantPrice: &reserv.DisplayPrice{Currency: "USD", PricePerNightCents: 10000, ExtraGuestFeeCents: 2500, Rate: "1.000000", RatesUpdatedAt: updatedAt}},
+		{name: "missing rate", currency: "EUR", wantStatus: http.StatusUnprocessableEntity, wantCode: "exchange_rate_not_found"},
+		{name: "invalid currency", currency: "brl", wantStatus: http.StatusBadRequest, wantCode: "invalid_currency"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			propertyID := uuid.New()
+			repo := mock.NewMockPropertyRepository(ctrl)
+			if tt.wantCode != "invalid_currency" {
+				repo.EXPECT().ExchangeRates(gomock.Any()).Return(rates, nil)
+				repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, PricePerNightCents: 10000, ExtraGuestFeeCents: 2500, Currency: "USD"}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/properties/"+propertyID.String()+"?currency="+tt.currency, nil)
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: uid,
+				},
+			})
+
+			resp := httptest.NewRecorder()
+			mux := http.NewServeMux()
+			h := handler.NewHandler(repo, nil, nil, nil)
+			h.RegisterRoutes(mux)
+			mux.ServeHTTP(resp, req.WithContext(ctx))
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+
+			if tt.wantCode != "" {
+				var apiErr handler.APIError
+				require.NoError(t, json.Unmarshal([]byte(rBody), &apiErr))
+				require.Equal(t, tt.wantCode, apiErr.Code)
+				return
+			}
+
+			var response reserv.Property
+			require.NoError(t, json.Unmarshal([]byte(rBody), &response))
+			require.Equal(t, int64(10000), response.PricePerNightCents)
+			require.Equal(t, "USD", response.Currency)
+			require.Equal(t, tt.wantPrice, response.DisplayPrice)
+		})
+	}
+}
+
+func TestGetProperties_DisplayCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uid := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	repo := mock.NewMockPropertyRepository(ctrl)
+	repo.EXPECT().ExchangeRates(gomock.Any()).Return([]reserv.ExchangeRate{
+		{Base: "USD", Quote: "BRL", Rate: "5", UpdatedAt: time.Now()},
+		{Base: "USD", Quote: "JPY", Rate: "150", UpdatedAt: time.Now()},
+	}, nil)
+	repo.EXPECT().Properties(gomock.Any(), gomock.Any()).Return([]reserv.Property{
+		{ID: uuid.New(), Title: "Dollars", PricePerNightCents: 10000, Currency: "USD"},
+		{ID: uuid.New(), Title: "Yen", PricePerNightCents: 15000, Currency: "JPY"},
+		{ID: uuid.New(), Title: "Euros", PricePerNightCents: 10000, Currency: "EUR"},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/properties?currency=BRL", nil)
+	req.Header.Set("Authorization", "Bearer test_token")
+	ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+		RegisteredClaims: clerk.RegisteredClaims{
+			Subject: uid,
+		},
+	})
+
+	resp := httptest.NewRecorder()
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.RegisterRoutes(mux)
+	mux.ServeHTTP(resp, req.WithContext(ctx))
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusOK, resp.Code, rBody)
+
+	var response []reserv.Property
+	require.NoError(t, json.Unmarshal([]byte(rBody), &response))
+	require.Len(t, response, 3)
+	require.NotNil(t, response[0].DisplayPrice)
+	require.Equal(t, int64(50000), response[0].DisplayPrice.PricePerNightCents)
+	// 15000 yen are 100 dollars, so 500 reais, through the cross rate.
+	require.NotNil(t, response[1].DisplayPrice)
+	require.Equal(t, int64(50000), response[1].DisplayPrice.PricePerNightCents)
+	// There is no rate between euros and reais, the property is listed without the display price.
+	require.Nil(t, response[2].DisplayPrice)
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// quoteStay loads the property, its pricing rules and the tax rules of its jurisdiction, checks the guests against the
+// capacity of the property and computes the server side quote for the stay, with the service fee of the platform.
+// The promotion, when not nil, must apply to the stay. The returned APIError is ready to be written to the response writer.
+func (h *Handler) quoteStay(ctx context.Context, propertyID string, checkIn, checkOut time.Time, guests reserv.Guests, promotion *reserv.Promotion) (reserv.Quote, *APIError) {
+	affected, property, err := h.repo.GetProperty(ctx, propertyID)
+	if err != nil {
+		slog.Error("failed to get property", "error", err)
+		return reserv.Quote{}, NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError)
+	}
+
+	if affected == 0 {
+		return reserv.Quote{}, NewAPIError("property_not_found", "property not found", http.StatusNotFound)
+	}
+
+	if err := property.CheckGuests(guests); err != nil {
+		slog.Warn("guests exceed the property capacity", "error", err, "property_id", propertyID)
+		return reserv.Quote{}, bookingRuleError(err)
+	}
+
+	if promotion != nil {
+		if err := promotion.AppliesTo(property, reserv.Nights(checkIn, checkOut)); err != nil {
+			return reserv.Quote{}, NewAPIError("promo_code_not_applicable", err.Error(), http.StatusUnprocessableEntity)
+		}
+	}
+
+	rules, err := h.repo.PricingRules(ctx, propertyID)
+	if err != nil {
+		slog.Error("failed to get pricing rules", "error", err)
+		return reserv.Quote{}, NewAPIError("get_pricing_rules_error", "failed to get pricing rules", http.StatusInternalServerError)
+	}
+
+	var taxRules []reserv.TaxRule
+	if property.Jurisdiction != "" {
+		taxRules, err = h.repo.TaxRules(ctx)
+		if err != nil {
+			slog.Error("failed to get tax rules", "error", err)
+			return reserv.Quote{}, NewAPIError("get_tax_rules_error", "failed to get tax rules", http.StatusInternalServerError)
+		}
+	}
+
+	quote, err := reserv.NewQuote(reserv.QuoteRequest{
+		Property:          property,
+		Rules:             rules,
+		TaxRules:          taxRules,
+		ServiceFeePercent: h.ServiceFeePercent,
+		Promotion:         promotion,
+		CheckInDate:       checkIn,
+		CheckOutDate:      checkOut,
+		Guests:            guests,
+	})
+	if errors.Is(err, reserv.ErrInvalidStay) {
+		return reserv.Quote{}, NewAPIError("invalid_stay", err.Error(), http.StatusUnprocessableEntity)
+	}
+	if errors.Is(err, reserv.ErrAmountOverflow) {
+		return reserv.Quote{}, NewAPIError("amount_overflow", "the price of the stay is too large", http.StatusUnprocessableEntity)
+	}
+	if err != nil {
+		slog.Error("failed to compute quote", "error", err)
+		return reserv.Quote{}, NewAPIError("quote_error", "failed to compute quote", http.StatusInternalServerError)
+	}
+
+	return quote, nil
+}
+
+// promotionByCode loads the promotion of a promo code typed by the guest and checks that it can still be redeemed.
+// An empty code returns no promotion. The returned APIError is ready to be written to the response writer.
+func (h *Handler) promotionByCode(ctx context.Context, code string) (*reserv.Promotion, *APIError) {
+	if code == "" {
+		return nil, nil
+	}
+
+	promotion, err := h.bookingRepo.PromotionByCode(ctx, code)
+	if errors.Is(err, reserv.ErrPromotionNotFound) {
+		return nil, NewAPIError("invalid_promo_code", "promo code not found", http.StatusUnprocessableEntity)
+	}
+	if err != nil {
+		slog.Error("failed to get promotion", "error", err)
+		return nil, NewAPIError("get_promotion_error", "failed to get promotion", http.StatusInternalServerError)
+	}
+
+	if err := promotion.Available(time.Now().UTC()); err != nil {
+		return nil, NewAPIError("promo_code_unavailable", err.Error(), http.StatusUnprocessableEntity)
+	}
+
+	return &promotion, nil
+}
+
+// parseGuests reads the guests of a stay from the query parameters adults, children, infants and pets.
+// Missing counts are 0, except adults, which defaults to 1.
+func parseGuests(query url.Values) (reserv.Guests, error) {
+	guests := reserv.Guests{Adults: 1}
+	for _, param := range []struct {
+		name string
+		dst  *int
+	}{{"adults", &guests.Adults}, {"children", &guests.Children}, {"infants", &guests.Infants}, {"pets", &guests.Pets}} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return reserv.Guests{}, fmt.Errorf("invalid %s: %v", param.name, err)
+		}
+		*param.dst = n
+	}
+	return guests, nil
+}
+
+// GetQuoteHandler returns the itemised price of a stay in a property. The promo_code parameter is optional.
+// Usage: GET /properties/{id}/quote?check_in=2025-01-01&check_out=2025-01-05&adults=2&children=1&promo_code=SUMMER25
+func (h *Handler) GetQuoteHandler(w http.ResponseWriter, r *http.Request) {
+	propertyID := r.PathValue("id")
+	if propertyID == "" {
+		NewAPIError("missing_property_id", "missing property id", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	checkIn := r.URL.Query().Get("check_in")
+	checkOut := r.URL.Query().Get("check_out")
+	slog.Info("get quote", "property_id", propertyID, "check_in", checkIn, "check_out", checkOut)
+	if checkIn == "" || checkOut == "" {
+		NewAPIError("missing_required_fields", "check_in and check_out are required", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	checkInDate, err := time.Parse(dateFormat, checkIn)
+	if err != nil {
+		slog.Warn("invalid date format", "error", err, "date", checkIn)
+		NewAPIError("invalid_date_format", "invalid date format. Expected YYYY-MM-DD", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	checkOutDate, err := time.Parse(dateFormat, checkOut)
+	if err != nil {
+		slog.Warn("invalid date format", "error", err, "date", checkOut)
+		NewAPIError("invalid_date_format", "invalid date format. Expected YYYY-MM-DD", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	guests, err := parseGuests(r.URL.Query())
+	if err != nil {
+		slog.Warn("invalid guests", "error", err)
+		NewAPIError("invalid_guests", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	promotion, apiErr := h.promotionByCode(r.Context(), r.URL.Query().Get("promo_code"))
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	quote, apiErr := h.quoteStay(r.Context(), propertyID, checkInDate, checkOutDate, guests, promotion)
+	if apiErr != nil {
+		apiErr.Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(quote)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
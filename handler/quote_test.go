@@ -0,0 +1,126 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetQuoteHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, PricePerNightCents: 10000, Currency: "USD", MaxGuests: 2, GuestsIncluded: 2}, nil)
+	repo.EXPECT().PricingRules(gomock.Any(), propertyID.String()).Return(nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/properties/"+propertyID.String()+"/quote?check_in=2025-01-01&check_out=2025-01-03", nil)
+	resp := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	handler := handler.NewHandler(repo, nil, nil, nil)
+	handler.RegisterRoutes(mux)
+	mux.ServeHTTP(resp, req)
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusOK, resp.Code, rBody)
+
+	var quote reserv.Quote
+	err := json.Unmarshal([]byte(rBody), &quote)
+	require.NoError(t, err)
+	require.Equal(t, int64(20000), quote.TotalPriceCents)
+	require.Equal(t, "USD", quote.Currency)
+	require.Len(t, quote.Nights, 2)
+	require.Len(t, quote.Lines, 1)
+}
+
+func TestGetQuoteHandler_InvalidStay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{ID: propertyID, PricePerNightCents: 10000, Currency: "USD", MaxGuests: 2, GuestsIncluded: 2}, nil)
+	repo.EXPECT().PricingRules(gomock.Any(), propertyID.String()).Return(nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/properties/"+propertyID.String()+"/quote?check_in=2025-01-03&check_out=2025-01-03", nil)
+	resp := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	handler := handler.NewHandler(repo, nil, nil, nil)
+	handler.RegisterRoutes(mux)
+	mux.ServeHTTP(resp, req)
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusUnprocessableEntity, resp.Code, rBody)
+
+	// missing dates
+	req = httptest.NewRequest(http.MethodGet, "/properties/"+propertyID.String()+"/quote?check_in=2025-01-03", nil)
+	resp = httptest.NewRecorder()
+	mux.ServeHTTP(resp, req)
+
+	rBody = resp.Body.String()
+	require.Equal(t, http.StatusBadRequest, resp.Code, rBody)
+}
+
+func TestGetQuoteHandler_Guests(t *testing.T) {
+	propertyID := uuid.New()
+	property := reserv.Property{ID: propertyID, PricePerNightCents: 10000, Currency: "USD", MaxGuests: 4, GuestsIncluded: 2, ExtraGuestFeeCents: 2000}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantCode   string
+		wantTotal  int64
+	}{
+		{name: "extra guests", query: "&adults=2&children=1&infants=1", wantStatus: http.StatusOK, wantTotal: 24000},
+		{name: "default single adult", wantStatus: http.StatusOK, wantTotal: 20000},
+		{name: "too many guests", query: "&adults=5", wantStatus: http.StatusUnprocessableEntity, wantCode: "too_many_guests"},
+		{name: "pets not allowed", query: "&pets=1", wantStatus: http.StatusUnprocessableEntity, wantCode: "too_many_pets"},
+		{name: "invalid count", query: "&children=two", wantStatus: http.StatusBadRequest, wantCode: "invalid_guests"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockPropertyRepository(ctrl)
+			repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, property, nil).MaxTimes(1)
+			repo.EXPECT().PricingRules(gomock.Any(), propertyID.String()).Return(nil, nil).MaxTimes(1)
+
+			req := httptest.NewRequest(http.MethodGet, "/properties/"+propertyID.String()+"/quote?check_in=2025-01-01&check_out=2025-01-03"+tt.query, nil)
+			resp := httptest.NewRecorder()
+
+			mux := http.NewServeMux()
+			h := handler.NewHandler(repo, nil, nil, nil)
+			h.RegisterRoutes(mux)
+			mux.ServeHTTP(resp, req)
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+			if tt.wantCode != "" {
+				var apiErr handler.APIError
+				require.NoError(t, json.Unmarshal([]byte(rBody), &apiErr))
+				require.Equal(t, tt.wantCode, apiErr.Code)
+				return
+			}
+
+			var quote reserv.Quote
+			require.NoError(t, json.Unmarshal([]byte(rBody), &quote))
+			require.Equal(t, tt.wantTotal, quote.TotalPriceCents)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/perebaj/reserv"
+)
+
+// CreateReview is the request to review a stay.
+type CreateReview struct {
+	// Rating is the overall star rating of the stay, from 1 to 5.
+	Rating int `json:"rating"`
+	reserv.ReviewScores
+	Comment string `json:"comment"`
+}
+
+// ReviewsResponse is a page of reviews, the newest first. NextCursor is empty in the last page.
+type ReviewsResponse struct {
+	Reviews    []reserv.Review `json:"reviews"`
+	NextCursor string          `json:"next_cursor"`
+}
+
+// CreateReviewHandler stores the review of a booking by its guest, from the check-out date of the booking. A booking
+// has a single review.
+// Usage: POST /bookings/{id}/reviews
+func (h *Handler) CreateReviewHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
+	if !ok {
+		slog.Warn("unauthorized, no claims")
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		NewAPIError("missing_booking_id", "missing booking id", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	var req CreateReview
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Warn("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("create review", "booking_id", id, "rating", req.Rating)
+
+	affected, booking, err := h.bookingRepo.GetBooking(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to get booking", "error", err)
+		NewAPIError("failed_to_get_booking", "failed to get booking", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if affected == 0 {
+		NewAPIError("booking_not_found", "booking not found", http.StatusNotFound).Write(w)
+		return
+	}
+
+	if booking.GuestID != claims.Subject {
+		slog.Warn("unauthorized, user is not the guest of the booking", "booking_id", id, "jwt_subject", claims.Subject)
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return
+	}
+
+	now := time.Now().UTC()
+	if err := reserv.CheckReviewable(booking, now); err != nil {
+		NewAPIError("review_not_allowed", err.Error(), http.StatusUnprocessableEntity).Write(w)
+		return
+	}
+
+	review := reserv.Review{
+		BookingID:    booking.ID,
+		PropertyID:   booking.PropertyID,
+		GuestID:      booking.GuestID,
+		Rating:       req.Rating,
+		ReviewScores: req.ReviewScores,
+		Comment:      strings.TrimSpace(req.Comment),
+		CreatedAt:    now,
+	}
+	if err := review.Validate(); err != nil {
+		NewAPIError("invalid_review", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	reviewID, err := h.bookingRepo.CreateReview(r.Context(), review)
+	if errors.Is(err, reserv.ErrReviewExists) {
+		NewAPIError("review_exists", "booking already has a review", http.StatusConflict).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to create review", "error", err)
+		NewAPIError("create_review_error", "failed to create review", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(map[string]string{"id": reviewID})
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// PropertyReviewsHandler returns a page of the reviews of a property, the newest first. Anyone can read them.
+// Usage: GET /properties/{id}/reviews?limit=20&cursor=...
+func (h *Handler) PropertyReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		NewAPIError("missing_property_id", "missing property id", http.StatusBadRequest).Write(w)
+		return
+	}
+
+	page, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+	slog.Info("property reviews", "property_id", id, "limit", page.Limit)
+
+	affected, _, err := h.repo.GetProperty(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to get property", "error", err)
+		NewAPIError("get_property_error", "failed to get property", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if affected == 0 {
+		NewAPIError("property_not_found", "property not found", http.StatusNotFound).Write(w)
+		return
+	}
+
+	reviews, err := h.bookingRepo.PropertyReviews(r.Context(), id, page)
+	if err != nil {
+		slog.Error("failed to get reviews", "error", err)
+		NewAPIError("get_reviews_error", "failed to get reviews", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	resp := ReviewsResponse{Reviews: []reserv.Review{}}
+	reviews, resp.NextCursor = reserv.NextPage(page, reviews, reserv.Review.Cursor)
+	resp.Reviews = append(resp.Reviews, reviews...)
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
@@ -0,0 +1,151 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateReviewHandler(t *testing.T) {
+	checkOut := time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)
+	completed := reserv.Booking{
+		ID:           "booking",
+		PropertyID:   "property",
+		GuestID:      "guest",
+		Status:       reserv.BookingStatusCompleted,
+		CheckInDate:  checkOut.AddDate(0, 0, -3),
+		CheckOutDate: checkOut,
+	}
+	// The host never checked the guest in, but the check-out date came.
+	stayed := completed
+	stayed.Status = reserv.BookingStatusConfirmed
+	upcoming := completed
+	upcoming.Status = reserv.BookingStatusConfirmed
+	upcoming.CheckInDate, upcoming.CheckOutDate = checkOut.AddDate(0, 0, 10), checkOut.AddDate(0, 0, 13)
+
+	valid := handler.CreateReview{
+		Rating:       5,
+		ReviewScores: reserv.ReviewScores{Cleanliness: 5, Accuracy: 4, CheckIn: 5, Communication: 5, Location: 4, Value: 4},
+		Comment:      " Lovely place ",
+	}
+	invalid := valid
+	invalid.Cleanliness = 0
+
+	tests := []struct {
+		name       string
+		subject    string
+		booking    *reserv.Booking
+		req        handler.CreateReview
+		createErr  error
+		wantStatus int
+	}{
+		{name: "guest reviews the stay", subject: "guest", booking: &completed, req: valid, wantStatus: http.StatusCreated},
+		{name: "guest reviews a stay never checked in", subject: "guest", booking: &stayed, req: valid, wantStatus: http.StatusCreated},
+		{name: "booking not found", subject: "guest", req: valid, wantStatus: http.StatusNotFound},
+		{name: "only the guest can review", subject: "host", booking: &completed, req: valid, wantStatus: http.StatusUnauthorized},
+		{name: "stay before check-out", subject: "guest", booking: &upcoming, req: valid, wantStatus: http.StatusUnprocessableEntity},
+		{name: "invalid scores", subject: "guest", booking: &completed, req: invalid, wantStatus: http.StatusBadRequest},
+		{
+			name:       "booking already reviewed",
+			subject:    "guest",
+			booking:    &completed,
+			req:        valid,
+			createErr:  reserv.ErrReviewExists,
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookingRepo := mock.NewMockBookingRepository(ctrl)
+			if tt.booking != nil {
+				bookingRepo.EXPECT().GetBooking(gomock.Any(), "booking").Return(1, *tt.booking, nil)
+			} else {
+				bookingRepo.EXPECT().GetBooking(gomock.Any(), "booking").Return(0, reserv.Booking{}, nil)
+			}
+			if tt.wantStatus == http.StatusCreated || tt.createErr != nil {
+				bookingRepo.EXPECT().CreateReview(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, review reserv.Review) (string, error) {
+					require.Equal(t, "booking", review.BookingID)
+					require.Equal(t, "property", review.PropertyID)
+					require.Equal(t, "guest", review.GuestID)
+					require.Equal(t, 5, review.Rating)
+					require.Equal(t, tt.req.ReviewScores, review.ReviewScores)
+					require.Equal(t, "Lovely place", review.Comment)
+					require.False(t, review.CreatedAt.IsZero())
+					return "review", tt.createErr
+				})
+			}
+
+			mux := http.NewServeMux()
+			h := handler.NewHandler(nil, nil, bookingRepo, nil)
+			h.RegisterRoutes(mux)
+
+			resp := serveThread(t, mux, http.MethodPost, "/bookings/booking/reviews", tt.subject, tt.req)
+			require.Equal(t, tt.wantStatus, resp.Code, resp.Body.String())
+			if tt.wantStatus == http.StatusCreated {
+				require.JSONEq(t, `{"id": "review"}`, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestPropertyReviewsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 5, 10, 10, 0, 0, 0, time.UTC)
+	reviews := []reserv.Review{
+		{ID: "review-3", PropertyID: "property", Rating: 5, CreatedAt: now},
+		{ID: "review-2", PropertyID: "property", Rating: 4, CreatedAt: now.Add(-time.Hour)},
+		{ID: "review-1", PropertyID: "property", Rating: 3, CreatedAt: now.Add(-2 * time.Hour)},
+	}
+
+	propertyRepo := mock.NewMockPropertyRepository(ctrl)
+	propertyRepo.EXPECT().GetProperty(gomock.Any(), "property").Return(1, reserv.Property{}, nil).AnyTimes()
+	propertyRepo.EXPECT().GetProperty(gomock.Any(), "unknown").Return(0, reserv.Property{}, nil)
+	bookingRepo := mock.NewMockBookingRepository(ctrl)
+	bookingRepo.EXPECT().PropertyReviews(gomock.Any(), "property", reserv.Page{Limit: 2}).Return(reviews, nil)
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(propertyRepo, nil, bookingRepo, nil)
+	h.RegisterRoutes(mux)
+
+	resp := serveThread(t, mux, http.MethodGet, "/properties/property/reviews?limit=2", "anyone", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	var got handler.ReviewsResponse
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &got))
+	require.Len(t, got.Reviews, 2)
+	require.Equal(t, "review-3", got.Reviews[0].ID)
+	require.NotEmpty(t, got.NextCursor)
+
+	// The next page starts after the last review of the page.
+	cursor, err := reserv.ParseCursor(got.NextCursor)
+	require.NoError(t, err)
+	bookingRepo.EXPECT().PropertyReviews(gomock.Any(), "property", reserv.Page{Limit: 2, After: &cursor}).Return(reviews[2:], nil)
+
+	resp = serveThread(t, mux, http.MethodGet, "/properties/property/reviews?limit=2&cursor="+got.NextCursor, "anyone", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	got = handler.ReviewsResponse{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &got))
+	require.Len(t, got.Reviews, 1)
+	require.Equal(t, "review-1", got.Reviews[0].ID)
+	require.Empty(t, got.NextCursor)
+
+	resp = serveThread(t, mux, http.MethodGet, "/properties/unknown/reviews", "anyone", nil)
+	require.Equal(t, http.StatusNotFound, resp.Code, resp.Body.String())
+
+	resp = serveThread(t, mux, http.MethodGet, "/properties/property/reviews?cursor=invalid", "anyone", nil)
+	require.Equal(t, http.StatusBadRequest, resp.Code, resp.Body.String())
+}
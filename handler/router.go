@@ -3,9 +3,12 @@ package handler
 import (
 	"log/slog"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/clerk/clerk-sdk-go/v2"
 	clerkhttp "github.com/clerk/clerk-sdk-go/v2/http"
+	"github.com/perebaj/reserv"
 )
 
 // Handler is responsable to gather all important implementations to inject into the handler.
@@ -14,11 +17,71 @@ type Handler struct {
 	repo        PropertyRepository
 	bookingRepo BookingRepository
 	CloudFlare  CloudFlareAPI
+	Payments    PaymentProvider
+	// HoldTTL is how long a booking hold keeps the dates reserved. It defaults to reserv.DefaultBookingHoldTTL.
+	HoldTTL time.Duration
+	// RequestDeadline is how long the host has to answer a booking request. It defaults to reserv.DefaultBookingRequestDeadline.
+	RequestDeadline time.Duration
+	// ServiceFeePercent is the fee of the platform added to the quotes. It defaults to 0, no fee.
+	ServiceFeePercent int
+	// AdminIDs are the Clerk user ids of the platform admins. They manage the platform data, like the tax rules and the promotions.
+	AdminIDs []string
 }
 
 // NewHandler creates a new handler
-func NewHandler(repo PropertyRepository, cloudFlare CloudFlareAPI, bookingRepo BookingRepository) *Handler {
-	return &Handler{repo: repo, CloudFlare: cloudFlare, bookingRepo: bookingRepo}
+func NewHandler(repo PropertyRepository, cloudFlare CloudFlareAPI, bookingRepo BookingRepository, payments PaymentProvider) *Handler {
+	return &Handler{
+		repo:            repo,
+		CloudFlare:      cloudFlare,
+		bookingRepo:     bookingRepo,
+		Payments:        payments,
+		HoldTTL:         reserv.DefaultBookingHoldTTL,
+		RequestDeadline: reserv.DefaultBookingRequestDeadline,
+	}
+}
+
+// authorizeAdmin checks that the user of the request is one of the platform admins.
+// If not, the error is written to the response writer and false is returned.
+func (h *Handler) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
+	if !ok {
+		slog.Warn("unauthorized, no claims")
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return false
+	}
+
+	if !slices.Contains(h.AdminIDs, claims.Subject) {
+		slog.Warn("unauthorized, user is not an admin", "jwt_subject", claims.Subject)
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return false
+	}
+
+	return true
+}
+
+// authorizeHostOrAdmin checks that the user of the request is the host with the id in the path or one of the platform admins,
+// and returns the host id. If not, the error is written to the response writer and false is returned.
+func (h *Handler) authorizeHostOrAdmin(w http.ResponseWriter, r *http.Request) (string, bool) {
+	claims, ok := clerk.SessionClaimsFromContext(r.Context())
+	if !ok {
+		slog.Warn("unauthorized, no claims")
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return "", false
+	}
+
+	hostID := r.PathValue("id")
+	if hostID == "" {
+		NewAPIError("missing_host_id", "missing host id", http.StatusBadRequest).Write(w)
+		return "", false
+	}
+
+	if claims.Subject != hostID && !slices.Contains(h.AdminIDs, claims.Subject) {
+		slog.Warn("unauthorized, different user from hostID and jwt", "host_id", hostID, "jwt_subject", claims.Subject)
+		NewAPIError("unauthorized", "unauthorized", http.StatusUnauthorized).Write(w)
+		return "", false
+	}
+
+	return hostID, true
 }
 
 // RegisterRoutes registers all property routes
@@ -53,6 +116,184 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 		}
 	})))
 
+	mux.Handle("/properties/{id}/quote", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetQuoteHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/properties/{id}/availability", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAvailabilityHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/properties/{id}/blocks", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetCalendarBlocksHandler(w, r)
+		case http.MethodPost:
+			h.CreateCalendarBlockHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/properties/{id}/blocks/{block_id}", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			h.UpdateCalendarBlockHandler(w, r)
+		case http.MethodDelete:
+			h.DeleteCalendarBlockHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/properties/{id}/pricing-rules", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetPricingRulesHandler(w, r)
+		case http.MethodPost:
+			h.CreatePricingRuleHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/properties/{id}/pricing-rules/{rule_id}", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			h.UpdatePricingRuleHandler(w, r)
+		case http.MethodDelete:
+			h.DeletePricingRuleHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/properties/{id}/booking-rules", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetBookingRulesHandler(w, r)
+		case http.MethodPut:
+			h.UpdateBookingRulesHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/properties/{id}/cancellation-policy", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetCancellationPolicyHandler(w, r)
+		case http.MethodPut:
+			h.UpdateCancellationPolicyHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/properties/{id}/calendar-token", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetCalendarTokenHandler(w, r)
+		case http.MethodPost:
+			h.CreateCalendarTokenHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// The calendar feed is fetched by external calendars, that can't send bearer tokens. It is protected by a secret token in the URL.
+	mux.HandleFunc("/properties/{id}/calendar.ics", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetCalendarFeedHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.Handle("/properties/{id}/calendar-imports", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetCalendarImportsHandler(w, r)
+		case http.MethodPost:
+			h.CreateCalendarImportHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/properties/{id}/calendar-imports/{import_id}", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			h.DeleteCalendarImportHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/properties/{id}/calendar-imports/{import_id}/sync", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			h.SyncCalendarImportHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/tax-rules", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetTaxRulesHandler(w, r)
+		case http.MethodPost:
+			h.CreateTaxRuleHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/tax-rules/{id}", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			h.UpdateTaxRuleHandler(w, r)
+		case http.MethodDelete:
+			h.DeleteTaxRuleHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/promotions", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetPromotionsHandler(w, r)
+		case http.MethodPost:
+			h.CreatePromotionHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/promotions/{id}", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetPromotionHandler(w, r)
+		case http.MethodPut:
+			h.UpdatePromotionHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
 	mux.Handle("/images", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
@@ -95,12 +336,166 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 		}
 	})))
 
+	mux.Handle("/bookings/holds", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			h.CreateBookingHoldHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
 	mux.Handle("/bookings/{id}", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
-		case http.MethodDelete:
-			h.DeleteBookingHandler(w, r)
 		case http.MethodGet:
 			h.GetBookingHandler(w, r)
+		case http.MethodPatch:
+			h.UpdateBookingDatesHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/bookings/{id}/changes", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.BookingChangesHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/bookings/{id}/"+string(bookingActionCancel), clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.CancellationPreviewHandler(w, r)
+		case http.MethodPost:
+			h.CancelBookingHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	for _, action := range []bookingAction{bookingActionConfirm, bookingActionDecline, bookingActionCheckIn, bookingActionComplete, bookingActionNoShow} {
+		transition := h.BookingTransitionHandler(action)
+		mux.Handle("/bookings/{id}/"+string(action), clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost:
+				transition(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})))
+	}
+
+	mux.Handle("/bookings/{id}/payments", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			h.CreatePaymentHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// The payment provider can't send bearer tokens. The webhooks are authenticated by their signature.
+	mux.HandleFunc("/payments/webhook", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			h.PaymentWebhookHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.Handle("/hosts/{id}/bookings", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.HostBookingsHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/hosts/{id}/balance", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.HostBalanceHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/hosts/{id}/payouts", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.HostPayoutsHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/hosts/{id}/statements/{month}", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.HostStatementHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/ledger/reconciliation", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.ReconciliationHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/threads", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.ThreadsHandler(w, r)
+		case http.MethodPost:
+			h.CreateThreadHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/threads/{id}/messages", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.MessagesHandler(w, r)
+		case http.MethodPost:
+			h.SendMessageHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/threads/{id}/read", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			h.MarkThreadReadHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/bookings/{id}/reviews", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			h.CreateReviewHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/properties/{id}/reviews", clerkhttp.WithHeaderAuthorization()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.PropertyReviewsHandler(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// TaxRuleRequest is the request body for creating or updating a tax rule.
+type TaxRuleRequest struct {
+	// Jurisdiction is the code of the place where the tax is charged. Example: "US-NY-NYC". Required.
+	Jurisdiction string `json:"jurisdiction"`
+	// Name is the name of the tax shown to the guests. Required.
+	Name string `json:"name"`
+	// RateBasisPoints is the rate of the tax in hundredths of a percent. Example: 1475 is 14.75%. Required.
+	RateBasisPoints int `json:"rate_basis_points"`
+}
+
+// GetTaxRulesHandler lists the tax rules of all the jurisdictions. Taxes are shown in every quote, so any user can call it.
+// Usage: GET /tax-rules
+func (h *Handler) GetTaxRulesHandler(w http.ResponseWriter, r *http.Request) {
+	slog.Info("get tax rules")
+	rules, err := h.repo.TaxRules(r.Context())
+	if err != nil {
+		slog.Error("failed to get tax rules", "error", err)
+		NewAPIError("get_tax_rules_error", "failed to get tax rules", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	if rules == nil {
+		rules = []reserv.TaxRule{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(rules)
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// CreateTaxRuleHandler creates a tax rule. Only the platform admins can call it.
+// Usage: POST /tax-rules
+func (h *Handler) CreateTaxRuleHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	var req TaxRuleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		slog.Error("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("create tax rule", "request", req)
+
+	now := time.Now().UTC()
+	rule := reserv.TaxRule{
+		Jurisdiction:    req.Jurisdiction,
+		Name:            req.Name,
+		RateBasisPoints: req.RateBasisPoints,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := rule.Validate(); err != nil {
+		NewAPIError("invalid_tax_rule", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	id, err := h.repo.CreateTaxRule(r.Context(), rule)
+	if err != nil {
+		slog.Error("failed to create tax rule", "error", err)
+		NewAPIError("create_tax_rule_error", "failed to create tax rule", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(map[string]string{"id": id})
+	if err != nil {
+		slog.Error("failed to encode response", "error", err)
+		NewAPIError("encode_response_error", "failed to encode response", http.StatusInternalServerError).Write(w)
+		return
+	}
+}
+
+// UpdateTaxRuleHandler replaces a tax rule. The bookings already made keep the taxes they were priced with. Only the
+// platform admins can call it.
+// Usage: PUT /tax-rules/{id}
+func (h *Handler) UpdateTaxRuleHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+	var req TaxRuleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		slog.Error("failed to decode request body", "error", err)
+		NewAPIError("invalid_request_body", "invalid request body", http.StatusBadRequest).Write(w)
+		return
+	}
+	slog.Info("update tax rule", "id", id, "request", req)
+
+	rule := reserv.TaxRule{
+		ID:              id,
+		Jurisdiction:    req.Jurisdiction,
+		Name:            req.Name,
+		RateBasisPoints: req.RateBasisPoints,
+		UpdatedAt:       time.Now().UTC(),
+	}
+
+	if err := rule.Validate(); err != nil {
+		NewAPIError("invalid_tax_rule", err.Error(), http.StatusBadRequest).Write(w)
+		return
+	}
+
+	err = h.repo.UpdateTaxRule(r.Context(), rule)
+	if errors.Is(err, reserv.ErrTaxRuleNotFound) {
+		NewAPIError("tax_rule_not_found", "tax rule not found", http.StatusNotFound).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to update tax rule", "error", err)
+		NewAPIError("update_tax_rule_error", "failed to update tax rule", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteTaxRuleHandler deletes a tax rule. Only the platform admins can call it.
+// Usage: DELETE /tax-rules/{id}
+func (h *Handler) DeleteTaxRuleHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+	slog.Info("delete tax rule", "id", id)
+
+	err := h.repo.DeleteTaxRule(r.Context(), id)
+	if errors.Is(err, reserv.ErrTaxRuleNotFound) {
+		NewAPIError("tax_rule_not_found", "tax rule not found", http.StatusNotFound).Write(w)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete tax rule", "error", err)
+		NewAPIError("delete_tax_rule_error", "failed to delete tax rule", http.StatusInternalServerError).Write(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
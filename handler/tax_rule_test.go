@@ -0,0 +1,165 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/handler"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateTaxRuleHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	adminID := "user_2x5CiRO5Mf0wBpWO8w469jEJhRq"
+	repo.EXPECT().CreateTaxRule(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, rule reserv.TaxRule) (string, error) {
+		require.Equal(t, "US-NY-NYC", rule.Jurisdiction)
+		require.Equal(t, "NYC occupancy tax", rule.Name)
+		require.Equal(t, 575, rule.RateBasisPoints)
+		require.False(t, rule.CreatedAt.IsZero())
+		return "rule-id", nil
+	})
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.AdminIDs = []string{adminID}
+	h.RegisterRoutes(mux)
+
+	tests := []struct {
+		name       string
+		subject    string
+		body       handler.TaxRuleRequest
+		wantStatus int
+	}{
+		{
+			name:       "valid rule",
+			subject:    adminID,
+			body:       handler.TaxRuleRequest{Jurisdiction: "US-NY-NYC", Name: "NYC occupancy tax", RateBasisPoints: 575},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid jurisdiction",
+			subject:    adminID,
+			body:       handler.TaxRuleRequest{Jurisdiction: "new york", Name: "NYC occupancy tax", RateBasisPoints: 575},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rate above 100%",
+			subject:    adminID,
+			body:       handler.TaxRuleRequest{Jurisdiction: "US-NY-NYC", Name: "NYC occupancy tax", RateBasisPoints: 57500},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "only admins can create rules",
+			subject:    "another_user",
+			body:       handler.TaxRuleRequest{Jurisdiction: "US-NY-NYC", Name: "NYC occupancy tax", RateBasisPoints: 575},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonBody, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/tax-rules", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test_token")
+			ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+				RegisteredClaims: clerk.RegisteredClaims{
+					Subject: tt.subject,
+				},
+			})
+			req = req.WithContext(ctx)
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+
+			rBody := resp.Body.String()
+			require.Equal(t, tt.wantStatus, resp.Code, rBody)
+		})
+	}
+}
+
+func TestDeleteTaxRuleHandler_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+	repo.EXPECT().DeleteTaxRule(gomock.Any(), "rule-id").Return(reserv.ErrTaxRuleNotFound)
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.AdminIDs = []string{"admin"}
+	h.RegisterRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodDelete, "/tax-rules/rule-id", nil)
+	req.Header.Set("Authorization", "Bearer test_token")
+	ctx := clerk.ContextWithSessionClaims(req.Context(), &clerk.SessionClaims{
+		RegisteredClaims: clerk.RegisteredClaims{
+			Subject: "admin",
+		},
+	})
+
+	resp := httptest.NewRecorder()
+	mux.ServeHTTP(resp, req.WithContext(ctx))
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusNotFound, resp.Code, rBody)
+}
+
+func TestGetQuoteHandler_FeesAndTaxes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPropertyRepository(ctrl)
+
+	propertyID := uuid.New()
+	repo.EXPECT().GetProperty(gomock.Any(), propertyID.String()).Return(1, reserv.Property{
+		ID:                 propertyID,
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		MaxGuests:          2,
+		GuestsIncluded:     2,
+		CleaningFeeCents:   5000,
+		Jurisdiction:       "US-NY-NYC",
+	}, nil)
+	repo.EXPECT().PricingRules(gomock.Any(), propertyID.String()).Return(nil, nil)
+	repo.EXPECT().TaxRules(gomock.Any()).Return([]reserv.TaxRule{
+		{ID: "nyc", Jurisdiction: "US-NY-NYC", Name: "NYC occupancy tax", RateBasisPoints: 1000},
+		{ID: "ca", Jurisdiction: "US-CA", Name: "CA occupancy tax", RateBasisPoints: 1000},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/properties/"+propertyID.String()+"/quote?check_in=2025-01-01&check_out=2025-01-03", nil)
+	resp := httptest.NewRecorder()
+
+	mux := http.NewServeMux()
+	h := handler.NewHandler(repo, nil, nil, nil)
+	h.ServiceFeePercent = 10
+	h.RegisterRoutes(mux)
+	mux.ServeHTTP(resp, req)
+
+	rBody := resp.Body.String()
+	require.Equal(t, http.StatusOK, resp.Code, rBody)
+
+	var quote reserv.Quote
+	require.NoError(t, json.Unmarshal([]byte(rBody), &quote))
+	require.Len(t, quote.Lines, 4)
+	require.Equal(t, reserv.QuoteLineServiceFee, quote.Lines[2].Kind)
+	require.Equal(t, int64(2500), quote.Lines[2].AmountCents)
+	require.Equal(t, "nyc", quote.Lines[3].SourceID)
+	require.Equal(t, int64(2500), quote.Lines[3].AmountCents)
+	require.Equal(t, int64(30000), quote.TotalPriceCents)
+}
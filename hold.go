@@ -0,0 +1,34 @@
+package reserv
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTooManyHolds is returned when a guest already holds MaxActiveHoldsPerGuest stays.
+var ErrTooManyHolds = errors.New("too many active booking holds")
+
+const (
+	// DefaultBookingHoldTTL is how long a hold keeps the dates of a stay reserved when no TTL is configured.
+	DefaultBookingHoldTTL = 15 * time.Minute
+	// MinBookingHoldTTL and MaxBookingHoldTTL bound the configured TTL of the holds.
+	MinBookingHoldTTL = time.Minute
+	MaxBookingHoldTTL = time.Hour
+	// MaxActiveHoldsPerGuest is how many stays a guest can hold at the same time, across all the properties. It keeps
+	// a single guest from locking the calendars by holding every free range.
+	MaxActiveHoldsPerGuest = 3
+)
+
+// BookingHold reserves the dates of a stay for a guest for a short time, while the guest completes the checkout.
+// Other guests can't book or hold the dates until the hold expires, but the guest who holds them can.
+type BookingHold struct {
+	ID         string `json:"id" db:"id"`
+	PropertyID string `json:"property_id" db:"property_id"`
+	GuestID    string `json:"guest_id" db:"guest_id"`
+	// CheckInDate and CheckOutDate are the held dates, following the same semantics of the bookings.
+	CheckInDate  time.Time `json:"check_in_date" db:"check_in_date"`
+	CheckOutDate time.Time `json:"check_out_date" db:"check_out_date"`
+	// ExpiresAt is the timestamp when the hold stops blocking the dates.
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,58 @@
+// Package holds expires the booking holds that were not turned into bookings.
+package holds
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+//go:generate mockgen -source holds.go -destination ../mock/holds.go -package mock
+
+// HoldRepository gathers the methods needed to expire booking holds.
+type HoldRepository interface {
+	// ExpireBookingHolds deletes the holds that expired at now and returns how many were deleted
+	ExpireBookingHolds(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Worker periodically deletes the expired booking holds.
+type Worker struct {
+	repo     HoldRepository
+	interval time.Duration
+}
+
+// NewWorker creates a worker that expires the holds every interval.
+func NewWorker(repo HoldRepository, interval time.Duration) *Worker {
+	return &Worker{repo: repo, interval: interval}
+}
+
+// Run expires the holds until ctx is done. It is blocking, so it must run on its own goroutine.
+func (w *Worker) Run(ctx context.Context) {
+	slog.Info("starting booking hold expiry worker", "interval", w.interval)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.Expire(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.Info("stopping booking hold expiry worker")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Expire deletes the holds that are already expired.
+func (w *Worker) Expire(ctx context.Context) {
+	expired, err := w.repo.ExpireBookingHolds(ctx, time.Now().UTC())
+	if err != nil {
+		slog.Error("failed to expire booking holds", "error", err)
+		return
+	}
+
+	if expired > 0 {
+		slog.Info("booking holds expired", "expired", expired)
+	}
+}
@@ -0,0 +1,52 @@
+package holds_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/perebaj/reserv/holds"
+	"github.com/perebaj/reserv/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWorker_Expire(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockHoldRepository(ctrl)
+	repo.EXPECT().ExpireBookingHolds(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, now time.Time) (int64, error) {
+		require.WithinDuration(t, time.Now(), now, time.Minute)
+		return 2, nil
+	})
+	repo.EXPECT().ExpireBookingHolds(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("connection refused"))
+
+	worker := holds.NewWorker(repo, time.Minute)
+	worker.Expire(context.Background())
+	// Errors are logged and retried on the next tick.
+	worker.Expire(context.Background())
+}
+
+func TestWorker_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockHoldRepository(ctrl)
+	repo.EXPECT().ExpireBookingHolds(gomock.Any(), gomock.Any()).Return(int64(0), nil).MinTimes(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		holds.NewWorker(repo, time.Hour).Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker didn't stop after the context was cancelled")
+	}
+}
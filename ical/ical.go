@@ -0,0 +1,337 @@
+// Package ical encodes and parses calendars in the iCalendar format, as specified by RFC 5545.
+// Only the subset needed to sync all-day availability with other platforms is supported.
+// Reference: https://datatracker.ietf.org/doc/html/rfc5545
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// dateFormat is the format of the DATE values. Example: 20250101
+	dateFormat = "20060102"
+	// dateTimeFormat is the format of the DATE-TIME values in UTC. Example: 20250101T120000Z
+	dateTimeFormat = "20060102T150405Z"
+	// maxLineOctets is the maximum length of a content line, excluding the line break.
+	maxLineOctets = 75
+)
+
+// Event is an all-day VEVENT.
+type Event struct {
+	// UID is the globally unique identifier of the event. Required.
+	UID string
+	// Start is the first day of the event. Required.
+	Start time.Time
+	// End is the day after the last day of the event, it is exclusive as defined by RFC 5545 for DATE values. Required.
+	End time.Time
+	// Summary is the title of the event. Example: "Reserved".
+	Summary string
+	// Description is an optional longer description of the event.
+	Description string
+	// Stamp is when the event was last modified. Required.
+	Stamp time.Time
+}
+
+// Calendar is a VCALENDAR with all-day events.
+type Calendar struct {
+	// ProdID identifies the product that created the calendar. Example: "-//reserv//calendar//EN".
+	ProdID string
+	// Name is the name of the calendar shown by the clients that support the X-WR-CALNAME extension.
+	Name string
+	// Events are the events of the calendar.
+	Events []Event
+}
+
+// Encode writes the calendar to w. Lines are terminated by CRLF and folded at 75 octets.
+func Encode(w io.Writer, cal Calendar) error {
+	bw := bufio.NewWriter(w)
+	e := &encoder{w: bw}
+
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", cal.ProdID)
+	e.line("CALSCALE", "GREGORIAN")
+	e.line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		e.line("X-WR-CALNAME", escapeText(cal.Name))
+	}
+
+	for _, event := range cal.Events {
+		e.line("BEGIN", "VEVENT")
+		e.line("UID", escapeText(event.UID))
+		e.line("DTSTAMP", event.Stamp.UTC().Format(dateTimeFormat))
+		e.line("DTSTART;VALUE=DATE", event.Start.Format(dateFormat))
+		e.line("DTEND;VALUE=DATE", event.End.Format(dateFormat))
+		if event.Summary != "" {
+			e.line("SUMMARY", escapeText(event.Summary))
+		}
+		if event.Description != "" {
+			e.line("DESCRIPTION", escapeText(event.Description))
+		}
+		e.line("TRANSP", "OPAQUE")
+		e.line("END", "VEVENT")
+	}
+
+	e.line("END", "VCALENDAR")
+	if e.err != nil {
+		return fmt.Errorf("failed to encode calendar: %v", e.err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to encode calendar: %v", err)
+	}
+	return nil
+}
+
+// encoder writes content lines and keeps the first error, so callers check it only once.
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a content line, folding it when it is longer than 75 octets.
+func (e *encoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = e.w.WriteString(fold(name + ":" + value))
+}
+
+// fold splits a content line in lines of at most 75 octets. Continuation lines start with a single space.
+// Lines are never split in the middle of a UTF-8 character.
+func fold(line string) string {
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > maxLineOctets {
+			b.WriteString("\r\n ")
+			// The leading space counts for the length of the continuation line.
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// escapeText escapes a TEXT value.
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// ErrInvalidCalendar is returned when the input is not an iCalendar stream.
+var ErrInvalidCalendar = errors.New("invalid calendar")
+
+// Parse reads the VEVENTs of an iCalendar stream and returns them as all-day events. Events with DATE-TIME values
+// are widened to the days they touch. Cancelled events and events without DTSTART are ignored. A calendar without
+// END:VCALENDAR is invalid, as it is likely truncated and its events incomplete.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %v", err)
+	}
+
+	var (
+		events    []Event
+		inCal     bool
+		closed    bool
+		inEvent   bool
+		current   Event
+		hasEnd    bool
+		endIsDate bool
+		cancelled bool
+	)
+	for _, line := range lines {
+		name, params, value, ok := splitLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCALENDAR"):
+			inCal = true
+		case !inCal:
+			continue
+		case name == "END" && strings.EqualFold(value, "VCALENDAR"):
+			inCal, closed = false, true
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent = true
+			current, hasEnd, endIsDate, cancelled = Event{}, false, false, false
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			inEvent = false
+			if cancelled || current.Start.IsZero() {
+				continue
+			}
+			// DATE values already have an exclusive end. DATE-TIME values end inside a day, unless they end at midnight.
+			switch {
+			case !hasEnd:
+				current.End = day(current.Start).AddDate(0, 0, 1)
+			case endIsDate, isMidnight(current.End):
+				current.End = day(current.End)
+			default:
+				current.End = day(current.End).AddDate(0, 0, 1)
+			}
+			current.Start = day(current.Start)
+			if !current.End.After(current.Start) {
+				current.End = current.Start.AddDate(0, 0, 1)
+			}
+			events = append(events, current)
+		case !inEvent:
+			continue
+		case name == "UID":
+			current.UID = unescapeText(value)
+		case name == "SUMMARY":
+			current.Summary = unescapeText(value)
+		case name == "DESCRIPTION":
+			current.Description = unescapeText(value)
+		case name == "STATUS":
+			cancelled = strings.EqualFold(value, "CANCELLED")
+		case name == "DTSTAMP":
+			current.Stamp, _ = parseTime(params, value)
+		case name == "DTSTART":
+			current.Start, err = parseTime(params, value)
+			if err != nil {
+				return nil, err
+			}
+		case name == "DTEND":
+			current.End, err = parseTime(params, value)
+			if err != nil {
+				return nil, err
+			}
+			hasEnd = true
+			endIsDate = isDate(params, value)
+		}
+	}
+
+	if inCal {
+		return nil, fmt.Errorf("%w: the calendar is not closed", ErrInvalidCalendar)
+	}
+	if !closed {
+		return nil, ErrInvalidCalendar
+	}
+	return events, nil
+}
+
+// unfold reads the content lines of r, joining the folded ones. Both CRLF and LF line breaks are accepted.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitLine splits a content line in its upper-cased name, its parameters and its value.
+// Example: "DTSTART;VALUE=DATE:20250101" returns "DTSTART", {"VALUE": "DATE"} and "20250101".
+func splitLine(line string) (string, map[string]string, string, bool) {
+	// The value starts at the first colon outside of a quoted parameter value.
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+// isDate reports whether a DTSTART or DTEND value is a DATE instead of a DATE-TIME.
+func isDate(params map[string]string, value string) bool {
+	return strings.EqualFold(params["VALUE"], "DATE") || len(value) == len(dateFormat)
+}
+
+// parseTime parses a DATE or DATE-TIME value. DATE-TIME values with a TZID are read in that time zone and
+// floating values are read as UTC.
+func parseTime(params map[string]string, value string) (time.Time, error) {
+	if isDate(params, value) {
+		t, err := time.Parse(dateFormat, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: invalid date %q", ErrInvalidCalendar, value)
+		}
+		return t, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeFormat, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: invalid date-time %q", ErrInvalidCalendar, value)
+		}
+		return t, nil
+	}
+
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(strings.TrimSuffix(dateTimeFormat, "Z"), value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid date-time %q", ErrInvalidCalendar, value)
+	}
+	return t, nil
+}
+
+// day returns the midnight of the day of t in UTC, keeping the calendar day of the time zone of t.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// isMidnight reports whether t is the first instant of its day.
+func isMidnight(t time.Time) bool {
+	h, m, sec := t.Clock()
+	return h == 0 && m == 0 && sec == 0 && t.Nanosecond() == 0
+}
+
+// unescapeText reverts escapeText.
+func unescapeText(s string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if !escaped && r == '\\' {
+			escaped = true
+			continue
+		}
+		if escaped && (r == 'n' || r == 'N') {
+			r = '\n'
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
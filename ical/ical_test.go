@@ -0,0 +1,134 @@
+package ical
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	var buf bytes.Buffer
+	err := Encode(&buf, Calendar{
+		ProdID: "-//reserv//calendar//EN",
+		Name:   "Beach house",
+		Events: []Event{
+			{
+				UID:     "booking-1@reserv",
+				Start:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				End:     time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC),
+				Summary: "Reserved",
+				Stamp:   time.Date(2024, 12, 1, 10, 30, 0, 0, time.UTC),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	want := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//reserv//calendar//EN\r\n" +
+		"CALSCALE:GREGORIAN\r\n" +
+		"METHOD:PUBLISH\r\n" +
+		"X-WR-CALNAME:Beach house\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:booking-1@reserv\r\n" +
+		"DTSTAMP:20241201T103000Z\r\n" +
+		"DTSTART;VALUE=DATE:20250101\r\n" +
+		"DTEND;VALUE=DATE:20250104\r\n" +
+		"SUMMARY:Reserved\r\n" +
+		"TRANSP:OPAQUE\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	require.Equal(t, want, buf.String())
+}
+
+func TestFold(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("á", 60)
+	folded := fold(line)
+
+	lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+	require.Greater(t, len(lines), 1)
+	for i, l := range lines {
+		require.LessOrEqual(t, len(l), maxLineOctets)
+		if i > 0 {
+			require.True(t, strings.HasPrefix(l, " "))
+		}
+	}
+
+	unfolded := strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", "")
+	require.Equal(t, line, unfolded)
+}
+
+func TestEscapeText(t *testing.T) {
+	require.Equal(t, `a\, b\; c\\d\ne`, escapeText("a, b; c\\d\ne"))
+}
+
+func TestParse(t *testing.T) {
+	f, err := os.Open("testdata/airbnb.ics")
+	require.NoError(t, err)
+	defer f.Close()
+
+	events, err := Parse(f)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	date := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	require.Equal(t, "1418fb94e984-5f2e2e07d8a0a1a0e3a8b3a8@airbnb.com", events[0].UID)
+	require.Equal(t, date(2025, 1, 5), events[0].Start)
+	require.Equal(t, date(2025, 1, 10), events[0].End)
+	require.Equal(t, "Reserved", events[0].Summary)
+	require.Equal(t, "Reservation URL: https://www.airbnb.com/hosting/reservations/details/HMABCDEF\nPhone Number (Last 4 Digits): 1234", events[0].Description)
+
+	// DATE-TIME values are widened to the days they touch, in their own time zone.
+	require.Equal(t, "booking-42@other", events[1].UID)
+	require.Equal(t, date(2025, 2, 1), events[1].Start)
+	require.Equal(t, date(2025, 2, 4), events[1].End)
+	require.Equal(t, "Blocked, owner stay", events[1].Summary)
+	require.Equal(t, time.Date(2024, 12, 1, 12, 0, 0, 0, time.UTC), events[1].Stamp)
+
+	// Ending at midnight doesn't touch the next day.
+	require.Equal(t, date(2025, 3, 1), events[2].Start)
+	require.Equal(t, date(2025, 3, 2), events[2].End)
+
+	// Without DTEND, a DATE event lasts one day.
+	require.Equal(t, date(2025, 4, 1), events[3].Start)
+	require.Equal(t, date(2025, 4, 2), events[3].End)
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	want := []Event{
+		{
+			UID:         "booking-1@reserv",
+			Start:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			End:         time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC),
+			Summary:     "Reserved; " + strings.Repeat("long summary, ", 10),
+			Description: "line 1\nline 2",
+			Stamp:       time.Date(2024, 12, 1, 10, 30, 0, 0, time.UTC),
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, Calendar{ProdID: "-//reserv//calendar//EN", Events: want}))
+
+	got, err := Parse(&buf)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("<html>not a calendar</html>"))
+	require.ErrorIs(t, err, ErrInvalidCalendar)
+
+	_, err = Parse(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:2025-01-01\nEND:VEVENT\nEND:VCALENDAR\n"))
+	require.ErrorIs(t, err, ErrInvalidCalendar)
+
+	// A truncated calendar misses events, so it is not parsed.
+	_, err = Parse(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\nDTSTART;VALUE=DATE:20250101\nEND:VEVENT\nBEGIN:VEVENT\nUID:2\n"))
+	require.ErrorIs(t, err, ErrInvalidCalendar)
+}
@@ -0,0 +1,317 @@
+package reserv
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ErrInvalidJournalEntry is returned when a journal entry can't be posted to the ledger, like an entry whose postings
+// don't sum to zero.
+var ErrInvalidJournalEntry = errors.New("invalid journal entry")
+
+// AccountCode is the kind of an account of the ledger. Each code has an account per currency, and the host payables
+// have an account per host as well.
+type AccountCode string
+
+const (
+	// AccountCash is the money held by the platform in the payment provider. It is an asset.
+	AccountCash AccountCode = "cash"
+	// AccountHostPayable is what the platform owes a host for the stays in their properties. It is a liability.
+	AccountHostPayable AccountCode = "host_payable"
+	// AccountPlatformRevenue is the service fees earned by the platform.
+	AccountPlatformRevenue AccountCode = "platform_revenue"
+	// AccountTaxesPayable is the taxes collected from the guests, still to be paid to the jurisdictions. It is a liability.
+	AccountTaxesPayable AccountCode = "taxes_payable"
+	// AccountGuestRefundsPayable is the refunds owed to the guests of cancelled bookings, still to be sent through the
+	// payment provider. It is a liability.
+	AccountGuestRefundsPayable AccountCode = "guest_refunds_payable"
+)
+
+// Valid reports whether c is one of the known account codes.
+func (c AccountCode) Valid() bool {
+	switch c {
+	case AccountCash, AccountHostPayable, AccountPlatformRevenue, AccountTaxesPayable, AccountGuestRefundsPayable:
+		return true
+	}
+	return false
+}
+
+// Balance returns the balance of an account whose postings sum to sum. Postings are positive for debits and negative
+// for credits, so the balance of the cash is the sum itself, while liabilities and revenue grow with credits.
+func (c AccountCode) Balance(sum int64) int64 {
+	if c == AccountCash {
+		return sum
+	}
+	return -sum
+}
+
+// JournalEntryKind is the event that moved the money of a journal entry.
+type JournalEntryKind string
+
+const (
+	// JournalEntryPayment is a payment captured from a guest, split between the host, the platform and the taxes.
+	JournalEntryPayment JournalEntryKind = "payment"
+	// JournalEntryCancellation is the refund owed to the guest of a cancelled booking, taken back from the host, the
+	// platform and the taxes in the proportion of the payment.
+	JournalEntryCancellation JournalEntryKind = "cancellation"
+	// JournalEntryRefund is a refund sent to a guest through the payment provider.
+	JournalEntryRefund JournalEntryKind = "refund"
+	// JournalEntryPayout is money sent to a host.
+	JournalEntryPayout JournalEntryKind = "payout"
+)
+
+// Valid reports whether k is one of the known journal entry kinds.
+func (k JournalEntryKind) Valid() bool {
+	switch k {
+	case JournalEntryPayment, JournalEntryCancellation, JournalEntryRefund, JournalEntryPayout:
+		return true
+	}
+	return false
+}
+
+// Posting is a line of a journal entry: an amount debited or credited to an account.
+type Posting struct {
+	// Account is the code of the account, in the currency of the entry.
+	Account AccountCode `json:"account" db:"account"`
+	// HostID is the host of the AccountHostPayable postings. It is empty for the other accounts.
+	HostID string `json:"host_id,omitempty" db:"host_id"`
+	// AmountCents is positive for debits and negative for credits, in the minor unit of the currency of the entry.
+	AmountCents int64 `json:"amount_cents" db:"amount_cents"`
+}
+
+// JournalEntry is a movement of money in the double-entry ledger. Its postings always sum to zero. Entries are never
+// changed or deleted: mistakes are fixed by posting new entries.
+type JournalEntry struct {
+	// ID is the unique identifier of the entry. It is generated by the database.
+	ID   string           `json:"id" db:"id"`
+	Kind JournalEntryKind `json:"kind" db:"kind"`
+	// BookingID, PaymentID and PayoutID are the booking, the payment and the payout that moved the money, when there
+	// are any.
+	BookingID *string `json:"booking_id,omitempty" db:"booking_id"`
+	PaymentID *string `json:"payment_id,omitempty" db:"payment_id"`
+	PayoutID  *string `json:"payout_id,omitempty" db:"payout_id"`
+	// Currency is the ISO 4217 code of all the postings of the entry.
+	Currency    string    `json:"currency" db:"currency"`
+	Description string    `json:"description" db:"description"`
+	Postings    []Posting `json:"postings" db:"-"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// Validate checks that the entry can be posted: at least two postings to known accounts, none of them zero, that sum
+// to zero. The returned error wraps ErrInvalidJournalEntry.
+func (e JournalEntry) Validate() error {
+	if !e.Kind.Valid() {
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidJournalEntry, e.Kind)
+	}
+
+	if err := ValidateCurrency(e.Currency); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJournalEntry, err)
+	}
+
+	if len(e.Postings) < 2 {
+		return fmt.Errorf("%w: an entry needs at least two postings", ErrInvalidJournalEntry)
+	}
+
+	sum := Money{Currency: e.Currency}
+	for _, posting := range e.Postings {
+		if !posting.Account.Valid() {
+			return fmt.Errorf("%w: unknown account %q", ErrInvalidJournalEntry, posting.Account)
+		}
+		if (posting.Account == AccountHostPayable) != (posting.HostID != "") {
+			return fmt.Errorf("%w: only the host payable postings have a host", ErrInvalidJournalEntry)
+		}
+		if posting.AmountCents == 0 {
+			return fmt.Errorf("%w: postings must not be zero", ErrInvalidJournalEntry)
+		}
+
+		var err error
+		sum, err = sum.Add(Money{Amount: posting.AmountCents, Currency: e.Currency})
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidJournalEntry, err)
+		}
+	}
+
+	if !sum.IsZero() {
+		return fmt.Errorf("%w: postings sum to %s instead of zero", ErrInvalidJournalEntry, sum)
+	}
+
+	return nil
+}
+
+// Split is how an amount paid for a booking is shared between the host, the platform and the tax jurisdictions.
+type Split struct {
+	HostCents       int64 `json:"host_cents"`
+	ServiceFeeCents int64 `json:"service_fee_cents"`
+	TaxCents        int64 `json:"tax_cents"`
+}
+
+// SplitAmount shares amountCents in the proportion of the line items of a booking: the service fee lines go to the
+// platform, the tax lines to the jurisdictions and the rest, discounts included, to the host. The platform and tax
+// shares are rounded toward zero, so the host gets the rounding. Bookings without line items are all for the host.
+func SplitAmount(lines []QuoteLine, amountCents int64) Split {
+	var total, fee, tax int64
+	for _, line := range lines {
+		total += line.AmountCents
+		switch line.Kind {
+		case QuoteLineServiceFee:
+			fee += line.AmountCents
+		case QuoteLineTax:
+			tax += line.AmountCents
+		}
+	}
+
+	if total <= 0 {
+		return Split{HostCents: amountCents}
+	}
+
+	split := Split{
+		ServiceFeeCents: share(fee, amountCents, total),
+		TaxCents:        share(tax, amountCents, total),
+	}
+	split.HostCents = amountCents - split.ServiceFeeCents - split.TaxCents
+	return split
+}
+
+// share returns part/total of amount, rounded toward zero. The product is computed with big integers, since the
+// amounts of a booking can be large enough to overflow an int64 when multiplied.
+func share(part, amount, total int64) int64 {
+	product := new(big.Int).Mul(big.NewInt(part), big.NewInt(amount))
+	return product.Quo(product, big.NewInt(total)).Int64()
+}
+
+// postings returns the postings of the split to the host, the platform and the taxes, multiplied by sign: -1 to credit
+// the split and 1 to debit it. Zero shares are left out.
+func (s Split) postings(hostID string, sign int64) []Posting {
+	var postings []Posting
+	if s.HostCents != 0 {
+		postings = append(postings, Posting{Account: AccountHostPayable, HostID: hostID, AmountCents: sign * s.HostCents})
+	}
+	if s.ServiceFeeCents != 0 {
+		postings = append(postings, Posting{Account: AccountPlatformRevenue, AmountCents: sign * s.ServiceFeeCents})
+	}
+	if s.TaxCents != 0 {
+		postings = append(postings, Posting{Account: AccountTaxesPayable, AmountCents: sign * s.TaxCents})
+	}
+	return postings
+}
+
+// PaymentEntry is the entry of a payment captured for a booking of a property of hostID: the cash received is credited
+// to the host, the platform and the taxes, split with the line items of the booking.
+func PaymentEntry(payment Payment, booking Booking, hostID string, now time.Time) JournalEntry {
+	entry := JournalEntry{
+		Kind:        JournalEntryPayment,
+		BookingID:   &payment.BookingID,
+		PaymentID:   &payment.ID,
+		Currency:    payment.Currency,
+		Description: "Payment of booking " + payment.BookingID,
+		Postings:    []Posting{{Account: AccountCash, AmountCents: payment.AmountCents}},
+		CreatedAt:   now,
+	}
+	entry.Postings = append(entry.Postings, SplitAmount(booking.LineItems, payment.AmountCents).postings(hostID, -1)...)
+	return entry
+}
+
+// CancellationEntry is the entry of the cancellation of a booking whose payment was captured: refundCents are taken
+// back from the host, the platform and the taxes, in the proportion of the payment, and owed to the guest until the
+// refund is sent. See RefundEntry.
+func CancellationEntry(payment Payment, booking Booking, hostID string, refundCents int64, now time.Time) JournalEntry {
+	entry := JournalEntry{
+		Kind:        JournalEntryCancellation,
+		BookingID:   &payment.BookingID,
+		PaymentID:   &payment.ID,
+		Currency:    payment.Currency,
+		Description: "Cancellation of booking " + payment.BookingID,
+		Postings:    SplitAmount(booking.LineItems, refundCents).postings(hostID, 1),
+		CreatedAt:   now,
+	}
+	entry.Postings = append(entry.Postings, Posting{Account: AccountGuestRefundsPayable, AmountCents: -refundCents})
+	return entry
+}
+
+// RefundEntry is the entry of a refund sent to the guest through the payment provider. It pays the refund owed since
+// the cancellation of the booking.
+func RefundEntry(payment Payment, refundCents int64, now time.Time) JournalEntry {
+	return JournalEntry{
+		Kind:        JournalEntryRefund,
+		BookingID:   &payment.BookingID,
+		PaymentID:   &payment.ID,
+		Currency:    payment.Currency,
+		Description: "Refund of booking " + payment.BookingID,
+		Postings: []Posting{
+			{Account: AccountGuestRefundsPayable, AmountCents: refundCents},
+			{Account: AccountCash, AmountCents: -refundCents},
+		},
+		CreatedAt: now,
+	}
+}
+
+// HostBalance is what the platform owes a host in a currency: the host share of the captured payments, less the
+// cancellations and the payouts already sent.
+type HostBalance struct {
+	HostID       string `json:"host_id" db:"host_id"`
+	Currency     string `json:"currency" db:"currency"`
+	BalanceCents int64  `json:"balance_cents" db:"balance_cents"`
+}
+
+// AccountBalance is the balance of all the accounts of a code in a currency, like the sum of the payables of all the
+// hosts. See AccountCode.Balance.
+type AccountBalance struct {
+	Account      AccountCode `json:"account" db:"account"`
+	Currency     string      `json:"currency" db:"currency"`
+	BalanceCents int64       `json:"balance_cents" db:"balance_cents"`
+}
+
+// TrialBalance is the sum of the debits and of the credits of the ledger in a currency. They are always equal in a
+// consistent ledger.
+type TrialBalance struct {
+	Currency     string `json:"currency" db:"currency"`
+	DebitsCents  int64  `json:"debits_cents" db:"debits_cents"`
+	CreditsCents int64  `json:"credits_cents" db:"credits_cents"`
+}
+
+// PaymentDiscrepancy is a payment whose net amount, captured less refunded, differs from the cash posted to the ledger
+// for it. It happens when a payment changes without its journal entry.
+type PaymentDiscrepancy struct {
+	PaymentID string        `json:"payment_id" db:"payment_id"`
+	BookingID string        `json:"booking_id" db:"booking_id"`
+	Status    PaymentStatus `json:"status" db:"status"`
+	Currency  string        `json:"currency" db:"currency"`
+	// PaymentCents is the net amount of the payment and LedgerCents is the cash of its journal entries.
+	PaymentCents int64 `json:"payment_cents" db:"payment_cents"`
+	LedgerCents  int64 `json:"ledger_cents" db:"ledger_cents"`
+}
+
+// ReconciliationReport checks the ledger against itself and against the payments.
+type ReconciliationReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	// TrialBalances are the debits and credits of each currency.
+	TrialBalances []TrialBalance `json:"trial_balances"`
+	// Balances are the balances of each account code and currency. The guest refunds payable are refunds not sent yet.
+	Balances []AccountBalance `json:"balances"`
+	// Discrepancies are the payments that don't match the ledger.
+	Discrepancies []PaymentDiscrepancy `json:"discrepancies"`
+	// Balanced reports whether the debits equal the credits in every currency and no payment has discrepancies.
+	Balanced bool `json:"balanced"`
+}
+
+// NewReconciliationReport builds the report of the trial balances, account balances and discrepancies of the ledger
+// at now. Nil slices are replaced by empty ones.
+func NewReconciliationReport(trial []TrialBalance, balances []AccountBalance, discrepancies []PaymentDiscrepancy, now time.Time) ReconciliationReport {
+	report := ReconciliationReport{
+		GeneratedAt:   now,
+		TrialBalances: append([]TrialBalance{}, trial...),
+		Balances:      append([]AccountBalance{}, balances...),
+		Discrepancies: append([]PaymentDiscrepancy{}, discrepancies...),
+		Balanced:      len(discrepancies) == 0,
+	}
+
+	for _, t := range trial {
+		if t.DebitsCents != t.CreditsCents {
+			report.Balanced = false
+		}
+	}
+
+	return report
+}
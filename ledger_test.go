@@ -0,0 +1,153 @@
+package reserv
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalEntry_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   JournalEntry
+		wantErr bool
+	}{
+		{name: "balanced", entry: JournalEntry{Kind: JournalEntryPayout, Currency: "USD", Postings: []Posting{
+			{Account: AccountHostPayable, HostID: "host", AmountCents: 1000},
+			{Account: AccountCash, AmountCents: -1000},
+		}}},
+		{name: "unbalanced", entry: JournalEntry{Kind: JournalEntryPayout, Currency: "USD", Postings: []Posting{
+			{Account: AccountHostPayable, HostID: "host", AmountCents: 1000},
+			{Account: AccountCash, AmountCents: -900},
+		}}, wantErr: true},
+		{name: "single posting", entry: JournalEntry{Kind: JournalEntryPayout, Currency: "USD", Postings: []Posting{
+			{Account: AccountCash, AmountCents: 0},
+		}}, wantErr: true},
+		{name: "zero posting", entry: JournalEntry{Kind: JournalEntryPayout, Currency: "USD", Postings: []Posting{
+			{Account: AccountHostPayable, HostID: "host", AmountCents: 0},
+			{Account: AccountCash, AmountCents: 0},
+		}}, wantErr: true},
+		{name: "host payable without host", entry: JournalEntry{Kind: JournalEntryPayout, Currency: "USD", Postings: []Posting{
+			{Account: AccountHostPayable, AmountCents: 1000},
+			{Account: AccountCash, AmountCents: -1000},
+		}}, wantErr: true},
+		{name: "host on another account", entry: JournalEntry{Kind: JournalEntryPayout, Currency: "USD", Postings: []Posting{
+			{Account: AccountHostPayable, HostID: "host", AmountCents: 1000},
+			{Account: AccountCash, HostID: "host", AmountCents: -1000},
+		}}, wantErr: true},
+		{name: "unknown account", entry: JournalEntry{Kind: JournalEntryPayout, Currency: "USD", Postings: []Posting{
+			{Account: "bank", AmountCents: 1000},
+			{Account: AccountCash, AmountCents: -1000},
+		}}, wantErr: true},
+		{name: "unknown kind", entry: JournalEntry{Kind: "gift", Currency: "USD", Postings: []Posting{
+			{Account: AccountHostPayable, HostID: "host", AmountCents: 1000},
+			{Account: AccountCash, AmountCents: -1000},
+		}}, wantErr: true},
+		{name: "invalid currency", entry: JournalEntry{Kind: JournalEntryPayout, Currency: "usd", Postings: []Posting{
+			{Account: AccountHostPayable, HostID: "host", AmountCents: 1000},
+			{Account: AccountCash, AmountCents: -1000},
+		}}, wantErr: true},
+		{name: "overflow", entry: JournalEntry{Kind: JournalEntryPayout, Currency: "USD", Postings: []Posting{
+			{Account: AccountHostPayable, HostID: "host", AmountCents: math.MaxInt64},
+			{Account: AccountHostPayable, HostID: "host", AmountCents: math.MaxInt64},
+			{Account: AccountCash, AmountCents: -1},
+		}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.entry.Validate()
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidJournalEntry)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSplitAmount(t *testing.T) {
+	lines := []QuoteLine{
+		{Kind: QuoteLineNightly, AmountCents: 30000},
+		{Kind: QuoteLineDiscount, AmountCents: -3000},
+		{Kind: QuoteLineCleaningFee, AmountCents: 5000},
+		{Kind: QuoteLineServiceFee, AmountCents: 3200},
+		{Kind: QuoteLineTax, AmountCents: 1280},
+		{Kind: QuoteLineTax, AmountCents: 640},
+	}
+
+	// The whole price is split as the lines.
+	require.Equal(t, Split{HostCents: 32000, ServiceFeeCents: 3200, TaxCents: 1920}, SplitAmount(lines, 37120))
+
+	// A partial amount is split in the same proportion, and the host gets the rounding.
+	require.Equal(t, Split{HostCents: 8621, ServiceFeeCents: 862, TaxCents: 517}, SplitAmount(lines, 10000))
+
+	// Bookings priced before the line items existed are all for the host.
+	require.Equal(t, Split{HostCents: 30000}, SplitAmount(nil, 30000))
+
+	// Large amounts don't overflow.
+	huge := []QuoteLine{{Kind: QuoteLineNightly, AmountCents: math.MaxInt64 / 2}, {Kind: QuoteLineServiceFee, AmountCents: math.MaxInt64 / 2}}
+	require.Equal(t, Split{HostCents: math.MaxInt64/2 - math.MaxInt64/4, ServiceFeeCents: math.MaxInt64 / 4}, SplitAmount(huge, math.MaxInt64/2))
+}
+
+func TestLedgerEntries(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	booking := Booking{ID: "booking", LineItems: []QuoteLine{
+		{Kind: QuoteLineNightly, AmountCents: 30000},
+		{Kind: QuoteLineServiceFee, AmountCents: 3000},
+		{Kind: QuoteLineTax, AmountCents: 1500},
+	}}
+	payment := Payment{ID: "payment", BookingID: "booking", AmountCents: 34500, Currency: "USD"}
+
+	entry := PaymentEntry(payment, booking, "host", now)
+	require.NoError(t, entry.Validate())
+	require.Equal(t, JournalEntryPayment, entry.Kind)
+	require.Equal(t, "booking", *entry.BookingID)
+	require.Equal(t, "payment", *entry.PaymentID)
+	require.Equal(t, []Posting{
+		{Account: AccountCash, AmountCents: 34500},
+		{Account: AccountHostPayable, HostID: "host", AmountCents: -30000},
+		{Account: AccountPlatformRevenue, AmountCents: -3000},
+		{Account: AccountTaxesPayable, AmountCents: -1500},
+	}, entry.Postings)
+
+	// Half of the payment is refunded: half of each share is taken back and owed to the guest.
+	entry = CancellationEntry(payment, booking, "host", 17250, now)
+	require.NoError(t, entry.Validate())
+	require.Equal(t, []Posting{
+		{Account: AccountHostPayable, HostID: "host", AmountCents: 15000},
+		{Account: AccountPlatformRevenue, AmountCents: 1500},
+		{Account: AccountTaxesPayable, AmountCents: 750},
+		{Account: AccountGuestRefundsPayable, AmountCents: -17250},
+	}, entry.Postings)
+
+	entry = RefundEntry(payment, 17250, now)
+	require.NoError(t, entry.Validate())
+	require.Equal(t, []Posting{
+		{Account: AccountGuestRefundsPayable, AmountCents: 17250},
+		{Account: AccountCash, AmountCents: -17250},
+	}, entry.Postings)
+}
+
+func TestAccountCode_Balance(t *testing.T) {
+	require.Equal(t, int64(1000), AccountCash.Balance(1000))
+	require.Equal(t, int64(1000), AccountHostPayable.Balance(-1000))
+	require.Equal(t, int64(-1000), AccountPlatformRevenue.Balance(1000))
+}
+
+func TestNewReconciliationReport(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	report := NewReconciliationReport(nil, nil, nil, now)
+	require.True(t, report.Balanced)
+	require.Empty(t, report.TrialBalances)
+	require.NotNil(t, report.Discrepancies)
+
+	report = NewReconciliationReport([]TrialBalance{{Currency: "USD", DebitsCents: 1000, CreditsCents: 900}}, nil, nil, now)
+	require.False(t, report.Balanced)
+
+	report = NewReconciliationReport([]TrialBalance{{Currency: "USD", DebitsCents: 1000, CreditsCents: 1000}}, nil, []PaymentDiscrepancy{{PaymentID: "payment", PaymentCents: 1000}}, now)
+	require.False(t, report.Balanced)
+}
@@ -0,0 +1,48 @@
+package reserv
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidDiscounts is returned when the long stay discounts of a property are invalid.
+var ErrInvalidDiscounts = errors.New("invalid long stay discounts")
+
+const (
+	// WeeklyDiscountNights and MonthlyDiscountNights are the shortest stays that get the weekly and monthly discounts.
+	WeeklyDiscountNights  = 7
+	MonthlyDiscountNights = 28
+	// MaxLongStayDiscountPercent is the highest long stay discount a host can offer.
+	MaxLongStayDiscountPercent = 99
+)
+
+// ValidateDiscounts checks the long stay discounts of the property. The monthly discount can't be lower than the
+// weekly one, otherwise staying longer would cost more per night. The returned error wraps ErrInvalidDiscounts.
+func (p Property) ValidateDiscounts() error {
+	for _, d := range []struct {
+		name    string
+		percent int
+	}{{"weekly_discount_percent", p.WeeklyDiscountPercent}, {"monthly_discount_percent", p.MonthlyDiscountPercent}} {
+		if d.percent < 0 || d.percent > MaxLongStayDiscountPercent {
+			return fmt.Errorf("%w: %s must be between 0 and %d", ErrInvalidDiscounts, d.name, MaxLongStayDiscountPercent)
+		}
+	}
+
+	if p.MonthlyDiscountPercent > 0 && p.MonthlyDiscountPercent < p.WeeklyDiscountPercent {
+		return fmt.Errorf("%w: monthly_discount_percent must not be lower than weekly_discount_percent", ErrInvalidDiscounts)
+	}
+
+	return nil
+}
+
+// LongStayDiscount returns the name and the percentage of the long stay discount of a stay of nights. The monthly
+// discount replaces the weekly one, they are never combined. It returns 0 when the stay has no discount.
+func (p Property) LongStayDiscount(nights int) (string, int) {
+	if nights >= MonthlyDiscountNights && p.MonthlyDiscountPercent > 0 {
+		return "Monthly", p.MonthlyDiscountPercent
+	}
+	if nights >= WeeklyDiscountNights && p.WeeklyDiscountPercent > 0 {
+		return "Weekly", p.WeeklyDiscountPercent
+	}
+	return "", 0
+}
@@ -0,0 +1,45 @@
+package reserv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProperty_ValidateDiscounts(t *testing.T) {
+	require.NoError(t, Property{}.ValidateDiscounts())
+	require.NoError(t, Property{WeeklyDiscountPercent: 10, MonthlyDiscountPercent: 25}.ValidateDiscounts())
+	require.NoError(t, Property{WeeklyDiscountPercent: 10}.ValidateDiscounts())
+	require.NoError(t, Property{MonthlyDiscountPercent: 25}.ValidateDiscounts())
+
+	require.ErrorIs(t, Property{WeeklyDiscountPercent: -1}.ValidateDiscounts(), ErrInvalidDiscounts)
+	require.ErrorIs(t, Property{MonthlyDiscountPercent: 100}.ValidateDiscounts(), ErrInvalidDiscounts)
+	require.ErrorIs(t, Property{WeeklyDiscountPercent: 20, MonthlyDiscountPercent: 10}.ValidateDiscounts(), ErrInvalidDiscounts)
+}
+
+func TestProperty_LongStayDiscount(t *testing.T) {
+	property := Property{WeeklyDiscountPercent: 10, MonthlyDiscountPercent: 25}
+
+	tests := []struct {
+		nights      int
+		wantName    string
+		wantPercent int
+	}{
+		{nights: 6},
+		{nights: 7, wantName: "Weekly", wantPercent: 10},
+		{nights: 27, wantName: "Weekly", wantPercent: 10},
+		{nights: 28, wantName: "Monthly", wantPercent: 25},
+		{nights: 90, wantName: "Monthly", wantPercent: 25},
+	}
+
+	for _, tt := range tests {
+		name, percent := property.LongStayDiscount(tt.nights)
+		require.Equal(t, tt.wantName, name, "nights: %d", tt.nights)
+		require.Equal(t, tt.wantPercent, percent, "nights: %d", tt.nights)
+	}
+
+	// Without a monthly discount, the weekly discount applies to the longer stays too.
+	name, percent := Property{WeeklyDiscountPercent: 10}.LongStayDiscount(30)
+	require.Equal(t, "Weekly", name)
+	require.Equal(t, 10, percent)
+}
@@ -0,0 +1,74 @@
+package reserv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrThreadNotFound is returned when a message thread doesn't exist.
+var ErrThreadNotFound = errors.New("thread not found")
+
+// ErrInvalidMessage is returned when the body of a message is empty or too long.
+var ErrInvalidMessage = errors.New("invalid message")
+
+// MaxMessageLength is the longest body of a message, in characters.
+const MaxMessageLength = 5000
+
+// Thread is a conversation between a guest and the host of a property. A thread is tied to a booking, or it is an
+// inquiry of the guest about the property before booking it. Each booking has one thread, and each guest has one
+// inquiry thread per property.
+type Thread struct {
+	ID         string `json:"id" db:"id"`
+	PropertyID string `json:"property_id" db:"property_id"`
+	// BookingID is the booking of the thread. It is nil for inquiries.
+	BookingID *string `json:"booking_id" db:"booking_id"`
+	// GuestID and HostID are the Clerk user ids of the two participants.
+	GuestID string `json:"guest_id" db:"guest_id"`
+	HostID  string `json:"host_id" db:"host_id"`
+	// UnreadCount is how many messages of the other participant the user listing the threads didn't read yet.
+	UnreadCount   int       `json:"unread_count" db:"unread_count"`
+	LastMessageAt time.Time `json:"last_message_at" db:"last_message_at"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsParticipant reports whether userID is the guest or the host of the thread.
+func (t Thread) IsParticipant(userID string) bool {
+	return userID != "" && (userID == t.GuestID || userID == t.HostID)
+}
+
+// Cursor returns the position of the thread in the lists of threads, ordered by the last message.
+func (t Thread) Cursor() Cursor {
+	return Cursor{Time: t.LastMessageAt, ID: t.ID}
+}
+
+// Message is a message sent by a participant of a thread.
+type Message struct {
+	ID       string `json:"id" db:"id"`
+	ThreadID string `json:"thread_id" db:"thread_id"`
+	SenderID string `json:"sender_id" db:"sender_id"`
+	Body     string `json:"body" db:"body"`
+	// ReadAt is when the other participant read the message. It is nil while unread.
+	ReadAt    *time.Time `json:"read_at" db:"read_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Cursor returns the position of the message in the lists of messages, ordered by creation.
+func (m Message) Cursor() Cursor {
+	return Cursor{Time: m.CreatedAt, ID: m.ID}
+}
+
+// NormalizeMessageBody trims the body of a message and checks that it is not empty and at most MaxMessageLength
+// characters long. The returned error wraps ErrInvalidMessage.
+func NormalizeMessageBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", fmt.Errorf("%w: the message is empty", ErrInvalidMessage)
+	}
+	if utf8.RuneCountInString(body) > MaxMessageLength {
+		return "", fmt.Errorf("%w: the message is longer than %d characters", ErrInvalidMessage, MaxMessageLength)
+	}
+	return body, nil
+}
@@ -0,0 +1,31 @@
+package reserv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThread_IsParticipant(t *testing.T) {
+	thread := Thread{GuestID: "guest", HostID: "host"}
+	require.True(t, thread.IsParticipant("guest"))
+	require.True(t, thread.IsParticipant("host"))
+	require.False(t, thread.IsParticipant("other"))
+	require.False(t, Thread{GuestID: "guest"}.IsParticipant(""))
+}
+
+func TestNormalizeMessageBody(t *testing.T) {
+	body, err := NormalizeMessageBody("  Is early check-in possible?\n")
+	require.NoError(t, err)
+	require.Equal(t, "Is early check-in possible?", body)
+
+	// The length is counted in characters, not bytes.
+	_, err = NormalizeMessageBody(strings.Repeat("é", MaxMessageLength))
+	require.NoError(t, err)
+
+	for _, body := range []string{"", " \n\t", strings.Repeat("a", MaxMessageLength+1)} {
+		_, err := NormalizeMessageBody(body)
+		require.ErrorIs(t, err, ErrInvalidMessage)
+	}
+}
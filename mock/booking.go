@@ -12,6 +12,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	reserv "github.com/perebaj/reserv"
 	gomock "go.uber.org/mock/gomock"
@@ -40,6 +41,21 @@ func (m *MockBookingRepository) EXPECT() *MockBookingRepositoryMockRecorder {
 	return m.recorder
 }
 
+// BookingChanges mocks base method.
+func (m *MockBookingRepository) BookingChanges(ctx context.Context, bookingID string) ([]reserv.BookingChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BookingChanges", ctx, bookingID)
+	ret0, _ := ret[0].([]reserv.BookingChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BookingChanges indicates an expected call of BookingChanges.
+func (mr *MockBookingRepositoryMockRecorder) BookingChanges(ctx, bookingID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookingChanges", reflect.TypeOf((*MockBookingRepository)(nil).BookingChanges), ctx, bookingID)
+}
+
 // Bookings mocks base method.
 func (m *MockBookingRepository) Bookings(ctx context.Context, filter reserv.BookingFilter) ([]reserv.Booking, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +71,35 @@ func (mr *MockBookingRepositoryMockRecorder) Bookings(ctx, filter any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bookings", reflect.TypeOf((*MockBookingRepository)(nil).Bookings), ctx, filter)
 }
 
+// CancelBooking mocks base method.
+func (m *MockBookingRepository) CancelBooking(ctx context.Context, id string, from, to reserv.BookingStatus, refundCents int64, entry *reserv.JournalEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelBooking", ctx, id, from, to, refundCents, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelBooking indicates an expected call of CancelBooking.
+func (mr *MockBookingRepositoryMockRecorder) CancelBooking(ctx, id, from, to, refundCents, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelBooking", reflect.TypeOf((*MockBookingRepository)(nil).CancelBooking), ctx, id, from, to, refundCents, entry)
+}
+
+// ChangeBookingDates mocks base method.
+func (m *MockBookingRepository) ChangeBookingDates(ctx context.Context, change reserv.BookingChange) (reserv.BookingChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeBookingDates", ctx, change)
+	ret0, _ := ret[0].(reserv.BookingChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeBookingDates indicates an expected call of ChangeBookingDates.
+func (mr *MockBookingRepositoryMockRecorder) ChangeBookingDates(ctx, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeBookingDates", reflect.TypeOf((*MockBookingRepository)(nil).ChangeBookingDates), ctx, change)
+}
+
 // CreateBooking mocks base method.
 func (m *MockBookingRepository) CreateBooking(ctx context.Context, booking reserv.Booking) (string, error) {
 	m.ctrl.T.Helper()
@@ -70,18 +115,95 @@ func (mr *MockBookingRepositoryMockRecorder) CreateBooking(ctx, booking any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBooking", reflect.TypeOf((*MockBookingRepository)(nil).CreateBooking), ctx, booking)
 }
 
-// DeleteBooking mocks base method.
-func (m *MockBookingRepository) DeleteBooking(ctx context.Context, id string) error {
+// CreateBookingHold mocks base method.
+func (m *MockBookingRepository) CreateBookingHold(ctx context.Context, hold reserv.BookingHold) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteBooking", ctx, id)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "CreateBookingHold", ctx, hold)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteBooking indicates an expected call of DeleteBooking.
-func (mr *MockBookingRepositoryMockRecorder) DeleteBooking(ctx, id any) *gomock.Call {
+// CreateBookingHold indicates an expected call of CreateBookingHold.
+func (mr *MockBookingRepositoryMockRecorder) CreateBookingHold(ctx, hold any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBooking", reflect.TypeOf((*MockBookingRepository)(nil).DeleteBooking), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBookingHold", reflect.TypeOf((*MockBookingRepository)(nil).CreateBookingHold), ctx, hold)
+}
+
+// CreateMessage mocks base method.
+func (m *MockBookingRepository) CreateMessage(ctx context.Context, message reserv.Message) (reserv.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMessage", ctx, message)
+	ret0, _ := ret[0].(reserv.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMessage indicates an expected call of CreateMessage.
+func (mr *MockBookingRepositoryMockRecorder) CreateMessage(ctx, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMessage", reflect.TypeOf((*MockBookingRepository)(nil).CreateMessage), ctx, message)
+}
+
+// CreatePayment mocks base method.
+func (m *MockBookingRepository) CreatePayment(ctx context.Context, payment reserv.Payment) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePayment", ctx, payment)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePayment indicates an expected call of CreatePayment.
+func (mr *MockBookingRepositoryMockRecorder) CreatePayment(ctx, payment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockBookingRepository)(nil).CreatePayment), ctx, payment)
+}
+
+// CreatePromotion mocks base method.
+func (m *MockBookingRepository) CreatePromotion(ctx context.Context, promotion reserv.Promotion) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromotion", ctx, promotion)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePromotion indicates an expected call of CreatePromotion.
+func (mr *MockBookingRepositoryMockRecorder) CreatePromotion(ctx, promotion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromotion", reflect.TypeOf((*MockBookingRepository)(nil).CreatePromotion), ctx, promotion)
+}
+
+// CreateReview mocks base method.
+func (m *MockBookingRepository) CreateReview(ctx context.Context, review reserv.Review) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReview", ctx, review)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReview indicates an expected call of CreateReview.
+func (mr *MockBookingRepositoryMockRecorder) CreateReview(ctx, review any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReview", reflect.TypeOf((*MockBookingRepository)(nil).CreateReview), ctx, review)
+}
+
+// CreateThread mocks base method.
+func (m *MockBookingRepository) CreateThread(ctx context.Context, thread reserv.Thread) (reserv.Thread, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateThread", ctx, thread)
+	ret0, _ := ret[0].(reserv.Thread)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateThread indicates an expected call of CreateThread.
+func (mr *MockBookingRepositoryMockRecorder) CreateThread(ctx, thread any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateThread", reflect.TypeOf((*MockBookingRepository)(nil).CreateThread), ctx, thread)
 }
 
 // GetBooking mocks base method.
@@ -99,3 +221,285 @@ func (mr *MockBookingRepositoryMockRecorder) GetBooking(ctx, id any) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBooking", reflect.TypeOf((*MockBookingRepository)(nil).GetBooking), ctx, id)
 }
+
+// GetBookingsByHostID mocks base method.
+func (m *MockBookingRepository) GetBookingsByHostID(ctx context.Context, filter reserv.HostBookingFilter) ([]reserv.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookingsByHostID", ctx, filter)
+	ret0, _ := ret[0].([]reserv.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookingsByHostID indicates an expected call of GetBookingsByHostID.
+func (mr *MockBookingRepositoryMockRecorder) GetBookingsByHostID(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingsByHostID", reflect.TypeOf((*MockBookingRepository)(nil).GetBookingsByHostID), ctx, filter)
+}
+
+// GetPromotion mocks base method.
+func (m *MockBookingRepository) GetPromotion(ctx context.Context, id string) (reserv.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromotion", ctx, id)
+	ret0, _ := ret[0].(reserv.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromotion indicates an expected call of GetPromotion.
+func (mr *MockBookingRepositoryMockRecorder) GetPromotion(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromotion", reflect.TypeOf((*MockBookingRepository)(nil).GetPromotion), ctx, id)
+}
+
+// GetThread mocks base method.
+func (m *MockBookingRepository) GetThread(ctx context.Context, id string) (reserv.Thread, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetThread", ctx, id)
+	ret0, _ := ret[0].(reserv.Thread)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetThread indicates an expected call of GetThread.
+func (mr *MockBookingRepositoryMockRecorder) GetThread(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetThread", reflect.TypeOf((*MockBookingRepository)(nil).GetThread), ctx, id)
+}
+
+// HostBalances mocks base method.
+func (m *MockBookingRepository) HostBalances(ctx context.Context, hostID string) ([]reserv.HostBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HostBalances", ctx, hostID)
+	ret0, _ := ret[0].([]reserv.HostBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HostBalances indicates an expected call of HostBalances.
+func (mr *MockBookingRepositoryMockRecorder) HostBalances(ctx, hostID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HostBalances", reflect.TypeOf((*MockBookingRepository)(nil).HostBalances), ctx, hostID)
+}
+
+// HostPayouts mocks base method.
+func (m *MockBookingRepository) HostPayouts(ctx context.Context, hostID string) ([]reserv.Payout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HostPayouts", ctx, hostID)
+	ret0, _ := ret[0].([]reserv.Payout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HostPayouts indicates an expected call of HostPayouts.
+func (mr *MockBookingRepositoryMockRecorder) HostPayouts(ctx, hostID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HostPayouts", reflect.TypeOf((*MockBookingRepository)(nil).HostPayouts), ctx, hostID)
+}
+
+// HostStatement mocks base method.
+func (m *MockBookingRepository) HostStatement(ctx context.Context, hostID string, month time.Time) ([]reserv.StatementLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HostStatement", ctx, hostID, month)
+	ret0, _ := ret[0].([]reserv.StatementLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HostStatement indicates an expected call of HostStatement.
+func (mr *MockBookingRepositoryMockRecorder) HostStatement(ctx, hostID, month any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HostStatement", reflect.TypeOf((*MockBookingRepository)(nil).HostStatement), ctx, hostID, month)
+}
+
+// MarkThreadRead mocks base method.
+func (m *MockBookingRepository) MarkThreadRead(ctx context.Context, threadID, readerID string, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkThreadRead", ctx, threadID, readerID, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkThreadRead indicates an expected call of MarkThreadRead.
+func (mr *MockBookingRepositoryMockRecorder) MarkThreadRead(ctx, threadID, readerID, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkThreadRead", reflect.TypeOf((*MockBookingRepository)(nil).MarkThreadRead), ctx, threadID, readerID, now)
+}
+
+// Messages mocks base method.
+func (m *MockBookingRepository) Messages(ctx context.Context, threadID string, page reserv.Page) ([]reserv.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Messages", ctx, threadID, page)
+	ret0, _ := ret[0].([]reserv.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Messages indicates an expected call of Messages.
+func (mr *MockBookingRepositoryMockRecorder) Messages(ctx, threadID, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Messages", reflect.TypeOf((*MockBookingRepository)(nil).Messages), ctx, threadID, page)
+}
+
+// Occupancy mocks base method.
+func (m *MockBookingRepository) Occupancy(ctx context.Context, propertyID string, from, to time.Time) ([]reserv.Occupancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Occupancy", ctx, propertyID, from, to)
+	ret0, _ := ret[0].([]reserv.Occupancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Occupancy indicates an expected call of Occupancy.
+func (mr *MockBookingRepositoryMockRecorder) Occupancy(ctx, propertyID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Occupancy", reflect.TypeOf((*MockBookingRepository)(nil).Occupancy), ctx, propertyID, from, to)
+}
+
+// PaymentByBookingID mocks base method.
+func (m *MockBookingRepository) PaymentByBookingID(ctx context.Context, bookingID string) (reserv.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PaymentByBookingID", ctx, bookingID)
+	ret0, _ := ret[0].(reserv.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PaymentByBookingID indicates an expected call of PaymentByBookingID.
+func (mr *MockBookingRepositoryMockRecorder) PaymentByBookingID(ctx, bookingID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaymentByBookingID", reflect.TypeOf((*MockBookingRepository)(nil).PaymentByBookingID), ctx, bookingID)
+}
+
+// PaymentByProviderID mocks base method.
+func (m *MockBookingRepository) PaymentByProviderID(ctx context.Context, provider, providerPaymentID string) (reserv.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PaymentByProviderID", ctx, provider, providerPaymentID)
+	ret0, _ := ret[0].(reserv.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PaymentByProviderID indicates an expected call of PaymentByProviderID.
+func (mr *MockBookingRepositoryMockRecorder) PaymentByProviderID(ctx, provider, providerPaymentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaymentByProviderID", reflect.TypeOf((*MockBookingRepository)(nil).PaymentByProviderID), ctx, provider, providerPaymentID)
+}
+
+// PromotionByCode mocks base method.
+func (m *MockBookingRepository) PromotionByCode(ctx context.Context, code string) (reserv.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromotionByCode", ctx, code)
+	ret0, _ := ret[0].(reserv.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PromotionByCode indicates an expected call of PromotionByCode.
+func (mr *MockBookingRepositoryMockRecorder) PromotionByCode(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromotionByCode", reflect.TypeOf((*MockBookingRepository)(nil).PromotionByCode), ctx, code)
+}
+
+// Promotions mocks base method.
+func (m *MockBookingRepository) Promotions(ctx context.Context) ([]reserv.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Promotions", ctx)
+	ret0, _ := ret[0].([]reserv.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Promotions indicates an expected call of Promotions.
+func (mr *MockBookingRepositoryMockRecorder) Promotions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Promotions", reflect.TypeOf((*MockBookingRepository)(nil).Promotions), ctx)
+}
+
+// PropertyReviews mocks base method.
+func (m *MockBookingRepository) PropertyReviews(ctx context.Context, propertyID string, page reserv.Page) ([]reserv.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PropertyReviews", ctx, propertyID, page)
+	ret0, _ := ret[0].([]reserv.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PropertyReviews indicates an expected call of PropertyReviews.
+func (mr *MockBookingRepositoryMockRecorder) PropertyReviews(ctx, propertyID, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PropertyReviews", reflect.TypeOf((*MockBookingRepository)(nil).PropertyReviews), ctx, propertyID, page)
+}
+
+// Reconciliation mocks base method.
+func (m *MockBookingRepository) Reconciliation(ctx context.Context, now time.Time) (reserv.ReconciliationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconciliation", ctx, now)
+	ret0, _ := ret[0].(reserv.ReconciliationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconciliation indicates an expected call of Reconciliation.
+func (mr *MockBookingRepositoryMockRecorder) Reconciliation(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconciliation", reflect.TypeOf((*MockBookingRepository)(nil).Reconciliation), ctx, now)
+}
+
+// Threads mocks base method.
+func (m *MockBookingRepository) Threads(ctx context.Context, userID string, page reserv.Page) ([]reserv.Thread, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Threads", ctx, userID, page)
+	ret0, _ := ret[0].([]reserv.Thread)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Threads indicates an expected call of Threads.
+func (mr *MockBookingRepositoryMockRecorder) Threads(ctx, userID, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Threads", reflect.TypeOf((*MockBookingRepository)(nil).Threads), ctx, userID, page)
+}
+
+// UpdateBookingStatus mocks base method.
+func (m *MockBookingRepository) UpdateBookingStatus(ctx context.Context, id string, from, to reserv.BookingStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBookingStatus", ctx, id, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBookingStatus indicates an expected call of UpdateBookingStatus.
+func (mr *MockBookingRepositoryMockRecorder) UpdateBookingStatus(ctx, id, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBookingStatus", reflect.TypeOf((*MockBookingRepository)(nil).UpdateBookingStatus), ctx, id, from, to)
+}
+
+// UpdatePayment mocks base method.
+func (m *MockBookingRepository) UpdatePayment(ctx context.Context, payment reserv.Payment, from reserv.PaymentStatus, entry *reserv.JournalEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePayment", ctx, payment, from, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePayment indicates an expected call of UpdatePayment.
+func (mr *MockBookingRepositoryMockRecorder) UpdatePayment(ctx, payment, from, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePayment", reflect.TypeOf((*MockBookingRepository)(nil).UpdatePayment), ctx, payment, from, entry)
+}
+
+// UpdatePromotion mocks base method.
+func (m *MockBookingRepository) UpdatePromotion(ctx context.Context, promotion reserv.Promotion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePromotion", ctx, promotion)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePromotion indicates an expected call of UpdatePromotion.
+func (mr *MockBookingRepositoryMockRecorder) UpdatePromotion(ctx, promotion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePromotion", reflect.TypeOf((*MockBookingRepository)(nil).UpdatePromotion), ctx, promotion)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: bookingrequests.go
+//
+// Generated by this command:
+//
+//	mockgen -source bookingrequests.go -destination ../mock/bookingrequests.go -package mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRequestRepository is a mock of RequestRepository interface.
+type MockRequestRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRequestRepositoryMockRecorder
+}
+
+// MockRequestRepositoryMockRecorder is the mock recorder for MockRequestRepository.
+type MockRequestRepositoryMockRecorder struct {
+	mock *MockRequestRepository
+}
+
+// NewMockRequestRepository creates a new mock instance.
+func NewMockRequestRepository(ctrl *gomock.Controller) *MockRequestRepository {
+	mock := &MockRequestRepository{ctrl: ctrl}
+	mock.recorder = &MockRequestRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRequestRepository) EXPECT() *MockRequestRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeclineExpiredBookingRequests mocks base method.
+func (m *MockRequestRepository) DeclineExpiredBookingRequests(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclineExpiredBookingRequests", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeclineExpiredBookingRequests indicates an expected call of DeclineExpiredBookingRequests.
+func (mr *MockRequestRepositoryMockRecorder) DeclineExpiredBookingRequests(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclineExpiredBookingRequests", reflect.TypeOf((*MockRequestRepository)(nil).DeclineExpiredBookingRequests), ctx, now)
+}
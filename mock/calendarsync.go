@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: calendarsync.go
+//
+// Generated by this command:
+//
+//	mockgen -source calendarsync.go -destination ../mock/calendarsync.go -package mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	reserv "github.com/perebaj/reserv"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockImportRepository is a mock of ImportRepository interface.
+type MockImportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockImportRepositoryMockRecorder
+}
+
+// MockImportRepositoryMockRecorder is the mock recorder for MockImportRepository.
+type MockImportRepositoryMockRecorder struct {
+	mock *MockImportRepository
+}
+
+// NewMockImportRepository creates a new mock instance.
+func NewMockImportRepository(ctrl *gomock.Controller) *MockImportRepository {
+	mock := &MockImportRepository{ctrl: ctrl}
+	mock.recorder = &MockImportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImportRepository) EXPECT() *MockImportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CalendarImportsToSync mocks base method.
+func (m *MockImportRepository) CalendarImportsToSync(ctx context.Context, syncedBefore time.Time) ([]reserv.CalendarImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalendarImportsToSync", ctx, syncedBefore)
+	ret0, _ := ret[0].([]reserv.CalendarImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalendarImportsToSync indicates an expected call of CalendarImportsToSync.
+func (mr *MockImportRepositoryMockRecorder) CalendarImportsToSync(ctx, syncedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalendarImportsToSync", reflect.TypeOf((*MockImportRepository)(nil).CalendarImportsToSync), ctx, syncedBefore)
+}
+
+// FailCalendarImport mocks base method.
+func (m *MockImportRepository) FailCalendarImport(ctx context.Context, id, syncErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailCalendarImport", ctx, id, syncErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailCalendarImport indicates an expected call of FailCalendarImport.
+func (mr *MockImportRepositoryMockRecorder) FailCalendarImport(ctx, id, syncErr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailCalendarImport", reflect.TypeOf((*MockImportRepository)(nil).FailCalendarImport), ctx, id, syncErr)
+}
+
+// SyncCalendarImport mocks base method.
+func (m *MockImportRepository) SyncCalendarImport(ctx context.Context, imp reserv.CalendarImport, blocks []reserv.CalendarBlock) (reserv.CalendarSyncResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncCalendarImport", ctx, imp, blocks)
+	ret0, _ := ret[0].(reserv.CalendarSyncResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncCalendarImport indicates an expected call of SyncCalendarImport.
+func (mr *MockImportRepositoryMockRecorder) SyncCalendarImport(ctx, imp, blocks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncCalendarImport", reflect.TypeOf((*MockImportRepository)(nil).SyncCalendarImport), ctx, imp, blocks)
+}
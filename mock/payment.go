@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: payment.go
+//
+// Generated by this command:
+//
+//	mockgen -source payment.go -destination ../mock/payment.go -package mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	reserv "github.com/perebaj/reserv"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPaymentProvider is a mock of PaymentProvider interface.
+type MockPaymentProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentProviderMockRecorder
+}
+
+// MockPaymentProviderMockRecorder is the mock recorder for MockPaymentProvider.
+type MockPaymentProviderMockRecorder struct {
+	mock *MockPaymentProvider
+}
+
+// NewMockPaymentProvider creates a new mock instance.
+func NewMockPaymentProvider(ctrl *gomock.Controller) *MockPaymentProvider {
+	mock := &MockPaymentProvider{ctrl: ctrl}
+	mock.recorder = &MockPaymentProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentProvider) EXPECT() *MockPaymentProviderMockRecorder {
+	return m.recorder
+}
+
+// Capture mocks base method.
+func (m *MockPaymentProvider) Capture(ctx context.Context, paymentID string, amountCents int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, paymentID, amountCents)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockPaymentProviderMockRecorder) Capture(ctx, paymentID, amountCents any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockPaymentProvider)(nil).Capture), ctx, paymentID, amountCents)
+}
+
+// CreateIntent mocks base method.
+func (m *MockPaymentProvider) CreateIntent(ctx context.Context, req reserv.PaymentIntentRequest) (reserv.PaymentIntent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIntent", ctx, req)
+	ret0, _ := ret[0].(reserv.PaymentIntent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIntent indicates an expected call of CreateIntent.
+func (mr *MockPaymentProviderMockRecorder) CreateIntent(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIntent", reflect.TypeOf((*MockPaymentProvider)(nil).CreateIntent), ctx, req)
+}
+
+// Refund mocks base method.
+func (m *MockPaymentProvider) Refund(ctx context.Context, paymentID string, amountCents int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", ctx, paymentID, amountCents)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refund indicates an expected call of Refund.
+func (mr *MockPaymentProviderMockRecorder) Refund(ctx, paymentID, amountCents any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockPaymentProvider)(nil).Refund), ctx, paymentID, amountCents)
+}
+
+// VerifyWebhook mocks base method.
+func (m *MockPaymentProvider) VerifyWebhook(payload []byte, signature string) (reserv.PaymentEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyWebhook", payload, signature)
+	ret0, _ := ret[0].(reserv.PaymentEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyWebhook indicates an expected call of VerifyWebhook.
+func (mr *MockPaymentProviderMockRecorder) VerifyWebhook(payload, signature any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyWebhook", reflect.TypeOf((*MockPaymentProvider)(nil).VerifyWebhook), payload, signature)
+}
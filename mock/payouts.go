@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: payouts.go
+//
+// Generated by this command:
+//
+//	mockgen -source payouts.go -destination ../mock/payouts.go -package mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	reserv "github.com/perebaj/reserv"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPayoutRepository is a mock of PayoutRepository interface.
+type MockPayoutRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPayoutRepositoryMockRecorder
+}
+
+// MockPayoutRepositoryMockRecorder is the mock recorder for MockPayoutRepository.
+type MockPayoutRepositoryMockRecorder struct {
+	mock *MockPayoutRepository
+}
+
+// NewMockPayoutRepository creates a new mock instance.
+func NewMockPayoutRepository(ctrl *gomock.Controller) *MockPayoutRepository {
+	mock := &MockPayoutRepository{ctrl: ctrl}
+	mock.recorder = &MockPayoutRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPayoutRepository) EXPECT() *MockPayoutRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CompletePayout mocks base method.
+func (m *MockPayoutRepository) CompletePayout(ctx context.Context, payout reserv.Payout, entry reserv.JournalEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompletePayout", ctx, payout, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompletePayout indicates an expected call of CompletePayout.
+func (mr *MockPayoutRepositoryMockRecorder) CompletePayout(ctx, payout, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompletePayout", reflect.TypeOf((*MockPayoutRepository)(nil).CompletePayout), ctx, payout, entry)
+}
+
+// PendingPayouts mocks base method.
+func (m *MockPayoutRepository) PendingPayouts(ctx context.Context) ([]reserv.Payout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingPayouts", ctx)
+	ret0, _ := ret[0].([]reserv.Payout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingPayouts indicates an expected call of PendingPayouts.
+func (mr *MockPayoutRepositoryMockRecorder) PendingPayouts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingPayouts", reflect.TypeOf((*MockPayoutRepository)(nil).PendingPayouts), ctx)
+}
+
+// SchedulePayouts mocks base method.
+func (m *MockPayoutRepository) SchedulePayouts(ctx context.Context, delayDays int, now time.Time) ([]reserv.Payout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchedulePayouts", ctx, delayDays, now)
+	ret0, _ := ret[0].([]reserv.Payout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SchedulePayouts indicates an expected call of SchedulePayouts.
+func (mr *MockPayoutRepositoryMockRecorder) SchedulePayouts(ctx, delayDays, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchedulePayouts", reflect.TypeOf((*MockPayoutRepository)(nil).SchedulePayouts), ctx, delayDays, now)
+}
+
+// MockPayoutProvider is a mock of PayoutProvider interface.
+type MockPayoutProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockPayoutProviderMockRecorder
+}
+
+// MockPayoutProviderMockRecorder is the mock recorder for MockPayoutProvider.
+type MockPayoutProviderMockRecorder struct {
+	mock *MockPayoutProvider
+}
+
+// NewMockPayoutProvider creates a new mock instance.
+func NewMockPayoutProvider(ctrl *gomock.Controller) *MockPayoutProvider {
+	mock := &MockPayoutProvider{ctrl: ctrl}
+	mock.recorder = &MockPayoutProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPayoutProvider) EXPECT() *MockPayoutProviderMockRecorder {
+	return m.recorder
+}
+
+// Payout mocks base method.
+func (m *MockPayoutProvider) Payout(ctx context.Context, req reserv.PayoutRequest) (reserv.PayoutTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Payout", ctx, req)
+	ret0, _ := ret[0].(reserv.PayoutTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Payout indicates an expected call of Payout.
+func (mr *MockPayoutProviderMockRecorder) Payout(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Payout", reflect.TypeOf((*MockPayoutProvider)(nil).Payout), ctx, req)
+}
@@ -12,6 +12,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	reserv "github.com/perebaj/reserv"
 	gomock "go.uber.org/mock/gomock"
@@ -55,6 +56,126 @@ func (mr *MockPropertyRepositoryMockRecorder) Amenities(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Amenities", reflect.TypeOf((*MockPropertyRepository)(nil).Amenities), ctx)
 }
 
+// BookingRules mocks base method.
+func (m *MockPropertyRepository) BookingRules(ctx context.Context, propertyID string) (reserv.BookingRules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BookingRules", ctx, propertyID)
+	ret0, _ := ret[0].(reserv.BookingRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BookingRules indicates an expected call of BookingRules.
+func (mr *MockPropertyRepositoryMockRecorder) BookingRules(ctx, propertyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookingRules", reflect.TypeOf((*MockPropertyRepository)(nil).BookingRules), ctx, propertyID)
+}
+
+// CalendarBlocks mocks base method.
+func (m *MockPropertyRepository) CalendarBlocks(ctx context.Context, propertyID string) ([]reserv.CalendarBlock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalendarBlocks", ctx, propertyID)
+	ret0, _ := ret[0].([]reserv.CalendarBlock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalendarBlocks indicates an expected call of CalendarBlocks.
+func (mr *MockPropertyRepositoryMockRecorder) CalendarBlocks(ctx, propertyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalendarBlocks", reflect.TypeOf((*MockPropertyRepository)(nil).CalendarBlocks), ctx, propertyID)
+}
+
+// CalendarExportToken mocks base method.
+func (m *MockPropertyRepository) CalendarExportToken(ctx context.Context, propertyID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalendarExportToken", ctx, propertyID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalendarExportToken indicates an expected call of CalendarExportToken.
+func (mr *MockPropertyRepositoryMockRecorder) CalendarExportToken(ctx, propertyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalendarExportToken", reflect.TypeOf((*MockPropertyRepository)(nil).CalendarExportToken), ctx, propertyID)
+}
+
+// CalendarImports mocks base method.
+func (m *MockPropertyRepository) CalendarImports(ctx context.Context, propertyID string) ([]reserv.CalendarImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalendarImports", ctx, propertyID)
+	ret0, _ := ret[0].([]reserv.CalendarImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalendarImports indicates an expected call of CalendarImports.
+func (mr *MockPropertyRepositoryMockRecorder) CalendarImports(ctx, propertyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalendarImports", reflect.TypeOf((*MockPropertyRepository)(nil).CalendarImports), ctx, propertyID)
+}
+
+// CalendarImportsToSync mocks base method.
+func (m *MockPropertyRepository) CalendarImportsToSync(ctx context.Context, syncedBefore time.Time) ([]reserv.CalendarImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalendarImportsToSync", ctx, syncedBefore)
+	ret0, _ := ret[0].([]reserv.CalendarImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalendarImportsToSync indicates an expected call of CalendarImportsToSync.
+func (mr *MockPropertyRepositoryMockRecorder) CalendarImportsToSync(ctx, syncedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalendarImportsToSync", reflect.TypeOf((*MockPropertyRepository)(nil).CalendarImportsToSync), ctx, syncedBefore)
+}
+
+// CancellationPolicy mocks base method.
+func (m *MockPropertyRepository) CancellationPolicy(ctx context.Context, propertyID string) (reserv.CancellationPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancellationPolicy", ctx, propertyID)
+	ret0, _ := ret[0].(reserv.CancellationPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancellationPolicy indicates an expected call of CancellationPolicy.
+func (mr *MockPropertyRepositoryMockRecorder) CancellationPolicy(ctx, propertyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancellationPolicy", reflect.TypeOf((*MockPropertyRepository)(nil).CancellationPolicy), ctx, propertyID)
+}
+
+// CreateCalendarBlock mocks base method.
+func (m *MockPropertyRepository) CreateCalendarBlock(ctx context.Context, block reserv.CalendarBlock) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCalendarBlock", ctx, block)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCalendarBlock indicates an expected call of CreateCalendarBlock.
+func (mr *MockPropertyRepositoryMockRecorder) CreateCalendarBlock(ctx, block any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCalendarBlock", reflect.TypeOf((*MockPropertyRepository)(nil).CreateCalendarBlock), ctx, block)
+}
+
+// CreateCalendarImport mocks base method.
+func (m *MockPropertyRepository) CreateCalendarImport(ctx context.Context, imp reserv.CalendarImport) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCalendarImport", ctx, imp)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCalendarImport indicates an expected call of CreateCalendarImport.
+func (mr *MockPropertyRepositoryMockRecorder) CreateCalendarImport(ctx, imp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCalendarImport", reflect.TypeOf((*MockPropertyRepository)(nil).CreateCalendarImport), ctx, imp)
+}
+
 // CreateImage mocks base method.
 func (m *MockPropertyRepository) CreateImage(ctx context.Context, image reserv.PropertyImage) (string, error) {
 	m.ctrl.T.Helper()
@@ -70,6 +191,21 @@ func (mr *MockPropertyRepositoryMockRecorder) CreateImage(ctx, image any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImage", reflect.TypeOf((*MockPropertyRepository)(nil).CreateImage), ctx, image)
 }
 
+// CreatePricingRule mocks base method.
+func (m *MockPropertyRepository) CreatePricingRule(ctx context.Context, rule reserv.PricingRule) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePricingRule", ctx, rule)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePricingRule indicates an expected call of CreatePricingRule.
+func (mr *MockPropertyRepositoryMockRecorder) CreatePricingRule(ctx, rule any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePricingRule", reflect.TypeOf((*MockPropertyRepository)(nil).CreatePricingRule), ctx, rule)
+}
+
 // CreateProperty mocks base method.
 func (m *MockPropertyRepository) CreateProperty(ctx context.Context, property reserv.Property) (string, error) {
 	m.ctrl.T.Helper()
@@ -99,6 +235,49 @@ func (mr *MockPropertyRepositoryMockRecorder) CreatePropertyAmenities(ctx, prope
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePropertyAmenities", reflect.TypeOf((*MockPropertyRepository)(nil).CreatePropertyAmenities), ctx, propertyID, amenities)
 }
 
+// CreateTaxRule mocks base method.
+func (m *MockPropertyRepository) CreateTaxRule(ctx context.Context, rule reserv.TaxRule) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTaxRule", ctx, rule)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTaxRule indicates an expected call of CreateTaxRule.
+func (mr *MockPropertyRepositoryMockRecorder) CreateTaxRule(ctx, rule any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTaxRule", reflect.TypeOf((*MockPropertyRepository)(nil).CreateTaxRule), ctx, rule)
+}
+
+// DeleteCalendarBlock mocks base method.
+func (m *MockPropertyRepository) DeleteCalendarBlock(ctx context.Context, propertyID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCalendarBlock", ctx, propertyID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCalendarBlock indicates an expected call of DeleteCalendarBlock.
+func (mr *MockPropertyRepositoryMockRecorder) DeleteCalendarBlock(ctx, propertyID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCalendarBlock", reflect.TypeOf((*MockPropertyRepository)(nil).DeleteCalendarBlock), ctx, propertyID, id)
+}
+
+// DeleteCalendarImport mocks base method.
+func (m *MockPropertyRepository) DeleteCalendarImport(ctx context.Context, propertyID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCalendarImport", ctx, propertyID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCalendarImport indicates an expected call of DeleteCalendarImport.
+func (mr *MockPropertyRepositoryMockRecorder) DeleteCalendarImport(ctx, propertyID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCalendarImport", reflect.TypeOf((*MockPropertyRepository)(nil).DeleteCalendarImport), ctx, propertyID, id)
+}
+
 // DeleteImage mocks base method.
 func (m *MockPropertyRepository) DeleteImage(ctx context.Context, imageID string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -114,6 +293,20 @@ func (mr *MockPropertyRepositoryMockRecorder) DeleteImage(ctx, imageID any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImage", reflect.TypeOf((*MockPropertyRepository)(nil).DeleteImage), ctx, imageID)
 }
 
+// DeletePricingRule mocks base method.
+func (m *MockPropertyRepository) DeletePricingRule(ctx context.Context, propertyID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePricingRule", ctx, propertyID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePricingRule indicates an expected call of DeletePricingRule.
+func (mr *MockPropertyRepositoryMockRecorder) DeletePricingRule(ctx, propertyID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePricingRule", reflect.TypeOf((*MockPropertyRepository)(nil).DeletePricingRule), ctx, propertyID, id)
+}
+
 // DeleteProperty mocks base method.
 func (m *MockPropertyRepository) DeleteProperty(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -128,6 +321,64 @@ func (mr *MockPropertyRepositoryMockRecorder) DeleteProperty(ctx, id any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProperty", reflect.TypeOf((*MockPropertyRepository)(nil).DeleteProperty), ctx, id)
 }
 
+// DeleteTaxRule mocks base method.
+func (m *MockPropertyRepository) DeleteTaxRule(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTaxRule", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTaxRule indicates an expected call of DeleteTaxRule.
+func (mr *MockPropertyRepositoryMockRecorder) DeleteTaxRule(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTaxRule", reflect.TypeOf((*MockPropertyRepository)(nil).DeleteTaxRule), ctx, id)
+}
+
+// ExchangeRates mocks base method.
+func (m *MockPropertyRepository) ExchangeRates(ctx context.Context) ([]reserv.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExchangeRates", ctx)
+	ret0, _ := ret[0].([]reserv.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExchangeRates indicates an expected call of ExchangeRates.
+func (mr *MockPropertyRepositoryMockRecorder) ExchangeRates(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExchangeRates", reflect.TypeOf((*MockPropertyRepository)(nil).ExchangeRates), ctx)
+}
+
+// FailCalendarImport mocks base method.
+func (m *MockPropertyRepository) FailCalendarImport(ctx context.Context, id, syncErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailCalendarImport", ctx, id, syncErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailCalendarImport indicates an expected call of FailCalendarImport.
+func (mr *MockPropertyRepositoryMockRecorder) FailCalendarImport(ctx, id, syncErr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailCalendarImport", reflect.TypeOf((*MockPropertyRepository)(nil).FailCalendarImport), ctx, id, syncErr)
+}
+
+// GetCalendarImport mocks base method.
+func (m *MockPropertyRepository) GetCalendarImport(ctx context.Context, propertyID, id string) (reserv.CalendarImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCalendarImport", ctx, propertyID, id)
+	ret0, _ := ret[0].(reserv.CalendarImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCalendarImport indicates an expected call of GetCalendarImport.
+func (mr *MockPropertyRepositoryMockRecorder) GetCalendarImport(ctx, propertyID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCalendarImport", reflect.TypeOf((*MockPropertyRepository)(nil).GetCalendarImport), ctx, propertyID, id)
+}
+
 // GetProperty mocks base method.
 func (m *MockPropertyRepository) GetProperty(ctx context.Context, id string) (int, reserv.Property, error) {
 	m.ctrl.T.Helper()
@@ -159,6 +410,21 @@ func (mr *MockPropertyRepositoryMockRecorder) GetPropertyAmenities(ctx, property
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPropertyAmenities", reflect.TypeOf((*MockPropertyRepository)(nil).GetPropertyAmenities), ctx, propertyID)
 }
 
+// PricingRules mocks base method.
+func (m *MockPropertyRepository) PricingRules(ctx context.Context, propertyID string) ([]reserv.PricingRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PricingRules", ctx, propertyID)
+	ret0, _ := ret[0].([]reserv.PricingRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PricingRules indicates an expected call of PricingRules.
+func (mr *MockPropertyRepositoryMockRecorder) PricingRules(ctx, propertyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PricingRules", reflect.TypeOf((*MockPropertyRepository)(nil).PricingRules), ctx, propertyID)
+}
+
 // Properties mocks base method.
 func (m *MockPropertyRepository) Properties(ctx context.Context, filter reserv.PropertyFilter) ([]reserv.Property, error) {
 	m.ctrl.T.Helper()
@@ -174,6 +440,106 @@ func (mr *MockPropertyRepositoryMockRecorder) Properties(ctx, filter any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Properties", reflect.TypeOf((*MockPropertyRepository)(nil).Properties), ctx, filter)
 }
 
+// SetBookingRules mocks base method.
+func (m *MockPropertyRepository) SetBookingRules(ctx context.Context, rules reserv.BookingRules) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBookingRules", ctx, rules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBookingRules indicates an expected call of SetBookingRules.
+func (mr *MockPropertyRepositoryMockRecorder) SetBookingRules(ctx, rules any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBookingRules", reflect.TypeOf((*MockPropertyRepository)(nil).SetBookingRules), ctx, rules)
+}
+
+// SetCalendarExportToken mocks base method.
+func (m *MockPropertyRepository) SetCalendarExportToken(ctx context.Context, propertyID, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCalendarExportToken", ctx, propertyID, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCalendarExportToken indicates an expected call of SetCalendarExportToken.
+func (mr *MockPropertyRepositoryMockRecorder) SetCalendarExportToken(ctx, propertyID, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCalendarExportToken", reflect.TypeOf((*MockPropertyRepository)(nil).SetCalendarExportToken), ctx, propertyID, token)
+}
+
+// SetCancellationPolicy mocks base method.
+func (m *MockPropertyRepository) SetCancellationPolicy(ctx context.Context, policy reserv.CancellationPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCancellationPolicy", ctx, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCancellationPolicy indicates an expected call of SetCancellationPolicy.
+func (mr *MockPropertyRepositoryMockRecorder) SetCancellationPolicy(ctx, policy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCancellationPolicy", reflect.TypeOf((*MockPropertyRepository)(nil).SetCancellationPolicy), ctx, policy)
+}
+
+// SyncCalendarImport mocks base method.
+func (m *MockPropertyRepository) SyncCalendarImport(ctx context.Context, imp reserv.CalendarImport, blocks []reserv.CalendarBlock) (reserv.CalendarSyncResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncCalendarImport", ctx, imp, blocks)
+	ret0, _ := ret[0].(reserv.CalendarSyncResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncCalendarImport indicates an expected call of SyncCalendarImport.
+func (mr *MockPropertyRepositoryMockRecorder) SyncCalendarImport(ctx, imp, blocks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncCalendarImport", reflect.TypeOf((*MockPropertyRepository)(nil).SyncCalendarImport), ctx, imp, blocks)
+}
+
+// TaxRules mocks base method.
+func (m *MockPropertyRepository) TaxRules(ctx context.Context) ([]reserv.TaxRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TaxRules", ctx)
+	ret0, _ := ret[0].([]reserv.TaxRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaxRules indicates an expected call of TaxRules.
+func (mr *MockPropertyRepositoryMockRecorder) TaxRules(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaxRules", reflect.TypeOf((*MockPropertyRepository)(nil).TaxRules), ctx)
+}
+
+// UpdateCalendarBlock mocks base method.
+func (m *MockPropertyRepository) UpdateCalendarBlock(ctx context.Context, block reserv.CalendarBlock) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCalendarBlock", ctx, block)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCalendarBlock indicates an expected call of UpdateCalendarBlock.
+func (mr *MockPropertyRepositoryMockRecorder) UpdateCalendarBlock(ctx, block any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCalendarBlock", reflect.TypeOf((*MockPropertyRepository)(nil).UpdateCalendarBlock), ctx, block)
+}
+
+// UpdatePricingRule mocks base method.
+func (m *MockPropertyRepository) UpdatePricingRule(ctx context.Context, rule reserv.PricingRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePricingRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePricingRule indicates an expected call of UpdatePricingRule.
+func (mr *MockPropertyRepositoryMockRecorder) UpdatePricingRule(ctx, rule any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePricingRule", reflect.TypeOf((*MockPropertyRepository)(nil).UpdatePricingRule), ctx, rule)
+}
+
 // UpdateProperty mocks base method.
 func (m *MockPropertyRepository) UpdateProperty(ctx context.Context, property reserv.Property, id string) error {
 	m.ctrl.T.Helper()
@@ -187,3 +553,17 @@ func (mr *MockPropertyRepositoryMockRecorder) UpdateProperty(ctx, property, id a
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProperty", reflect.TypeOf((*MockPropertyRepository)(nil).UpdateProperty), ctx, property, id)
 }
+
+// UpdateTaxRule mocks base method.
+func (m *MockPropertyRepository) UpdateTaxRule(ctx context.Context, rule reserv.TaxRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaxRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaxRule indicates an expected call of UpdateTaxRule.
+func (mr *MockPropertyRepositoryMockRecorder) UpdateTaxRule(ctx, rule any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaxRule", reflect.TypeOf((*MockPropertyRepository)(nil).UpdateTaxRule), ctx, rule)
+}
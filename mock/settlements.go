@@ -0,0 +1,191 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: settlements.go
+//
+// Generated by this command:
+//
+//	mockgen -source settlements.go -destination ../mock/settlements.go -package mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	reserv "github.com/perebaj/reserv"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPaymentUpdater is a mock of PaymentUpdater interface.
+type MockPaymentUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentUpdaterMockRecorder
+}
+
+// MockPaymentUpdaterMockRecorder is the mock recorder for MockPaymentUpdater.
+type MockPaymentUpdaterMockRecorder struct {
+	mock *MockPaymentUpdater
+}
+
+// NewMockPaymentUpdater creates a new mock instance.
+func NewMockPaymentUpdater(ctrl *gomock.Controller) *MockPaymentUpdater {
+	mock := &MockPaymentUpdater{ctrl: ctrl}
+	mock.recorder = &MockPaymentUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentUpdater) EXPECT() *MockPaymentUpdaterMockRecorder {
+	return m.recorder
+}
+
+// UpdatePayment mocks base method.
+func (m *MockPaymentUpdater) UpdatePayment(ctx context.Context, payment reserv.Payment, from reserv.PaymentStatus, entry *reserv.JournalEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePayment", ctx, payment, from, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePayment indicates an expected call of UpdatePayment.
+func (mr *MockPaymentUpdaterMockRecorder) UpdatePayment(ctx, payment, from, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePayment", reflect.TypeOf((*MockPaymentUpdater)(nil).UpdatePayment), ctx, payment, from, entry)
+}
+
+// MockSettlementRepository is a mock of SettlementRepository interface.
+type MockSettlementRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSettlementRepositoryMockRecorder
+}
+
+// MockSettlementRepositoryMockRecorder is the mock recorder for MockSettlementRepository.
+type MockSettlementRepositoryMockRecorder struct {
+	mock *MockSettlementRepository
+}
+
+// NewMockSettlementRepository creates a new mock instance.
+func NewMockSettlementRepository(ctrl *gomock.Controller) *MockSettlementRepository {
+	mock := &MockSettlementRepository{ctrl: ctrl}
+	mock.recorder = &MockSettlementRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSettlementRepository) EXPECT() *MockSettlementRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetBooking mocks base method.
+func (m *MockSettlementRepository) GetBooking(ctx context.Context, id string) (int, reserv.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBooking", ctx, id)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(reserv.Booking)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBooking indicates an expected call of GetBooking.
+func (mr *MockSettlementRepositoryMockRecorder) GetBooking(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBooking", reflect.TypeOf((*MockSettlementRepository)(nil).GetBooking), ctx, id)
+}
+
+// GetProperty mocks base method.
+func (m *MockSettlementRepository) GetProperty(ctx context.Context, id string) (int, reserv.Property, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProperty", ctx, id)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(reserv.Property)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetProperty indicates an expected call of GetProperty.
+func (mr *MockSettlementRepositoryMockRecorder) GetProperty(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProperty", reflect.TypeOf((*MockSettlementRepository)(nil).GetProperty), ctx, id)
+}
+
+// UnsettledPayments mocks base method.
+func (m *MockSettlementRepository) UnsettledPayments(ctx context.Context, updatedBefore time.Time) ([]reserv.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsettledPayments", ctx, updatedBefore)
+	ret0, _ := ret[0].([]reserv.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnsettledPayments indicates an expected call of UnsettledPayments.
+func (mr *MockSettlementRepositoryMockRecorder) UnsettledPayments(ctx, updatedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsettledPayments", reflect.TypeOf((*MockSettlementRepository)(nil).UnsettledPayments), ctx, updatedBefore)
+}
+
+// UpdatePayment mocks base method.
+func (m *MockSettlementRepository) UpdatePayment(ctx context.Context, payment reserv.Payment, from reserv.PaymentStatus, entry *reserv.JournalEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePayment", ctx, payment, from, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePayment indicates an expected call of UpdatePayment.
+func (mr *MockSettlementRepositoryMockRecorder) UpdatePayment(ctx, payment, from, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePayment", reflect.TypeOf((*MockSettlementRepository)(nil).UpdatePayment), ctx, payment, from, entry)
+}
+
+// MockSettlementProvider is a mock of SettlementProvider interface.
+type MockSettlementProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockSettlementProviderMockRecorder
+}
+
+// MockSettlementProviderMockRecorder is the mock recorder for MockSettlementProvider.
+type MockSettlementProviderMockRecorder struct {
+	mock *MockSettlementProvider
+}
+
+// NewMockSettlementProvider creates a new mock instance.
+func NewMockSettlementProvider(ctrl *gomock.Controller) *MockSettlementProvider {
+	mock := &MockSettlementProvider{ctrl: ctrl}
+	mock.recorder = &MockSettlementProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSettlementProvider) EXPECT() *MockSettlementProviderMockRecorder {
+	return m.recorder
+}
+
+// Capture mocks base method.
+func (m *MockSettlementProvider) Capture(ctx context.Context, paymentID string, amountCents int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, paymentID, amountCents)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockSettlementProviderMockRecorder) Capture(ctx, paymentID, amountCents any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockSettlementProvider)(nil).Capture), ctx, paymentID, amountCents)
+}
+
+// Refund mocks base method.
+func (m *MockSettlementProvider) Refund(ctx context.Context, paymentID string, amountCents int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", ctx, paymentID, amountCents)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refund indicates an expected call of Refund.
+func (mr *MockSettlementProviderMockRecorder) Refund(ctx, paymentID, amountCents any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockSettlementProvider)(nil).Refund), ctx, paymentID, amountCents)
+}
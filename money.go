@@ -0,0 +1,153 @@
+package reserv
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrInvalidCurrency is returned when a currency is not an active ISO 4217 code, like "usd" or "dollar".
+var ErrInvalidCurrency = errors.New("invalid currency")
+
+// ErrCurrencyMismatch is returned when amounts in different currencies are combined.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// ErrAmountOverflow is returned when an amount doesn't fit in an int64 of minor units.
+var ErrAmountOverflow = errors.New("amount overflow")
+
+// currencyMinorUnits maps the active ISO 4217 currency codes to the number of digits of their minor unit. Example: USD
+// has 2 (cents), JPY has 0 and KWD has 3. Precious metals and testing codes are left out, since they can't price a stay.
+var currencyMinorUnits = map[string]int{
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "ANG": 2, "AOA": 2, "ARS": 2, "AUD": 2, "AWG": 2, "AZN": 2,
+	"BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2, "BHD": 3, "BIF": 0, "BMD": 2, "BND": 2, "BOB": 2, "BOV": 2,
+	"BRL": 2, "BSD": 2, "BTN": 2, "BWP": 2, "BYN": 2, "BZD": 2, "CAD": 2, "CDF": 2, "CHE": 2, "CHF": 2,
+	"CHW": 2, "CLF": 4, "CLP": 0, "CNY": 2, "COP": 2, "COU": 2, "CRC": 2, "CUP": 2, "CVE": 2, "CZK": 2,
+	"DJF": 0, "DKK": 2, "DOP": 2, "DZD": 2, "EGP": 2, "ERN": 2, "ETB": 2, "EUR": 2, "FJD": 2, "FKP": 2,
+	"GBP": 2, "GEL": 2, "GHS": 2, "GIP": 2, "GMD": 2, "GNF": 0, "GTQ": 2, "GYD": 2, "HKD": 2, "HNL": 2,
+	"HTG": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "IQD": 3, "IRR": 2, "ISK": 0, "JMD": 2, "JOD": 3,
+	"JPY": 0, "KES": 2, "KGS": 2, "KHR": 2, "KMF": 0, "KPW": 2, "KRW": 0, "KWD": 3, "KYD": 2, "KZT": 2,
+	"LAK": 2, "LBP": 2, "LKR": 2, "LRD": 2, "LSL": 2, "LYD": 3, "MAD": 2, "MDL": 2, "MGA": 2, "MKD": 2,
+	"MMK": 2, "MNT": 2, "MOP": 2, "MRU": 2, "MUR": 2, "MVR": 2, "MWK": 2, "MXN": 2, "MXV": 2, "MYR": 2,
+	"MZN": 2, "NAD": 2, "NGN": 2, "NIO": 2, "NOK": 2, "NPR": 2, "NZD": 2, "OMR": 3, "PAB": 2, "PEN": 2,
+	"PGK": 2, "PHP": 2, "PKR": 2, "PLN": 2, "PYG": 0, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2, "RWF": 0,
+	"SAR": 2, "SBD": 2, "SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2, "SHP": 2, "SLE": 2, "SOS": 2, "SRD": 2,
+	"SSP": 2, "STN": 2, "SVC": 2, "SYP": 2, "SZL": 2, "THB": 2, "TJS": 2, "TMT": 2, "TND": 3, "TOP": 2,
+	"TRY": 2, "TTD": 2, "TWD": 2, "TZS": 2, "UAH": 2, "UGX": 0, "USD": 2, "USN": 2, "UYI": 0, "UYU": 2,
+	"UYW": 4, "UZS": 2, "VED": 2, "VES": 2, "VND": 0, "VUV": 0, "WST": 2, "XAF": 0, "XCD": 2, "XOF": 0,
+	"XPF": 0, "YER": 2, "ZAR": 2, "ZMW": 2, "ZWG": 2,
+}
+
+// ValidateCurrency checks that code is an active ISO 4217 currency code, in upper case. The returned error wraps
+// ErrInvalidCurrency.
+func ValidateCurrency(code string) error {
+	if _, ok := currencyMinorUnits[code]; ok {
+		return nil
+	}
+	if _, ok := currencyMinorUnits[strings.ToUpper(code)]; ok {
+		return fmt.Errorf("%w: %q must be upper case, like %q", ErrInvalidCurrency, code, strings.ToUpper(code))
+	}
+	return fmt.Errorf("%w: %q is not an ISO 4217 currency code, like \"USD\" or \"BRL\"", ErrInvalidCurrency, code)
+}
+
+// MinorUnits returns the number of digits of the minor unit of a currency. Example: 2 for USD and 0 for JPY. The
+// currency must be valid, see ValidateCurrency.
+func MinorUnits(currency string) int {
+	return currencyMinorUnits[currency]
+}
+
+// Money is an amount in the minor unit of its currency. Example: {1050, "USD"} is 10.50 dollars and {1050, "JPY"} is
+// 1050 yen. The amounts stored with a _cents suffix, like Property.PricePerNightCents, are minor units as well.
+// The arithmetic methods never mix currencies and fail instead of overflowing.
+type Money struct {
+	// Amount is the amount in the minor unit of the currency.
+	Amount int64 `json:"amount"`
+	// Currency is the ISO 4217 code of the currency. Example: "USD".
+	Currency string `json:"currency"`
+}
+
+// NewMoney returns an amount in the minor unit of currency. It returns an error wrapping ErrInvalidCurrency if the
+// currency is not valid.
+func NewMoney(amount int64, currency string) (Money, error) {
+	if err := ValidateCurrency(currency); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// IsZero reports whether the amount is zero, whatever the currency.
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Add returns m + other. Both must have the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: can't add %s to %s", ErrCurrencyMismatch, other.Currency, m.Currency)
+	}
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) || (other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrAmountOverflow, m, other)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m - other. Both must have the same currency.
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrAmountOverflow, m, other)
+	}
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// Mul returns m multiplied by n.
+func (m Money) Mul(n int64) (Money, error) {
+	if m.Amount == 0 || n == 0 {
+		return Money{Currency: m.Currency}, nil
+	}
+	product := m.Amount * n
+	if product/n != m.Amount || (m.Amount == -1 && n == math.MinInt64) || (n == -1 && m.Amount == math.MinInt64) {
+		return Money{}, fmt.Errorf("%w: %s x %d", ErrAmountOverflow, m, n)
+	}
+	return Money{Amount: product, Currency: m.Currency}, nil
+}
+
+// Percent returns percent% of m, rounded toward zero to the minor unit, so a share of a price is never more than the
+// exact value. It can't overflow when percent is between -100 and 100.
+func (m Money) Percent(percent int) (Money, error) {
+	// Splitting the amount in hundreds and remainder keeps the intermediate values as small as the result.
+	hundreds, err := Money{Amount: m.Amount / 100, Currency: m.Currency}.Mul(int64(percent))
+	if err != nil {
+		return Money{}, err
+	}
+	return hundreds.Add(Money{Amount: m.Amount % 100 * int64(percent) / 100, Currency: m.Currency})
+}
+
+// BasisPoints returns bps hundredths of a percent of m, like 1475 for 14.75%, rounded toward zero to the minor unit.
+// It can't overflow when bps is between -10000 and 10000.
+func (m Money) BasisPoints(bps int) (Money, error) {
+	tenThousands, err := Money{Amount: m.Amount / 10000, Currency: m.Currency}.Mul(int64(bps))
+	if err != nil {
+		return Money{}, err
+	}
+	return tenThousands.Add(Money{Amount: m.Amount % 10000 * int64(bps) / 10000, Currency: m.Currency})
+}
+
+// String formats the amount in the major unit of the currency. Example: "USD 10.50", "JPY 1050".
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+// Decimal formats the amount in the major unit of the currency, without the currency. Example: "10.50", "1050".
+func (m Money) Decimal() string {
+	digits := MinorUnits(m.Currency)
+	sign, amount := "", uint64(m.Amount)
+	if m.Amount < 0 {
+		sign, amount = "-", uint64(-(m.Amount+1))+1
+	}
+	if digits == 0 {
+		return fmt.Sprintf("%s%d", sign, amount)
+	}
+
+	scale := uint64(math.Pow10(digits))
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, digits, amount%scale)
+}
@@ -0,0 +1,123 @@
+package reserv
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCurrency(t *testing.T) {
+	for _, code := range []string{"USD", "BRL", "EUR", "JPY", "KWD"} {
+		require.NoError(t, ValidateCurrency(code), code)
+	}
+
+	for _, code := range []string{"", "usd", "Usd", "dollar", "US", "USDD", "XXX", "XAU"} {
+		require.ErrorIs(t, ValidateCurrency(code), ErrInvalidCurrency, code)
+	}
+}
+
+func TestNewMoney(t *testing.T) {
+	m, err := NewMoney(1050, "USD")
+	require.NoError(t, err)
+	require.Equal(t, Money{Amount: 1050, Currency: "USD"}, m)
+
+	_, err = NewMoney(1050, "usd")
+	require.ErrorIs(t, err, ErrInvalidCurrency)
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	usd := func(amount int64) Money { return Money{Amount: amount, Currency: "USD"} }
+
+	sum, err := usd(1050).Add(usd(250))
+	require.NoError(t, err)
+	require.Equal(t, usd(1300), sum)
+
+	diff, err := usd(1050).Sub(usd(2000))
+	require.NoError(t, err)
+	require.Equal(t, usd(-950), diff)
+
+	product, err := usd(1050).Mul(3)
+	require.NoError(t, err)
+	require.Equal(t, usd(3150), product)
+
+	_, err = usd(1050).Add(Money{Amount: 250, Currency: "BRL"})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = usd(math.MaxInt64).Add(usd(1))
+	require.ErrorIs(t, err, ErrAmountOverflow)
+
+	_, err = usd(math.MinInt64).Sub(usd(1))
+	require.ErrorIs(t, err, ErrAmountOverflow)
+
+	_, err = usd(0).Sub(usd(math.MinInt64))
+	require.ErrorIs(t, err, ErrAmountOverflow)
+
+	_, err = usd(math.MaxInt64 / 2).Mul(3)
+	require.ErrorIs(t, err, ErrAmountOverflow)
+
+	_, err = usd(-1).Mul(math.MinInt64)
+	require.ErrorIs(t, err, ErrAmountOverflow)
+}
+
+func TestMoney_Percent(t *testing.T) {
+	tests := []struct {
+		amount  int64
+		percent int
+		want    int64
+	}{
+		{amount: 30001, percent: 50, want: 15000},
+		{amount: 30001, percent: 100, want: 30001},
+		{amount: 99, percent: 33, want: 32},
+		{amount: -99, percent: 33, want: -32},
+		{amount: math.MaxInt64, percent: 100, want: math.MaxInt64},
+		{amount: math.MaxInt64, percent: 50, want: math.MaxInt64 / 2},
+	}
+
+	for _, tt := range tests {
+		got, err := Money{Amount: tt.amount, Currency: "USD"}.Percent(tt.percent)
+		require.NoError(t, err)
+		require.Equal(t, Money{Amount: tt.want, Currency: "USD"}, got, "%d%% of %d", tt.percent, tt.amount)
+	}
+}
+
+func TestMoney_BasisPoints(t *testing.T) {
+	tests := []struct {
+		amount int64
+		bps    int
+		want   int64
+	}{
+		{amount: 30000, bps: 1475, want: 4425},
+		{amount: 30001, bps: 10000, want: 30001},
+		{amount: 999, bps: 575, want: 57},
+		{amount: -999, bps: 575, want: -57},
+		{amount: math.MaxInt64, bps: 10000, want: math.MaxInt64},
+		{amount: math.MaxInt64, bps: 5000, want: math.MaxInt64 / 2},
+	}
+
+	for _, tt := range tests {
+		got, err := Money{Amount: tt.amount, Currency: "USD"}.BasisPoints(tt.bps)
+		require.NoError(t, err)
+		require.Equal(t, Money{Amount: tt.want, Currency: "USD"}, got, "%d bps of %d", tt.bps, tt.amount)
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{Money{Amount: 1050, Currency: "USD"}, "USD 10.50"},
+		{Money{Amount: 5, Currency: "BRL"}, "BRL 0.05"},
+		{Money{Amount: -1050, Currency: "EUR"}, "EUR -10.50"},
+		{Money{Amount: 1050, Currency: "JPY"}, "JPY 1050"},
+		{Money{Amount: 1050, Currency: "KWD"}, "KWD 1.050"},
+		{Money{Amount: math.MinInt64, Currency: "USD"}, "USD -92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, tt.money.String())
+	}
+
+	require.Equal(t, "-10.50", Money{Amount: -1050, Currency: "USD"}.Decimal())
+}
@@ -0,0 +1,89 @@
+package reserv
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidCursor is returned when the cursor of a page wasn't returned by a previous page.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const (
+	// DefaultPageLimit is how many items a page has when the limit is not set.
+	DefaultPageLimit = 20
+	// MaxPageLimit is the largest limit of a page.
+	MaxPageLimit = 100
+)
+
+// Cursor is the position of the last item of a page, in lists ordered by a time and then by id, newest first. The
+// next page starts right after it, so items added meanwhile don't shift the pages.
+type Cursor struct {
+	Time time.Time `json:"t"`
+	ID   string    `json:"id"`
+}
+
+// String encodes the cursor to be sent to the clients. It is opaque to them.
+func (c Cursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor decodes a cursor encoded by Cursor.String. The returned error wraps ErrInvalidCursor.
+func ParseCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	if c.Time.IsZero() || c.ID == "" {
+		return Cursor{}, fmt.Errorf("%w: missing position", ErrInvalidCursor)
+	}
+	return c, nil
+}
+
+// Page is the request of a page of a list.
+type Page struct {
+	// Limit is how many items the page has, between 1 and MaxPageLimit.
+	Limit int
+	// After is the cursor of the last item of the previous page. It is nil for the first page.
+	After *Cursor
+}
+
+// NewPage validates the limit and cursor sent by the clients, both optional, and returns the page they request.
+// An empty limit is DefaultPageLimit.
+func NewPage(limit int, cursor string) (Page, error) {
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	if limit < 1 || limit > MaxPageLimit {
+		return Page{}, fmt.Errorf("limit must be between 1 and %d", MaxPageLimit)
+	}
+
+	page := Page{Limit: limit}
+	if cursor != "" {
+		after, err := ParseCursor(cursor)
+		if err != nil {
+			return Page{}, err
+		}
+		page.After = &after
+	}
+	return page, nil
+}
+
+// NextPage trims the items fetched for page, which the repositories fetch one more than the limit of, to the limit.
+// It returns the cursor of the next page, or an empty cursor when there are no more items.
+func NextPage[T any](page Page, items []T, cursor func(T) Cursor) ([]T, string) {
+	if len(items) <= page.Limit {
+		return items, ""
+	}
+	items = items[:page.Limit]
+	return items, cursor(items[len(items)-1]).String()
+}
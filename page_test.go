@@ -0,0 +1,58 @@
+package reserv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := Cursor{Time: time.Date(2025, 5, 1, 10, 0, 0, 123456000, time.UTC), ID: "9b1c4d5e-0000-4000-8000-000000000000"}
+
+	got, err := ParseCursor(cursor.String())
+	require.NoError(t, err)
+	require.True(t, cursor.Time.Equal(got.Time))
+	require.Equal(t, cursor.ID, got.ID)
+
+	for _, s := range []string{"not base64!", "bm90IGpzb24", Cursor{ID: "id"}.String(), Cursor{Time: cursor.Time}.String()} {
+		_, err := ParseCursor(s)
+		require.ErrorIs(t, err, ErrInvalidCursor, s)
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	page, err := NewPage(0, "")
+	require.NoError(t, err)
+	require.Equal(t, Page{Limit: DefaultPageLimit}, page)
+
+	cursor := Cursor{Time: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), ID: "id"}
+	page, err = NewPage(MaxPageLimit, cursor.String())
+	require.NoError(t, err)
+	require.Equal(t, MaxPageLimit, page.Limit)
+	require.Equal(t, cursor.ID, page.After.ID)
+
+	_, err = NewPage(-1, "")
+	require.Error(t, err)
+	_, err = NewPage(MaxPageLimit+1, "")
+	require.Error(t, err)
+	_, err = NewPage(10, "invalid")
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestNextPage(t *testing.T) {
+	now := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	messages := []Message{{ID: "3", CreatedAt: now}, {ID: "2", CreatedAt: now.Add(-time.Minute)}, {ID: "1", CreatedAt: now.Add(-2 * time.Minute)}}
+
+	// The repositories fetch one more than the limit, so a full page has a next page.
+	items, next := NextPage(Page{Limit: 2}, messages, Message.Cursor)
+	require.Len(t, items, 2)
+	cursor, err := ParseCursor(next)
+	require.NoError(t, err)
+	require.Equal(t, "2", cursor.ID)
+	require.True(t, now.Add(-time.Minute).Equal(cursor.Time))
+
+	items, next = NextPage(Page{Limit: 3}, messages, Message.Cursor)
+	require.Len(t, items, 3)
+	require.Empty(t, next)
+}
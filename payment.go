@@ -0,0 +1,127 @@
+package reserv
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrPaymentNotFound is returned when a booking has no payment or a provider payment is unknown.
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// ErrPaymentExists is returned when a booking already has a payment in progress or captured.
+var ErrPaymentExists = errors.New("booking already has a payment")
+
+// ErrInvalidPaymentTransition is returned when a payment can't move from its current status to the requested one.
+var ErrInvalidPaymentTransition = errors.New("invalid payment status transition")
+
+// ErrPaymentAmountMismatch is returned when the amount of a payment differs from the total price of its booking.
+var ErrPaymentAmountMismatch = errors.New("payment amount doesn't match the booking price")
+
+// ErrInvalidWebhookSignature is returned when the signature of a webhook of the payment provider doesn't match its payload.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// PaymentStatus is the status of a payment in its lifecycle.
+type PaymentStatus string
+
+const (
+	// PaymentStatusPending is a payment intent waiting for the guest to pay.
+	PaymentStatusPending PaymentStatus = "pending"
+	// PaymentStatusAuthorized is a payment whose amount is reserved on the guest card, but not charged yet.
+	PaymentStatusAuthorized PaymentStatus = "authorized"
+	// PaymentStatusCaptured is a payment charged from the guest.
+	PaymentStatusCaptured PaymentStatus = "captured"
+	// PaymentStatusRefunded is a captured payment that was refunded, fully or partially. See Payment.RefundedCents.
+	PaymentStatusRefunded PaymentStatus = "refunded"
+	// PaymentStatusVoided is an authorized payment released without charging the guest.
+	PaymentStatusVoided PaymentStatus = "voided"
+	// PaymentStatusFailed is a payment that the provider couldn't authorize.
+	PaymentStatusFailed PaymentStatus = "failed"
+)
+
+// paymentTransitions maps each payment status to the statuses it can move to. Statuses without entries are final.
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending: {
+		PaymentStatusAuthorized,
+		PaymentStatusFailed,
+	},
+	PaymentStatusAuthorized: {
+		PaymentStatusCaptured,
+		PaymentStatusVoided,
+	},
+	PaymentStatusCaptured: {
+		PaymentStatusRefunded,
+	},
+}
+
+// CanTransitionTo reports whether a payment with the status s can move to the status to.
+func (s PaymentStatus) CanTransitionTo(to PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive reports whether a payment with the status s is in progress or charged. A booking has a single active payment,
+// so a new one can only be created once the previous one failed or was voided.
+func (s PaymentStatus) IsActive() bool {
+	switch s {
+	case PaymentStatusPending, PaymentStatusAuthorized, PaymentStatusCaptured, PaymentStatusRefunded:
+		return true
+	}
+	return false
+}
+
+// Payment is the charge of a booking in a payment provider.
+type Payment struct {
+	ID        string `json:"id" db:"id"`
+	BookingID string `json:"booking_id" db:"booking_id"`
+	// Provider is the name of the payment provider and ProviderPaymentID is the id of the payment there.
+	Provider          string        `json:"provider" db:"provider"`
+	ProviderPaymentID string        `json:"provider_payment_id" db:"provider_payment_id"`
+	Status            PaymentStatus `json:"status" db:"status"`
+	// AmountCents is the amount charged from the guest and RefundedCents is the part of it given back.
+	AmountCents   int64     `json:"amount_cents" db:"amount_cents"`
+	RefundedCents int64     `json:"refunded_cents" db:"refunded_cents"`
+	Currency      string    `json:"currency" db:"currency"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PaymentIntentRequest is what a payment provider needs to start charging a booking.
+type PaymentIntentRequest struct {
+	BookingID   string
+	AmountCents int64
+	Currency    string
+}
+
+// PaymentIntent is a payment started in a payment provider. The guest completes it with the ClientSecret.
+type PaymentIntent struct {
+	// Provider is the name of the payment provider that created the intent.
+	Provider string
+	// ID is the id of the payment in the provider.
+	ID           string
+	ClientSecret string
+}
+
+// PaymentEventType is the kind of the events sent by the payment providers through webhooks.
+type PaymentEventType string
+
+const (
+	// PaymentEventAuthorized is sent when the guest completes the payment and the amount is reserved.
+	PaymentEventAuthorized PaymentEventType = "payment.authorized"
+	// PaymentEventFailed is sent when the payment of the guest is declined.
+	PaymentEventFailed PaymentEventType = "payment.failed"
+)
+
+// PaymentEvent is a verified webhook event of a payment provider.
+type PaymentEvent struct {
+	ID   string           `json:"id"`
+	Type PaymentEventType `json:"type"`
+	// Provider is the name of the payment provider that sent the event. It is set by the provider when verifying the event.
+	Provider string `json:"-"`
+	// PaymentID is the id of the payment in the provider.
+	PaymentID string    `json:"payment_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package reserv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from PaymentStatus
+		to   PaymentStatus
+		want bool
+	}{
+		{PaymentStatusPending, PaymentStatusAuthorized, true},
+		{PaymentStatusPending, PaymentStatusFailed, true},
+		{PaymentStatusPending, PaymentStatusCaptured, false},
+		{PaymentStatusAuthorized, PaymentStatusCaptured, true},
+		{PaymentStatusAuthorized, PaymentStatusVoided, true},
+		{PaymentStatusAuthorized, PaymentStatusRefunded, false},
+		{PaymentStatusCaptured, PaymentStatusRefunded, true},
+		{PaymentStatusCaptured, PaymentStatusVoided, false},
+		{PaymentStatusRefunded, PaymentStatusRefunded, false},
+		{PaymentStatusFailed, PaymentStatusAuthorized, false},
+		{PaymentStatusVoided, PaymentStatusCaptured, false},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to), "%s -> %s", tt.from, tt.to)
+	}
+}
+
+func TestPaymentStatus_IsActive(t *testing.T) {
+	for _, s := range []PaymentStatus{PaymentStatusPending, PaymentStatusAuthorized, PaymentStatusCaptured, PaymentStatusRefunded} {
+		require.True(t, s.IsActive(), s)
+	}
+	for _, s := range []PaymentStatus{PaymentStatusVoided, PaymentStatusFailed} {
+		require.False(t, s.IsActive(), s)
+	}
+}
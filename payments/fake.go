@@ -0,0 +1,259 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// FakeProviderName is the name of the fake payment provider.
+const FakeProviderName = "fake"
+
+// ErrFakePaymentNotFound is returned by the fake provider for unknown payments.
+var ErrFakePaymentNotFound = errors.New("fake payment not found")
+
+// fakePayment is the state of a payment in the fake provider.
+type fakePayment struct {
+	amountCents   int64
+	currency      string
+	status        reserv.PaymentStatus
+	refundedCents int64
+}
+
+// fakePayout is a payout transferred by the fake provider.
+type fakePayout struct {
+	id          string
+	hostID      string
+	amountCents int64
+	currency    string
+}
+
+// Fake is an in-process payment provider. It keeps the payments in memory and sends signed webhooks like a real
+// provider, so the whole payment flow can run offline, in tests and in local development.
+// The guest side of the flow is simulated with Authorize and Decline. It transfers the payouts of the hosts as well,
+// keeping them in memory.
+type Fake struct {
+	secret []byte
+	// WebhookURL is where the webhooks are sent. When empty, the webhooks are not sent.
+	WebhookURL string
+	// Client is the client used to send the webhooks.
+	Client *http.Client
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+	payouts  map[string]*fakePayout
+}
+
+// NewFake creates a fake provider that signs its webhooks with secret.
+func NewFake(secret string) *Fake {
+	return &Fake{
+		secret:   []byte(secret),
+		Client:   &http.Client{Timeout: 10 * time.Second},
+		payments: make(map[string]*fakePayment),
+		payouts:  make(map[string]*fakePayout),
+	}
+}
+
+// CreateIntent starts a payment waiting for the guest.
+func (f *Fake) CreateIntent(_ context.Context, req reserv.PaymentIntentRequest) (reserv.PaymentIntent, error) {
+	if req.AmountCents <= 0 {
+		return reserv.PaymentIntent{}, fmt.Errorf("invalid amount %d", req.AmountCents)
+	}
+
+	id := "fake_pi_" + randomHex(8)
+	f.mu.Lock()
+	f.payments[id] = &fakePayment{amountCents: req.AmountCents, currency: req.Currency, status: reserv.PaymentStatusPending}
+	f.mu.Unlock()
+
+	return reserv.PaymentIntent{Provider: FakeProviderName, ID: id, ClientSecret: id + "_secret_" + randomHex(8)}, nil
+}
+
+// Capture charges an authorized payment. Capturing it again with the same amount succeeds without charging it twice.
+func (f *Fake) Capture(_ context.Context, paymentID string, amountCents int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[paymentID]
+	if !ok {
+		return ErrFakePaymentNotFound
+	}
+
+	if p.status != reserv.PaymentStatusAuthorized && p.status != reserv.PaymentStatusCaptured {
+		return fmt.Errorf("%w: can't capture a %s payment", reserv.ErrInvalidPaymentTransition, p.status)
+	}
+
+	if amountCents != p.amountCents {
+		return fmt.Errorf("capture amount %d doesn't match the authorized amount %d", amountCents, p.amountCents)
+	}
+
+	p.status = reserv.PaymentStatusCaptured
+	return nil
+}
+
+// Refund gives amountCents of a captured payment back to the guest. Authorized payments are voided instead, releasing
+// the whole amount, and voiding them again succeeds.
+func (f *Fake) Refund(_ context.Context, paymentID string, amountCents int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[paymentID]
+	if !ok {
+		return ErrFakePaymentNotFound
+	}
+
+	switch p.status {
+	case reserv.PaymentStatusAuthorized, reserv.PaymentStatusVoided:
+		p.status = reserv.PaymentStatusVoided
+		return nil
+	case reserv.PaymentStatusCaptured:
+		if amountCents <= 0 || amountCents > p.amountCents {
+			return fmt.Errorf("invalid refund amount %d for a payment of %d", amountCents, p.amountCents)
+		}
+		p.status = reserv.PaymentStatusRefunded
+		p.refundedCents = amountCents
+		return nil
+	}
+	return fmt.Errorf("%w: can't refund a %s payment", reserv.ErrInvalidPaymentTransition, p.status)
+}
+
+// VerifyWebhook checks the signature of a webhook sent by the fake provider and decodes its event.
+func (f *Fake) VerifyWebhook(payload []byte, signature string) (reserv.PaymentEvent, error) {
+	if err := Verify(f.secret, payload, signature, time.Now()); err != nil {
+		return reserv.PaymentEvent{}, err
+	}
+
+	var event reserv.PaymentEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return reserv.PaymentEvent{}, fmt.Errorf("failed to decode webhook event: %v", err)
+	}
+	event.Provider = FakeProviderName
+	return event, nil
+}
+
+// Authorize simulates the guest completing the payment. The payment is authorized and a payment.authorized webhook is sent.
+func (f *Fake) Authorize(ctx context.Context, paymentID string) error {
+	return f.complete(ctx, paymentID, reserv.PaymentStatusAuthorized, reserv.PaymentEventAuthorized)
+}
+
+// Decline simulates the card of the guest being declined. The payment fails and a payment.failed webhook is sent.
+func (f *Fake) Decline(ctx context.Context, paymentID string) error {
+	return f.complete(ctx, paymentID, reserv.PaymentStatusFailed, reserv.PaymentEventFailed)
+}
+
+// Status returns the status of a payment in the fake provider and the amount refunded.
+func (f *Fake) Status(paymentID string) (reserv.PaymentStatus, int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[paymentID]
+	if !ok {
+		return "", 0, ErrFakePaymentNotFound
+	}
+	return p.status, p.refundedCents, nil
+}
+
+// Payout transfers a payout to its host. A payout sent again returns the transfer of the first time, as long as it
+// has the same host and amount.
+func (f *Fake) Payout(_ context.Context, req reserv.PayoutRequest) (reserv.PayoutTransfer, error) {
+	if req.AmountCents <= 0 {
+		return reserv.PayoutTransfer{}, fmt.Errorf("invalid payout amount %d", req.AmountCents)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if p, ok := f.payouts[req.PayoutID]; ok {
+		if p.hostID != req.HostID || p.amountCents != req.AmountCents || p.currency != req.Currency {
+			return reserv.PayoutTransfer{}, fmt.Errorf("payout %s was already transferred with another host or amount", req.PayoutID)
+		}
+		return reserv.PayoutTransfer{Provider: FakeProviderName, ID: p.id}, nil
+	}
+
+	p := &fakePayout{id: "fake_po_" + randomHex(8), hostID: req.HostID, amountCents: req.AmountCents, currency: req.Currency}
+	f.payouts[req.PayoutID] = p
+	return reserv.PayoutTransfer{Provider: FakeProviderName, ID: p.id}, nil
+}
+
+// PaidOut returns the sum of the payouts transferred to a host in currency.
+func (f *Fake) PaidOut(hostID, currency string) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var total int64
+	for _, p := range f.payouts {
+		if p.hostID == hostID && p.currency == currency {
+			total += p.amountCents
+		}
+	}
+	return total
+}
+
+func (f *Fake) complete(ctx context.Context, paymentID string, to reserv.PaymentStatus, eventType reserv.PaymentEventType) error {
+	f.mu.Lock()
+	p, ok := f.payments[paymentID]
+	if !ok {
+		f.mu.Unlock()
+		return ErrFakePaymentNotFound
+	}
+
+	if p.status != reserv.PaymentStatusPending {
+		f.mu.Unlock()
+		return fmt.Errorf("%w: the payment is already %s", reserv.ErrInvalidPaymentTransition, p.status)
+	}
+	p.status = to
+	f.mu.Unlock()
+
+	return f.send(ctx, reserv.PaymentEvent{
+		ID:        "fake_evt_" + randomHex(8),
+		Type:      eventType,
+		PaymentID: paymentID,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// send posts the signed event to the WebhookURL. Like a real provider, any response other than 2xx is an error.
+func (f *Fake) send(ctx context.Context, event reserv.PaymentEvent) error {
+	if f.WebhookURL == "" {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(f.secret, payload, time.Now()))
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
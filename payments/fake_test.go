@@ -0,0 +1,140 @@
+package payments_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/payments"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	fake := payments.NewFake("whsec_test")
+
+	var events []reserv.PaymentEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		event, err := fake.VerifyWebhook(payload, r.Header.Get(payments.SignatureHeader))
+		require.NoError(t, err)
+		events = append(events, event)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	fake.WebhookURL = srv.URL
+
+	intent, err := fake.CreateIntent(ctx, reserv.PaymentIntentRequest{BookingID: "123", AmountCents: 30000, Currency: "USD"})
+	require.NoError(t, err)
+	require.Equal(t, payments.FakeProviderName, intent.Provider)
+	require.NotEmpty(t, intent.ID)
+	require.NotEmpty(t, intent.ClientSecret)
+
+	err = fake.Capture(ctx, intent.ID, 30000)
+	require.True(t, errors.Is(err, reserv.ErrInvalidPaymentTransition), err)
+
+	require.NoError(t, fake.Authorize(ctx, intent.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, reserv.PaymentEventAuthorized, events[0].Type)
+	require.Equal(t, intent.ID, events[0].PaymentID)
+	require.Equal(t, payments.FakeProviderName, events[0].Provider)
+
+	require.Error(t, fake.Authorize(ctx, intent.ID))
+	require.Error(t, fake.Capture(ctx, intent.ID, 100))
+	require.NoError(t, fake.Capture(ctx, intent.ID, 30000))
+	// A retried capture doesn't fail.
+	require.NoError(t, fake.Capture(ctx, intent.ID, 30000))
+
+	require.Error(t, fake.Refund(ctx, intent.ID, 30001))
+	require.NoError(t, fake.Refund(ctx, intent.ID, 15000))
+	status, refunded, err := fake.Status(intent.ID)
+	require.NoError(t, err)
+	require.Equal(t, reserv.PaymentStatusRefunded, status)
+	require.Equal(t, int64(15000), refunded)
+
+	_, _, err = fake.Status("unknown")
+	require.True(t, errors.Is(err, payments.ErrFakePaymentNotFound), err)
+}
+
+func TestFake_Decline(t *testing.T) {
+	ctx := context.Background()
+	fake := payments.NewFake("whsec_test")
+
+	intent, err := fake.CreateIntent(ctx, reserv.PaymentIntentRequest{BookingID: "123", AmountCents: 30000, Currency: "USD"})
+	require.NoError(t, err)
+
+	require.NoError(t, fake.Decline(ctx, intent.ID))
+	status, _, err := fake.Status(intent.ID)
+	require.NoError(t, err)
+	require.Equal(t, reserv.PaymentStatusFailed, status)
+
+	require.Error(t, fake.Refund(ctx, intent.ID, 30000))
+}
+
+func TestFake_Void(t *testing.T) {
+	ctx := context.Background()
+	fake := payments.NewFake("whsec_test")
+
+	intent, err := fake.CreateIntent(ctx, reserv.PaymentIntentRequest{BookingID: "123", AmountCents: 30000, Currency: "USD"})
+	require.NoError(t, err)
+	require.NoError(t, fake.Authorize(ctx, intent.ID))
+
+	require.NoError(t, fake.Refund(ctx, intent.ID, 30000))
+	// A retried void doesn't fail.
+	require.NoError(t, fake.Refund(ctx, intent.ID, 30000))
+	status, refunded, err := fake.Status(intent.ID)
+	require.NoError(t, err)
+	require.Equal(t, reserv.PaymentStatusVoided, status)
+	require.Zero(t, refunded)
+
+	require.Error(t, fake.Capture(ctx, intent.ID, 30000))
+}
+
+func TestFake_WebhookRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	fake := payments.NewFake("whsec_test")
+	fake.WebhookURL = srv.URL
+
+	intent, err := fake.CreateIntent(context.Background(), reserv.PaymentIntentRequest{BookingID: "123", AmountCents: 30000, Currency: "USD"})
+	require.NoError(t, err)
+	require.Error(t, fake.Authorize(context.Background(), intent.ID))
+}
+
+func TestFake_Payout(t *testing.T) {
+	ctx := context.Background()
+	fake := payments.NewFake("whsec_test")
+
+	req := reserv.PayoutRequest{PayoutID: "payout-1", HostID: "host", AmountCents: 27000, Currency: "USD"}
+	transfer, err := fake.Payout(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, payments.FakeProviderName, transfer.Provider)
+	require.NotEmpty(t, transfer.ID)
+
+	// A payout sent again is transferred once.
+	again, err := fake.Payout(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, transfer, again)
+	require.Equal(t, int64(27000), fake.PaidOut("host", "USD"))
+
+	req.AmountCents = 30000
+	_, err = fake.Payout(ctx, req)
+	require.Error(t, err)
+
+	_, err = fake.Payout(ctx, reserv.PayoutRequest{PayoutID: "payout-2", HostID: "host", AmountCents: 0, Currency: "USD"})
+	require.Error(t, err)
+
+	_, err = fake.Payout(ctx, reserv.PayoutRequest{PayoutID: "payout-3", HostID: "host", AmountCents: 5000, Currency: "USD"})
+	require.NoError(t, err)
+	require.Equal(t, int64(32000), fake.PaidOut("host", "USD"))
+	require.Zero(t, fake.PaidOut("host", "BRL"))
+}
@@ -0,0 +1,69 @@
+// Package payments contains the payment providers and the signing of their webhooks.
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// SignatureHeader is the header that carries the signature of the webhooks.
+const SignatureHeader = "Reserv-Signature"
+
+// SignatureTolerance is how old a webhook can be. Older webhooks are rejected, so a captured request can't be replayed later.
+const SignatureTolerance = 5 * time.Minute
+
+// Sign returns the signature of a webhook payload sent at t, in the format t=<unix seconds>,v1=<hex HMAC-SHA256>.
+// The timestamp is part of the signed content, so it can't be changed without breaking the signature.
+func Sign(secret, payload []byte, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac(secret, timestamp, payload))
+}
+
+// Verify checks that signature was produced by Sign with the same secret and payload, and that it is not older than
+// SignatureTolerance at now. It returns an error wrapping reserv.ErrInvalidWebhookSignature otherwise.
+func Verify(secret, payload []byte, signature string, now time.Time) error {
+	var timestamp, v1 string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			v1 = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing timestamp", reserv.ErrInvalidWebhookSignature)
+	}
+
+	got, err := hex.DecodeString(v1)
+	if err != nil || len(got) == 0 {
+		return fmt.Errorf("%w: missing signature", reserv.ErrInvalidWebhookSignature)
+	}
+
+	if !hmac.Equal(got, mac(secret, timestamp, payload)) {
+		return fmt.Errorf("%w: signature mismatch", reserv.ErrInvalidWebhookSignature)
+	}
+
+	if age := now.Sub(time.Unix(unix, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return fmt.Errorf("%w: timestamp outside the tolerance", reserv.ErrInvalidWebhookSignature)
+	}
+	return nil
+}
+
+func mac(secret []byte, timestamp string, payload []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(payload)
+	return h.Sum(nil)
+}
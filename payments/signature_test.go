@@ -0,0 +1,47 @@
+package payments_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/payments"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("whsec_test")
+	payload := []byte(`{"id":"evt_1","type":"payment.authorized"}`)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	signature := payments.Sign(secret, payload, now)
+
+	tests := []struct {
+		name      string
+		secret    []byte
+		payload   []byte
+		signature string
+		now       time.Time
+		wantErr   bool
+	}{
+		{name: "valid", secret: secret, payload: payload, signature: signature, now: now},
+		{name: "within the tolerance", secret: secret, payload: payload, signature: signature, now: now.Add(payments.SignatureTolerance)},
+		{name: "too old", secret: secret, payload: payload, signature: signature, now: now.Add(payments.SignatureTolerance + time.Second), wantErr: true},
+		{name: "wrong secret", secret: []byte("other"), payload: payload, signature: signature, now: now, wantErr: true},
+		{name: "tampered payload", secret: secret, payload: []byte(`{"id":"evt_1","type":"payment.failed"}`), signature: signature, now: now, wantErr: true},
+		{name: "tampered timestamp", secret: secret, payload: payload, signature: "t=1735736401" + signature[len("t=1735732800"):], now: now, wantErr: true},
+		{name: "missing signature", secret: secret, payload: payload, signature: "", now: now, wantErr: true},
+		{name: "malformed signature", secret: secret, payload: payload, signature: "t=abc,v1=zz", now: now, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := payments.Verify(tt.secret, tt.payload, tt.signature, tt.now)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, errors.Is(err, reserv.ErrInvalidWebhookSignature), err)
+		})
+	}
+}
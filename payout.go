@@ -0,0 +1,134 @@
+package reserv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrPayoutNotPending is returned when a payout that was already paid is paid again.
+var ErrPayoutNotPending = errors.New("payout is not pending")
+
+const (
+	// DefaultPayoutDelayDays is how many days after the check-in the hosts are paid by default.
+	DefaultPayoutDelayDays = 1
+	// MaxPayoutDelayDays is the longest the payout of a stay can wait after its check-in.
+	MaxPayoutDelayDays = 60
+)
+
+// PayoutStatus is the status of a payout in its lifecycle.
+type PayoutStatus string
+
+const (
+	// PayoutStatusPending is a payout scheduled but not sent to the payout provider yet. Payouts whose transfer
+	// failed stay pending and are retried.
+	PayoutStatusPending PayoutStatus = "pending"
+	// PayoutStatusPaid is a payout transferred to the host.
+	PayoutStatusPaid PayoutStatus = "paid"
+)
+
+// PayoutItem is the share of a booking in a payout: what the host earned with the stay, less the cancellations.
+type PayoutItem struct {
+	PayoutID   string `json:"payout_id" db:"payout_id"`
+	BookingID  string `json:"booking_id" db:"booking_id"`
+	PropertyID string `json:"property_id" db:"property_id"`
+	// CheckInDate and CheckOutDate are the dates of the stay when it was paid out.
+	CheckInDate  time.Time `json:"check_in_date" db:"check_in_date"`
+	CheckOutDate time.Time `json:"check_out_date" db:"check_out_date"`
+	AmountCents  int64     `json:"amount_cents" db:"amount_cents"`
+}
+
+// Payout is a batch of the earnings of a host, in a currency, transferred at once by the payout provider.
+type Payout struct {
+	ID     string       `json:"id" db:"id"`
+	HostID string       `json:"host_id" db:"host_id"`
+	Status PayoutStatus `json:"status" db:"status"`
+	// AmountCents is the sum of the amounts of the items.
+	AmountCents int64  `json:"amount_cents" db:"amount_cents"`
+	Currency    string `json:"currency" db:"currency"`
+	// Provider is the name of the payout provider and ProviderPayoutID is the id of the transfer there. They are empty
+	// until the payout is paid.
+	Provider         string       `json:"provider" db:"provider"`
+	ProviderPayoutID string       `json:"provider_payout_id" db:"provider_payout_id"`
+	Items            []PayoutItem `json:"items" db:"-"`
+	CreatedAt        time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at" db:"updated_at"`
+	PaidAt           *time.Time   `json:"paid_at" db:"paid_at"`
+}
+
+// PayoutRequest is what a payout provider needs to transfer a payout to a host. The PayoutID identifies the transfer,
+// so a payout sent twice is transferred once.
+type PayoutRequest struct {
+	PayoutID    string
+	HostID      string
+	AmountCents int64
+	Currency    string
+}
+
+// PayoutTransfer is a payout transferred by a payout provider.
+type PayoutTransfer struct {
+	// Provider is the name of the payout provider that made the transfer.
+	Provider string
+	// ID is the id of the transfer in the provider.
+	ID string
+}
+
+// PayoutEntry is the entry of a payout sent to its host: the cash leaves the platform and pays what it owed the host.
+func PayoutEntry(payout Payout, now time.Time) JournalEntry {
+	return JournalEntry{
+		Kind:        JournalEntryPayout,
+		PayoutID:    &payout.ID,
+		Currency:    payout.Currency,
+		Description: "Payout " + payout.ID + " to host " + payout.HostID,
+		Postings: []Posting{
+			{Account: AccountHostPayable, HostID: payout.HostID, AmountCents: payout.AmountCents},
+			{Account: AccountCash, AmountCents: -payout.AmountCents},
+		},
+		CreatedAt: now,
+	}
+}
+
+// StatementLine is a booking paid out to a host, a line of their monthly earnings statement.
+type StatementLine struct {
+	PaidAt time.Time `db:"paid_at"`
+	PayoutItem
+	Currency string `db:"currency"`
+}
+
+// ParseStatementMonth parses the month of a statement, formatted as 2006-01, and returns its first day in UTC.
+func ParseStatementMonth(s string) (time.Time, error) {
+	month, err := time.Parse("2006-01", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month %q, the format is YYYY-MM", s)
+	}
+	return month, nil
+}
+
+// WriteStatementCSV writes the lines of an earnings statement as CSV, with a header. Amounts are formatted in the
+// major unit of their currency, like 123.45.
+func WriteStatementCSV(w io.Writer, lines []StatementLine) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"paid_at", "payout_id", "booking_id", "property_id", "check_in_date", "check_out_date", "currency", "amount"}); err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		if err := cw.Write([]string{
+			line.PaidAt.UTC().Format(time.RFC3339),
+			line.PayoutID,
+			line.BookingID,
+			line.PropertyID,
+			line.CheckInDate.Format(time.DateOnly),
+			line.CheckOutDate.Format(time.DateOnly),
+			line.Currency,
+			Money{Amount: line.AmountCents, Currency: line.Currency}.Decimal(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
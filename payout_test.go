@@ -0,0 +1,74 @@
+package reserv
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayoutEntry(t *testing.T) {
+	now := time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC)
+	entry := PayoutEntry(Payout{ID: "payout", HostID: "host", AmountCents: 27000, Currency: "USD"}, now)
+	require.NoError(t, entry.Validate())
+	require.Equal(t, JournalEntryPayout, entry.Kind)
+	require.Equal(t, "payout", *entry.PayoutID)
+	require.Nil(t, entry.BookingID)
+	require.Equal(t, []Posting{
+		{Account: AccountHostPayable, HostID: "host", AmountCents: 27000},
+		{Account: AccountCash, AmountCents: -27000},
+	}, entry.Postings)
+}
+
+func TestParseStatementMonth(t *testing.T) {
+	month, err := ParseStatementMonth("2025-05")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), month)
+
+	for _, s := range []string{"", "2025-13", "2025-5", "2025-05-01", "may"} {
+		_, err := ParseStatementMonth(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestWriteStatementCSV(t *testing.T) {
+	paidAt := time.Date(2025, 5, 2, 10, 30, 0, 0, time.UTC)
+	lines := []StatementLine{
+		{
+			PaidAt: paidAt,
+			PayoutItem: PayoutItem{
+				PayoutID:     "payout-1",
+				BookingID:    "booking-1",
+				PropertyID:   "property-1",
+				CheckInDate:  time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+				CheckOutDate: time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC),
+				AmountCents:  30005,
+			},
+			Currency: "USD",
+		},
+		{
+			PaidAt: paidAt,
+			PayoutItem: PayoutItem{
+				PayoutID:     "payout-2",
+				BookingID:    "booking-2",
+				PropertyID:   "property-2",
+				CheckInDate:  time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+				CheckOutDate: time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC),
+				AmountCents:  20000,
+			},
+			Currency: "JPY",
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteStatementCSV(&buf, lines))
+	require.Equal(t, "paid_at,payout_id,booking_id,property_id,check_in_date,check_out_date,currency,amount\n"+
+		"2025-05-02T10:30:00Z,payout-1,booking-1,property-1,2025-05-01,2025-05-04,USD,300.05\n"+
+		"2025-05-02T10:30:00Z,payout-2,booking-2,property-2,2025-04-30,2025-05-02,JPY,20000\n", buf.String())
+
+	// A month without payouts has only the header.
+	buf.Reset()
+	require.NoError(t, WriteStatementCSV(&buf, nil))
+	require.Equal(t, "paid_at,payout_id,booking_id,property_id,check_in_date,check_out_date,currency,amount\n", buf.String())
+}
@@ -0,0 +1,113 @@
+// Package payouts pays the hosts the earnings of their stays, some days after the check-in of the guests.
+package payouts
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+//go:generate mockgen -source payouts.go -destination ../mock/payouts.go -package mock
+
+// PayoutRepository gathers the methods needed to schedule and pay the payouts.
+type PayoutRepository interface {
+	// SchedulePayouts creates the payouts of the bookings checked in at least delayDays before now and returns them
+	SchedulePayouts(ctx context.Context, delayDays int, now time.Time) ([]reserv.Payout, error)
+	// PendingPayouts returns the payouts not paid yet
+	PendingPayouts(ctx context.Context) ([]reserv.Payout, error)
+	// CompletePayout stores a payout as paid and posts its entry to the ledger
+	CompletePayout(ctx context.Context, payout reserv.Payout, entry reserv.JournalEntry) error
+}
+
+// PayoutProvider is the interface for the payout providers that transfer the earnings to the hosts.
+type PayoutProvider interface {
+	// Payout transfers a payout to its host. Sending the same payout again must not transfer it twice
+	Payout(ctx context.Context, req reserv.PayoutRequest) (reserv.PayoutTransfer, error)
+}
+
+// Worker periodically schedules the payouts of the stays and sends them to the payout provider.
+type Worker struct {
+	repo      PayoutRepository
+	provider  PayoutProvider
+	delayDays int
+	interval  time.Duration
+}
+
+// NewWorker creates a worker that pays the hosts every interval, delayDays after the check-in of their bookings.
+func NewWorker(repo PayoutRepository, provider PayoutProvider, delayDays int, interval time.Duration) *Worker {
+	return &Worker{repo: repo, provider: provider, delayDays: delayDays, interval: interval}
+}
+
+// Run pays the hosts until ctx is done. It is blocking, so it must run on its own goroutine.
+func (w *Worker) Run(ctx context.Context) {
+	slog.Info("starting payout worker", "interval", w.interval, "delay_days", w.delayDays)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.Pay(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.Info("stopping payout worker")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Pay schedules the payouts of the bookings that became due and sends every pending payout to the provider. Payouts
+// that fail stay pending and are sent again on the next run.
+func (w *Worker) Pay(ctx context.Context) {
+	scheduled, err := w.repo.SchedulePayouts(ctx, w.delayDays, time.Now().UTC())
+	if err != nil {
+		// The payouts scheduled before are still paid.
+		slog.Error("failed to schedule payouts", "error", err)
+	} else if len(scheduled) > 0 {
+		slog.Info("payouts scheduled", "scheduled", len(scheduled))
+	}
+
+	pending, err := w.repo.PendingPayouts(ctx)
+	if err != nil {
+		slog.Error("failed to get pending payouts", "error", err)
+		return
+	}
+
+	for _, payout := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+		w.pay(ctx, payout)
+	}
+}
+
+// pay transfers a payout and stores it as paid. The provider doesn't transfer a payout twice, so a payout transferred
+// but not stored is sent again safely.
+func (w *Worker) pay(ctx context.Context, payout reserv.Payout) {
+	transfer, err := w.provider.Payout(ctx, reserv.PayoutRequest{
+		PayoutID:    payout.ID,
+		HostID:      payout.HostID,
+		AmountCents: payout.AmountCents,
+		Currency:    payout.Currency,
+	})
+	if err != nil {
+		slog.Error("failed to transfer payout", "id", payout.ID, "host_id", payout.HostID, "error", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	payout.Status = reserv.PayoutStatusPaid
+	payout.Provider = transfer.Provider
+	payout.ProviderPayoutID = transfer.ID
+	payout.PaidAt = &now
+	payout.UpdatedAt = now
+
+	if err := w.repo.CompletePayout(ctx, payout, reserv.PayoutEntry(payout, now)); err != nil {
+		slog.Error("failed to complete payout", "id", payout.ID, "host_id", payout.HostID, "error", err)
+		return
+	}
+
+	slog.Info("payout paid", "id", payout.ID, "host_id", payout.HostID, "amount_cents", payout.AmountCents, "currency", payout.Currency)
+}
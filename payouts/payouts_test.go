@@ -0,0 +1,110 @@
+package payouts_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/mock"
+	"github.com/perebaj/reserv/payments"
+	"github.com/perebaj/reserv/payouts"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWorker_Pay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pending := []reserv.Payout{
+		{ID: "payout-1", HostID: "host-1", Status: reserv.PayoutStatusPending, AmountCents: 27000, Currency: "USD"},
+		{ID: "payout-2", HostID: "host-2", Status: reserv.PayoutStatusPending, AmountCents: 50000, Currency: "BRL"},
+	}
+
+	repo := mock.NewMockPayoutRepository(ctrl)
+	repo.EXPECT().SchedulePayouts(gomock.Any(), 3, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, now time.Time) ([]reserv.Payout, error) {
+		require.WithinDuration(t, time.Now(), now, time.Minute)
+		return pending[1:], nil
+	})
+	repo.EXPECT().PendingPayouts(gomock.Any()).Return(pending, nil)
+
+	var completed []reserv.Payout
+	repo.EXPECT().CompletePayout(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, payout reserv.Payout, entry reserv.JournalEntry) error {
+		require.NoError(t, entry.Validate())
+		require.Equal(t, payout.ID, *entry.PayoutID)
+		completed = append(completed, payout)
+		return nil
+	}).Times(2)
+
+	fake := payments.NewFake("whsec_test")
+	payouts.NewWorker(repo, fake, 3, time.Minute).Pay(context.Background())
+
+	require.Len(t, completed, 2)
+	for _, payout := range completed {
+		require.Equal(t, reserv.PayoutStatusPaid, payout.Status)
+		require.Equal(t, payments.FakeProviderName, payout.Provider)
+		require.NotEmpty(t, payout.ProviderPayoutID)
+		require.NotNil(t, payout.PaidAt)
+	}
+	require.Equal(t, int64(27000), fake.PaidOut("host-1", "USD"))
+	require.Equal(t, int64(50000), fake.PaidOut("host-2", "BRL"))
+}
+
+func TestWorker_PayFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pending := []reserv.Payout{
+		{ID: "payout-1", HostID: "host-1", Status: reserv.PayoutStatusPending, AmountCents: 27000, Currency: "USD"},
+		{ID: "payout-2", HostID: "host-2", Status: reserv.PayoutStatusPending, AmountCents: 50000, Currency: "BRL"},
+	}
+
+	repo := mock.NewMockPayoutRepository(ctrl)
+	// The payouts scheduled before are paid even if scheduling fails.
+	repo.EXPECT().SchedulePayouts(gomock.Any(), 1, gomock.Any()).Return(nil, errors.New("connection refused"))
+	repo.EXPECT().PendingPayouts(gomock.Any()).Return(pending, nil)
+
+	// A failed transfer leaves the payout pending and the next payouts are still paid.
+	provider := mock.NewMockPayoutProvider(ctrl)
+	provider.EXPECT().Payout(gomock.Any(), reserv.PayoutRequest{PayoutID: "payout-1", HostID: "host-1", AmountCents: 27000, Currency: "USD"}).
+		Return(reserv.PayoutTransfer{}, errors.New("bank account not verified"))
+	provider.EXPECT().Payout(gomock.Any(), reserv.PayoutRequest{PayoutID: "payout-2", HostID: "host-2", AmountCents: 50000, Currency: "BRL"}).
+		Return(reserv.PayoutTransfer{Provider: "bank", ID: "transfer-2"}, nil)
+	repo.EXPECT().CompletePayout(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, payout reserv.Payout, _ reserv.JournalEntry) error {
+		require.Equal(t, "payout-2", payout.ID)
+		require.Equal(t, "transfer-2", payout.ProviderPayoutID)
+		return reserv.ErrPayoutNotPending
+	})
+
+	worker := payouts.NewWorker(repo, provider, 1, time.Minute)
+	worker.Pay(context.Background())
+
+	repo.EXPECT().SchedulePayouts(gomock.Any(), 1, gomock.Any()).Return(nil, nil)
+	repo.EXPECT().PendingPayouts(gomock.Any()).Return(nil, errors.New("connection refused"))
+	worker.Pay(context.Background())
+}
+
+func TestWorker_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockPayoutRepository(ctrl)
+	repo.EXPECT().SchedulePayouts(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).MinTimes(1)
+	repo.EXPECT().PendingPayouts(gomock.Any()).Return(nil, nil).MinTimes(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		payouts.NewWorker(repo, payments.NewFake("whsec_test"), 1, time.Hour).Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker didn't stop after the context was cancelled")
+	}
+}
@@ -0,0 +1,202 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/perebaj/reserv"
+)
+
+// checkManualCalendarBlock checks that the block exists for the property and was created by the host. It returns
+// reserv.ErrCalendarBlockNotFound or reserv.ErrImportedCalendarBlock otherwise.
+func checkManualCalendarBlock(ctx context.Context, q sqlx.QueryerContext, propertyID, id string) error {
+	query := `
+		SELECT import_id IS NOT NULL FROM calendar_blocks WHERE id = $1 AND property_id = $2
+	`
+
+	var imported bool
+	err := sqlx.GetContext(ctx, q, &imported, query, id, propertyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return reserv.ErrCalendarBlockNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get calendar block: %v", err)
+	}
+
+	if imported {
+		return reserv.ErrImportedCalendarBlock
+	}
+	return nil
+}
+
+// overlapsCalendarBlock reports whether there is a calendar block of the property that overlaps start-end (both inclusive).
+func overlapsCalendarBlock(ctx context.Context, tx *sqlx.Tx, propertyID string, start, end time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM calendar_blocks
+			WHERE property_id = $1
+				AND daterange(start_date, end_date, '[]') && daterange($2, $3, '[]')
+		)
+	`
+
+	var overlaps bool
+	if err := tx.GetContext(ctx, &overlaps, query, propertyID, start, end); err != nil {
+		return false, fmt.Errorf("failed to check calendar blocks overlap: %v", err)
+	}
+	return overlaps, nil
+}
+
+// CreateCalendarBlock creates a calendar block for a property. Blocks can't overlap active bookings, in this case
+// reserv.ErrBookingOverlap is returned. Blocks can overlap other blocks.
+func (r *Repository) CreateCalendarBlock(ctx context.Context, block reserv.CalendarBlock) (string, error) {
+	slog.Info("creating calendar block", "property_id", block.PropertyID)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := lockProperty(ctx, tx, block.PropertyID); err != nil {
+		return "", err
+	}
+
+	booked, err := overlapsActiveBooking(ctx, tx, block.PropertyID, block.StartDate, block.EndDate, "")
+	if err != nil {
+		return "", err
+	}
+
+	if booked {
+		return "", reserv.ErrBookingOverlap
+	}
+
+	query := `
+		INSERT INTO calendar_blocks (
+			property_id,
+			start_date,
+			end_date,
+			reason,
+			created_at,
+			updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	var id string
+	if err := tx.QueryRowxContext(ctx, query,
+		block.PropertyID,
+		block.StartDate,
+		block.EndDate,
+		block.Reason,
+		block.CreatedAt,
+		block.UpdatedAt,
+	).Scan(&id); err != nil {
+		return "", fmt.Errorf("failed to create calendar block: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit calendar block: %v", err)
+	}
+
+	return id, nil
+}
+
+// UpdateCalendarBlock updates the dates and reason of a calendar block. The new dates can't overlap active bookings.
+func (r *Repository) UpdateCalendarBlock(ctx context.Context, block reserv.CalendarBlock) error {
+	slog.Info("updating calendar block", "id", block.ID, "property_id", block.PropertyID)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := lockProperty(ctx, tx, block.PropertyID); err != nil {
+		return err
+	}
+
+	if err := checkManualCalendarBlock(ctx, tx, block.PropertyID, block.ID); err != nil {
+		return err
+	}
+
+	booked, err := overlapsActiveBooking(ctx, tx, block.PropertyID, block.StartDate, block.EndDate, "")
+	if err != nil {
+		return err
+	}
+
+	if booked {
+		return reserv.ErrBookingOverlap
+	}
+
+	query := `
+		UPDATE calendar_blocks
+			SET start_date = $3,
+			end_date = $4,
+			reason = $5,
+			updated_at = $6
+		WHERE id = $1 AND property_id = $2 AND import_id IS NULL
+	`
+
+	res, err := tx.ExecContext(ctx, query, block.ID, block.PropertyID, block.StartDate, block.EndDate, block.Reason, block.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update calendar block: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		return reserv.ErrCalendarBlockNotFound
+	}
+
+	return tx.Commit()
+}
+
+// DeleteCalendarBlock deletes a calendar block of a property.
+func (r *Repository) DeleteCalendarBlock(ctx context.Context, propertyID, id string) error {
+	slog.Info("deleting calendar block", "id", id, "property_id", propertyID)
+	query := `
+		DELETE FROM calendar_blocks WHERE id = $1 AND property_id = $2 AND import_id IS NULL
+	`
+
+	res, err := r.db.ExecContext(ctx, query, id, propertyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar block: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		// The block doesn't exist or was imported.
+		return checkManualCalendarBlock(ctx, r.db, propertyID, id)
+	}
+
+	return nil
+}
+
+// CalendarBlocks returns all calendar blocks of a property ordered by the start date.
+func (r *Repository) CalendarBlocks(ctx context.Context, propertyID string) ([]reserv.CalendarBlock, error) {
+	slog.Info("getting calendar blocks", "property_id", propertyID)
+	query := `
+		SELECT * FROM calendar_blocks WHERE property_id = $1 ORDER BY start_date
+	`
+
+	var blocks []reserv.CalendarBlock
+	if err := r.db.SelectContext(ctx, &blocks, query, propertyID); err != nil {
+		return nil, fmt.Errorf("failed to get calendar blocks: %v", err)
+	}
+
+	return blocks, nil
+}
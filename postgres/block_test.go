@@ -0,0 +1,249 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarBlocks(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+	hostID := uuid.New().String()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             hostID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	blockID, err := repo.CreateCalendarBlock(ctx, reserv.CalendarBlock{
+		PropertyID: propertyID,
+		StartDate:  time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC),
+		Reason:     "maintenance",
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, blockID)
+
+	// A booking that checks out on the first blocked day is rejected, exactly like a booking.
+	_, err = repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      hostID,
+		CheckInDate:  time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+	})
+	require.ErrorIs(t, err, reserv.ErrBookingOverlap)
+
+	bookingID, err := repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      hostID,
+		CheckInDate:  time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, bookingID)
+
+	// Blocks can't overlap active bookings.
+	_, err = repo.CreateCalendarBlock(ctx, reserv.CalendarBlock{
+		PropertyID: propertyID,
+		StartDate:  time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	})
+	require.ErrorIs(t, err, reserv.ErrBookingOverlap)
+
+	err = repo.UpdateCalendarBlock(ctx, reserv.CalendarBlock{
+		ID:         blockID,
+		PropertyID: propertyID,
+		StartDate:  time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC),
+		UpdatedAt:  time.Now(),
+	})
+	require.ErrorIs(t, err, reserv.ErrBookingOverlap)
+
+	occupancy, err := repo.Occupancy(ctx, propertyID, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, occupancy, 2)
+	require.Equal(t, reserv.OccupancyBlock, occupancy[0].Kind)
+	require.Equal(t, reserv.OccupancyBooking, occupancy[1].Kind)
+
+	blocks, err := repo.CalendarBlocks(ctx, propertyID)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	require.Equal(t, "maintenance", blocks[0].Reason)
+
+	err = repo.DeleteCalendarBlock(ctx, uuid.New().String(), blockID)
+	require.ErrorIs(t, err, reserv.ErrCalendarBlockNotFound)
+
+	err = repo.DeleteCalendarBlock(ctx, propertyID, blockID)
+	require.NoError(t, err)
+
+	// Without the block, the dates can be booked.
+	_, err = repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      hostID,
+		CheckInDate:  time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+}
+
+func TestCalendarExportToken(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             uuid.New().String(),
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	token, err := repo.CalendarExportToken(ctx, propertyID)
+	require.NoError(t, err)
+	require.Empty(t, token)
+
+	require.NoError(t, repo.SetCalendarExportToken(ctx, propertyID, "first"))
+	require.NoError(t, repo.SetCalendarExportToken(ctx, propertyID, "second"))
+
+	token, err = repo.CalendarExportToken(ctx, propertyID)
+	require.NoError(t, err)
+	require.Equal(t, "second", token)
+
+	require.NoError(t, repo.DeleteProperty(ctx, propertyID))
+}
+
+func TestSyncCalendarImport(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+	hostID := uuid.New().String()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             hostID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	bookingID, err := repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      hostID,
+		CheckInDate:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	importID, err := repo.CreateCalendarImport(ctx, reserv.CalendarImport{
+		PropertyID: propertyID,
+		Name:       "Airbnb",
+		URL:        "https://www.airbnb.com/calendar/ical/1.ics",
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	})
+	require.NoError(t, err)
+
+	imports, err := repo.CalendarImportsToSync(ctx, time.Now())
+	require.NoError(t, err)
+	require.Contains(t, importIDs(imports), importID)
+
+	imp, err := repo.GetCalendarImport(ctx, propertyID, importID)
+	require.NoError(t, err)
+
+	result, err := repo.SyncCalendarImport(ctx, imp, []reserv.CalendarBlock{
+		{StartDate: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), ExternalUID: "conflict"},
+		{StartDate: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC), ExternalUID: "ok"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Blocked)
+	require.Len(t, result.Conflicts, 1)
+	require.Equal(t, "conflict", result.Conflicts[0].UID)
+	require.Equal(t, bookingID, result.Conflicts[0].BookingID)
+
+	imp, err = repo.GetCalendarImport(ctx, propertyID, importID)
+	require.NoError(t, err)
+	require.NotNil(t, imp.LastSyncAt)
+	require.Equal(t, result.Conflicts[0].BookingID, imp.Conflicts[0].BookingID)
+
+	// Imported blocks are respected by the overlap check.
+	_, err = repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      hostID,
+		CheckInDate:  time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC),
+	})
+	require.ErrorIs(t, err, reserv.ErrBookingOverlap)
+
+	// A new sync replaces the blocks of the previous one.
+	result, err = repo.SyncCalendarImport(ctx, imp, []reserv.CalendarBlock{
+		{StartDate: time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2025, 2, 12, 0, 0, 0, 0, time.UTC), ExternalUID: "new"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Blocked)
+	require.Empty(t, result.Conflicts)
+
+	blocks, err := repo.CalendarBlocks(ctx, propertyID)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	require.Equal(t, "new", blocks[0].ExternalUID)
+	require.Equal(t, importID, *blocks[0].ImportID)
+
+	// Imported blocks are changed in the external calendar, not by hand.
+	blocks[0].Reason = "changed"
+	blocks[0].UpdatedAt = time.Now()
+	require.ErrorIs(t, repo.UpdateCalendarBlock(ctx, blocks[0]), reserv.ErrImportedCalendarBlock)
+	require.ErrorIs(t, repo.DeleteCalendarBlock(ctx, propertyID, blocks[0].ID), reserv.ErrImportedCalendarBlock)
+
+	require.NoError(t, repo.FailCalendarImport(ctx, importID, "unexpected status 404"))
+	imp, err = repo.GetCalendarImport(ctx, propertyID, importID)
+	require.NoError(t, err)
+	require.Equal(t, "unexpected status 404", imp.LastSyncError)
+
+	require.NoError(t, repo.DeleteCalendarImport(ctx, propertyID, importID))
+	blocks, err = repo.CalendarBlocks(ctx, propertyID)
+	require.NoError(t, err)
+	require.Empty(t, blocks)
+
+	_, err = repo.GetCalendarImport(ctx, propertyID, importID)
+	require.ErrorIs(t, err, reserv.ErrCalendarImportNotFound)
+}
+
+func importIDs(imports []reserv.CalendarImport) []string {
+	ids := make([]string, 0, len(imports))
+	for _, imp := range imports {
+		ids = append(ids, imp.ID)
+	}
+	return ids
+}
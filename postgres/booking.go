@@ -4,37 +4,96 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/perebaj/reserv"
 )
 
-// CreateBooking creates a new booking considering the existing bookings to avoid overlapping.
+// bookingsNoOverlapConstraint is the exclusion constraint that avoids overlapping bookings for the same property.
+const bookingsNoOverlapConstraint = "bookings_no_overlap"
+
+// activeBookings is the condition that filters the bookings that block the property dates. It must follow the
+// predicate of the bookings_no_overlap constraint.
+const activeBookings = "status NOT IN ('cancelled_by_guest', 'cancelled_by_host', 'declined')"
+
+// exclusionViolation is the postgres error code raised when an exclusion constraint is violated.
+// Reference: https://www.postgresql.org/docs/current/errcodes-appendix.html
+const exclusionViolation = "23P01"
+
+// isConstraintViolation reports whether err was raised by the given exclusion constraint.
+func isConstraintViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == exclusionViolation && pqErr.Constraint == constraint
+}
+
+// CreateBooking creates a new booking considering the existing bookings and calendar blocks to avoid overlapping.
 // An important detail about this implementation is that the booking will never accept overlapping. So, if a property has booked 2025-01-01 to 2025-01-05
 // and another booking is requested for 2025-01-05 to 2025-01-10, the booking will be rejected. We are not considering hours of check in and check out.
+// The overlap between bookings is enforced by the bookings_no_overlap exclusion constraint, so concurrent requests for the same dates can't double-book
+// a property. Calendar blocks live in another table, so they are checked while holding a lock on the property row, the same lock
+// taken by CreateCalendarBlock. When the booking overlaps, reserv.ErrBookingOverlap is returned. When it doesn't leave the
+// preparation days of the property free around the other bookings, reserv.ErrPreparationTime is returned.
+// Active holds of other guests also block the dates, while the holds of the guest are released once the booking is created.
+// Cancelled bookings are not considered. If the booking has no status, it is created as confirmed. The line items of the
+// booking are stored in the same transaction. When the booking has a promotion, its redemption is counted in the same
+// transaction as well, and an error wrapping reserv.ErrPromotionUnavailable is returned if it expired or reached its
+// usage limit. Redemptions are given back when the request is declined, by the host or by the expiry of its deadline,
+// but not when the booking is cancelled.
 func (r *Repository) CreateBooking(ctx context.Context, newBooking reserv.Booking) (string, error) {
-	query := `
-		SELECT EXISTS (
-			-- SELECT will return 1 if there is a booking that overlaps with the new booking
-			SELECT 1 FROM bookings WHERE property_id = $1 AND (
-				(check_in_date <= $2 AND check_out_date >= $2) OR
-				(check_in_date <= $3 AND check_out_date >= $3) OR
-				(check_in_date >= $2 AND check_out_date <= $3)
-			)
-		)
-	`
+	slog.Info("creating booking")
+	status := newBooking.Status
+	if status == "" {
+		status = reserv.BookingStatusConfirmed
+	}
 
-	var isBooked bool
-	if err := r.db.GetContext(ctx, &isBooked, query, newBooking.PropertyID, newBooking.CheckInDate, newBooking.CheckOutDate); err != nil {
-		return "", fmt.Errorf("failed to check if booking exists: %v", err)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %v", err)
 	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
 
-	if isBooked {
-		return "", fmt.Errorf("booking overlaps")
+	if err := lockProperty(ctx, tx, newBooking.PropertyID); err != nil {
+		return "", err
+	}
+
+	blocked, err := overlapsCalendarBlock(ctx, tx, newBooking.PropertyID, newBooking.CheckInDate, newBooking.CheckOutDate)
+	if err != nil {
+		return "", err
+	}
+
+	if blocked {
+		return "", reserv.ErrBookingOverlap
+	}
+
+	if err := checkPreparationTime(ctx, tx, newBooking.PropertyID, newBooking.CheckInDate, newBooking.CheckOutDate, ""); err != nil {
+		return "", err
+	}
+
+	held, err := overlapsActiveHold(ctx, tx, newBooking.PropertyID, newBooking.CheckInDate, newBooking.CheckOutDate, newBooking.GuestID, time.Now().UTC())
+	if err != nil {
+		return "", err
+	}
+
+	if held {
+		return "", reserv.ErrBookingOverlap
+	}
+
+	if newBooking.PromotionID != nil {
+		if err := redeemPromotion(ctx, tx, *newBooking.PromotionID, time.Now().UTC()); err != nil {
+			return "", err
+		}
 	}
 
-	slog.Info("creating booking")
 	q := `
 		INSERT INTO bookings (
 			property_id,
@@ -44,13 +103,20 @@ func (r *Repository) CreateBooking(ctx context.Context, newBooking reserv.Bookin
 			total_price_cents,
 			currency,
 			created_at,
-			updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			updated_at,
+			status,
+			adults,
+			children,
+			infants,
+			pets,
+			respond_by,
+			promotion_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		RETURNING id
 	`
 
 	var id string
-	if err := r.db.QueryRowxContext(ctx, q,
+	if err := tx.QueryRowxContext(ctx, q,
 		newBooking.PropertyID,
 		newBooking.GuestID,
 		newBooking.CheckInDate,
@@ -59,14 +125,141 @@ func (r *Repository) CreateBooking(ctx context.Context, newBooking reserv.Bookin
 		newBooking.Currency,
 		newBooking.CreatedAt,
 		newBooking.UpdatedAt,
+		status,
+		newBooking.Adults,
+		newBooking.Children,
+		newBooking.Infants,
+		newBooking.Pets,
+		newBooking.RespondBy,
+		newBooking.PromotionID,
 	).Scan(&id); err != nil {
+		if isConstraintViolation(err, bookingsNoOverlapConstraint) {
+			return "", reserv.ErrBookingOverlap
+		}
 		return "", fmt.Errorf("failed to create booking: %v", err)
 	}
 
+	if err := insertBookingLineItems(ctx, tx, id, newBooking.LineItems); err != nil {
+		return "", err
+	}
+
+	if err := releaseHolds(ctx, tx, newBooking.PropertyID, newBooking.GuestID, newBooking.CheckInDate, newBooking.CheckOutDate); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit booking: %v", err)
+	}
+
+	return id, nil
+}
+
+// insertBookingLineItems stores the line items of a booking, keeping their order.
+func insertBookingLineItems(ctx context.Context, tx *sqlx.Tx, bookingID string, items []reserv.QuoteLine) error {
+	query := `
+		INSERT INTO booking_line_items (booking_id, position, kind, description, amount_cents, source_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	for i, item := range items {
+		if _, err := tx.ExecContext(ctx, query, bookingID, i, item.Kind, item.Description, item.AmountCents, item.SourceID); err != nil {
+			return fmt.Errorf("failed to create booking line item: %v", err)
+		}
+	}
+	return nil
+}
+
+// checkPreparationTime checks that a stay leaves the preparation days of the property free before and after the other
+// active bookings. The property row must be locked by the transaction, since the exclusion constraint only covers the stay itself.
+// The booking with the id excludeID is ignored, so a booking can be moved without conflicting with itself.
+func checkPreparationTime(ctx context.Context, tx *sqlx.Tx, propertyID string, checkIn, checkOut time.Time, excludeID string) error {
+	days, err := preparationDays(ctx, tx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	if days == 0 {
+		return nil
+	}
+
+	booked, err := overlapsActiveBooking(ctx, tx, propertyID, checkIn, checkOut, excludeID)
+	if err != nil {
+		return err
+	}
+
+	if booked {
+		return reserv.ErrBookingOverlap
+	}
+
+	near, err := overlapsActiveBooking(ctx, tx, propertyID, checkIn.AddDate(0, 0, -days), checkOut.AddDate(0, 0, days), excludeID)
+	if err != nil {
+		return err
+	}
+
+	if near {
+		return fmt.Errorf("%w: %d preparation days are required between stays", reserv.ErrPreparationTime, days)
+	}
+	return nil
+}
+
+// lockProperty locks the property row until the end of the transaction. Every write that must be checked against
+// more than one table (bookings and calendar blocks) takes this lock, so the checks and the writes are serialized per property.
+func lockProperty(ctx context.Context, tx *sqlx.Tx, propertyID string) error {
+	var id string
+	err := tx.GetContext(ctx, &id, `SELECT id FROM properties WHERE id = $1 FOR UPDATE`, propertyID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("property %s not found", propertyID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock property: %v", err)
+	}
+	return nil
+}
+
+// overlapsActiveBooking reports whether there is an active booking of the property that overlaps start-end (both inclusive).
+// The booking with the id excludeID is ignored. Use an empty string to consider all the bookings.
+func overlapsActiveBooking(ctx context.Context, tx *sqlx.Tx, propertyID string, start, end time.Time, excludeID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM bookings
+			WHERE property_id = $1
+				AND ` + activeBookings + `
+				AND daterange(check_in_date, check_out_date, '[]') && daterange($2, $3, '[]')
+				AND id::text <> $4
+		)
+	`
+
+	var overlaps bool
+	if err := tx.GetContext(ctx, &overlaps, query, propertyID, start, end, excludeID); err != nil {
+		return false, fmt.Errorf("failed to check bookings overlap: %v", err)
+	}
+	return overlaps, nil
+}
+
+// overlappingBooking returns the id of an active booking of the property that overlaps start-end (both inclusive).
+// If there is no such booking, an empty string is returned.
+func overlappingBooking(ctx context.Context, tx *sqlx.Tx, propertyID string, start, end time.Time) (string, error) {
+	query := `
+		SELECT id FROM bookings
+		WHERE property_id = $1
+			AND ` + activeBookings + `
+			AND daterange(check_in_date, check_out_date, '[]') && daterange($2, $3, '[]')
+		ORDER BY check_in_date
+		LIMIT 1
+	`
+
+	var id string
+	err := tx.GetContext(ctx, &id, query, propertyID, start, end)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get overlapping booking: %v", err)
+	}
 	return id, nil
 }
 
-// GetBooking returns a booking by id.
+// GetBooking returns a booking by id, with its line items.
 func (r *Repository) GetBooking(ctx context.Context, id string) (int, reserv.Booking, error) {
 	slog.Info("getting booking", "id", id)
 	query := `
@@ -81,23 +274,264 @@ func (r *Repository) GetBooking(ctx context.Context, id string) (int, reserv.Boo
 		return 0, reserv.Booking{}, fmt.Errorf("failed to get booking: %v", err)
 	}
 
+	query = `
+		SELECT kind, description, amount_cents, source_id FROM booking_line_items WHERE booking_id = $1 ORDER BY position
+	`
+
+	if err := r.db.SelectContext(ctx, &booking.LineItems, query, id); err != nil {
+		return 0, reserv.Booking{}, fmt.Errorf("failed to get booking line items: %v", err)
+	}
+
 	return 1, booking, nil
 }
 
-// DeleteBooking deletes a booking by id.
-func (r *Repository) DeleteBooking(ctx context.Context, id string) error {
-	slog.Info("deleting booking", "id", id)
+// UpdateBookingStatus moves a booking from the status from to the status to. The update only happens if the booking is
+// still in the status from, so two concurrent transitions can't both succeed. In that case, reserv.ErrInvalidBookingTransition is returned.
+// A declined request gives its promotion redemption back in the same transaction, since the guest never benefited from it.
+func (r *Repository) UpdateBookingStatus(ctx context.Context, id string, from, to reserv.BookingStatus) error {
+	slog.Info("updating booking status", "id", id, "from", from, "to", to)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		UPDATE bookings SET status = $3, updated_at = $4 WHERE id = $1 AND status = $2 RETURNING promotion_id
+	`
+
+	var promotionIDs []*string
+	if err := tx.SelectContext(ctx, &promotionIDs, query, id, from, to, time.Now()); err != nil {
+		return fmt.Errorf("failed to update booking status: %v", err)
+	}
+
+	if len(promotionIDs) == 0 {
+		return reserv.ErrInvalidBookingTransition
+	}
+
+	if to == reserv.BookingStatusDeclined {
+		if err := releasePromotions(ctx, tx, promotionIDs); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit booking status: %v", err)
+	}
+
+	return nil
+}
+
+// DeclineExpiredBookingRequests declines the booking requests whose deadline passed at now and returns how many were
+// declined. Only pending bookings are updated, so a request approved concurrently is never declined. Like
+// UpdateBookingStatus, the promotion redemptions of the declined requests are given back in the same transaction.
+func (r *Repository) DeclineExpiredBookingRequests(ctx context.Context, now time.Time) (int64, error) {
+	slog.Info("declining expired booking requests", "now", now)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
 	query := `
-		DELETE FROM bookings WHERE id = $1
+		UPDATE bookings SET status = $1, updated_at = $2
+		WHERE status = $3 AND respond_by <= $2
+		RETURNING promotion_id
 	`
 
-	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
-		return fmt.Errorf("failed to delete booking: %v", err)
+	var promotionIDs []*string
+	if err := tx.SelectContext(ctx, &promotionIDs, query, reserv.BookingStatusDeclined, now, reserv.BookingStatusPending); err != nil {
+		return 0, fmt.Errorf("failed to decline expired booking requests: %v", err)
+	}
+
+	if err := releasePromotions(ctx, tx, promotionIDs); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit declined booking requests: %v", err)
+	}
+
+	return int64(len(promotionIDs)), nil
+}
+
+// CancelBooking moves a booking from the status from to the cancelled status to and stores the refund given back to
+// the guest. Like UpdateBookingStatus, it returns reserv.ErrInvalidBookingTransition if the booking is no longer in
+// the status from. The entry of the refund owed to the guest, when not nil, is posted to the ledger in the same
+// transaction.
+func (r *Repository) CancelBooking(ctx context.Context, id string, from, to reserv.BookingStatus, refundCents int64, entry *reserv.JournalEntry) error {
+	slog.Info("cancelling booking", "id", id, "from", from, "to", to, "refund_cents", refundCents)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		UPDATE bookings SET status = $3, refund_cents = $4, cancelled_at = $5, updated_at = $5
+		WHERE id = $1 AND status = $2
+	`
+
+	res, err := tx.ExecContext(ctx, query, id, from, to, refundCents, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to cancel booking: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		return reserv.ErrInvalidBookingTransition
+	}
+
+	if entry != nil {
+		if err := postJournalEntry(ctx, tx, *entry); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit booking cancellation: %v", err)
 	}
 
 	return nil
 }
 
+// ChangeBookingDates moves a booking to the dates and price of the change and appends the change to the history of the
+// booking, in the same transaction. The new dates are checked like the dates of a new booking, ignoring the booking itself,
+// so the guest never loses the old dates before getting the new ones. It returns reserv.ErrBookingNotModifiable if the
+// booking can't be changed anymore and reserv.ErrBookingOverlap or reserv.ErrPreparationTime if the new dates are not free.
+// The line items of the booking are replaced by the ones of the change, when it has any.
+// The previous dates and price of the returned change are filled from the booking.
+func (r *Repository) ChangeBookingDates(ctx context.Context, change reserv.BookingChange) (reserv.BookingChange, error) {
+	slog.Info("changing booking dates", "id", change.BookingID)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return reserv.BookingChange{}, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var booking reserv.Booking
+	if err := tx.GetContext(ctx, &booking, `SELECT * FROM bookings WHERE id = $1 FOR UPDATE`, change.BookingID); err != nil {
+		return reserv.BookingChange{}, fmt.Errorf("failed to get booking: %v", err)
+	}
+
+	if !booking.Status.CanChangeDates() {
+		return reserv.BookingChange{}, reserv.ErrBookingNotModifiable
+	}
+
+	if err := lockProperty(ctx, tx, booking.PropertyID); err != nil {
+		return reserv.BookingChange{}, err
+	}
+
+	blocked, err := overlapsCalendarBlock(ctx, tx, booking.PropertyID, change.CheckInDate, change.CheckOutDate)
+	if err != nil {
+		return reserv.BookingChange{}, err
+	}
+
+	if blocked {
+		return reserv.BookingChange{}, reserv.ErrBookingOverlap
+	}
+
+	if err := checkPreparationTime(ctx, tx, booking.PropertyID, change.CheckInDate, change.CheckOutDate, booking.ID); err != nil {
+		return reserv.BookingChange{}, err
+	}
+
+	held, err := overlapsActiveHold(ctx, tx, booking.PropertyID, change.CheckInDate, change.CheckOutDate, booking.GuestID, time.Now().UTC())
+	if err != nil {
+		return reserv.BookingChange{}, err
+	}
+
+	if held {
+		return reserv.BookingChange{}, reserv.ErrBookingOverlap
+	}
+
+	query := `
+		UPDATE bookings SET check_in_date = $2, check_out_date = $3, total_price_cents = $4, updated_at = $5 WHERE id = $1
+	`
+
+	// The exclusion constraint compares the new row with the other bookings only, so the booking never overlaps itself.
+	if _, err := tx.ExecContext(ctx, query, booking.ID, change.CheckInDate, change.CheckOutDate, change.TotalPriceCents, change.CreatedAt); err != nil {
+		if isConstraintViolation(err, bookingsNoOverlapConstraint) {
+			return reserv.BookingChange{}, reserv.ErrBookingOverlap
+		}
+		return reserv.BookingChange{}, fmt.Errorf("failed to update booking dates: %v", err)
+	}
+
+	if change.LineItems != nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM booking_line_items WHERE booking_id = $1`, booking.ID); err != nil {
+			return reserv.BookingChange{}, fmt.Errorf("failed to delete booking line items: %v", err)
+		}
+
+		if err := insertBookingLineItems(ctx, tx, booking.ID, change.LineItems); err != nil {
+			return reserv.BookingChange{}, err
+		}
+	}
+
+	change.PreviousCheckInDate = booking.CheckInDate
+	change.PreviousCheckOutDate = booking.CheckOutDate
+	change.PreviousTotalPriceCents = booking.TotalPriceCents
+
+	query = `
+		INSERT INTO booking_changes (
+			booking_id,
+			changed_by,
+			previous_check_in_date,
+			previous_check_out_date,
+			previous_total_price_cents,
+			check_in_date,
+			check_out_date,
+			total_price_cents,
+			created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	if err := tx.QueryRowxContext(ctx, query,
+		change.BookingID,
+		change.ChangedBy,
+		change.PreviousCheckInDate,
+		change.PreviousCheckOutDate,
+		change.PreviousTotalPriceCents,
+		change.CheckInDate,
+		change.CheckOutDate,
+		change.TotalPriceCents,
+		change.CreatedAt,
+	).Scan(&change.ID); err != nil {
+		return reserv.BookingChange{}, fmt.Errorf("failed to create booking change: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return reserv.BookingChange{}, fmt.Errorf("failed to commit booking change: %v", err)
+	}
+
+	return change, nil
+}
+
+// BookingChanges returns the history of date changes of a booking, oldest first.
+func (r *Repository) BookingChanges(ctx context.Context, bookingID string) ([]reserv.BookingChange, error) {
+	slog.Info("getting booking changes", "booking_id", bookingID)
+	query := `
+		SELECT * FROM booking_changes WHERE booking_id = $1 ORDER BY created_at
+	`
+
+	changes := []reserv.BookingChange{}
+	if err := r.db.SelectContext(ctx, &changes, query, bookingID); err != nil {
+		return nil, fmt.Errorf("failed to get booking changes: %v", err)
+	}
+	return changes, nil
+}
+
 // Bookings returns all bookings.
 func (r *Repository) Bookings(ctx context.Context, filter reserv.BookingFilter) ([]reserv.Booking, error) {
 	slog.Info("getting all bookings")
@@ -137,15 +571,84 @@ func (r *Repository) Bookings(ctx context.Context, filter reserv.BookingFilter)
 	return bookings, nil
 }
 
-// GetBookingsByHostID returns all bookings by host id.
-func (r *Repository) GetBookingsByHostID(ctx context.Context, hostID string) ([]reserv.Booking, error) {
-	slog.Info("getting bookings by host id", "host_id", hostID)
+// Occupancy returns the date ranges occupied by active bookings, calendar blocks and active holds of a property that overlap the window from-to.
+// It uses the same inclusive ranges of the bookings_no_overlap constraint and never returns who booked the property.
+func (r *Repository) Occupancy(ctx context.Context, propertyID string, from, to time.Time) ([]reserv.Occupancy, error) {
+	slog.Info("getting property occupancy", "property_id", propertyID, "from", from, "to", to)
 	query := `
-		SELECT * FROM bookings WHERE host_id = $1 ORDER BY created_at DESC
+		SELECT 'booking' AS kind, check_in_date AS start_date, check_out_date AS end_date
+		FROM bookings
+		WHERE property_id = $1
+			AND ` + activeBookings + `
+			AND daterange(check_in_date, check_out_date, '[]') && daterange($2, $3, '[]')
+		UNION ALL
+		SELECT 'block' AS kind, start_date, end_date
+		FROM calendar_blocks
+		WHERE property_id = $1
+			AND daterange(start_date, end_date, '[]') && daterange($2, $3, '[]')
+		UNION ALL
+		SELECT 'hold' AS kind, check_in_date AS start_date, check_out_date AS end_date
+		FROM booking_holds
+		WHERE property_id = $1
+			AND expires_at > $4
+			AND daterange(check_in_date, check_out_date, '[]') && daterange($2, $3, '[]')
+		ORDER BY start_date
 	`
 
-	var bookings []reserv.Booking
-	if err := r.db.SelectContext(ctx, &bookings, query, hostID); err != nil {
+	var occupancy []reserv.Occupancy
+	if err := r.db.SelectContext(ctx, &occupancy, query, propertyID, from, to, time.Now().UTC()); err != nil {
+		return nil, fmt.Errorf("failed to get property occupancy: %v", err)
+	}
+
+	return occupancy, nil
+}
+
+// GetBookingsByHostID returns the bookings of all the properties of a host, newest first. Bookings don't store the host,
+// so they are joined with the properties they belong to.
+func (r *Repository) GetBookingsByHostID(ctx context.Context, filter reserv.HostBookingFilter) ([]reserv.Booking, error) {
+	slog.Info("getting bookings by host id", "filter", filter)
+	query := `
+		SELECT b.* FROM bookings b
+		JOIN properties p ON p.id = b.property_id
+		WHERE p.host_id = :host_id
+	`
+
+	args := map[string]interface{}{"host_id": filter.HostID}
+
+	if filter.PropertyID != "" {
+		query += " AND b.property_id = :property_id"
+		args["property_id"] = filter.PropertyID
+	}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		query += " AND b.status = ANY(:statuses)"
+		args["statuses"] = pq.Array(statuses)
+	}
+
+	if !filter.From.IsZero() {
+		query += " AND b.check_out_date >= :from"
+		args["from"] = filter.From
+	}
+
+	if !filter.To.IsZero() {
+		query += " AND b.check_in_date <= :to"
+		args["to"] = filter.To
+	}
+
+	query += " ORDER BY b.created_at DESC"
+	slog.Info("final query for host bookings", "query", query, "args", args)
+
+	query, params, err := sqlx.Named(query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build host bookings query: %v", err)
+	}
+
+	bookings := []reserv.Booking{}
+	if err := r.db.SelectContext(ctx, &bookings, r.db.Rebind(query), params...); err != nil {
 		return nil, fmt.Errorf("failed to get bookings by host id: %v", err)
 	}
 
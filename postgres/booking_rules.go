@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/perebaj/reserv"
+)
+
+// BookingRules returns the booking rules of a property. Properties whose host never configured them get reserv.DefaultBookingRules.
+func (r *Repository) BookingRules(ctx context.Context, propertyID string) (reserv.BookingRules, error) {
+	slog.Info("getting booking rules", "property_id", propertyID)
+	query := `
+		SELECT * FROM booking_rules WHERE property_id = $1
+	`
+
+	var rules reserv.BookingRules
+	err := r.db.GetContext(ctx, &rules, query, propertyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return reserv.DefaultBookingRules(propertyID), nil
+	}
+	if err != nil {
+		return reserv.BookingRules{}, fmt.Errorf("failed to get booking rules: %v", err)
+	}
+	return rules, nil
+}
+
+// SetBookingRules creates or replaces the booking rules of a property.
+func (r *Repository) SetBookingRules(ctx context.Context, rules reserv.BookingRules) error {
+	slog.Info("setting booking rules", "property_id", rules.PropertyID)
+	query := `
+		INSERT INTO booking_rules (
+			property_id,
+			min_nights,
+			max_nights,
+			advance_notice_days,
+			booking_horizon_days,
+			check_in_weekdays,
+			preparation_days,
+			updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (property_id) DO UPDATE SET
+			min_nights = EXCLUDED.min_nights,
+			max_nights = EXCLUDED.max_nights,
+			advance_notice_days = EXCLUDED.advance_notice_days,
+			booking_horizon_days = EXCLUDED.booking_horizon_days,
+			check_in_weekdays = EXCLUDED.check_in_weekdays,
+			preparation_days = EXCLUDED.preparation_days,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query,
+		rules.PropertyID,
+		rules.MinNights,
+		rules.MaxNights,
+		rules.AdvanceNoticeDays,
+		rules.BookingHorizonDays,
+		rules.CheckInWeekdays,
+		rules.PreparationDays,
+		rules.UpdatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to set booking rules: %v", err)
+	}
+	return nil
+}
+
+// preparationDays returns the preparation days of a property, 0 when it uses the default rules.
+func preparationDays(ctx context.Context, tx *sqlx.Tx, propertyID string) (int, error) {
+	query := `
+		SELECT preparation_days FROM booking_rules WHERE property_id = $1
+	`
+
+	var days int
+	err := tx.GetContext(ctx, &days, query, propertyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get preparation days: %v", err)
+	}
+	return days, nil
+}
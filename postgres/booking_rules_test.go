@@ -0,0 +1,92 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookingRules(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+	hostID := uuid.New().String()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             hostID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	rules, err := repo.BookingRules(ctx, propertyID)
+	require.NoError(t, err)
+	require.Equal(t, reserv.DefaultBookingRules(propertyID), rules)
+
+	rules.MinNights = 2
+	rules.CheckInWeekdays = reserv.Weekdays{time.Friday, time.Saturday}
+	rules.PreparationDays = 2
+	rules.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, repo.SetBookingRules(ctx, rules))
+
+	got, err := repo.BookingRules(ctx, propertyID)
+	require.NoError(t, err)
+	require.Equal(t, rules.CheckInWeekdays, got.CheckInWeekdays)
+	require.Equal(t, 2, got.PreparationDays)
+	require.Equal(t, 2, got.MinNights)
+
+	_, err = repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      hostID,
+		CheckInDate:  time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	// The check-out day plus 2 preparation days must be free: 2025-01-12, 2025-01-13 and 2025-01-14.
+	_, err = repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      hostID,
+		CheckInDate:  time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC),
+	})
+	require.ErrorIs(t, err, reserv.ErrPreparationTime)
+
+	_, err = repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      hostID,
+		CheckInDate:  time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC),
+	})
+	require.ErrorIs(t, err, reserv.ErrPreparationTime)
+
+	// Overlaps are still reported as overlaps.
+	_, err = repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      hostID,
+		CheckInDate:  time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC),
+	})
+	require.ErrorIs(t, err, reserv.ErrBookingOverlap)
+
+	_, err = repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      hostID,
+		CheckInDate:  time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+}
@@ -4,6 +4,7 @@ package postgres_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -77,7 +78,7 @@ func TestCreateBooking(t *testing.T) {
 	}
 
 	id3, err := repo.CreateBooking(context.Background(), want3)
-	require.Error(t, err, "booking overlaps")
+	require.ErrorIs(t, err, reserv.ErrBookingOverlap)
 	require.Empty(t, id3)
 
 	//Creating a booking that have 1 day more than the second booking
@@ -101,7 +102,7 @@ func TestCreateBooking(t *testing.T) {
 	}
 
 	id4, err := repo.CreateBooking(context.Background(), want5)
-	require.Error(t, err, "booking overlaps")
+	require.ErrorIs(t, err, reserv.ErrBookingOverlap)
 	require.Empty(t, id4)
 
 	want6 := reserv.Booking{
@@ -117,6 +118,69 @@ func TestCreateBooking(t *testing.T) {
 
 }
 
+// TestCreateBooking_Concurrent fires parallel bookings for the same dates at one property.
+// Only one of them must be accepted, the others must fail with reserv.ErrBookingOverlap.
+func TestCreateBooking_Concurrent(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	hostID := uuid.New().String()
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             hostID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	const workers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	start := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			// Each booking has a different range, but all of them share 2025-03-05.
+			_, err := repo.CreateBooking(ctx, reserv.Booking{
+				PropertyID:      propertyID,
+				GuestID:         uuid.New().String(),
+				CheckInDate:     time.Date(2025, 3, 5-i%5, 0, 0, 0, 0, time.UTC),
+				CheckOutDate:    time.Date(2025, 3, 5+i%3, 0, 0, 0, 0, time.UTC),
+				TotalPriceCents: 10000,
+				Currency:        "USD",
+				CreatedAt:       time.Now(),
+				UpdatedAt:       time.Now(),
+			})
+			errs <- err
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	var created int
+	for err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		require.ErrorIs(t, err, reserv.ErrBookingOverlap)
+	}
+	require.Equal(t, 1, created)
+
+	bookings, err := repo.Bookings(ctx, reserv.BookingFilter{PropertyID: propertyID})
+	require.NoError(t, err)
+	require.Len(t, bookings, 1)
+}
+
 func TestGetBooking(t *testing.T) {
 	db := OpenDB(t)
 	defer db.Close()
@@ -143,10 +207,16 @@ func TestGetBooking(t *testing.T) {
 		GuestID:         guestID,
 		CheckInDate:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
 		CheckOutDate:    time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		Guests:          reserv.Guests{Adults: 2, Children: 1, Infants: 1, Pets: 1},
 		TotalPriceCents: 10000,
 		Currency:        "USD",
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
+		LineItems: []reserv.QuoteLine{
+			{Kind: reserv.QuoteLineNightly, Description: "1 nights", AmountCents: 8000},
+			{Kind: reserv.QuoteLineCleaningFee, Description: "Cleaning fee", AmountCents: 1500},
+			{Kind: reserv.QuoteLineTax, Description: "NYC occupancy tax (5%)", AmountCents: 500, SourceID: uuid.New().String()},
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 	}
 
 	id, err := repo.CreateBooking(context.Background(), booking)
@@ -159,13 +229,16 @@ func TestGetBooking(t *testing.T) {
 	require.Equal(t, got.ID, id)
 	require.Equal(t, got.PropertyID, booking.PropertyID)
 	require.Equal(t, got.GuestID, booking.GuestID)
+	require.Equal(t, booking.Guests, got.Guests)
+	require.Equal(t, booking.LineItems, got.LineItems)
 }
 
-func TestDeleteBooking(t *testing.T) {
+func TestUpdateBookingStatus(t *testing.T) {
 	db := OpenDB(t)
 	defer db.Close()
 
 	repo := postgres.NewRepository(db)
+	ctx := context.Background()
 
 	guestID := uuid.New().String()
 
@@ -179,7 +252,7 @@ func TestDeleteBooking(t *testing.T) {
 		UpdatedAt:          time.Now(),
 	}
 
-	propertyID, err := repo.CreateProperty(context.Background(), property)
+	propertyID, err := repo.CreateProperty(ctx, property)
 	require.NoError(t, err)
 
 	booking := reserv.Booking{
@@ -189,16 +262,99 @@ func TestDeleteBooking(t *testing.T) {
 		CheckOutDate: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
 	}
 
-	id, err := repo.CreateBooking(context.Background(), booking)
+	id, err := repo.CreateBooking(ctx, booking)
 	require.NoError(t, err)
 	require.NotEmpty(t, id)
 
-	err = repo.DeleteBooking(context.Background(), id)
+	affected, got, err := repo.GetBooking(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, 1, affected)
+	require.Equal(t, reserv.BookingStatusConfirmed, got.Status)
+
+	// The same dates can't be booked while the booking is active.
+	_, err = repo.CreateBooking(ctx, booking)
+	require.ErrorIs(t, err, reserv.ErrBookingOverlap)
+
+	err = repo.UpdateBookingStatus(ctx, id, reserv.BookingStatusConfirmed, reserv.BookingStatusCancelledByGuest)
+	require.NoError(t, err)
+
+	// The booking is not confirmed anymore, so the transition is rejected.
+	err = repo.UpdateBookingStatus(ctx, id, reserv.BookingStatusConfirmed, reserv.BookingStatusCancelledByHost)
+	require.ErrorIs(t, err, reserv.ErrInvalidBookingTransition)
+
+	affected, got, err = repo.GetBooking(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, 1, affected)
+	require.Equal(t, reserv.BookingStatusCancelledByGuest, got.Status)
+
+	// Cancelled bookings don't block the dates anymore.
+	id2, err := repo.CreateBooking(ctx, booking)
+	require.NoError(t, err)
+	require.NotEmpty(t, id2)
+}
+
+func TestDeclineExpiredBookingRequests(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             uuid.New().String(),
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	expired := now.Add(-time.Minute)
+	open := now.Add(time.Hour)
+
+	expiredID, err := repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      "guest-1",
+		CheckInDate:  time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 5, 3, 0, 0, 0, 0, time.UTC),
+		Status:       reserv.BookingStatusPending,
+		RespondBy:    &expired,
+	})
 	require.NoError(t, err)
 
-	affected, _, err := repo.GetBooking(context.Background(), id)
+	openID, err := repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      "guest-2",
+		CheckInDate:  time.Date(2025, 5, 10, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 5, 12, 0, 0, 0, 0, time.UTC),
+		Status:       reserv.BookingStatusPending,
+		RespondBy:    &open,
+	})
+	require.NoError(t, err)
+
+	declined, err := repo.DeclineExpiredBookingRequests(ctx, now)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, declined, int64(1))
+
+	_, got, err := repo.GetBooking(ctx, expiredID)
+	require.NoError(t, err)
+	require.Equal(t, reserv.BookingStatusDeclined, got.Status)
+
+	_, got, err = repo.GetBooking(ctx, openID)
+	require.NoError(t, err)
+	require.Equal(t, reserv.BookingStatusPending, got.Status)
+
+	// Declined requests don't block the dates anymore.
+	_, err = repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      "guest-3",
+		CheckInDate:  time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 5, 3, 0, 0, 0, 0, time.UTC),
+	})
 	require.NoError(t, err)
-	require.Equal(t, affected, 0)
 }
 
 func TestBookings(t *testing.T) {
@@ -269,3 +425,219 @@ func TestBookings(t *testing.T) {
 		require.Equal(t, booking.GuestID, guestID)
 	}
 }
+
+func TestOccupancy(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+	guestID := uuid.New().String()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             guestID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	id, err := repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      guestID,
+		CheckInDate:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	cancelledID, err := repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      guestID,
+		CheckInDate:  time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	err = repo.UpdateBookingStatus(ctx, cancelledID, reserv.BookingStatusConfirmed, reserv.BookingStatusCancelledByGuest)
+	require.NoError(t, err)
+
+	// The window starts on the check-out day, so the booking must be returned.
+	occupancy, err := repo.Occupancy(ctx, propertyID, time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, occupancy, 1, "booking %s", id)
+	require.Equal(t, reserv.OccupancyBooking, occupancy[0].Kind)
+	require.True(t, occupancy[0].StartDate.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	require.True(t, occupancy[0].EndDate.Equal(time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC)))
+
+	occupancy, err = repo.Occupancy(ctx, propertyID, time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Empty(t, occupancy)
+}
+
+func TestChangeBookingDates(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+	guestID := uuid.New().String()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             guestID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	id, err := repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:      propertyID,
+		GuestID:         guestID,
+		CheckInDate:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate:    time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+		TotalPriceCents: 40000,
+		Currency:        "USD",
+	})
+	require.NoError(t, err)
+
+	otherID, err := repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:   propertyID,
+		GuestID:      guestID,
+		CheckInDate:  time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	// Moving the booking over its own dates doesn't conflict with itself.
+	change, err := repo.ChangeBookingDates(ctx, reserv.BookingChange{
+		BookingID:       id,
+		ChangedBy:       guestID,
+		CheckInDate:     time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+		CheckOutDate:    time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+		TotalPriceCents: 30000,
+		LineItems:       []reserv.QuoteLine{{Kind: reserv.QuoteLineNightly, Description: "3 nights", AmountCents: 30000}},
+		CreatedAt:       time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, change.ID)
+	require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), change.PreviousCheckInDate)
+	require.Equal(t, int64(40000), change.PreviousTotalPriceCents)
+
+	_, got, err := repo.GetBooking(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), got.CheckInDate.UTC())
+	require.Equal(t, time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), got.CheckOutDate.UTC())
+	require.Equal(t, int64(30000), got.TotalPriceCents)
+	require.Equal(t, change.LineItems, got.LineItems)
+
+	// The other booking still blocks its dates.
+	_, err = repo.ChangeBookingDates(ctx, reserv.BookingChange{
+		BookingID:    id,
+		ChangedBy:    guestID,
+		CheckInDate:  time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+		CreatedAt:    time.Now().UTC(),
+	})
+	require.ErrorIs(t, err, reserv.ErrBookingOverlap)
+
+	err = repo.UpdateBookingStatus(ctx, otherID, reserv.BookingStatusConfirmed, reserv.BookingStatusCancelledByGuest)
+	require.NoError(t, err)
+
+	_, err = repo.ChangeBookingDates(ctx, reserv.BookingChange{
+		BookingID:       otherID,
+		ChangedBy:       guestID,
+		CheckInDate:     time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate:    time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC),
+		TotalPriceCents: 10000,
+		CreatedAt:       time.Now().UTC(),
+	})
+	require.ErrorIs(t, err, reserv.ErrBookingNotModifiable)
+
+	changes, err := repo.BookingChanges(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, change.ID, changes[0].ID)
+	require.Equal(t, int64(30000), changes[0].TotalPriceCents)
+
+	require.NoError(t, repo.DeleteProperty(ctx, propertyID))
+}
+
+func TestGetBookingsByHostID(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+	hostID := uuid.New().String()
+	guestID := uuid.New().String()
+
+	newProperty := func() string {
+		id, err := repo.CreateProperty(ctx, reserv.Property{
+			HostID:             hostID,
+			Title:              "Test Property",
+			Description:        "Test Description",
+			PricePerNightCents: 10000,
+			Currency:           "USD",
+			CreatedAt:          time.Now(),
+			UpdatedAt:          time.Now(),
+		})
+		require.NoError(t, err)
+		return id
+	}
+	propertyA, propertyB := newProperty(), newProperty()
+
+	newBooking := func(propertyID string, checkIn, checkOut time.Time) string {
+		id, err := repo.CreateBooking(ctx, reserv.Booking{
+			PropertyID:   propertyID,
+			GuestID:      guestID,
+			CheckInDate:  checkIn,
+			CheckOutDate: checkOut,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		})
+		require.NoError(t, err)
+		return id
+	}
+	january := newBooking(propertyA, time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC))
+	february := newBooking(propertyB, time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 12, 0, 0, 0, 0, time.UTC))
+	cancelled := newBooking(propertyB, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, repo.UpdateBookingStatus(ctx, cancelled, reserv.BookingStatusConfirmed, reserv.BookingStatusCancelledByHost))
+
+	ids := func(bookings []reserv.Booking) []string {
+		var ids []string
+		for _, b := range bookings {
+			ids = append(ids, b.ID)
+		}
+		return ids
+	}
+
+	bookings, err := repo.GetBookingsByHostID(ctx, reserv.HostBookingFilter{HostID: hostID})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{january, february, cancelled}, ids(bookings))
+
+	bookings, err = repo.GetBookingsByHostID(ctx, reserv.HostBookingFilter{HostID: hostID, PropertyID: propertyA})
+	require.NoError(t, err)
+	require.Equal(t, []string{january}, ids(bookings))
+
+	bookings, err = repo.GetBookingsByHostID(ctx, reserv.HostBookingFilter{HostID: hostID, Statuses: []reserv.BookingStatus{reserv.BookingStatusCancelledByHost}})
+	require.NoError(t, err)
+	require.Equal(t, []string{cancelled}, ids(bookings))
+
+	// The range keeps the stays that overlap it, including the check-out day.
+	bookings, err = repo.GetBookingsByHostID(ctx, reserv.HostBookingFilter{
+		HostID: hostID,
+		From:   time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{january, february}, ids(bookings))
+
+	bookings, err = repo.GetBookingsByHostID(ctx, reserv.HostBookingFilter{HostID: uuid.New().String()})
+	require.NoError(t, err)
+	require.Empty(t, bookings)
+}
@@ -0,0 +1,259 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// CalendarExportToken returns the secret token of the iCalendar export feed of a property.
+// If the property has no token yet, an empty string is returned.
+func (r *Repository) CalendarExportToken(ctx context.Context, propertyID string) (string, error) {
+	slog.Info("getting calendar export token", "property_id", propertyID)
+	query := `
+		SELECT token FROM calendar_export_tokens WHERE property_id = $1
+	`
+
+	var token string
+	err := r.db.GetContext(ctx, &token, query, propertyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get calendar export token: %v", err)
+	}
+	return token, nil
+}
+
+// SetCalendarExportToken sets the secret token of the iCalendar export feed of a property.
+// The previous token, if any, stops working.
+func (r *Repository) SetCalendarExportToken(ctx context.Context, propertyID, token string) error {
+	slog.Info("setting calendar export token", "property_id", propertyID)
+	query := `
+		INSERT INTO calendar_export_tokens (property_id, token, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (property_id) DO UPDATE SET token = EXCLUDED.token, created_at = EXCLUDED.created_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, propertyID, token, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to set calendar export token: %v", err)
+	}
+	return nil
+}
+
+// CreateCalendarImport registers an external calendar of a property. It returns the id of the import.
+func (r *Repository) CreateCalendarImport(ctx context.Context, imp reserv.CalendarImport) (string, error) {
+	slog.Info("creating calendar import", "property_id", imp.PropertyID)
+	query := `
+		INSERT INTO calendar_imports (
+			property_id,
+			name,
+			url,
+			created_at,
+			updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var id string
+	if err := r.db.QueryRowxContext(ctx, query,
+		imp.PropertyID,
+		imp.Name,
+		imp.URL,
+		imp.CreatedAt,
+		imp.UpdatedAt,
+	).Scan(&id); err != nil {
+		return "", fmt.Errorf("failed to create calendar import: %v", err)
+	}
+
+	return id, nil
+}
+
+// GetCalendarImport returns a calendar import of a property. If it doesn't exist, reserv.ErrCalendarImportNotFound is returned.
+func (r *Repository) GetCalendarImport(ctx context.Context, propertyID, id string) (reserv.CalendarImport, error) {
+	slog.Info("getting calendar import", "id", id, "property_id", propertyID)
+	query := `
+		SELECT * FROM calendar_imports WHERE id = $1 AND property_id = $2
+	`
+
+	var imp reserv.CalendarImport
+	err := r.db.GetContext(ctx, &imp, query, id, propertyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return reserv.CalendarImport{}, reserv.ErrCalendarImportNotFound
+	}
+	if err != nil {
+		return reserv.CalendarImport{}, fmt.Errorf("failed to get calendar import: %v", err)
+	}
+	return imp, nil
+}
+
+// CalendarImports returns all calendar imports of a property.
+func (r *Repository) CalendarImports(ctx context.Context, propertyID string) ([]reserv.CalendarImport, error) {
+	slog.Info("getting calendar imports", "property_id", propertyID)
+	query := `
+		SELECT * FROM calendar_imports WHERE property_id = $1 ORDER BY created_at
+	`
+
+	var imports []reserv.CalendarImport
+	if err := r.db.SelectContext(ctx, &imports, query, propertyID); err != nil {
+		return nil, fmt.Errorf("failed to get calendar imports: %v", err)
+	}
+	return imports, nil
+}
+
+// CalendarImportsToSync returns the calendar imports with a URL whose last sync attempt happened before syncedBefore.
+func (r *Repository) CalendarImportsToSync(ctx context.Context, syncedBefore time.Time) ([]reserv.CalendarImport, error) {
+	query := `
+		SELECT * FROM calendar_imports
+		WHERE url <> '' AND (last_sync_at IS NULL OR last_sync_at < $1)
+		ORDER BY last_sync_at NULLS FIRST
+	`
+
+	var imports []reserv.CalendarImport
+	if err := r.db.SelectContext(ctx, &imports, query, syncedBefore); err != nil {
+		return nil, fmt.Errorf("failed to get calendar imports to sync: %v", err)
+	}
+	return imports, nil
+}
+
+// DeleteCalendarImport deletes a calendar import of a property and the blocks created by it.
+func (r *Repository) DeleteCalendarImport(ctx context.Context, propertyID, id string) error {
+	slog.Info("deleting calendar import", "id", id, "property_id", propertyID)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		DELETE FROM calendar_blocks WHERE import_id = $1 AND property_id = $2
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id, propertyID); err != nil {
+		return fmt.Errorf("failed to delete imported calendar blocks: %v", err)
+	}
+
+	query = `
+		DELETE FROM calendar_imports WHERE id = $1 AND property_id = $2
+	`
+
+	res, err := tx.ExecContext(ctx, query, id, propertyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar import: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		return reserv.ErrCalendarImportNotFound
+	}
+
+	return tx.Commit()
+}
+
+// SyncCalendarImport replaces the blocks created by a calendar import with blocks, all in the same transaction.
+// Blocks that overlap active bookings are not created and are returned as conflicts. The result is stored on the import.
+func (r *Repository) SyncCalendarImport(ctx context.Context, imp reserv.CalendarImport, blocks []reserv.CalendarBlock) (reserv.CalendarSyncResult, error) {
+	slog.Info("syncing calendar import", "id", imp.ID, "property_id", imp.PropertyID, "events", len(blocks))
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return reserv.CalendarSyncResult{}, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := lockProperty(ctx, tx, imp.PropertyID); err != nil {
+		return reserv.CalendarSyncResult{}, err
+	}
+
+	query := `
+		DELETE FROM calendar_blocks WHERE import_id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, imp.ID); err != nil {
+		return reserv.CalendarSyncResult{}, fmt.Errorf("failed to delete imported calendar blocks: %v", err)
+	}
+
+	result := reserv.CalendarSyncResult{Conflicts: reserv.CalendarConflicts{}}
+	now := time.Now().UTC()
+	for _, block := range blocks {
+		bookingID, err := overlappingBooking(ctx, tx, imp.PropertyID, block.StartDate, block.EndDate)
+		if err != nil {
+			return reserv.CalendarSyncResult{}, err
+		}
+
+		if bookingID != "" {
+			result.Conflicts = append(result.Conflicts, reserv.CalendarConflict{
+				UID:       block.ExternalUID,
+				StartDate: block.StartDate,
+				EndDate:   block.EndDate,
+				BookingID: bookingID,
+			})
+			continue
+		}
+
+		query := `
+			INSERT INTO calendar_blocks (
+				property_id,
+				start_date,
+				end_date,
+				reason,
+				import_id,
+				external_uid,
+				created_at,
+				updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		`
+
+		if _, err := tx.ExecContext(ctx, query, imp.PropertyID, block.StartDate, block.EndDate, block.Reason, imp.ID, block.ExternalUID, now); err != nil {
+			return reserv.CalendarSyncResult{}, fmt.Errorf("failed to create imported calendar block: %v", err)
+		}
+		result.Blocked++
+	}
+
+	query = `
+		UPDATE calendar_imports
+			SET last_sync_at = $2,
+			last_sync_error = '',
+			conflicts = $3,
+			updated_at = $2
+		WHERE id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, imp.ID, now, result.Conflicts); err != nil {
+		return reserv.CalendarSyncResult{}, fmt.Errorf("failed to update calendar import: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return reserv.CalendarSyncResult{}, fmt.Errorf("failed to commit calendar import sync: %v", err)
+	}
+
+	return result, nil
+}
+
+// FailCalendarImport records a failed sync attempt of a calendar import. The blocks of the last successful sync are kept.
+func (r *Repository) FailCalendarImport(ctx context.Context, id string, syncErr string) error {
+	query := `
+		UPDATE calendar_imports
+			SET last_sync_at = $2,
+			last_sync_error = $3,
+			updated_at = $2
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, time.Now().UTC(), syncErr); err != nil {
+		return fmt.Errorf("failed to update calendar import: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/perebaj/reserv"
+)
+
+// CancellationPolicy returns the cancellation policy of a property. Properties whose host never configured it get
+// reserv.DefaultCancellationPolicy.
+func (r *Repository) CancellationPolicy(ctx context.Context, propertyID string) (reserv.CancellationPolicy, error) {
+	slog.Info("getting cancellation policy", "property_id", propertyID)
+	query := `
+		SELECT * FROM cancellation_policies WHERE property_id = $1
+	`
+
+	var policy reserv.CancellationPolicy
+	err := r.db.GetContext(ctx, &policy, query, propertyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return reserv.DefaultCancellationPolicy(propertyID), nil
+	}
+	if err != nil {
+		return reserv.CancellationPolicy{}, fmt.Errorf("failed to get cancellation policy: %v", err)
+	}
+	return policy, nil
+}
+
+// SetCancellationPolicy creates or replaces the cancellation policy of a property. Bookings cancelled before the change
+// keep the refund computed with the previous policy.
+func (r *Repository) SetCancellationPolicy(ctx context.Context, policy reserv.CancellationPolicy) error {
+	slog.Info("setting cancellation policy", "property_id", policy.PropertyID, "kind", policy.Kind)
+	query := `
+		INSERT INTO cancellation_policies (property_id, kind, tiers, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (property_id) DO UPDATE SET
+			kind = EXCLUDED.kind,
+			tiers = EXCLUDED.tiers,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, policy.PropertyID, policy.Kind, policy.Tiers, policy.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to set cancellation policy: %v", err)
+	}
+	return nil
+}
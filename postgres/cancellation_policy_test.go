@@ -0,0 +1,98 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancellationPolicy(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+	hostID := uuid.New().String()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             hostID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	policy, err := repo.CancellationPolicy(ctx, propertyID)
+	require.NoError(t, err)
+	require.Equal(t, reserv.DefaultCancellationPolicy(propertyID), policy)
+
+	policy.Kind = reserv.CancellationCustom
+	policy.Tiers = reserv.RefundTiers{{DaysBeforeCheckIn: 30, RefundPercent: 100}, {DaysBeforeCheckIn: 3, RefundPercent: 25}}
+	policy.UpdatedAt = time.Now().UTC()
+	require.NoError(t, repo.SetCancellationPolicy(ctx, policy))
+
+	got, err := repo.CancellationPolicy(ctx, propertyID)
+	require.NoError(t, err)
+	require.Equal(t, policy.Kind, got.Kind)
+	require.Equal(t, policy.Tiers, got.Tiers)
+
+	require.NoError(t, repo.DeleteProperty(ctx, propertyID))
+}
+
+func TestCancelBooking(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+	guestID := uuid.New().String()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             guestID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	id, err := repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:      propertyID,
+		GuestID:         guestID,
+		CheckInDate:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate:    time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+		TotalPriceCents: 20000,
+		Currency:        "USD",
+	})
+	require.NoError(t, err)
+
+	_, got, err := repo.GetBooking(ctx, id)
+	require.NoError(t, err)
+	require.Zero(t, got.RefundCents)
+	require.Nil(t, got.CancelledAt)
+
+	err = repo.CancelBooking(ctx, id, reserv.BookingStatusConfirmed, reserv.BookingStatusCancelledByGuest, 10000, nil)
+	require.NoError(t, err)
+
+	// The booking is not confirmed anymore, so it can't be cancelled again.
+	err = repo.CancelBooking(ctx, id, reserv.BookingStatusConfirmed, reserv.BookingStatusCancelledByHost, 20000, nil)
+	require.ErrorIs(t, err, reserv.ErrInvalidBookingTransition)
+
+	_, got, err = repo.GetBooking(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, reserv.BookingStatusCancelledByGuest, got.Status)
+	require.Equal(t, int64(10000), got.RefundCents)
+	require.NotNil(t, got.CancelledAt)
+}
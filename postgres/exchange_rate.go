@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/perebaj/reserv"
+)
+
+// ExchangeRates gets all the exchange rates.
+func (r *Repository) ExchangeRates(ctx context.Context) ([]reserv.ExchangeRate, error) {
+	slog.Info("getting exchange rates")
+	query := `
+		SELECT base_currency, quote_currency, rate::TEXT AS rate, updated_at FROM exchange_rates ORDER BY base_currency, quote_currency
+	`
+
+	var rates []reserv.ExchangeRate
+	if err := r.db.SelectContext(ctx, &rates, query); err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %v", err)
+	}
+
+	return rates, nil
+}
+
+// ReplaceExchangeRates replaces all the exchange rates in a single transaction, so readers never see a partial table.
+func (r *Repository) ReplaceExchangeRates(ctx context.Context, rates []reserv.ExchangeRate) error {
+	slog.Info("replacing exchange rates", "count", len(rates))
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM exchange_rates`); err != nil {
+		return fmt.Errorf("failed to delete exchange rates: %v", err)
+	}
+
+	for _, rate := range rates {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO exchange_rates (base_currency, quote_currency, rate, updated_at) VALUES ($1, $2, $3, $4)
+		`, rate.Base, rate.Quote, rate.Rate, rate.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert exchange rate %s/%s: %v", rate.Base, rate.Quote, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangeRates(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	rates, err := repo.ExchangeRates(ctx)
+	require.NoError(t, err)
+	require.Empty(t, rates)
+
+	loadedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = repo.ReplaceExchangeRates(ctx, []reserv.ExchangeRate{
+		{Base: "USD", Quote: "EUR", Rate: "0.91", UpdatedAt: loadedAt},
+		{Base: "USD", Quote: "BRL", Rate: "5.123456789", UpdatedAt: loadedAt},
+	})
+	require.NoError(t, err)
+
+	rates, err = repo.ExchangeRates(ctx)
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	require.Equal(t, "BRL", rates[0].Quote)
+	require.Equal(t, "5.123456789", rates[0].Rate)
+	require.Equal(t, "EUR", rates[1].Quote)
+	require.Equal(t, "0.91", rates[1].Rate)
+	require.True(t, loadedAt.Equal(rates[1].UpdatedAt))
+
+	// A new load replaces the whole table.
+	err = repo.ReplaceExchangeRates(ctx, []reserv.ExchangeRate{
+		{Base: "USD", Quote: "JPY", Rate: "150", UpdatedAt: loadedAt.Add(time.Hour)},
+	})
+	require.NoError(t, err)
+
+	rates, err = repo.ExchangeRates(ctx)
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	require.Equal(t, "JPY", rates[0].Quote)
+	require.Equal(t, "150", rates[0].Rate)
+
+	// An invalid rate is rejected by the database and the table is kept.
+	err = repo.ReplaceExchangeRates(ctx, []reserv.ExchangeRate{
+		{Base: "USD", Quote: "EUR", Rate: "0", UpdatedAt: loadedAt},
+	})
+	require.Error(t, err)
+
+	rates, err = repo.ExchangeRates(ctx)
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+}
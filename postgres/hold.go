@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/perebaj/reserv"
+)
+
+// overlapsActiveHold reports whether there is a hold of the property, active at now, that overlaps start-end (both inclusive).
+// The holds of the guest guestID are ignored, since a guest never competes with their own holds.
+func overlapsActiveHold(ctx context.Context, tx *sqlx.Tx, propertyID string, start, end time.Time, guestID string, now time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM booking_holds
+			WHERE property_id = $1
+				AND expires_at > $4
+				AND daterange(check_in_date, check_out_date, '[]') && daterange($2, $3, '[]')
+				AND guest_id <> $5
+		)
+	`
+
+	var overlaps bool
+	if err := tx.GetContext(ctx, &overlaps, query, propertyID, start, end, now, guestID); err != nil {
+		return false, fmt.Errorf("failed to check booking holds overlap: %v", err)
+	}
+	return overlaps, nil
+}
+
+// releaseHolds deletes the holds of the guest for the property that overlap start-end (both inclusive).
+func releaseHolds(ctx context.Context, tx *sqlx.Tx, propertyID, guestID string, start, end time.Time) error {
+	query := `
+		DELETE FROM booking_holds
+		WHERE property_id = $1
+			AND guest_id = $2
+			AND daterange(check_in_date, check_out_date, '[]') && daterange($3, $4, '[]')
+	`
+
+	if _, err := tx.ExecContext(ctx, query, propertyID, guestID, start, end); err != nil {
+		return fmt.Errorf("failed to release booking holds: %v", err)
+	}
+	return nil
+}
+
+// lockGuestHolds serializes the creation of the holds of a guest, which can be of different properties, until the end of
+// the transaction.
+func lockGuestHolds(ctx context.Context, tx *sqlx.Tx, guestID string) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('booking_holds:' || $1))`, guestID); err != nil {
+		return fmt.Errorf("failed to lock guest holds: %v", err)
+	}
+	return nil
+}
+
+// CreateBookingHold holds the dates of a stay for a guest until hold.ExpiresAt. The dates are checked like the dates of
+// a new booking, under the same property lock, so a hold never overlaps active bookings, calendar blocks or active
+// holds of other guests. In this case, reserv.ErrBookingOverlap or reserv.ErrPreparationTime is returned.
+// The new hold replaces the holds of the guest that overlap it, so holding the same dates again refreshes the hold.
+// A guest holds at most reserv.MaxActiveHoldsPerGuest stays, otherwise reserv.ErrTooManyHolds is returned.
+func (r *Repository) CreateBookingHold(ctx context.Context, hold reserv.BookingHold) (string, error) {
+	slog.Info("creating booking hold", "property_id", hold.PropertyID, "expires_at", hold.ExpiresAt)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// The guest is locked before the property, so two holds of the guest never wait for each other in reverse order.
+	if err := lockGuestHolds(ctx, tx, hold.GuestID); err != nil {
+		return "", err
+	}
+
+	if err := lockProperty(ctx, tx, hold.PropertyID); err != nil {
+		return "", err
+	}
+
+	blocked, err := overlapsCalendarBlock(ctx, tx, hold.PropertyID, hold.CheckInDate, hold.CheckOutDate)
+	if err != nil {
+		return "", err
+	}
+
+	if blocked {
+		return "", reserv.ErrBookingOverlap
+	}
+
+	// Holds are not covered by the bookings_no_overlap constraint, so the overlap with the bookings is checked here.
+	booked, err := overlapsActiveBooking(ctx, tx, hold.PropertyID, hold.CheckInDate, hold.CheckOutDate, "")
+	if err != nil {
+		return "", err
+	}
+
+	if booked {
+		return "", reserv.ErrBookingOverlap
+	}
+
+	if err := checkPreparationTime(ctx, tx, hold.PropertyID, hold.CheckInDate, hold.CheckOutDate, ""); err != nil {
+		return "", err
+	}
+
+	held, err := overlapsActiveHold(ctx, tx, hold.PropertyID, hold.CheckInDate, hold.CheckOutDate, hold.GuestID, hold.CreatedAt)
+	if err != nil {
+		return "", err
+	}
+
+	if held {
+		return "", reserv.ErrBookingOverlap
+	}
+
+	if err := releaseHolds(ctx, tx, hold.PropertyID, hold.GuestID, hold.CheckInDate, hold.CheckOutDate); err != nil {
+		return "", err
+	}
+
+	var active int
+	query := `
+		SELECT COUNT(*) FROM booking_holds WHERE guest_id = $1 AND expires_at > $2
+	`
+
+	if err := tx.GetContext(ctx, &active, query, hold.GuestID, hold.CreatedAt); err != nil {
+		return "", fmt.Errorf("failed to count booking holds: %v", err)
+	}
+
+	if active >= reserv.MaxActiveHoldsPerGuest {
+		return "", reserv.ErrTooManyHolds
+	}
+
+	query = `
+		INSERT INTO booking_holds (
+			property_id,
+			guest_id,
+			check_in_date,
+			check_out_date,
+			expires_at,
+			created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	var id string
+	if err := tx.QueryRowxContext(ctx, query,
+		hold.PropertyID,
+		hold.GuestID,
+		hold.CheckInDate,
+		hold.CheckOutDate,
+		hold.ExpiresAt,
+		hold.CreatedAt,
+	).Scan(&id); err != nil {
+		return "", fmt.Errorf("failed to create booking hold: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit booking hold: %v", err)
+	}
+
+	return id, nil
+}
+
+// ExpireBookingHolds deletes the holds that expired at now and returns how many were deleted. Expired holds already
+// stop blocking the dates, so this only keeps the table small.
+func (r *Repository) ExpireBookingHolds(ctx context.Context, now time.Time) (int64, error) {
+	slog.Info("expiring booking holds", "now", now)
+	res, err := r.db.ExecContext(ctx, `DELETE FROM booking_holds WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire booking holds: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	return rows, nil
+}
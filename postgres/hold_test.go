@@ -0,0 +1,121 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookingHolds(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	hostID := uuid.New().String()
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             hostID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	hold := reserv.BookingHold{
+		PropertyID:   propertyID,
+		GuestID:      "guest-1",
+		CheckInDate:  time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC),
+		ExpiresAt:    now.Add(15 * time.Minute),
+		CreatedAt:    now,
+	}
+
+	id, err := repo.CreateBookingHold(ctx, hold)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+
+	// Another guest can't hold or book the held dates.
+	other := hold
+	other.GuestID = "guest-2"
+	_, err = repo.CreateBookingHold(ctx, other)
+	require.ErrorIs(t, err, reserv.ErrBookingOverlap)
+
+	booking := reserv.Booking{
+		PropertyID:      propertyID,
+		GuestID:         "guest-2",
+		CheckInDate:     time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC),
+		CheckOutDate:    time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC),
+		TotalPriceCents: 30000,
+		Currency:        "USD",
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	_, err = repo.CreateBooking(ctx, booking)
+	require.ErrorIs(t, err, reserv.ErrBookingOverlap)
+
+	occupancy, err := repo.Occupancy(ctx, propertyID, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, []reserv.Occupancy{{Kind: reserv.OccupancyHold, StartDate: hold.CheckInDate, EndDate: hold.CheckOutDate}}, occupancy)
+
+	// The guest who holds the dates can book them, releasing the hold.
+	booking.GuestID = "guest-1"
+	booking.CheckInDate = hold.CheckInDate
+	booking.CheckOutDate = hold.CheckOutDate
+	_, err = repo.CreateBooking(ctx, booking)
+	require.NoError(t, err)
+
+	occupancy, err = repo.Occupancy(ctx, propertyID, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, occupancy, 1)
+	require.Equal(t, reserv.OccupancyBooking, occupancy[0].Kind)
+
+	// Expired holds don't block the dates, even before they are deleted.
+	expired := hold
+	expired.CheckInDate = time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	expired.CheckOutDate = time.Date(2025, 4, 3, 0, 0, 0, 0, time.UTC)
+	expired.ExpiresAt = now.Add(-time.Minute)
+	expired.CreatedAt = now.Add(-16 * time.Minute)
+	_, err = repo.CreateBookingHold(ctx, expired)
+	require.NoError(t, err)
+
+	booking.GuestID = "guest-2"
+	booking.CheckInDate = expired.CheckInDate
+	booking.CheckOutDate = expired.CheckOutDate
+	_, err = repo.CreateBooking(ctx, booking)
+	require.NoError(t, err)
+
+	deleted, err := repo.ExpireBookingHolds(ctx, now)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	// Holding the same dates again refreshes the hold, and a guest holds a limited number of stays.
+	limited := hold
+	limited.GuestID = "guest-3"
+	for i := 0; i < reserv.MaxActiveHoldsPerGuest; i++ {
+		limited.CheckInDate = time.Date(2025, 5, 1+i*5, 0, 0, 0, 0, time.UTC)
+		limited.CheckOutDate = limited.CheckInDate.AddDate(0, 0, 2)
+		_, err = repo.CreateBookingHold(ctx, limited)
+		require.NoError(t, err)
+		_, err = repo.CreateBookingHold(ctx, limited)
+		require.NoError(t, err)
+	}
+
+	limited.CheckInDate = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	limited.CheckOutDate = limited.CheckInDate.AddDate(0, 0, 2)
+	_, err = repo.CreateBookingHold(ctx, limited)
+	require.ErrorIs(t, err, reserv.ErrTooManyHolds)
+
+	require.NoError(t, repo.DeleteProperty(ctx, propertyID))
+}
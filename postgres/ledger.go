@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/perebaj/reserv"
+)
+
+// postJournalEntry validates and stores a journal entry with its postings in tx, creating the accounts the first time
+// they are used. The entry is posted only if the rest of the transaction commits, so money never moves in the ledger
+// without the change of the booking or payment that moved it.
+func postJournalEntry(ctx context.Context, tx *sqlx.Tx, entry reserv.JournalEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO journal_entries (kind, booking_id, payment_id, payout_id, currency, description, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var id string
+	if err := tx.QueryRowxContext(ctx, query,
+		entry.Kind,
+		entry.BookingID,
+		entry.PaymentID,
+		entry.PayoutID,
+		entry.Currency,
+		entry.Description,
+		entry.CreatedAt,
+	).Scan(&id); err != nil {
+		return fmt.Errorf("failed to create journal entry: %v", err)
+	}
+
+	for i, posting := range entry.Postings {
+		accountID, err := account(ctx, tx, posting.Account, posting.HostID, entry.Currency, entry.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO postings (journal_entry_id, position, account_id, amount_cents) VALUES ($1, $2, $3, $4)
+		`
+		if _, err := tx.ExecContext(ctx, query, id, i, accountID, posting.AmountCents); err != nil {
+			return fmt.Errorf("failed to create posting: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// account returns the id of the account of code, hostID and currency, creating it if it doesn't exist. The upsert
+// waits for a concurrent creation of the same account instead of failing, and returns its id.
+func account(ctx context.Context, tx *sqlx.Tx, code reserv.AccountCode, hostID, currency string, now time.Time) (string, error) {
+	query := `
+		INSERT INTO accounts (code, host_id, currency, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (code, host_id, currency) DO UPDATE SET code = EXCLUDED.code
+		RETURNING id
+	`
+
+	var id string
+	if err := tx.QueryRowxContext(ctx, query, code, hostID, currency, now).Scan(&id); err != nil {
+		return "", fmt.Errorf("failed to get account: %v", err)
+	}
+	return id, nil
+}
+
+// HostBalances returns what the platform owes a host, one balance per currency the host earned in. Hosts without
+// earnings have no balances.
+func (r *Repository) HostBalances(ctx context.Context, hostID string) ([]reserv.HostBalance, error) {
+	slog.Info("getting host balances", "host_id", hostID)
+	query := `
+		SELECT a.host_id, a.currency, -COALESCE(SUM(p.amount_cents), 0) AS balance_cents
+		FROM accounts a
+		LEFT JOIN postings p ON p.account_id = a.id
+		WHERE a.code = $1 AND a.host_id = $2
+		GROUP BY a.host_id, a.currency
+		ORDER BY a.currency
+	`
+
+	var balances []reserv.HostBalance
+	if err := r.db.SelectContext(ctx, &balances, query, reserv.AccountHostPayable, hostID); err != nil {
+		return nil, fmt.Errorf("failed to get host balances: %v", err)
+	}
+	return balances, nil
+}
+
+// Reconciliation builds the reconciliation report of the ledger at now: the debits and credits of each currency, the
+// balance of each account code and the payments whose net amount differs from the cash posted for them.
+func (r *Repository) Reconciliation(ctx context.Context, now time.Time) (reserv.ReconciliationReport, error) {
+	slog.Info("reconciling ledger")
+	query := `
+		SELECT
+			a.currency,
+			COALESCE(SUM(p.amount_cents) FILTER (WHERE p.amount_cents > 0), 0) AS debits_cents,
+			-COALESCE(SUM(p.amount_cents) FILTER (WHERE p.amount_cents < 0), 0) AS credits_cents
+		FROM postings p
+		JOIN accounts a ON a.id = p.account_id
+		GROUP BY a.currency
+		ORDER BY a.currency
+	`
+
+	var trial []reserv.TrialBalance
+	if err := r.db.SelectContext(ctx, &trial, query); err != nil {
+		return reserv.ReconciliationReport{}, fmt.Errorf("failed to get trial balances: %v", err)
+	}
+
+	query = `
+		SELECT a.code AS account, a.currency, COALESCE(SUM(p.amount_cents), 0) AS balance_cents
+		FROM accounts a
+		LEFT JOIN postings p ON p.account_id = a.id
+		GROUP BY a.code, a.currency
+		ORDER BY a.currency, a.code
+	`
+
+	var balances []reserv.AccountBalance
+	if err := r.db.SelectContext(ctx, &balances, query); err != nil {
+		return reserv.ReconciliationReport{}, fmt.Errorf("failed to get account balances: %v", err)
+	}
+	for i, balance := range balances {
+		balances[i].BalanceCents = balance.Account.Balance(balance.BalanceCents)
+	}
+
+	// Only captured and refunded payments moved money. Their cash is what was captured less what was refunded.
+	query = `
+		WITH ledger AS (
+			SELECT e.payment_id, SUM(p.amount_cents) AS cash_cents
+			FROM journal_entries e
+			JOIN postings p ON p.journal_entry_id = e.id
+			JOIN accounts a ON a.id = p.account_id
+			WHERE a.code = $1 AND e.payment_id IS NOT NULL
+			GROUP BY e.payment_id
+		)
+		SELECT
+			pay.id AS payment_id,
+			pay.booking_id,
+			pay.status,
+			pay.currency,
+			CASE WHEN pay.status IN ($2, $3) THEN pay.amount_cents - pay.refunded_cents ELSE 0 END AS payment_cents,
+			COALESCE(l.cash_cents, 0) AS ledger_cents
+		FROM payments pay
+		LEFT JOIN ledger l ON l.payment_id = pay.id
+		WHERE CASE WHEN pay.status IN ($2, $3) THEN pay.amount_cents - pay.refunded_cents ELSE 0 END <> COALESCE(l.cash_cents, 0)
+		ORDER BY pay.created_at
+	`
+
+	var discrepancies []reserv.PaymentDiscrepancy
+	if err := r.db.SelectContext(ctx, &discrepancies, query,
+		reserv.AccountCash,
+		reserv.PaymentStatusCaptured,
+		reserv.PaymentStatusRefunded,
+	); err != nil {
+		return reserv.ReconciliationReport{}, fmt.Errorf("failed to get payment discrepancies: %v", err)
+	}
+
+	return reserv.NewReconciliationReport(trial, balances, discrepancies, now), nil
+}
@@ -0,0 +1,136 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedger(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	hostID := uuid.New().String()
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             hostID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	booking := reserv.Booking{
+		PropertyID:      propertyID,
+		GuestID:         "guest-1",
+		Status:          reserv.BookingStatusConfirmed,
+		CheckInDate:     time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate:    time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC),
+		TotalPriceCents: 34500,
+		Currency:        "USD",
+		LineItems: []reserv.QuoteLine{
+			{Kind: reserv.QuoteLineNightly, Description: "3 nights", AmountCents: 30000},
+			{Kind: reserv.QuoteLineServiceFee, Description: "Service fee (10%)", AmountCents: 3000},
+			{Kind: reserv.QuoteLineTax, Description: "NY sales tax (5%)", AmountCents: 1500},
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	booking.ID, err = repo.CreateBooking(ctx, booking)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	payment := reserv.Payment{
+		BookingID:         booking.ID,
+		Provider:          "fake",
+		ProviderPaymentID: "fake_pi_1",
+		Status:            reserv.PaymentStatusAuthorized,
+		AmountCents:       34500,
+		Currency:          "USD",
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	payment.ID, err = repo.CreatePayment(ctx, payment)
+	require.NoError(t, err)
+
+	balances, err := repo.HostBalances(ctx, hostID)
+	require.NoError(t, err)
+	require.Empty(t, balances)
+
+	// The capture posts the payment once: a second capture of the same payment fails and posts nothing.
+	payment.Status = reserv.PaymentStatusCaptured
+	entry := reserv.PaymentEntry(payment, booking, hostID, now)
+	require.NoError(t, repo.UpdatePayment(ctx, payment, reserv.PaymentStatusAuthorized, &entry))
+	require.ErrorIs(t, repo.UpdatePayment(ctx, payment, reserv.PaymentStatusAuthorized, &entry), reserv.ErrInvalidPaymentTransition)
+
+	balances, err = repo.HostBalances(ctx, hostID)
+	require.NoError(t, err)
+	require.Equal(t, []reserv.HostBalance{{HostID: hostID, Currency: "USD", BalanceCents: 30000}}, balances)
+
+	// An unbalanced entry rolls back the cancellation.
+	unbalanced := reserv.CancellationEntry(payment, booking, hostID, 17250, now)
+	unbalanced.Postings[0].AmountCents++
+	err = repo.CancelBooking(ctx, booking.ID, reserv.BookingStatusConfirmed, reserv.BookingStatusCancelledByGuest, 17250, &unbalanced)
+	require.ErrorIs(t, err, reserv.ErrInvalidJournalEntry)
+
+	_, got, err := repo.GetBooking(ctx, booking.ID)
+	require.NoError(t, err)
+	require.Equal(t, reserv.BookingStatusConfirmed, got.Status)
+
+	entry = reserv.CancellationEntry(payment, booking, hostID, 17250, now)
+	require.NoError(t, repo.CancelBooking(ctx, booking.ID, reserv.BookingStatusConfirmed, reserv.BookingStatusCancelledByGuest, 17250, &entry))
+
+	balances, err = repo.HostBalances(ctx, hostID)
+	require.NoError(t, err)
+	require.Equal(t, []reserv.HostBalance{{HostID: hostID, Currency: "USD", BalanceCents: 15000}}, balances)
+
+	// The refund is still owed to the guest, but the payment wasn't refunded yet, so the ledger matches the payments.
+	report, err := repo.Reconciliation(ctx, now)
+	require.NoError(t, err)
+	require.True(t, report.Balanced)
+	require.Equal(t, []reserv.TrialBalance{{Currency: "USD", DebitsCents: 51750, CreditsCents: 51750}}, report.TrialBalances)
+	require.Contains(t, report.Balances, reserv.AccountBalance{Account: reserv.AccountGuestRefundsPayable, Currency: "USD", BalanceCents: 17250})
+	require.Empty(t, report.Discrepancies)
+
+	// A refund stored without its entry shows up as a discrepancy.
+	payment.Status = reserv.PaymentStatusRefunded
+	payment.RefundedCents = 17250
+	require.NoError(t, repo.UpdatePayment(ctx, payment, reserv.PaymentStatusCaptured, nil))
+
+	report, err = repo.Reconciliation(ctx, now)
+	require.NoError(t, err)
+	require.False(t, report.Balanced)
+	require.Equal(t, []reserv.PaymentDiscrepancy{{
+		PaymentID:    payment.ID,
+		BookingID:    booking.ID,
+		Status:       reserv.PaymentStatusRefunded,
+		Currency:     "USD",
+		PaymentCents: 17250,
+		LedgerCents:  34500,
+	}}, report.Discrepancies)
+
+	// The ledger is append-only.
+	_, err = db.ExecContext(ctx, `DELETE FROM postings`)
+	require.Error(t, err)
+	_, err = db.ExecContext(ctx, `UPDATE journal_entries SET description = ''`)
+	require.Error(t, err)
+
+	// The payment was charged, so the property and its ledger are kept.
+	require.ErrorIs(t, repo.DeleteProperty(ctx, propertyID), reserv.ErrPropertyHasPayments)
+
+	balances, err = repo.HostBalances(ctx, hostID)
+	require.NoError(t, err)
+	require.Equal(t, []reserv.HostBalance{{HostID: hostID, Currency: "USD", BalanceCents: 15000}}, balances)
+}
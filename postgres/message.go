@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+// CreateThread creates a thread, unless the booking already has one or, for inquiries, the guest already has an
+// inquiry thread about the property. It returns the thread and whether it was created, or the existing thread.
+func (r *Repository) CreateThread(ctx context.Context, thread reserv.Thread) (reserv.Thread, bool, error) {
+	slog.Info("creating thread", "property_id", thread.PropertyID, "booking_id", thread.BookingID)
+
+	// The conflict target must match the partial unique index of the kind of the thread.
+	conflict := `(property_id, guest_id) WHERE booking_id IS NULL`
+	if thread.BookingID != nil {
+		conflict = `(booking_id) WHERE booking_id IS NOT NULL`
+	}
+
+	query := `
+		INSERT INTO threads (property_id, booking_id, guest_id, host_id, last_message_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT ` + conflict + ` DO NOTHING
+		RETURNING *
+	`
+
+	var created reserv.Thread
+	err := r.db.GetContext(ctx, &created, query,
+		thread.PropertyID,
+		thread.BookingID,
+		thread.GuestID,
+		thread.HostID,
+		thread.LastMessageAt,
+		thread.CreatedAt,
+	)
+	if err == nil {
+		return created, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return reserv.Thread{}, false, fmt.Errorf("failed to create thread: %v", err)
+	}
+
+	var existing reserv.Thread
+	if thread.BookingID != nil {
+		query = `SELECT * FROM threads WHERE booking_id = $1`
+		err = r.db.GetContext(ctx, &existing, query, *thread.BookingID)
+	} else {
+		query = `SELECT * FROM threads WHERE property_id = $1 AND guest_id = $2 AND booking_id IS NULL`
+		err = r.db.GetContext(ctx, &existing, query, thread.PropertyID, thread.GuestID)
+	}
+	if err != nil {
+		return reserv.Thread{}, false, fmt.Errorf("failed to get existing thread: %v", err)
+	}
+	return existing, false, nil
+}
+
+// GetThread returns a thread. It returns reserv.ErrThreadNotFound if the thread doesn't exist.
+func (r *Repository) GetThread(ctx context.Context, id string) (reserv.Thread, error) {
+	slog.Info("getting thread", "id", id)
+	query := `
+		SELECT * FROM threads WHERE id = $1
+	`
+
+	var thread reserv.Thread
+	if err := r.db.GetContext(ctx, &thread, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return reserv.Thread{}, reserv.ErrThreadNotFound
+		}
+		return reserv.Thread{}, fmt.Errorf("failed to get thread: %v", err)
+	}
+	return thread, nil
+}
+
+// cursorArgs returns the time and id of the cursor of page, or nils for the first page.
+func cursorArgs(page reserv.Page) (*time.Time, *string) {
+	if page.After == nil {
+		return nil, nil
+	}
+	return &page.After.Time, &page.After.ID
+}
+
+// Threads returns a page of the threads where userID is the guest or the host, the most recent message first, with
+// how many messages of the other participant userID didn't read. It fetches one thread more than the limit of the
+// page, see reserv.NextPage.
+func (r *Repository) Threads(ctx context.Context, userID string, page reserv.Page) ([]reserv.Thread, error) {
+	slog.Info("getting threads", "user_id", userID)
+	query := `
+		SELECT
+			t.*,
+			(
+				SELECT COUNT(*) FROM messages m
+				WHERE m.thread_id = t.id AND m.sender_id <> $1 AND m.read_at IS NULL
+			) AS unread_count
+		FROM threads t
+		WHERE (t.guest_id = $1 OR t.host_id = $1)
+			AND ($2::timestamp IS NULL OR (t.last_message_at, t.id) < ($2::timestamp, $3::uuid))
+		ORDER BY t.last_message_at DESC, t.id DESC
+		LIMIT $4
+	`
+
+	afterTime, afterID := cursorArgs(page)
+	var threads []reserv.Thread
+	if err := r.db.SelectContext(ctx, &threads, query, userID, afterTime, afterID, page.Limit+1); err != nil {
+		return nil, fmt.Errorf("failed to get threads: %v", err)
+	}
+	return threads, nil
+}
+
+// CreateMessage stores a message and moves its thread to the top of the lists of threads. It returns the message
+// with its id.
+func (r *Repository) CreateMessage(ctx context.Context, message reserv.Message) (reserv.Message, error) {
+	slog.Info("creating message", "thread_id", message.ThreadID, "sender_id", message.SenderID)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return reserv.Message{}, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		INSERT INTO messages (thread_id, sender_id, body, created_at) VALUES ($1, $2, $3, $4)
+		RETURNING *
+	`
+
+	var created reserv.Message
+	if err := tx.GetContext(ctx, &created, query, message.ThreadID, message.SenderID, message.Body, message.CreatedAt); err != nil {
+		return reserv.Message{}, fmt.Errorf("failed to create message: %v", err)
+	}
+
+	query = `
+		UPDATE threads SET last_message_at = $2 WHERE id = $1 AND last_message_at < $2
+	`
+
+	if _, err := tx.ExecContext(ctx, query, message.ThreadID, created.CreatedAt); err != nil {
+		return reserv.Message{}, fmt.Errorf("failed to update thread: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return reserv.Message{}, fmt.Errorf("failed to commit message: %v", err)
+	}
+
+	return created, nil
+}
+
+// Messages returns a page of the messages of a thread, the newest first. It fetches one message more than the limit
+// of the page, see reserv.NextPage.
+func (r *Repository) Messages(ctx context.Context, threadID string, page reserv.Page) ([]reserv.Message, error) {
+	slog.Info("getting messages", "thread_id", threadID)
+	query := `
+		SELECT * FROM messages
+		WHERE thread_id = $1
+			AND ($2::timestamp IS NULL OR (created_at, id) < ($2::timestamp, $3::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`
+
+	afterTime, afterID := cursorArgs(page)
+	var messages []reserv.Message
+	if err := r.db.SelectContext(ctx, &messages, query, threadID, afterTime, afterID, page.Limit+1); err != nil {
+		return nil, fmt.Errorf("failed to get messages: %v", err)
+	}
+	return messages, nil
+}
+
+// MarkThreadRead marks the messages of a thread sent to readerID as read at now. It returns how many messages were
+// unread.
+func (r *Repository) MarkThreadRead(ctx context.Context, threadID, readerID string, now time.Time) (int64, error) {
+	slog.Info("marking thread read", "thread_id", threadID, "reader_id", readerID)
+	query := `
+		UPDATE messages SET read_at = $3 WHERE thread_id = $1 AND sender_id <> $2 AND read_at IS NULL
+	`
+
+	res, err := r.db.ExecContext(ctx, query, threadID, readerID, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark messages read: %v", err)
+	}
+
+	read, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return read, nil
+}
@@ -0,0 +1,143 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessages(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	hostID := uuid.New().String()
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             hostID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	bookingID, err := repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:      propertyID,
+		GuestID:         "guest",
+		Status:          reserv.BookingStatusConfirmed,
+		CheckInDate:     time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate:    time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC),
+		TotalPriceCents: 30000,
+		Currency:        "USD",
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+	inquiry, created, err := repo.CreateThread(ctx, reserv.Thread{PropertyID: propertyID, GuestID: "guest", HostID: hostID, LastMessageAt: now, CreatedAt: now})
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Nil(t, inquiry.BookingID)
+
+	// A guest has a single inquiry per property.
+	again, created, err := repo.CreateThread(ctx, reserv.Thread{PropertyID: propertyID, GuestID: "guest", HostID: hostID, LastMessageAt: now, CreatedAt: now})
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, inquiry.ID, again.ID)
+
+	// The booking has its own thread, and a single one.
+	bookingThread, created, err := repo.CreateThread(ctx, reserv.Thread{PropertyID: propertyID, BookingID: &bookingID, GuestID: "guest", HostID: hostID, LastMessageAt: now, CreatedAt: now})
+	require.NoError(t, err)
+	require.True(t, created)
+	require.NotEqual(t, inquiry.ID, bookingThread.ID)
+	require.Equal(t, bookingID, *bookingThread.BookingID)
+
+	again, created, err = repo.CreateThread(ctx, reserv.Thread{PropertyID: propertyID, BookingID: &bookingID, GuestID: "guest", HostID: hostID, LastMessageAt: now, CreatedAt: now})
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, bookingThread.ID, again.ID)
+
+	got, err := repo.GetThread(ctx, inquiry.ID)
+	require.NoError(t, err)
+	require.Equal(t, hostID, got.HostID)
+
+	_, err = repo.GetThread(ctx, uuid.New().String())
+	require.ErrorIs(t, err, reserv.ErrThreadNotFound)
+
+	// Three messages in the inquiry: the guest asks twice and the host answers.
+	for i, m := range []struct{ sender, body string }{{"guest", "Hi"}, {"guest", "Is early check-in possible?"}, {hostID, "Sure"}} {
+		message, err := repo.CreateMessage(ctx, reserv.Message{
+			ThreadID:  inquiry.ID,
+			SenderID:  m.sender,
+			Body:      m.body,
+			CreatedAt: now.Add(time.Duration(i+1) * time.Minute),
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, message.ID)
+	}
+
+	// The inquiry has the most recent message, so it comes first.
+	threads, err := repo.Threads(ctx, hostID, reserv.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, threads, 2)
+	require.Equal(t, inquiry.ID, threads[0].ID)
+	require.Equal(t, 0, threads[0].UnreadCount)
+	require.True(t, now.Add(3*time.Minute).Equal(threads[0].LastMessageAt))
+
+	threads, err = repo.Threads(ctx, "guest", reserv.Page{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, threads, 2, "the page has one thread more than the limit")
+	require.Equal(t, 1, threads[0].UnreadCount)
+
+	cursor := threads[0].Cursor()
+	threads, err = repo.Threads(ctx, "guest", reserv.Page{Limit: 1, After: &cursor})
+	require.NoError(t, err)
+	require.Len(t, threads, 1)
+	require.Equal(t, bookingThread.ID, threads[0].ID)
+
+	threads, err = repo.Threads(ctx, "other", reserv.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, threads)
+
+	messages, err := repo.Messages(ctx, inquiry.ID, reserv.Page{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+	require.Equal(t, "Sure", messages[0].Body)
+
+	cursor = messages[1].Cursor()
+	messages, err = repo.Messages(ctx, inquiry.ID, reserv.Page{Limit: 2, After: &cursor})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	require.Equal(t, "Hi", messages[0].Body)
+
+	// The host reads the two messages of the guest, not their own.
+	read, err := repo.MarkThreadRead(ctx, inquiry.ID, hostID, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), read)
+
+	read, err = repo.MarkThreadRead(ctx, inquiry.ID, hostID, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, read)
+
+	messages, err = repo.Messages(ctx, inquiry.ID, reserv.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Nil(t, messages[0].ReadAt)
+	require.NotNil(t, messages[1].ReadAt)
+
+	// Deleting the property deletes its threads.
+	require.NoError(t, repo.DeleteProperty(ctx, propertyID))
+	_, err = repo.GetThread(ctx, inquiry.ID)
+	require.ErrorIs(t, err, reserv.ErrThreadNotFound)
+}
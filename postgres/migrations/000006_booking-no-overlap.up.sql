@@ -0,0 +1,13 @@
+-- btree_gist is required to mix the equality operator (property_id) with the range operator (dates) in the same
+-- exclusion constraint.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- The range is inclusive on both sides to keep the same semantics of the previous overlap check: a booking that
+-- checks in on the same day that another one checks out is rejected.
+ALTER TABLE
+    bookings
+ADD
+    CONSTRAINT bookings_no_overlap EXCLUDE USING gist (
+        property_id WITH =,
+        daterange(check_in_date, check_out_date, '[]') WITH &&
+    );
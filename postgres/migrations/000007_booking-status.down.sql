@@ -0,0 +1,16 @@
+ALTER TABLE
+    bookings DROP CONSTRAINT bookings_no_overlap;
+
+ALTER TABLE
+    bookings
+ADD
+    CONSTRAINT bookings_no_overlap EXCLUDE USING gist (
+        property_id WITH =,
+        daterange(check_in_date, check_out_date, '[]') WITH &&
+    );
+
+ALTER TABLE
+    bookings DROP CONSTRAINT bookings_status_check;
+
+ALTER TABLE
+    bookings DROP COLUMN status;
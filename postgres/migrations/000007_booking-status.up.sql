@@ -0,0 +1,36 @@
+-- Existing bookings went straight in, so they are considered confirmed.
+ALTER TABLE
+    bookings
+ADD
+    COLUMN status TEXT NOT NULL DEFAULT 'confirmed';
+
+ALTER TABLE
+    bookings
+ADD
+    CONSTRAINT bookings_status_check CHECK (
+        status IN (
+            'pending',
+            'confirmed',
+            'cancelled_by_guest',
+            'cancelled_by_host',
+            'checked_in',
+            'completed',
+            'no_show'
+        )
+    );
+
+-- Cancelled bookings must not block the property dates anymore.
+ALTER TABLE
+    bookings DROP CONSTRAINT bookings_no_overlap;
+
+ALTER TABLE
+    bookings
+ADD
+    CONSTRAINT bookings_no_overlap EXCLUDE USING gist (
+        property_id WITH =,
+        daterange(check_in_date, check_out_date, '[]') WITH &&
+    )
+WHERE
+    (
+        status NOT IN ('cancelled_by_guest', 'cancelled_by_host')
+    );
@@ -0,0 +1,13 @@
+CREATE TABLE calendar_blocks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    property_id UUID NOT NULL REFERENCES properties(id),
+    -- start_date and end_date are both inclusive, following the same semantics of the bookings overlap check.
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT calendar_blocks_dates_check CHECK (end_date >= start_date)
+);
+
+CREATE INDEX calendar_blocks_property_id_idx ON calendar_blocks (property_id);
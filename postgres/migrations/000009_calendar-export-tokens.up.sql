@@ -0,0 +1,7 @@
+-- calendar_export_tokens keeps the secret token of the iCalendar export feed of each property.
+-- External calendar fetchers can't send bearer tokens, so the token goes in the feed URL.
+CREATE TABLE calendar_export_tokens (
+    property_id UUID PRIMARY KEY REFERENCES properties(id),
+    token TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
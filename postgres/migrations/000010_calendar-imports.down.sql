@@ -0,0 +1,4 @@
+DELETE FROM calendar_blocks WHERE import_id IS NOT NULL;
+ALTER TABLE calendar_blocks DROP COLUMN external_uid;
+ALTER TABLE calendar_blocks DROP COLUMN import_id;
+DROP TABLE calendar_imports;
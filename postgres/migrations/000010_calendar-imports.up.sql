@@ -0,0 +1,20 @@
+CREATE TABLE calendar_imports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    property_id UUID NOT NULL REFERENCES properties(id),
+    name TEXT NOT NULL,
+    -- url is empty for imports synced only by uploading files.
+    url TEXT NOT NULL DEFAULT '',
+    last_sync_at TIMESTAMP,
+    last_sync_error TEXT NOT NULL DEFAULT '',
+    -- conflicts are the events of the last sync that overlap local bookings.
+    conflicts JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX calendar_imports_property_id_idx ON calendar_imports (property_id);
+
+ALTER TABLE calendar_blocks ADD COLUMN import_id UUID REFERENCES calendar_imports(id);
+ALTER TABLE calendar_blocks ADD COLUMN external_uid TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX calendar_blocks_import_id_idx ON calendar_blocks (import_id);
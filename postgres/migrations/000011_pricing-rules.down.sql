@@ -0,0 +1 @@
+DROP TABLE pricing_rules;
@@ -0,0 +1,19 @@
+CREATE TABLE pricing_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    property_id UUID NOT NULL REFERENCES properties(id),
+    name TEXT NOT NULL,
+    -- start_date and end_date are both inclusive and optional.
+    start_date DATE,
+    end_date DATE,
+    -- weekdays is a bitmask where Sunday is the bit 0. Zero means every day.
+    weekdays SMALLINT NOT NULL DEFAULT 0,
+    priority INT NOT NULL DEFAULT 0,
+    price_cents BIGINT,
+    adjustment_percent INT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT pricing_rules_dates_check CHECK (end_date >= start_date),
+    CONSTRAINT pricing_rules_price_check CHECK ((price_cents IS NULL) <> (adjustment_percent IS NULL))
+);
+
+CREATE INDEX pricing_rules_property_id_idx ON pricing_rules (property_id);
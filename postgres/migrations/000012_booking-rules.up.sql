@@ -0,0 +1,12 @@
+-- booking_rules keeps the restrictions of each property on the stays. Properties without a row use the default rules.
+CREATE TABLE booking_rules (
+    property_id UUID PRIMARY KEY REFERENCES properties(id),
+    min_nights INT NOT NULL,
+    max_nights INT NOT NULL,
+    advance_notice_days INT NOT NULL,
+    booking_horizon_days INT NOT NULL,
+    -- check_in_weekdays is a bitmask where Sunday is the bit 0. Zero means every day.
+    check_in_weekdays SMALLINT NOT NULL DEFAULT 0,
+    preparation_days INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL
+);
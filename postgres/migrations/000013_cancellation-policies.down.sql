@@ -0,0 +1,4 @@
+ALTER TABLE bookings DROP COLUMN cancelled_at;
+ALTER TABLE bookings DROP COLUMN refund_cents;
+
+DROP TABLE cancellation_policies;
@@ -0,0 +1,11 @@
+-- cancellation_policies keeps the refund rules of each property. Properties without a row use the flexible policy.
+CREATE TABLE cancellation_policies (
+    property_id UUID PRIMARY KEY REFERENCES properties(id),
+    kind TEXT NOT NULL,
+    -- tiers is a list of {"days_before_check_in", "refund_percent"} objects.
+    tiers JSONB NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE bookings ADD COLUMN refund_cents INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN cancelled_at TIMESTAMP;
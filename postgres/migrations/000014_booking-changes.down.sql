@@ -0,0 +1 @@
+DROP TABLE booking_changes;
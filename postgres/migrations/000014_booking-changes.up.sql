@@ -0,0 +1,15 @@
+-- booking_changes is the append only history of date changes of the bookings.
+CREATE TABLE booking_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    booking_id UUID NOT NULL REFERENCES bookings(id),
+    changed_by TEXT NOT NULL,
+    previous_check_in_date DATE NOT NULL,
+    previous_check_out_date DATE NOT NULL,
+    previous_total_price_cents INTEGER NOT NULL,
+    check_in_date DATE NOT NULL,
+    check_out_date DATE NOT NULL,
+    total_price_cents INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX booking_changes_booking_id_idx ON booking_changes (booking_id);
@@ -0,0 +1,12 @@
+ALTER TABLE bookings DROP COLUMN pets;
+ALTER TABLE bookings DROP COLUMN infants;
+ALTER TABLE bookings DROP COLUMN children;
+ALTER TABLE bookings DROP COLUMN adults;
+
+ALTER TABLE properties DROP COLUMN extra_guest_fee_cents;
+ALTER TABLE properties DROP COLUMN guests_included;
+ALTER TABLE properties DROP COLUMN max_pets;
+ALTER TABLE properties DROP COLUMN bathrooms;
+ALTER TABLE properties DROP COLUMN beds;
+ALTER TABLE properties DROP COLUMN bedrooms;
+ALTER TABLE properties DROP COLUMN max_guests;
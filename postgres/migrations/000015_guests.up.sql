@@ -0,0 +1,13 @@
+-- Existing properties accept a single guest and include it in the nightly price until the host updates them.
+ALTER TABLE properties ADD COLUMN max_guests INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE properties ADD COLUMN bedrooms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE properties ADD COLUMN beds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE properties ADD COLUMN bathrooms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE properties ADD COLUMN max_pets INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE properties ADD COLUMN guests_included INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE properties ADD COLUMN extra_guest_fee_cents INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE bookings ADD COLUMN adults INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE bookings ADD COLUMN children INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN infants INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN pets INTEGER NOT NULL DEFAULT 0;
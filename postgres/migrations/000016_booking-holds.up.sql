@@ -0,0 +1,14 @@
+-- booking_holds reserves dates for a guest during the checkout. Holds stop blocking the dates at expires_at, even
+-- before the expiry worker deletes them.
+CREATE TABLE booking_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    property_id UUID NOT NULL REFERENCES properties(id),
+    guest_id TEXT NOT NULL,
+    check_in_date DATE NOT NULL,
+    check_out_date DATE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX booking_holds_property_id_idx ON booking_holds (property_id);
+CREATE INDEX booking_holds_expires_at_idx ON booking_holds (expires_at);
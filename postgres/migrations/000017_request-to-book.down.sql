@@ -0,0 +1,48 @@
+-- Declined requests never got to the guest, so they are kept as cancelled by the host.
+UPDATE
+    bookings
+SET
+    status = 'cancelled_by_host'
+WHERE
+    status = 'declined';
+
+ALTER TABLE
+    bookings DROP CONSTRAINT bookings_no_overlap;
+
+ALTER TABLE
+    bookings
+ADD
+    CONSTRAINT bookings_no_overlap EXCLUDE USING gist (
+        property_id WITH =,
+        daterange(check_in_date, check_out_date, '[]') WITH &&
+    )
+WHERE
+    (
+        status NOT IN ('cancelled_by_guest', 'cancelled_by_host')
+    );
+
+ALTER TABLE
+    bookings DROP CONSTRAINT bookings_status_check;
+
+ALTER TABLE
+    bookings
+ADD
+    CONSTRAINT bookings_status_check CHECK (
+        status IN (
+            'pending',
+            'confirmed',
+            'cancelled_by_guest',
+            'cancelled_by_host',
+            'checked_in',
+            'completed',
+            'no_show'
+        )
+    );
+
+DROP INDEX bookings_pending_respond_by_idx;
+
+ALTER TABLE
+    bookings DROP COLUMN respond_by;
+
+ALTER TABLE
+    properties DROP COLUMN instant_book;
@@ -0,0 +1,50 @@
+-- Existing properties keep confirming their bookings right away.
+ALTER TABLE
+    properties
+ADD
+    COLUMN instant_book BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- respond_by is the deadline for the host to answer a booking request. It is NULL for instantly booked stays.
+ALTER TABLE
+    bookings
+ADD
+    COLUMN respond_by TIMESTAMP;
+
+CREATE INDEX bookings_pending_respond_by_idx ON bookings (respond_by)
+WHERE
+    status = 'pending';
+
+ALTER TABLE
+    bookings DROP CONSTRAINT bookings_status_check;
+
+ALTER TABLE
+    bookings
+ADD
+    CONSTRAINT bookings_status_check CHECK (
+        status IN (
+            'pending',
+            'confirmed',
+            'cancelled_by_guest',
+            'cancelled_by_host',
+            'checked_in',
+            'completed',
+            'no_show',
+            'declined'
+        )
+    );
+
+-- Declined requests must not block the property dates.
+ALTER TABLE
+    bookings DROP CONSTRAINT bookings_no_overlap;
+
+ALTER TABLE
+    bookings
+ADD
+    CONSTRAINT bookings_no_overlap EXCLUDE USING gist (
+        property_id WITH =,
+        daterange(check_in_date, check_out_date, '[]') WITH &&
+    )
+WHERE
+    (
+        status NOT IN ('cancelled_by_guest', 'cancelled_by_host', 'declined')
+    );
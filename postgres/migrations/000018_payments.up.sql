@@ -0,0 +1,27 @@
+CREATE TABLE payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    booking_id UUID NOT NULL REFERENCES bookings(id),
+    provider TEXT NOT NULL,
+    provider_payment_id TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (
+        status IN (
+            'pending',
+            'authorized',
+            'captured',
+            'refunded',
+            'voided',
+            'failed'
+        )
+    ),
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    refunded_cents BIGINT NOT NULL DEFAULT 0,
+    currency TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT payments_provider_payment_id_key UNIQUE (provider, provider_payment_id)
+);
+
+-- A booking is paid once. Failed and voided payments can be retried, refunded payments are kept for the history.
+CREATE UNIQUE INDEX payments_booking_id_active_idx ON payments (booking_id)
+WHERE
+    status IN ('pending', 'authorized', 'captured', 'refunded');
@@ -0,0 +1,10 @@
+ALTER TABLE payments DROP CONSTRAINT payments_currency_check;
+ALTER TABLE bookings DROP CONSTRAINT bookings_currency_check;
+ALTER TABLE properties DROP CONSTRAINT properties_currency_check;
+
+ALTER TABLE booking_changes ALTER COLUMN total_price_cents TYPE INTEGER;
+ALTER TABLE booking_changes ALTER COLUMN previous_total_price_cents TYPE INTEGER;
+ALTER TABLE bookings ALTER COLUMN refund_cents TYPE INTEGER;
+ALTER TABLE bookings ALTER COLUMN total_price_cents TYPE INTEGER;
+ALTER TABLE properties ALTER COLUMN extra_guest_fee_cents TYPE INTEGER;
+ALTER TABLE properties ALTER COLUMN price_per_night_cents TYPE INTEGER;
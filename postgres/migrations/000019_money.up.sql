@@ -0,0 +1,16 @@
+-- Amounts are int64 minor units in the code, so the columns that were INTEGER are widened to BIGINT.
+ALTER TABLE properties ALTER COLUMN price_per_night_cents TYPE BIGINT;
+ALTER TABLE properties ALTER COLUMN extra_guest_fee_cents TYPE BIGINT;
+ALTER TABLE bookings ALTER COLUMN total_price_cents TYPE BIGINT;
+ALTER TABLE bookings ALTER COLUMN refund_cents TYPE BIGINT;
+ALTER TABLE booking_changes ALTER COLUMN previous_total_price_cents TYPE BIGINT;
+ALTER TABLE booking_changes ALTER COLUMN total_price_cents TYPE BIGINT;
+
+-- Currencies are ISO 4217 codes in upper case. Lower case codes, like "usd", are fixed. Other invalid values, like
+-- "dollar", can't be guessed, so the constraints are NOT VALID: they apply to new and updated rows only.
+UPDATE properties SET currency = UPPER(currency) WHERE currency <> UPPER(currency);
+UPDATE bookings SET currency = UPPER(currency) WHERE currency <> UPPER(currency);
+
+ALTER TABLE properties ADD CONSTRAINT properties_currency_check CHECK (currency ~ '^[A-Z]{3}$') NOT VALID;
+ALTER TABLE bookings ADD CONSTRAINT bookings_currency_check CHECK (currency ~ '^[A-Z]{3}$') NOT VALID;
+ALTER TABLE payments ADD CONSTRAINT payments_currency_check CHECK (currency ~ '^[A-Z]{3}$') NOT VALID;
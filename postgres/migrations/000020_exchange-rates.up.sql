@@ -0,0 +1,8 @@
+-- The exchange rates are replaced as a whole by the exchangerates command. They are only used to display prices.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base_currency TEXT NOT NULL,
+    quote_currency TEXT NOT NULL,
+    rate NUMERIC NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base_currency, quote_currency)
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS booking_line_items;
+DROP TABLE IF EXISTS tax_rules;
+
+ALTER TABLE properties DROP COLUMN jurisdiction;
+ALTER TABLE properties DROP COLUMN cleaning_fee_cents;
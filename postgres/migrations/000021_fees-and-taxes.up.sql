@@ -0,0 +1,27 @@
+ALTER TABLE properties ADD COLUMN cleaning_fee_cents BIGINT NOT NULL DEFAULT 0 CHECK (cleaning_fee_cents >= 0);
+ALTER TABLE properties ADD COLUMN jurisdiction TEXT NOT NULL DEFAULT '';
+
+-- tax_rules are the occupancy taxes of each jurisdiction. A rule of "US-NY" also applies to "US-NY-NYC".
+CREATE TABLE tax_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    jurisdiction TEXT NOT NULL,
+    name TEXT NOT NULL,
+    rate_basis_points INT NOT NULL CHECK (rate_basis_points BETWEEN 1 AND 10000),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- booking_line_items are the lines of the quote used to price a booking. Their sum is the total price of the booking.
+CREATE TABLE booking_line_items (
+    booking_id UUID NOT NULL REFERENCES bookings(id),
+    position INT NOT NULL,
+    kind TEXT NOT NULL,
+    description TEXT NOT NULL,
+    amount_cents BIGINT NOT NULL,
+    source_id TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (booking_id, position)
+);
+
+-- Existing bookings were priced before the breakdown existed, so their whole price is kept as a single nightly line.
+INSERT INTO booking_line_items (booking_id, position, kind, description, amount_cents)
+SELECT id, 0, 'nightly', (check_out_date - check_in_date) || ' nights', total_price_cents FROM bookings;
@@ -0,0 +1,3 @@
+ALTER TABLE bookings DROP COLUMN promotion_id;
+
+DROP TABLE IF EXISTS promotions;
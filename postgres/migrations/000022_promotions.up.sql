@@ -0,0 +1,22 @@
+-- promotions are the promo codes managed by the platform admins. Redemptions is only increased while it is below
+-- max_redemptions, in the transaction that creates the booking, so concurrent bookings can't go over the limit.
+CREATE TABLE promotions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code TEXT NOT NULL,
+    percent_off INT CHECK (percent_off BETWEEN 1 AND 100),
+    amount_off_cents BIGINT CHECK (amount_off_cents > 0),
+    currency TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP,
+    max_redemptions INT NOT NULL DEFAULT 0 CHECK (max_redemptions >= 0),
+    redemptions INT NOT NULL DEFAULT 0,
+    min_nights INT NOT NULL DEFAULT 0 CHECK (min_nights >= 0),
+    property_ids TEXT[] NOT NULL DEFAULT '{}',
+    host_ids TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT promotions_code_key UNIQUE (code),
+    CONSTRAINT promotions_discount_check CHECK ((percent_off IS NULL) <> (amount_off_cents IS NULL)),
+    CONSTRAINT promotions_redemptions_check CHECK (max_redemptions = 0 OR redemptions <= max_redemptions)
+);
+
+ALTER TABLE bookings ADD COLUMN promotion_id UUID REFERENCES promotions(id);
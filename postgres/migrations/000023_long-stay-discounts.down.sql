@@ -0,0 +1,3 @@
+ALTER TABLE properties DROP CONSTRAINT properties_long_stay_discounts_check;
+ALTER TABLE properties DROP COLUMN monthly_discount_percent;
+ALTER TABLE properties DROP COLUMN weekly_discount_percent;
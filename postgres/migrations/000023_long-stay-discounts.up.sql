@@ -0,0 +1,5 @@
+-- The monthly discount replaces the weekly one for stays of 28 nights or more, so it can't be lower.
+ALTER TABLE properties ADD COLUMN weekly_discount_percent INT NOT NULL DEFAULT 0 CHECK (weekly_discount_percent BETWEEN 0 AND 99);
+ALTER TABLE properties ADD COLUMN monthly_discount_percent INT NOT NULL DEFAULT 0 CHECK (monthly_discount_percent BETWEEN 0 AND 99);
+ALTER TABLE properties ADD CONSTRAINT properties_long_stay_discounts_check
+    CHECK (monthly_discount_percent = 0 OR monthly_discount_percent >= weekly_discount_percent);
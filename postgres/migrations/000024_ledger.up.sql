@@ -0,0 +1,77 @@
+-- accounts are the accounts of the double-entry ledger. There is one account per code and currency, and the host
+-- payables have one per host as well. The other accounts have an empty host_id.
+CREATE TABLE accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code TEXT NOT NULL CHECK (
+        code IN (
+            'cash',
+            'host_payable',
+            'platform_revenue',
+            'taxes_payable',
+            'guest_refunds_payable'
+        )
+    ),
+    host_id TEXT NOT NULL DEFAULT '',
+    currency TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT accounts_code_host_id_currency_key UNIQUE (code, host_id, currency)
+);
+
+-- journal_entries are the movements of money. They keep the ids of their booking and payment without foreign keys,
+-- since the ledger outlives the deleted properties and their bookings.
+CREATE TABLE journal_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind TEXT NOT NULL CHECK (
+        kind IN (
+            'payment',
+            'cancellation',
+            'refund',
+            'payout'
+        )
+    ),
+    booking_id UUID,
+    payment_id UUID,
+    currency TEXT NOT NULL,
+    description TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX journal_entries_payment_id_idx ON journal_entries (payment_id);
+
+-- postings are the debits (positive) and credits (negative) of the journal entries.
+CREATE TABLE postings (
+    journal_entry_id UUID NOT NULL REFERENCES journal_entries(id),
+    position INT NOT NULL,
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    amount_cents BIGINT NOT NULL CHECK (amount_cents <> 0),
+    PRIMARY KEY (journal_entry_id, position)
+);
+
+CREATE INDEX postings_account_id_idx ON postings (account_id);
+
+-- The ledger is append-only: mistakes are fixed by posting new entries.
+CREATE FUNCTION ledger_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER journal_entries_append_only BEFORE UPDATE OR DELETE ON journal_entries
+FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+
+CREATE TRIGGER postings_append_only BEFORE UPDATE OR DELETE ON postings
+FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+
+-- The postings of an entry must sum to zero. The check runs at commit, once all the postings of the entry are inserted.
+CREATE FUNCTION postings_balanced() RETURNS trigger AS $$
+BEGIN
+    IF (SELECT SUM(amount_cents) FROM postings WHERE journal_entry_id = NEW.journal_entry_id) <> 0 THEN
+        RAISE EXCEPTION 'journal entry % is not balanced', NEW.journal_entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER postings_balanced AFTER INSERT ON postings
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION postings_balanced();
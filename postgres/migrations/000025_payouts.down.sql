@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS journal_entries_booking_id_idx;
+
+ALTER TABLE journal_entries DROP COLUMN IF EXISTS payout_id;
+
+DROP TABLE IF EXISTS payout_items;
+
+DROP TABLE IF EXISTS payouts;
@@ -0,0 +1,36 @@
+-- payouts are the batches of the earnings of a host in a currency, transferred at once by the payout provider.
+CREATE TABLE payouts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    host_id TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'paid')),
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    currency TEXT NOT NULL,
+    provider TEXT NOT NULL DEFAULT '',
+    provider_payout_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    paid_at TIMESTAMP
+);
+
+CREATE INDEX payouts_host_id_idx ON payouts (host_id, created_at);
+
+CREATE INDEX payouts_pending_idx ON payouts (created_at)
+WHERE
+    status = 'pending';
+
+-- payout_items are the bookings of the payouts. A booking is paid out once. Like the ledger, they keep a copy of the
+-- booking without foreign keys, so the statements outlive the deleted properties and their bookings.
+CREATE TABLE payout_items (
+    payout_id UUID NOT NULL REFERENCES payouts(id),
+    booking_id UUID NOT NULL,
+    property_id UUID NOT NULL,
+    check_in_date DATE NOT NULL,
+    check_out_date DATE NOT NULL,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    PRIMARY KEY (payout_id, booking_id),
+    CONSTRAINT payout_items_booking_id_key UNIQUE (booking_id)
+);
+
+ALTER TABLE journal_entries ADD COLUMN payout_id UUID;
+
+CREATE INDEX journal_entries_booking_id_idx ON journal_entries (booking_id);
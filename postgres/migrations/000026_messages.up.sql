@@ -0,0 +1,40 @@
+-- threads are the conversations between a guest and the host of a property, about a booking or, before booking, an
+-- inquiry about the property.
+CREATE TABLE threads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    property_id UUID NOT NULL REFERENCES properties(id),
+    booking_id UUID REFERENCES bookings(id),
+    guest_id TEXT NOT NULL,
+    host_id TEXT NOT NULL,
+    last_message_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    CHECK (guest_id <> host_id)
+);
+
+-- Each booking has one thread, and each guest has one inquiry thread per property.
+CREATE UNIQUE INDEX threads_booking_id_idx ON threads (booking_id)
+WHERE
+    booking_id IS NOT NULL;
+
+CREATE UNIQUE INDEX threads_inquiry_idx ON threads (property_id, guest_id)
+WHERE
+    booking_id IS NULL;
+
+CREATE INDEX threads_guest_id_idx ON threads (guest_id, last_message_at DESC, id DESC);
+
+CREATE INDEX threads_host_id_idx ON threads (host_id, last_message_at DESC, id DESC);
+
+CREATE TABLE messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    thread_id UUID NOT NULL REFERENCES threads(id),
+    sender_id TEXT NOT NULL,
+    body TEXT NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX messages_thread_id_idx ON messages (thread_id, created_at DESC, id DESC);
+
+CREATE INDEX messages_unread_idx ON messages (thread_id, sender_id)
+WHERE
+    read_at IS NULL;
@@ -0,0 +1,19 @@
+-- reviews are the reviews of the stays by the guests, after the check-out. Each booking has at most one review.
+CREATE TABLE reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    booking_id UUID NOT NULL REFERENCES bookings(id),
+    property_id UUID NOT NULL REFERENCES properties(id),
+    guest_id TEXT NOT NULL,
+    rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    cleanliness INTEGER NOT NULL CHECK (cleanliness BETWEEN 1 AND 5),
+    accuracy INTEGER NOT NULL CHECK (accuracy BETWEEN 1 AND 5),
+    check_in INTEGER NOT NULL CHECK (check_in BETWEEN 1 AND 5),
+    communication INTEGER NOT NULL CHECK (communication BETWEEN 1 AND 5),
+    location INTEGER NOT NULL CHECK (location BETWEEN 1 AND 5),
+    value INTEGER NOT NULL CHECK (value BETWEEN 1 AND 5),
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT reviews_booking_id_key UNIQUE (booking_id)
+);
+
+CREATE INDEX reviews_property_id_idx ON reviews (property_id, created_at DESC, id DESC);
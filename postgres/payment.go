@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/perebaj/reserv"
+)
+
+// paymentsActiveIndex is the unique index that allows a single active payment per booking.
+const paymentsActiveIndex = "payments_booking_id_active_idx"
+
+// uniqueViolation is the postgres error code raised when a unique constraint or index is violated.
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err was raised by the given unique constraint or index.
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == uniqueViolation && pqErr.Constraint == constraint
+}
+
+// CreatePayment stores a payment started in a payment provider. It returns reserv.ErrPaymentExists if the booking
+// already has a pending, authorized, captured or refunded payment.
+func (r *Repository) CreatePayment(ctx context.Context, payment reserv.Payment) (string, error) {
+	slog.Info("creating payment", "booking_id", payment.BookingID, "provider", payment.Provider)
+	query := `
+		INSERT INTO payments (
+			booking_id,
+			provider,
+			provider_payment_id,
+			status,
+			amount_cents,
+			currency,
+			created_at,
+			updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	var id string
+	if err := r.db.QueryRowxContext(ctx, query,
+		payment.BookingID,
+		payment.Provider,
+		payment.ProviderPaymentID,
+		payment.Status,
+		payment.AmountCents,
+		payment.Currency,
+		payment.CreatedAt,
+		payment.UpdatedAt,
+	).Scan(&id); err != nil {
+		if isUniqueViolation(err, paymentsActiveIndex) {
+			return "", reserv.ErrPaymentExists
+		}
+		return "", fmt.Errorf("failed to create payment: %v", err)
+	}
+
+	return id, nil
+}
+
+// PaymentByBookingID returns the latest payment of a booking. It returns reserv.ErrPaymentNotFound if the booking has no payment.
+func (r *Repository) PaymentByBookingID(ctx context.Context, bookingID string) (reserv.Payment, error) {
+	slog.Info("getting payment by booking id", "booking_id", bookingID)
+	query := `
+		SELECT * FROM payments WHERE booking_id = $1 ORDER BY created_at DESC LIMIT 1
+	`
+
+	var payment reserv.Payment
+	err := r.db.GetContext(ctx, &payment, query, bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return reserv.Payment{}, reserv.ErrPaymentNotFound
+	}
+	if err != nil {
+		return reserv.Payment{}, fmt.Errorf("failed to get payment: %v", err)
+	}
+	return payment, nil
+}
+
+// PaymentByProviderID returns a payment by its id in the payment provider. It returns reserv.ErrPaymentNotFound if it doesn't exist.
+func (r *Repository) PaymentByProviderID(ctx context.Context, provider, providerPaymentID string) (reserv.Payment, error) {
+	slog.Info("getting payment by provider id", "provider", provider, "provider_payment_id", providerPaymentID)
+	query := `
+		SELECT * FROM payments WHERE provider = $1 AND provider_payment_id = $2
+	`
+
+	var payment reserv.Payment
+	err := r.db.GetContext(ctx, &payment, query, provider, providerPaymentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return reserv.Payment{}, reserv.ErrPaymentNotFound
+	}
+	if err != nil {
+		return reserv.Payment{}, fmt.Errorf("failed to get payment: %v", err)
+	}
+	return payment, nil
+}
+
+// UpdatePayment stores the status and the refunded amount of a payment. Like UpdateBookingStatus, the update only
+// happens if the payment is still in the status from, otherwise reserv.ErrInvalidPaymentTransition is returned.
+// The entry, when not nil, is posted to the ledger in the same transaction, so the money moved by the payment is
+// recorded exactly once.
+func (r *Repository) UpdatePayment(ctx context.Context, payment reserv.Payment, from reserv.PaymentStatus, entry *reserv.JournalEntry) error {
+	slog.Info("updating payment", "id", payment.ID, "from", from, "to", payment.Status)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		UPDATE payments SET status = $3, refunded_cents = $4, updated_at = $5 WHERE id = $1 AND status = $2
+	`
+
+	res, err := tx.ExecContext(ctx, query, payment.ID, from, payment.Status, payment.RefundedCents, payment.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update payment: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		return reserv.ErrInvalidPaymentTransition
+	}
+
+	if entry != nil {
+		if err := postJournalEntry(ctx, tx, *entry); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit payment: %v", err)
+	}
+
+	return nil
+}
+
+// UnsettledPayments returns the authorized payments of the bookings that are not pending anymore, updated before
+// updatedBefore, the oldest first. They must be captured or voided.
+func (r *Repository) UnsettledPayments(ctx context.Context, updatedBefore time.Time) ([]reserv.Payment, error) {
+	query := `
+		SELECT p.* FROM payments p
+		JOIN bookings b ON b.id = p.booking_id
+		WHERE p.status = $1 AND b.status <> $2 AND p.updated_at < $3
+		ORDER BY p.updated_at
+	`
+
+	var payments []reserv.Payment
+	if err := r.db.SelectContext(ctx, &payments, query, reserv.PaymentStatusAuthorized, reserv.BookingStatusPending, updatedBefore); err != nil {
+		return nil, fmt.Errorf("failed to get unsettled payments: %v", err)
+	}
+	return payments, nil
+}
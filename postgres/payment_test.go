@@ -0,0 +1,182 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayments(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             uuid.New().String(),
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	bookingID, err := repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:      propertyID,
+		GuestID:         "guest-1",
+		CheckInDate:     time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate:    time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC),
+		TotalPriceCents: 30000,
+		Currency:        "USD",
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	})
+	require.NoError(t, err)
+
+	_, err = repo.PaymentByBookingID(ctx, bookingID)
+	require.ErrorIs(t, err, reserv.ErrPaymentNotFound)
+
+	now := time.Now().UTC()
+	payment := reserv.Payment{
+		BookingID:         bookingID,
+		Provider:          "fake",
+		ProviderPaymentID: "fake_pi_1",
+		Status:            reserv.PaymentStatusPending,
+		AmountCents:       30000,
+		Currency:          "USD",
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	payment.ID, err = repo.CreatePayment(ctx, payment)
+	require.NoError(t, err)
+	require.NotEmpty(t, payment.ID)
+
+	// A booking has a single payment in progress.
+	second := payment
+	second.ProviderPaymentID = "fake_pi_2"
+	_, err = repo.CreatePayment(ctx, second)
+	require.ErrorIs(t, err, reserv.ErrPaymentExists)
+
+	got, err := repo.PaymentByProviderID(ctx, "fake", "fake_pi_1")
+	require.NoError(t, err)
+	require.Equal(t, payment.ID, got.ID)
+	require.Equal(t, reserv.PaymentStatusPending, got.Status)
+
+	_, err = repo.PaymentByProviderID(ctx, "other", "fake_pi_1")
+	require.ErrorIs(t, err, reserv.ErrPaymentNotFound)
+
+	got.Status = reserv.PaymentStatusFailed
+	require.NoError(t, repo.UpdatePayment(ctx, got, reserv.PaymentStatusPending, nil))
+
+	// The status changed, so a concurrent update from pending fails.
+	got.Status = reserv.PaymentStatusAuthorized
+	require.ErrorIs(t, repo.UpdatePayment(ctx, got, reserv.PaymentStatusPending, nil), reserv.ErrInvalidPaymentTransition)
+
+	// A failed payment doesn't block a new attempt.
+	second.CreatedAt = now.Add(time.Second)
+	second.ID, err = repo.CreatePayment(ctx, second)
+	require.NoError(t, err)
+
+	latest, err := repo.PaymentByBookingID(ctx, bookingID)
+	require.NoError(t, err)
+	require.Equal(t, second.ID, latest.ID)
+
+	latest.Status = reserv.PaymentStatusAuthorized
+	require.NoError(t, repo.UpdatePayment(ctx, latest, reserv.PaymentStatusPending, nil))
+	latest.Status = reserv.PaymentStatusCaptured
+	require.NoError(t, repo.UpdatePayment(ctx, latest, reserv.PaymentStatusAuthorized, nil))
+	latest.Status = reserv.PaymentStatusRefunded
+	latest.RefundedCents = 15000
+	require.NoError(t, repo.UpdatePayment(ctx, latest, reserv.PaymentStatusCaptured, nil))
+
+	latest, err = repo.PaymentByBookingID(ctx, bookingID)
+	require.NoError(t, err)
+	require.Equal(t, reserv.PaymentStatusRefunded, latest.Status)
+	require.Equal(t, int64(15000), latest.RefundedCents)
+
+	// The refunded payment keeps the property.
+	require.ErrorIs(t, repo.DeleteProperty(ctx, propertyID), reserv.ErrPropertyHasPayments)
+}
+
+func TestUnsettledPayments(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             uuid.New().String(),
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	bookingID, err := repo.CreateBooking(ctx, reserv.Booking{
+		PropertyID:      propertyID,
+		GuestID:         "guest-1",
+		CheckInDate:     time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate:    time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC),
+		TotalPriceCents: 30000,
+		Currency:        "USD",
+		Status:          reserv.BookingStatusPending,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	payment := reserv.Payment{
+		BookingID:         bookingID,
+		Provider:          "fake",
+		ProviderPaymentID: "fake_pi_1",
+		Status:            reserv.PaymentStatusPending,
+		AmountCents:       30000,
+		Currency:          "USD",
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	payment.ID, err = repo.CreatePayment(ctx, payment)
+	require.NoError(t, err)
+
+	payment.Status = reserv.PaymentStatusAuthorized
+	require.NoError(t, repo.UpdatePayment(ctx, payment, reserv.PaymentStatusPending, nil))
+
+	// The host didn't answer the request yet.
+	payments, err := repo.UnsettledPayments(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, payments)
+
+	require.NoError(t, repo.UpdateBookingStatus(ctx, bookingID, reserv.BookingStatusPending, reserv.BookingStatusDeclined))
+
+	payments, err = repo.UnsettledPayments(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, payments, 1)
+	require.Equal(t, payment.ID, payments[0].ID)
+
+	// Recent payments are left to the handlers.
+	payments, err = repo.UnsettledPayments(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, payments)
+
+	payment.Status = reserv.PaymentStatusVoided
+	require.NoError(t, repo.UpdatePayment(ctx, payment, reserv.PaymentStatusAuthorized, nil))
+
+	payments, err = repo.UnsettledPayments(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, payments)
+}
@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/perebaj/reserv"
+)
+
+// payable is the amount owed to the host of a booking that can be paid out.
+type payable struct {
+	reserv.PayoutItem
+	HostID   string `db:"host_id"`
+	Currency string `db:"currency"`
+}
+
+// SchedulePayouts creates the payouts of the bookings checked in at least delayDays before now, one payout per host
+// and currency. Each booking is paid out once, with what the ledger owes the host for it: the host share of the
+// payment less the cancellations. Bookings that owe the host nothing are left out. It returns the payouts created.
+func (r *Repository) SchedulePayouts(ctx context.Context, delayDays int, now time.Time) ([]reserv.Payout, error) {
+	slog.Info("scheduling payouts", "delay_days", delayDays)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		SELECT
+			b.id AS booking_id,
+			b.property_id,
+			b.check_in_date,
+			b.check_out_date,
+			a.host_id,
+			a.currency,
+			-SUM(p.amount_cents) AS amount_cents
+		FROM bookings b
+		JOIN journal_entries e ON e.booking_id = b.id
+		JOIN postings p ON p.journal_entry_id = e.id
+		JOIN accounts a ON a.id = p.account_id
+		WHERE a.code = $1
+			AND b.status IN ($2, $3)
+			AND b.check_in_date + $4::int <= $5::date
+			AND NOT EXISTS (SELECT 1 FROM payout_items i WHERE i.booking_id = b.id)
+		GROUP BY b.id, a.host_id, a.currency
+		HAVING SUM(p.amount_cents) < 0
+		ORDER BY a.host_id, a.currency, b.check_in_date, b.id
+	`
+
+	var payables []payable
+	if err := tx.SelectContext(ctx, &payables, query,
+		reserv.AccountHostPayable,
+		reserv.BookingStatusCheckedIn,
+		reserv.BookingStatusCompleted,
+		delayDays,
+		now,
+	); err != nil {
+		return nil, fmt.Errorf("failed to get payable bookings: %v", err)
+	}
+
+	// The payables are ordered by host and currency, so each payout is a run of them.
+	var payouts []reserv.Payout
+	for _, p := range payables {
+		last := len(payouts) - 1
+		if last < 0 || payouts[last].HostID != p.HostID || payouts[last].Currency != p.Currency {
+			payouts = append(payouts, reserv.Payout{
+				HostID:    p.HostID,
+				Status:    reserv.PayoutStatusPending,
+				Currency:  p.Currency,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+			last++
+		}
+
+		total, err := reserv.Money{Amount: payouts[last].AmountCents, Currency: p.Currency}.Add(reserv.Money{Amount: p.AmountCents, Currency: p.Currency})
+		if err != nil {
+			return nil, err
+		}
+		payouts[last].AmountCents = total.Amount
+		payouts[last].Items = append(payouts[last].Items, p.PayoutItem)
+	}
+
+	for i, payout := range payouts {
+		query := `
+			INSERT INTO payouts (host_id, status, amount_cents, currency, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`
+		if err := tx.QueryRowxContext(ctx, query,
+			payout.HostID,
+			payout.Status,
+			payout.AmountCents,
+			payout.Currency,
+			payout.CreatedAt,
+			payout.UpdatedAt,
+		).Scan(&payouts[i].ID); err != nil {
+			return nil, fmt.Errorf("failed to create payout: %v", err)
+		}
+
+		for j, item := range payout.Items {
+			query := `
+				INSERT INTO payout_items (payout_id, booking_id, property_id, check_in_date, check_out_date, amount_cents)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`
+			if _, err := tx.ExecContext(ctx, query,
+				payouts[i].ID,
+				item.BookingID,
+				item.PropertyID,
+				item.CheckInDate,
+				item.CheckOutDate,
+				item.AmountCents,
+			); err != nil {
+				return nil, fmt.Errorf("failed to create payout item: %v", err)
+			}
+			payouts[i].Items[j].PayoutID = payouts[i].ID
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit payouts: %v", err)
+	}
+
+	return payouts, nil
+}
+
+// PendingPayouts returns the payouts not paid yet, the oldest first.
+func (r *Repository) PendingPayouts(ctx context.Context) ([]reserv.Payout, error) {
+	slog.Info("getting pending payouts")
+	query := `
+		SELECT * FROM payouts WHERE status = $1 ORDER BY created_at, id
+	`
+
+	var payouts []reserv.Payout
+	if err := r.db.SelectContext(ctx, &payouts, query, reserv.PayoutStatusPending); err != nil {
+		return nil, fmt.Errorf("failed to get pending payouts: %v", err)
+	}
+	return r.withPayoutItems(ctx, payouts)
+}
+
+// CompletePayout stores a payout as paid, with its provider, transfer id and paid_at, and posts its entry to the
+// ledger in the same transaction. It returns reserv.ErrPayoutNotPending if the payout was already paid.
+func (r *Repository) CompletePayout(ctx context.Context, payout reserv.Payout, entry reserv.JournalEntry) error {
+	slog.Info("completing payout", "id", payout.ID, "host_id", payout.HostID)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		UPDATE payouts SET status = $2, provider = $3, provider_payout_id = $4, paid_at = $5, updated_at = $6
+		WHERE id = $1 AND status = $7
+	`
+
+	res, err := tx.ExecContext(ctx, query,
+		payout.ID,
+		reserv.PayoutStatusPaid,
+		payout.Provider,
+		payout.ProviderPayoutID,
+		payout.PaidAt,
+		payout.UpdatedAt,
+		reserv.PayoutStatusPending,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update payout: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		return reserv.ErrPayoutNotPending
+	}
+
+	if err := postJournalEntry(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit payout: %v", err)
+	}
+
+	return nil
+}
+
+// HostPayouts returns the payouts of a host with their items, the newest first.
+func (r *Repository) HostPayouts(ctx context.Context, hostID string) ([]reserv.Payout, error) {
+	slog.Info("getting host payouts", "host_id", hostID)
+	query := `
+		SELECT * FROM payouts WHERE host_id = $1 ORDER BY created_at DESC, id
+	`
+
+	var payouts []reserv.Payout
+	if err := r.db.SelectContext(ctx, &payouts, query, hostID); err != nil {
+		return nil, fmt.Errorf("failed to get host payouts: %v", err)
+	}
+	return r.withPayoutItems(ctx, payouts)
+}
+
+// withPayoutItems loads the items of the payouts, ordered by check-in.
+func (r *Repository) withPayoutItems(ctx context.Context, payouts []reserv.Payout) ([]reserv.Payout, error) {
+	if len(payouts) == 0 {
+		return payouts, nil
+	}
+
+	ids := make([]string, len(payouts))
+	for i, payout := range payouts {
+		ids[i] = payout.ID
+	}
+
+	query := `
+		SELECT * FROM payout_items WHERE payout_id = ANY($1) ORDER BY check_in_date, booking_id
+	`
+
+	var items []reserv.PayoutItem
+	if err := r.db.SelectContext(ctx, &items, query, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to get payout items: %v", err)
+	}
+
+	byPayout := make(map[string][]reserv.PayoutItem, len(payouts))
+	for _, item := range items {
+		byPayout[item.PayoutID] = append(byPayout[item.PayoutID], item)
+	}
+	for i, payout := range payouts {
+		payouts[i].Items = append([]reserv.PayoutItem{}, byPayout[payout.ID]...)
+	}
+	return payouts, nil
+}
+
+// HostStatement returns the bookings of a host paid out in the month that starts at month, in the order they were
+// paid.
+func (r *Repository) HostStatement(ctx context.Context, hostID string, month time.Time) ([]reserv.StatementLine, error) {
+	slog.Info("getting host statement", "host_id", hostID, "month", month.Format("2006-01"))
+	query := `
+		SELECT
+			p.paid_at,
+			p.currency,
+			i.payout_id,
+			i.booking_id,
+			i.property_id,
+			i.check_in_date,
+			i.check_out_date,
+			i.amount_cents
+		FROM payouts p
+		JOIN payout_items i ON i.payout_id = p.id
+		WHERE p.host_id = $1 AND p.status = $2 AND p.paid_at >= $3 AND p.paid_at < $4
+		ORDER BY p.paid_at, p.id, i.check_in_date, i.booking_id
+	`
+
+	var lines []reserv.StatementLine
+	if err := r.db.SelectContext(ctx, &lines, query, hostID, reserv.PayoutStatusPaid, month, month.AddDate(0, 1, 0)); err != nil {
+		return nil, fmt.Errorf("failed to get host statement: %v", err)
+	}
+	return lines, nil
+}
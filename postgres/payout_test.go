@@ -0,0 +1,161 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+// createPaidBooking creates a booking of 3 nights from checkIn, captures its payment and moves it to status.
+func createPaidBooking(t *testing.T, repo *postgres.Repository, propertyID, hostID string, checkIn time.Time, status reserv.BookingStatus) reserv.Booking {
+	t.Helper()
+	ctx := context.Background()
+
+	booking := reserv.Booking{
+		PropertyID:      propertyID,
+		GuestID:         "guest-1",
+		Status:          reserv.BookingStatusConfirmed,
+		CheckInDate:     checkIn,
+		CheckOutDate:    checkIn.AddDate(0, 0, 3),
+		TotalPriceCents: 33000,
+		Currency:        "USD",
+		LineItems: []reserv.QuoteLine{
+			{Kind: reserv.QuoteLineNightly, Description: "3 nights", AmountCents: 30000},
+			{Kind: reserv.QuoteLineServiceFee, Description: "Service fee (10%)", AmountCents: 3000},
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	var err error
+	booking.ID, err = repo.CreateBooking(ctx, booking)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	payment := reserv.Payment{
+		BookingID:         booking.ID,
+		Provider:          "fake",
+		ProviderPaymentID: fmt.Sprintf("fake_pi_%s", booking.ID),
+		Status:            reserv.PaymentStatusAuthorized,
+		AmountCents:       33000,
+		Currency:          "USD",
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	payment.ID, err = repo.CreatePayment(ctx, payment)
+	require.NoError(t, err)
+
+	payment.Status = reserv.PaymentStatusCaptured
+	entry := reserv.PaymentEntry(payment, booking, hostID, now)
+	require.NoError(t, repo.UpdatePayment(ctx, payment, reserv.PaymentStatusAuthorized, &entry))
+
+	if status != reserv.BookingStatusConfirmed {
+		require.NoError(t, repo.UpdateBookingStatus(ctx, booking.ID, reserv.BookingStatusConfirmed, status))
+	}
+	return booking
+}
+
+func TestPayouts(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	hostID := uuid.New().String()
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             hostID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	completed := createPaidBooking(t, repo, propertyID, hostID, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), reserv.BookingStatusCheckedIn)
+	require.NoError(t, repo.UpdateBookingStatus(ctx, completed.ID, reserv.BookingStatusCheckedIn, reserv.BookingStatusCompleted))
+	checkedIn := createPaidBooking(t, repo, propertyID, hostID, time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC), reserv.BookingStatusCheckedIn)
+	// Checked in less than delayDays ago.
+	createPaidBooking(t, repo, propertyID, hostID, time.Date(2025, 5, 9, 0, 0, 0, 0, time.UTC), reserv.BookingStatusCheckedIn)
+	// Not checked in.
+	createPaidBooking(t, repo, propertyID, hostID, time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC), reserv.BookingStatusConfirmed)
+
+	payouts, err := repo.SchedulePayouts(ctx, 2, now)
+	require.NoError(t, err)
+	require.Len(t, payouts, 1)
+	payout := payouts[0]
+	require.NotEmpty(t, payout.ID)
+	require.Equal(t, hostID, payout.HostID)
+	require.Equal(t, reserv.PayoutStatusPending, payout.Status)
+	require.Equal(t, int64(60000), payout.AmountCents)
+	require.Equal(t, "USD", payout.Currency)
+	require.Len(t, payout.Items, 2)
+	require.Equal(t, completed.ID, payout.Items[0].BookingID)
+	require.Equal(t, checkedIn.ID, payout.Items[1].BookingID)
+	require.Equal(t, int64(30000), payout.Items[0].AmountCents)
+
+	// The bookings are paid out once.
+	again, err := repo.SchedulePayouts(ctx, 2, now)
+	require.NoError(t, err)
+	require.Empty(t, again)
+
+	pending, err := repo.PendingPayouts(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, payout.ID, pending[0].ID)
+	require.Len(t, pending[0].Items, 2)
+
+	paidAt := time.Date(2025, 5, 10, 13, 0, 0, 0, time.UTC)
+	payout.Status = reserv.PayoutStatusPaid
+	payout.Provider = "fake"
+	payout.ProviderPayoutID = "fake_po_1"
+	payout.PaidAt = &paidAt
+	payout.UpdatedAt = paidAt
+	entry := reserv.PayoutEntry(payout, paidAt)
+	require.NoError(t, repo.CompletePayout(ctx, payout, entry))
+	require.ErrorIs(t, repo.CompletePayout(ctx, payout, entry), reserv.ErrPayoutNotPending)
+
+	pending, err = repo.PendingPayouts(ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	// The payout pays what the ledger owed the host for the two bookings.
+	balances, err := repo.HostBalances(ctx, hostID)
+	require.NoError(t, err)
+	require.Equal(t, []reserv.HostBalance{{HostID: hostID, Currency: "USD", BalanceCents: 60000}}, balances)
+
+	history, err := repo.HostPayouts(ctx, hostID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, reserv.PayoutStatusPaid, history[0].Status)
+	require.Equal(t, "fake_po_1", history[0].ProviderPayoutID)
+	require.True(t, paidAt.Equal(*history[0].PaidAt))
+	require.Len(t, history[0].Items, 2)
+
+	lines, err := repo.HostStatement(ctx, hostID, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	require.Equal(t, payout.ID, lines[0].PayoutID)
+	require.Equal(t, completed.ID, lines[0].BookingID)
+	require.Equal(t, "USD", lines[0].Currency)
+	require.Equal(t, int64(30000), lines[0].AmountCents)
+
+	lines, err = repo.HostStatement(ctx, hostID, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Empty(t, lines)
+
+	report, err := repo.Reconciliation(ctx, now)
+	require.NoError(t, err)
+	require.True(t, report.Balanced)
+}
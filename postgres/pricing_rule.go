@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/perebaj/reserv"
+)
+
+// CreatePricingRule creates a pricing rule for a property. It returns the id of the rule.
+func (r *Repository) CreatePricingRule(ctx context.Context, rule reserv.PricingRule) (string, error) {
+	slog.Info("creating pricing rule", "property_id", rule.PropertyID)
+	query := `
+		INSERT INTO pricing_rules (
+			property_id,
+			name,
+			start_date,
+			end_date,
+			weekdays,
+			priority,
+			price_cents,
+			adjustment_percent,
+			created_at,
+			updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	var id string
+	if err := r.db.QueryRowxContext(ctx, query,
+		rule.PropertyID,
+		rule.Name,
+		rule.StartDate,
+		rule.EndDate,
+		rule.Weekdays,
+		rule.Priority,
+		rule.PriceCents,
+		rule.AdjustmentPercent,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	).Scan(&id); err != nil {
+		return "", fmt.Errorf("failed to create pricing rule: %v", err)
+	}
+
+	return id, nil
+}
+
+// UpdatePricingRule updates all the fields of a pricing rule of a property, except the creation time.
+func (r *Repository) UpdatePricingRule(ctx context.Context, rule reserv.PricingRule) error {
+	slog.Info("updating pricing rule", "id", rule.ID, "property_id", rule.PropertyID)
+	query := `
+		UPDATE pricing_rules
+			SET name = $3,
+			start_date = $4,
+			end_date = $5,
+			weekdays = $6,
+			priority = $7,
+			price_cents = $8,
+			adjustment_percent = $9,
+			updated_at = $10
+		WHERE id = $1 AND property_id = $2
+	`
+
+	res, err := r.db.ExecContext(ctx, query,
+		rule.ID,
+		rule.PropertyID,
+		rule.Name,
+		rule.StartDate,
+		rule.EndDate,
+		rule.Weekdays,
+		rule.Priority,
+		rule.PriceCents,
+		rule.AdjustmentPercent,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update pricing rule: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		return reserv.ErrPricingRuleNotFound
+	}
+
+	return nil
+}
+
+// DeletePricingRule deletes a pricing rule of a property.
+func (r *Repository) DeletePricingRule(ctx context.Context, propertyID, id string) error {
+	slog.Info("deleting pricing rule", "id", id, "property_id", propertyID)
+	query := `
+		DELETE FROM pricing_rules WHERE id = $1 AND property_id = $2
+	`
+
+	res, err := r.db.ExecContext(ctx, query, id, propertyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete pricing rule: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		return reserv.ErrPricingRuleNotFound
+	}
+
+	return nil
+}
+
+// PricingRules returns all pricing rules of a property, from the highest to the lowest priority.
+func (r *Repository) PricingRules(ctx context.Context, propertyID string) ([]reserv.PricingRule, error) {
+	slog.Info("getting pricing rules", "property_id", propertyID)
+	query := `
+		SELECT * FROM pricing_rules WHERE property_id = $1 ORDER BY priority DESC, created_at DESC
+	`
+
+	var rules []reserv.PricingRule
+	if err := r.db.SelectContext(ctx, &rules, query, propertyID); err != nil {
+		return nil, fmt.Errorf("failed to get pricing rules: %v", err)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,88 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPricingRules(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             uuid.New().String(),
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	price := int64(15000)
+	weekendsID, err := repo.CreatePricingRule(ctx, reserv.PricingRule{
+		PropertyID: propertyID,
+		Name:       "Weekends",
+		Weekdays:   reserv.Weekdays{time.Friday, time.Saturday},
+		PriceCents: &price,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	})
+	require.NoError(t, err)
+
+	start := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	adjustment := 20
+	seasonID, err := repo.CreatePricingRule(ctx, reserv.PricingRule{
+		PropertyID:        propertyID,
+		Name:              "High season",
+		StartDate:         &start,
+		EndDate:           &end,
+		Priority:          10,
+		AdjustmentPercent: &adjustment,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	})
+	require.NoError(t, err)
+
+	rules, err := repo.PricingRules(ctx, propertyID)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Equal(t, seasonID, rules[0].ID)
+	require.Equal(t, start, rules[0].StartDate.UTC())
+	require.Empty(t, rules[0].Weekdays)
+	require.Nil(t, rules[0].PriceCents)
+	require.Equal(t, weekendsID, rules[1].ID)
+	require.Equal(t, reserv.Weekdays{time.Friday, time.Saturday}, rules[1].Weekdays)
+	require.Equal(t, price, *rules[1].PriceCents)
+
+	rule := rules[1]
+	rule.Weekdays = reserv.Weekdays{time.Saturday}
+	rule.UpdatedAt = time.Now()
+	require.NoError(t, repo.UpdatePricingRule(ctx, rule))
+
+	rule.PropertyID = uuid.New().String()
+	require.ErrorIs(t, repo.UpdatePricingRule(ctx, rule), reserv.ErrPricingRuleNotFound)
+
+	require.NoError(t, repo.DeletePricingRule(ctx, propertyID, seasonID))
+	require.ErrorIs(t, repo.DeletePricingRule(ctx, propertyID, seasonID), reserv.ErrPricingRuleNotFound)
+
+	rules, err = repo.PricingRules(ctx, propertyID)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, reserv.Weekdays{time.Saturday}, rules[0].Weekdays)
+
+	require.NoError(t, repo.DeleteProperty(ctx, propertyID))
+}
@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/perebaj/reserv"
+)
+
+const (
+	// promotionsCodeKey is the unique constraint that keeps a single promotion per code.
+	promotionsCodeKey = "promotions_code_key"
+	// promotionsRedemptionsCheck is the check constraint that keeps the redemptions of a promotion within its limit.
+	promotionsRedemptionsCheck = "promotions_redemptions_check"
+)
+
+// checkViolation is the postgres error code raised when a check constraint is violated.
+const checkViolation = "23514"
+
+// isCheckViolation reports whether err was raised by the given check constraint.
+func isCheckViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == checkViolation && pqErr.Constraint == constraint
+}
+
+// promotionRow is a row of the promotions table. The restrictions of the promotion are stored as text arrays.
+type promotionRow struct {
+	reserv.Promotion
+	PropertyIDs pq.StringArray `db:"property_ids"`
+	HostIDs     pq.StringArray `db:"host_ids"`
+}
+
+// promotion returns the promotion of the row. Empty restrictions are empty slices, never nil.
+func (row promotionRow) promotion() reserv.Promotion {
+	promotion := row.Promotion
+	promotion.PropertyIDs = append([]string{}, row.PropertyIDs...)
+	promotion.HostIDs = append([]string{}, row.HostIDs...)
+	return promotion
+}
+
+// textArray converts values to a text array that is never NULL, since a nil pq.StringArray is stored as NULL.
+func textArray(values []string) pq.StringArray {
+	if values == nil {
+		return pq.StringArray{}
+	}
+	return values
+}
+
+// CreatePromotion creates a promotion. It returns the id of the promotion or reserv.ErrPromoCodeExists if another
+// promotion already uses the code.
+func (r *Repository) CreatePromotion(ctx context.Context, promotion reserv.Promotion) (string, error) {
+	slog.Info("creating promotion", "code", promotion.Code)
+	query := `
+		INSERT INTO promotions (
+			code,
+			percent_off,
+			amount_off_cents,
+			currency,
+			expires_at,
+			max_redemptions,
+			min_nights,
+			property_ids,
+			host_ids,
+			created_at,
+			updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	var id string
+	if err := r.db.QueryRowxContext(ctx, query,
+		promotion.Code,
+		promotion.PercentOff,
+		promotion.AmountOffCents,
+		promotion.Currency,
+		promotion.ExpiresAt,
+		promotion.MaxRedemptions,
+		promotion.MinNights,
+		textArray(promotion.PropertyIDs),
+		textArray(promotion.HostIDs),
+		promotion.CreatedAt,
+		promotion.UpdatedAt,
+	).Scan(&id); err != nil {
+		if isUniqueViolation(err, promotionsCodeKey) {
+			return "", reserv.ErrPromoCodeExists
+		}
+		return "", fmt.Errorf("failed to create promotion: %v", err)
+	}
+
+	return id, nil
+}
+
+// UpdatePromotion updates all the fields of a promotion, except its redemptions and creation time. The bookings that
+// already redeemed the code keep their discount. It returns reserv.ErrPromotionNotFound if the promotion doesn't
+// exist, reserv.ErrPromoCodeExists if another promotion already uses the code and an error wrapping
+// reserv.ErrInvalidPromotion if the usage limit is below the redemptions.
+func (r *Repository) UpdatePromotion(ctx context.Context, promotion reserv.Promotion) error {
+	slog.Info("updating promotion", "id", promotion.ID, "code", promotion.Code)
+	query := `
+		UPDATE promotions
+			SET code = $2,
+			percent_off = $3,
+			amount_off_cents = $4,
+			currency = $5,
+			expires_at = $6,
+			max_redemptions = $7,
+			min_nights = $8,
+			property_ids = $9,
+			host_ids = $10,
+			updated_at = $11
+		WHERE id = $1
+	`
+
+	res, err := r.db.ExecContext(ctx, query,
+		promotion.ID,
+		promotion.Code,
+		promotion.PercentOff,
+		promotion.AmountOffCents,
+		promotion.Currency,
+		promotion.ExpiresAt,
+		promotion.MaxRedemptions,
+		promotion.MinNights,
+		textArray(promotion.PropertyIDs),
+		textArray(promotion.HostIDs),
+		promotion.UpdatedAt,
+	)
+	if isUniqueViolation(err, promotionsCodeKey) {
+		return reserv.ErrPromoCodeExists
+	}
+	if isCheckViolation(err, promotionsRedemptionsCheck) {
+		return fmt.Errorf("%w: max_redemptions must not be below the redemptions of the code", reserv.ErrInvalidPromotion)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update promotion: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		return reserv.ErrPromotionNotFound
+	}
+
+	return nil
+}
+
+// GetPromotion returns a promotion by id. It returns reserv.ErrPromotionNotFound if it doesn't exist.
+func (r *Repository) GetPromotion(ctx context.Context, id string) (reserv.Promotion, error) {
+	slog.Info("getting promotion", "id", id)
+	return r.getPromotion(ctx, `SELECT * FROM promotions WHERE id = $1`, id)
+}
+
+// PromotionByCode returns the promotion of a code, in any case. It returns reserv.ErrPromotionNotFound if no
+// promotion uses the code.
+func (r *Repository) PromotionByCode(ctx context.Context, code string) (reserv.Promotion, error) {
+	slog.Info("getting promotion by code")
+	return r.getPromotion(ctx, `SELECT * FROM promotions WHERE code = $1`, reserv.NormalizePromoCode(code))
+}
+
+func (r *Repository) getPromotion(ctx context.Context, query string, arg any) (reserv.Promotion, error) {
+	var row promotionRow
+	if err := r.db.GetContext(ctx, &row, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return reserv.Promotion{}, reserv.ErrPromotionNotFound
+		}
+		return reserv.Promotion{}, fmt.Errorf("failed to get promotion: %v", err)
+	}
+
+	return row.promotion(), nil
+}
+
+// Promotions returns all the promotions, the newest first.
+func (r *Repository) Promotions(ctx context.Context) ([]reserv.Promotion, error) {
+	slog.Info("getting promotions")
+	query := `
+		SELECT * FROM promotions ORDER BY created_at DESC
+	`
+
+	var rows []promotionRow
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("failed to get promotions: %v", err)
+	}
+
+	promotions := make([]reserv.Promotion, 0, len(rows))
+	for _, row := range rows {
+		promotions = append(promotions, row.promotion())
+	}
+
+	return promotions, nil
+}
+
+// redeemPromotion counts a redemption of the promotion, if it didn't expire at now and is below its usage limit.
+// The update locks the promotion row until the transaction ends, so concurrent redemptions wait for each other and
+// see the up-to-date count. It returns an error wrapping reserv.ErrPromotionUnavailable otherwise.
+func redeemPromotion(ctx context.Context, tx *sqlx.Tx, id string, now time.Time) error {
+	query := `
+		UPDATE promotions SET redemptions = redemptions + 1
+		WHERE id = $1
+			AND (expires_at IS NULL OR expires_at > $2)
+			AND (max_redemptions = 0 OR redemptions < max_redemptions)
+	`
+
+	res, err := tx.ExecContext(ctx, query, id, now)
+	if err != nil {
+		return fmt.Errorf("failed to redeem promotion: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("%w: the code expired or reached its usage limit", reserv.ErrPromotionUnavailable)
+	}
+
+	return nil
+}
+
+// releasePromotions gives back a redemption of each promotion of promotionIDs, one per declined booking. Bookings
+// without a promotion have nil ids, which are skipped.
+func releasePromotions(ctx context.Context, tx *sqlx.Tx, promotionIDs []*string) error {
+	query := `
+		UPDATE promotions SET redemptions = GREATEST(redemptions - 1, 0) WHERE id = $1
+	`
+
+	for _, id := range promotionIDs {
+		if id == nil {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, query, *id); err != nil {
+			return fmt.Errorf("failed to release promotion: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromotions(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	percentOff := 25
+	promotion := reserv.Promotion{
+		Code:           "SUMMER25",
+		PercentOff:     &percentOff,
+		MaxRedemptions: 100,
+		MinNights:      2,
+		HostIDs:        []string{"host_1"},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	id, err := repo.CreatePromotion(ctx, promotion)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+
+	_, err = repo.CreatePromotion(ctx, promotion)
+	require.ErrorIs(t, err, reserv.ErrPromoCodeExists)
+
+	got, err := repo.PromotionByCode(ctx, "summer25")
+	require.NoError(t, err)
+	require.Equal(t, id, got.ID)
+	require.Equal(t, &percentOff, got.PercentOff)
+	require.Nil(t, got.AmountOffCents)
+	require.Nil(t, got.ExpiresAt)
+	require.Equal(t, []string{}, got.PropertyIDs)
+	require.Equal(t, []string{"host_1"}, got.HostIDs)
+
+	amountOff := int64(2000)
+	expiresAt := now.Add(24 * time.Hour)
+	promotion.ID = id
+	promotion.Code = "WELCOME"
+	promotion.PercentOff = nil
+	promotion.AmountOffCents = &amountOff
+	promotion.Currency = "USD"
+	promotion.ExpiresAt = &expiresAt
+	promotion.HostIDs = nil
+	require.NoError(t, repo.UpdatePromotion(ctx, promotion))
+
+	got, err = repo.GetPromotion(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "WELCOME", got.Code)
+	require.Equal(t, &amountOff, got.AmountOffCents)
+	require.Equal(t, "USD", got.Currency)
+	require.Equal(t, expiresAt, got.ExpiresAt.UTC())
+	require.Empty(t, got.HostIDs)
+
+	promotions, err := repo.Promotions(ctx)
+	require.NoError(t, err)
+	require.Len(t, promotions, 1)
+
+	_, err = repo.PromotionByCode(ctx, "SUMMER25")
+	require.ErrorIs(t, err, reserv.ErrPromotionNotFound)
+
+	promotion.ID = uuid.New().String()
+	require.ErrorIs(t, repo.UpdatePromotion(ctx, promotion), reserv.ErrPromotionNotFound)
+}
+
+func TestCreateBooking_PromotionConcurrent(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	percentOff := 10
+	promotionID, err := repo.CreatePromotion(ctx, reserv.Promotion{
+		Code:           "LIMITED",
+		PercentOff:     &percentOff,
+		MaxRedemptions: 3,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	})
+	require.NoError(t, err)
+
+	hostID := uuid.New().String()
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             hostID,
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	const workers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	start := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			// Every booking has its own dates, so only the usage limit of the code can reject them.
+			_, err := repo.CreateBooking(ctx, reserv.Booking{
+				PropertyID:      propertyID,
+				GuestID:         uuid.New().String(),
+				CheckInDate:     time.Date(2025, 3, 1+3*i, 0, 0, 0, 0, time.UTC),
+				CheckOutDate:    time.Date(2025, 3, 2+3*i, 0, 0, 0, 0, time.UTC),
+				TotalPriceCents: 9000,
+				Currency:        "USD",
+				PromotionID:     &promotionID,
+				CreatedAt:       time.Now(),
+				UpdatedAt:       time.Now(),
+			})
+			errs <- err
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	var created int
+	for err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		require.ErrorIs(t, err, reserv.ErrPromotionUnavailable)
+	}
+	require.Equal(t, 3, created)
+
+	promotion, err := repo.GetPromotion(ctx, promotionID)
+	require.NoError(t, err)
+	require.Equal(t, 3, promotion.Redemptions)
+
+	bookings, err := repo.Bookings(ctx, reserv.BookingFilter{PropertyID: propertyID})
+	require.NoError(t, err)
+	require.Len(t, bookings, 3)
+	require.Equal(t, &promotionID, bookings[0].PromotionID)
+}
+
+func TestDeclineBooking_ReleasesPromotion(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	percentOff := 10
+	promotionID, err := repo.CreatePromotion(ctx, reserv.Promotion{
+		Code:           "LIMITED",
+		PercentOff:     &percentOff,
+		MaxRedemptions: 2,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	})
+	require.NoError(t, err)
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             uuid.New().String(),
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	request := func(day int, respondBy time.Time) string {
+		id, err := repo.CreateBooking(ctx, reserv.Booking{
+			PropertyID:      propertyID,
+			GuestID:         uuid.New().String(),
+			CheckInDate:     time.Date(2025, 3, day, 0, 0, 0, 0, time.UTC),
+			CheckOutDate:    time.Date(2025, 3, day+1, 0, 0, 0, 0, time.UTC),
+			TotalPriceCents: 9000,
+			Currency:        "USD",
+			Status:          reserv.BookingStatusPending,
+			RespondBy:       &respondBy,
+			PromotionID:     &promotionID,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		})
+		require.NoError(t, err)
+		return id
+	}
+	redemptions := func() int {
+		promotion, err := repo.GetPromotion(ctx, promotionID)
+		require.NoError(t, err)
+		return promotion.Redemptions
+	}
+
+	declined := request(1, now.Add(time.Hour))
+	expired := request(5, now.Add(-time.Minute))
+	require.Equal(t, 2, redemptions())
+
+	// The host declines the request, so the guest can use the code again.
+	require.NoError(t, repo.UpdateBookingStatus(ctx, declined, reserv.BookingStatusPending, reserv.BookingStatusDeclined))
+	require.Equal(t, 1, redemptions())
+
+	// The request expires unanswered.
+	n, err := repo.DeclineExpiredBookingRequests(ctx, now)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	require.Equal(t, 0, redemptions())
+
+	// The request was already declined, so the redemption isn't given back twice.
+	require.ErrorIs(t, repo.UpdateBookingStatus(ctx, expired, reserv.BookingStatusPending, reserv.BookingStatusDeclined), reserv.ErrInvalidBookingTransition)
+	require.Equal(t, 0, redemptions())
+
+	// A confirmed booking keeps its redemption when it is cancelled.
+	approved := request(10, now.Add(time.Hour))
+	require.NoError(t, repo.UpdateBookingStatus(ctx, approved, reserv.BookingStatusPending, reserv.BookingStatusConfirmed))
+	require.NoError(t, repo.UpdateBookingStatus(ctx, approved, reserv.BookingStatusConfirmed, reserv.BookingStatusCancelledByGuest))
+	require.Equal(t, 1, redemptions())
+}
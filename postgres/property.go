@@ -108,9 +108,21 @@ func (r *Repository) CreateProperty(ctx context.Context, property reserv.Propert
 			price_per_night_cents,
 			currency,
 			host_id,
+			max_guests,
+			bedrooms,
+			beds,
+			bathrooms,
+			max_pets,
+			guests_included,
+			extra_guest_fee_cents,
+			instant_book,
+			cleaning_fee_cents,
+			jurisdiction,
+			weekly_discount_percent,
+			monthly_discount_percent,
 			created_at,
 			updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 		RETURNING id
 	`
 
@@ -121,6 +133,18 @@ func (r *Repository) CreateProperty(ctx context.Context, property reserv.Propert
 		property.PricePerNightCents,
 		property.Currency,
 		property.HostID,
+		property.MaxGuests,
+		property.Bedrooms,
+		property.Beds,
+		property.Bathrooms,
+		property.MaxPets,
+		property.GuestsIncluded,
+		property.ExtraGuestFeeCents,
+		property.InstantBook,
+		property.CleaningFeeCents,
+		property.Jurisdiction,
+		property.WeeklyDiscountPercent,
+		property.MonthlyDiscountPercent,
 		property.CreatedAt,
 		property.UpdatedAt,
 	).Scan(&id); err != nil {
@@ -139,18 +163,50 @@ func (r *Repository) UpdateProperty(ctx context.Context, property reserv.Propert
 			description = $3,
 			price_per_night_cents = $4,
 			currency = $5,
-			updated_at = $6
+			max_guests = $6,
+			bedrooms = $7,
+			beds = $8,
+			bathrooms = $9,
+			max_pets = $10,
+			guests_included = $11,
+			extra_guest_fee_cents = $12,
+			instant_book = $13,
+			cleaning_fee_cents = $14,
+			jurisdiction = $15,
+			weekly_discount_percent = $16,
+			monthly_discount_percent = $17,
+			updated_at = $18
 		WHERE id = $1
 	`
 
-	if _, err := r.db.ExecContext(ctx, query, id, property.Title, property.Description, property.PricePerNightCents, property.Currency, property.UpdatedAt); err != nil {
+	if _, err := r.db.ExecContext(ctx, query, id,
+		property.Title,
+		property.Description,
+		property.PricePerNightCents,
+		property.Currency,
+		property.MaxGuests,
+		property.Bedrooms,
+		property.Beds,
+		property.Bathrooms,
+		property.MaxPets,
+		property.GuestsIncluded,
+		property.ExtraGuestFeeCents,
+		property.InstantBook,
+		property.CleaningFeeCents,
+		property.Jurisdiction,
+		property.WeeklyDiscountPercent,
+		property.MonthlyDiscountPercent,
+		property.UpdatedAt,
+	); err != nil {
 		return fmt.Errorf("failed to update property: %v", err)
 	}
 
 	return nil
 }
 
-// DeleteProperty deletes a property and its amenities.
+// DeleteProperty deletes a property and its amenities. The money of the payments must stay traceable, so it returns
+// reserv.ErrPropertyHasPayments if a booking of the property has a payment in progress or charged. Failed and voided
+// payments moved no money and are deleted.
 func (r *Repository) DeleteProperty(ctx context.Context, id string) error {
 	slog.Info("deleting property", "id", id)
 	tx, err := r.db.BeginTx(ctx, nil)
@@ -161,7 +217,24 @@ func (r *Repository) DeleteProperty(ctx context.Context, id string) error {
 		_ = tx.Rollback()
 	}()
 
+	// Payments created after the check still block the deletion, through the foreign key of the bookings.
 	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM payments
+			WHERE booking_id IN (SELECT id FROM bookings WHERE property_id = $1)
+				AND status NOT IN ($2, $3)
+		)
+	`
+
+	var hasPayments bool
+	if err := tx.QueryRowContext(ctx, query, id, reserv.PaymentStatusFailed, reserv.PaymentStatusVoided).Scan(&hasPayments); err != nil {
+		return fmt.Errorf("failed to check payments: %v", err)
+	}
+	if hasPayments {
+		return reserv.ErrPropertyHasPayments
+	}
+
+	query = `
 		DELETE FROM property_images WHERE property_id = $1
 	`
 
@@ -177,6 +250,110 @@ func (r *Repository) DeleteProperty(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to delete property amenities: %v", err)
 	}
 
+	query = `
+		DELETE FROM cancellation_policies WHERE property_id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete cancellation policy: %v", err)
+	}
+
+	query = `
+		DELETE FROM booking_rules WHERE property_id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete booking rules: %v", err)
+	}
+
+	query = `
+		DELETE FROM pricing_rules WHERE property_id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete pricing rules: %v", err)
+	}
+
+	query = `
+		DELETE FROM calendar_export_tokens WHERE property_id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete calendar export tokens: %v", err)
+	}
+
+	query = `
+		DELETE FROM calendar_blocks WHERE property_id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete calendar blocks: %v", err)
+	}
+
+	query = `
+		DELETE FROM calendar_imports WHERE property_id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete calendar imports: %v", err)
+	}
+
+	query = `
+		DELETE FROM booking_holds WHERE property_id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete booking holds: %v", err)
+	}
+
+	query = `
+		DELETE FROM messages WHERE thread_id IN (SELECT id FROM threads WHERE property_id = $1)
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete messages: %v", err)
+	}
+
+	query = `
+		DELETE FROM threads WHERE property_id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete threads: %v", err)
+	}
+
+	query = `
+		DELETE FROM reviews WHERE property_id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete reviews: %v", err)
+	}
+
+	query = `
+		DELETE FROM payments WHERE booking_id IN (SELECT id FROM bookings WHERE property_id = $1) AND status IN ($2, $3)
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id, reserv.PaymentStatusFailed, reserv.PaymentStatusVoided); err != nil {
+		return fmt.Errorf("failed to delete payments: %v", err)
+	}
+
+	query = `
+		DELETE FROM booking_line_items WHERE booking_id IN (SELECT id FROM bookings WHERE property_id = $1)
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete booking line items: %v", err)
+	}
+
+	query = `
+		DELETE FROM booking_changes WHERE booking_id IN (SELECT id FROM bookings WHERE property_id = $1)
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete booking changes: %v", err)
+	}
+
 	query = `
 		DELETE FROM bookings WHERE property_id = $1
 	`
@@ -221,8 +398,7 @@ func (r *Repository) Properties(ctx context.Context, filter reserv.PropertyFilte
 
 	baseQuery := `
 		SELECT
-			p.id, p.host_id, p.title, p.description,
-			p.price_per_night_cents, p.currency, p.created_at, p.updated_at,
+			p.*,
 			COALESCE(
 				json_agg(
 					DISTINCT jsonb_build_object(
@@ -241,7 +417,22 @@ func (r *Repository) Properties(ctx context.Context, filter reserv.PropertyFilte
 						'name', a.name
 					)
 				) FILTER (WHERE a.id IS NOT NULL), '[]'
-			) AS amenities
+			) AS amenities,
+			(
+				SELECT jsonb_build_object(
+					'count', COUNT(*),
+					'average', COALESCE(ROUND(AVG(r.rating), 2), 0),
+					'categories', jsonb_build_object(
+						'cleanliness', COALESCE(ROUND(AVG(r.cleanliness), 2), 0),
+						'accuracy', COALESCE(ROUND(AVG(r.accuracy), 2), 0),
+						'check_in', COALESCE(ROUND(AVG(r.check_in), 2), 0),
+						'communication', COALESCE(ROUND(AVG(r.communication), 2), 0),
+						'location', COALESCE(ROUND(AVG(r.location), 2), 0),
+						'value', COALESCE(ROUND(AVG(r.value), 2), 0)
+					)
+				)
+				FROM reviews r WHERE r.property_id = p.id
+			) AS rating
 		FROM
 			properties p
 		LEFT JOIN
@@ -265,6 +456,7 @@ func (r *Repository) Properties(ctx context.Context, filter reserv.PropertyFilte
 		reserv.Property
 		ImagesJSON    json.RawMessage `db:"images"`
 		AmenitiesJSON json.RawMessage `db:"amenities"`
+		RatingJSON    json.RawMessage `db:"rating"`
 	}
 
 	var propertiesWithJSON []PropertyWithJSON
@@ -290,6 +482,10 @@ func (r *Repository) Properties(ctx context.Context, filter reserv.PropertyFilte
 		if err := json.Unmarshal(p.AmenitiesJSON, &properties[i].Amenities); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal amenities: %v, raw JSON: %s", err, string(p.AmenitiesJSON))
 		}
+
+		if err := json.Unmarshal(p.RatingJSON, &properties[i].Rating); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal rating: %v, raw JSON: %s", err, string(p.RatingJSON))
+		}
 	}
 	return properties, nil
 }
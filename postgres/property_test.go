@@ -201,13 +201,24 @@ func TestCreateProperty(t *testing.T) {
 	ctx := context.Background()
 
 	property := reserv.Property{
-		Title:              "Test Property",
-		Description:        "Test Description",
-		PricePerNightCents: 10000,
-		Currency:           "USD",
-		HostID:             "user_2x5CiRO5Mf0wBpWO8w469jEJhRq",
-		CreatedAt:          time.Now(),
-		UpdatedAt:          time.Now(),
+		Title:                  "Test Property",
+		Description:            "Test Description",
+		PricePerNightCents:     10000,
+		Currency:               "USD",
+		HostID:                 "user_2x5CiRO5Mf0wBpWO8w469jEJhRq",
+		MaxGuests:              4,
+		Bedrooms:               2,
+		Beds:                   3,
+		Bathrooms:              1,
+		MaxPets:                1,
+		GuestsIncluded:         2,
+		ExtraGuestFeeCents:     1500,
+		CleaningFeeCents:       5000,
+		Jurisdiction:           "US-NY-NYC",
+		WeeklyDiscountPercent:  10,
+		MonthlyDiscountPercent: 25,
+		CreatedAt:              time.Now(),
+		UpdatedAt:              time.Now(),
 	}
 
 	propertyID, err := repo.CreateProperty(ctx, property)
@@ -222,6 +233,17 @@ func TestCreateProperty(t *testing.T) {
 	require.Equal(t, property.PricePerNightCents, createdProperty.PricePerNightCents)
 	require.Equal(t, property.Currency, createdProperty.Currency)
 	require.Equal(t, property.HostID, createdProperty.HostID)
+	require.Equal(t, property.MaxGuests, createdProperty.MaxGuests)
+	require.Equal(t, property.Bedrooms, createdProperty.Bedrooms)
+	require.Equal(t, property.Beds, createdProperty.Beds)
+	require.Equal(t, property.Bathrooms, createdProperty.Bathrooms)
+	require.Equal(t, property.MaxPets, createdProperty.MaxPets)
+	require.Equal(t, property.GuestsIncluded, createdProperty.GuestsIncluded)
+	require.Equal(t, property.ExtraGuestFeeCents, createdProperty.ExtraGuestFeeCents)
+	require.Equal(t, property.CleaningFeeCents, createdProperty.CleaningFeeCents)
+	require.Equal(t, property.Jurisdiction, createdProperty.Jurisdiction)
+	require.Equal(t, property.WeeklyDiscountPercent, createdProperty.WeeklyDiscountPercent)
+	require.Equal(t, property.MonthlyDiscountPercent, createdProperty.MonthlyDiscountPercent)
 	require.NotNil(t, createdProperty.CreatedAt)
 	require.NotNil(t, createdProperty.UpdatedAt)
 }
@@ -323,11 +345,34 @@ func TestDeleteProperty(t *testing.T) {
 	_, err = repo.CreateImage(ctx, image)
 	require.NoError(t, err)
 
+	payment := reserv.Payment{
+		BookingID:         bookingID,
+		Provider:          "fake",
+		ProviderPaymentID: "fake_pi_" + uuid.New().String(),
+		Status:            reserv.PaymentStatusPending,
+		AmountCents:       10000,
+		Currency:          "USD",
+		CreatedAt:         time.Now().UTC(),
+		UpdatedAt:         time.Now().UTC(),
+	}
+	payment.ID, err = repo.CreatePayment(ctx, payment)
+	require.NoError(t, err)
+
+	// The payment is in progress, so the property is kept.
+	require.ErrorIs(t, repo.DeleteProperty(ctx, propertyID), reserv.ErrPropertyHasPayments)
+	affected, _, err := repo.GetProperty(ctx, propertyID)
+	require.NoError(t, err)
+	require.Equal(t, 1, affected)
+
+	// A failed payment moved no money, so it is deleted with the property.
+	payment.Status = reserv.PaymentStatusFailed
+	require.NoError(t, repo.UpdatePayment(ctx, payment, reserv.PaymentStatusPending, nil))
+
 	err = repo.DeleteProperty(ctx, propertyID)
 	require.NoError(t, err)
 
 	var deletedProperty reserv.Property
-	affected, deletedProperty, err := repo.GetProperty(ctx, propertyID)
+	affected, deletedProperty, err = repo.GetProperty(ctx, propertyID)
 	require.NoError(t, err)
 	require.Equal(t, 0, affected)
 	require.Empty(t, deletedProperty)
@@ -424,6 +469,7 @@ func TestProperties(t *testing.T) {
 		require.NotZero(t, property.PricePerNightCents)
 		require.Equal(t, int64(10000), property.PricePerNightCents)
 		require.Equal(t, "USD", property.Currency)
+		require.Equal(t, &reserv.PropertyRating{}, property.Rating, "properties without reviews have no rating")
 		require.NotEmpty(t, property.Amenities)
 		require.Len(t, property.Amenities, 2)
 		for _, amenity := range property.Amenities {
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/perebaj/reserv"
+)
+
+// reviewsBookingIDKey is the unique constraint that allows a single review per booking.
+const reviewsBookingIDKey = "reviews_booking_id_key"
+
+// CreateReview stores the review of a booking. It returns reserv.ErrReviewExists if the booking already has a review.
+func (r *Repository) CreateReview(ctx context.Context, review reserv.Review) (string, error) {
+	slog.Info("creating review", "booking_id", review.BookingID, "property_id", review.PropertyID)
+	query := `
+		INSERT INTO reviews (
+			booking_id,
+			property_id,
+			guest_id,
+			rating,
+			cleanliness,
+			accuracy,
+			check_in,
+			communication,
+			location,
+			value,
+			comment,
+			created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`
+
+	var id string
+	if err := r.db.QueryRowxContext(ctx, query,
+		review.BookingID,
+		review.PropertyID,
+		review.GuestID,
+		review.Rating,
+		review.Cleanliness,
+		review.Accuracy,
+		review.CheckIn,
+		review.Communication,
+		review.Location,
+		review.Value,
+		review.Comment,
+		review.CreatedAt,
+	).Scan(&id); err != nil {
+		if isUniqueViolation(err, reviewsBookingIDKey) {
+			return "", reserv.ErrReviewExists
+		}
+		return "", fmt.Errorf("failed to create review: %v", err)
+	}
+
+	return id, nil
+}
+
+// PropertyReviews returns a page of the reviews of a property, the newest first. It fetches one review more than the
+// limit of the page, see reserv.NextPage.
+func (r *Repository) PropertyReviews(ctx context.Context, propertyID string, page reserv.Page) ([]reserv.Review, error) {
+	slog.Info("getting property reviews", "property_id", propertyID)
+	query := `
+		SELECT * FROM reviews
+		WHERE property_id = $1
+			AND ($2::timestamp IS NULL OR (created_at, id) < ($2::timestamp, $3::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`
+
+	afterTime, afterID := cursorArgs(page)
+	var reviews []reserv.Review
+	if err := r.db.SelectContext(ctx, &reviews, query, propertyID, afterTime, afterID, page.Limit+1); err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %v", err)
+	}
+	return reviews, nil
+}
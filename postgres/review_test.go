@@ -0,0 +1,119 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviews(t *testing.T) {
+	db := OpenDB(t)
+	defer db.Close()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	propertyID, err := repo.CreateProperty(ctx, reserv.Property{
+		HostID:             "host",
+		Title:              "Test Property",
+		Description:        "Test Description",
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	var bookingIDs []string
+	for _, checkIn := range []time.Time{
+		time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+	} {
+		bookingID, err := repo.CreateBooking(ctx, reserv.Booking{
+			PropertyID:      propertyID,
+			GuestID:         "guest",
+			Status:          reserv.BookingStatusCompleted,
+			CheckInDate:     checkIn,
+			CheckOutDate:    checkIn.AddDate(0, 0, 3),
+			TotalPriceCents: 30000,
+			Currency:        "USD",
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		})
+		require.NoError(t, err)
+		bookingIDs = append(bookingIDs, bookingID)
+	}
+
+	now := time.Date(2025, 5, 10, 10, 0, 0, 0, time.UTC)
+	for i, rating := range []int{5, 2} {
+		id, err := repo.CreateReview(ctx, reserv.Review{
+			BookingID:    bookingIDs[i],
+			PropertyID:   propertyID,
+			GuestID:      "guest",
+			Rating:       rating,
+			ReviewScores: reserv.ReviewScores{Cleanliness: rating, Accuracy: 4, CheckIn: 5, Communication: 5, Location: 3, Value: rating},
+			Comment:      "Nice place",
+			CreatedAt:    now.Add(time.Duration(i) * time.Hour),
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, id)
+	}
+
+	// A booking has a single review.
+	_, err = repo.CreateReview(ctx, reserv.Review{
+		BookingID:    bookingIDs[0],
+		PropertyID:   propertyID,
+		GuestID:      "guest",
+		Rating:       1,
+		ReviewScores: reserv.ReviewScores{Cleanliness: 1, Accuracy: 1, CheckIn: 1, Communication: 1, Location: 1, Value: 1},
+		CreatedAt:    now,
+	})
+	require.ErrorIs(t, err, reserv.ErrReviewExists)
+
+	reviews, err := repo.PropertyReviews(ctx, propertyID, reserv.Page{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, reviews, 2, "the page has one review more than the limit")
+	require.Equal(t, bookingIDs[1], reviews[0].BookingID)
+	require.Equal(t, 2, reviews[0].Rating)
+	require.Equal(t, 4, reviews[0].Accuracy)
+	require.Equal(t, "Nice place", reviews[0].Comment)
+
+	cursor := reviews[0].Cursor()
+	reviews, err = repo.PropertyReviews(ctx, propertyID, reserv.Page{Limit: 1, After: &cursor})
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	require.Equal(t, bookingIDs[0], reviews[0].BookingID)
+
+	reviews, err = repo.PropertyReviews(ctx, uuid.New().String(), reserv.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, reviews)
+
+	properties, err := repo.Properties(ctx, reserv.PropertyFilter{HostID: "host"})
+	require.NoError(t, err)
+	require.Len(t, properties, 1)
+	require.Equal(t, &reserv.PropertyRating{
+		Count:   2,
+		Average: 3.5,
+		Categories: reserv.RatingAverages{
+			Cleanliness:   3.5,
+			Accuracy:      4,
+			CheckIn:       5,
+			Communication: 5,
+			Location:      3,
+			Value:         3.5,
+		},
+	}, properties[0].Rating)
+
+	// Deleting the property deletes its reviews.
+	require.NoError(t, repo.DeleteProperty(ctx, propertyID))
+	reviews, err = repo.PropertyReviews(ctx, propertyID, reserv.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, reviews)
+}
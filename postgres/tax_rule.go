@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/perebaj/reserv"
+)
+
+// CreateTaxRule creates a tax rule. It returns the id of the rule.
+func (r *Repository) CreateTaxRule(ctx context.Context, rule reserv.TaxRule) (string, error) {
+	slog.Info("creating tax rule", "jurisdiction", rule.Jurisdiction)
+	query := `
+		INSERT INTO tax_rules (jurisdiction, name, rate_basis_points, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var id string
+	if err := r.db.QueryRowxContext(ctx, query,
+		rule.Jurisdiction,
+		rule.Name,
+		rule.RateBasisPoints,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	).Scan(&id); err != nil {
+		return "", fmt.Errorf("failed to create tax rule: %v", err)
+	}
+
+	return id, nil
+}
+
+// UpdateTaxRule updates all the fields of a tax rule, except the creation time. Bookings already made keep the taxes
+// they were priced with.
+func (r *Repository) UpdateTaxRule(ctx context.Context, rule reserv.TaxRule) error {
+	slog.Info("updating tax rule", "id", rule.ID, "jurisdiction", rule.Jurisdiction)
+	query := `
+		UPDATE tax_rules SET jurisdiction = $2, name = $3, rate_basis_points = $4, updated_at = $5 WHERE id = $1
+	`
+
+	res, err := r.db.ExecContext(ctx, query, rule.ID, rule.Jurisdiction, rule.Name, rule.RateBasisPoints, rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update tax rule: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		return reserv.ErrTaxRuleNotFound
+	}
+
+	return nil
+}
+
+// DeleteTaxRule deletes a tax rule.
+func (r *Repository) DeleteTaxRule(ctx context.Context, id string) error {
+	slog.Info("deleting tax rule", "id", id)
+	res, err := r.db.ExecContext(ctx, `DELETE FROM tax_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tax rule: %v", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		return reserv.ErrTaxRuleNotFound
+	}
+
+	return nil
+}
+
+// TaxRules returns all the tax rules, ordered by jurisdiction. The table is small, so the rules that apply to a
+// property are filtered by reserv.TaxRule.AppliesTo.
+func (r *Repository) TaxRules(ctx context.Context) ([]reserv.TaxRule, error) {
+	slog.Info("getting tax rules")
+	query := `
+		SELECT * FROM tax_rules ORDER BY jurisdiction, created_at
+	`
+
+	var rules []reserv.TaxRule
+	if err := r.db.SelectContext(ctx, &rules, query); err != nil {
+		return nil, fmt.Errorf("failed to get tax rules: %v", err)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,54 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxRules(t *testing.T) {
+	db := OpenDB(t)
+	defer func() {
+		_ = db.Close()
+	}()
+
+	repo := postgres.NewRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	rule := reserv.TaxRule{
+		Jurisdiction:    "US-NY-NYC",
+		Name:            "NYC occupancy tax",
+		RateBasisPoints: 575,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	id, err := repo.CreateTaxRule(ctx, rule)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+
+	rule.ID = id
+	rule.RateBasisPoints = 600
+	require.NoError(t, repo.UpdateTaxRule(ctx, rule))
+
+	rules, err := repo.TaxRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, id, rules[0].ID)
+	require.Equal(t, "US-NY-NYC", rules[0].Jurisdiction)
+	require.Equal(t, 600, rules[0].RateBasisPoints)
+
+	require.NoError(t, repo.DeleteTaxRule(ctx, id))
+	require.ErrorIs(t, repo.DeleteTaxRule(ctx, id), reserv.ErrTaxRuleNotFound)
+
+	rule.ID = uuid.New().String()
+	require.ErrorIs(t, repo.UpdateTaxRule(ctx, rule), reserv.ErrTaxRuleNotFound)
+}
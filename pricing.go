@@ -0,0 +1,308 @@
+package reserv
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidStay is returned when the check-out date is not after the check-in date.
+var ErrInvalidStay = errors.New("check-out date must be after check-in date")
+
+// QuoteLineKind is the kind of a line of a quote.
+type QuoteLineKind string
+
+const (
+	// QuoteLineNightly is the sum of the nightly prices of the stay.
+	QuoteLineNightly QuoteLineKind = "nightly"
+	// QuoteLineExtraGuests is the fee of the guests above the guests included in the nightly price.
+	QuoteLineExtraGuests QuoteLineKind = "extra_guests"
+	// QuoteLineDiscount is a discount on the stay. Its amount is negative.
+	QuoteLineDiscount QuoteLineKind = "discount"
+	// QuoteLineCleaningFee is the cleaning fee of the property, charged once per stay.
+	QuoteLineCleaningFee QuoteLineKind = "cleaning_fee"
+	// QuoteLineServiceFee is the fee of the platform, a share of the stay and the cleaning fee after discounts.
+	QuoteLineServiceFee QuoteLineKind = "service_fee"
+	// QuoteLineTax is an occupancy tax of the jurisdiction of the property.
+	QuoteLineTax QuoteLineKind = "tax"
+)
+
+// QuoteLine is an item of a quote. The sum of all lines is the total price of the stay.
+type QuoteLine struct {
+	// Kind is the kind of the line. Example: "nightly".
+	Kind QuoteLineKind `json:"kind" db:"kind"`
+	// Description is a human-readable description of the line. Example: "3 nights".
+	Description string `json:"description" db:"description"`
+	// AmountCents is the amount of the line in the minor unit of the currency of the quote.
+	AmountCents int64 `json:"amount_cents" db:"amount_cents"`
+	// SourceID is the id of what originated the line, like the tax rule of a tax line. Optional.
+	SourceID string `json:"source_id,omitempty" db:"source_id"`
+}
+
+// NightPrice is the price of a single night of a stay.
+type NightPrice struct {
+	// Date is the date of the night. Format: 2025-01-01T00:00:00Z
+	Date time.Time `json:"date"`
+	// PriceCents is the price of the night in cents.
+	PriceCents int64 `json:"price_cents"`
+	// PricingRuleID is the id of the pricing rule used to price the night. Empty when the property price is used.
+	PricingRuleID string `json:"pricing_rule_id,omitempty"`
+}
+
+// Quote is the price of a stay computed by the server. It is the source of truth for the price of a booking.
+type Quote struct {
+	// PropertyID is the id of the property that the quote is for.
+	PropertyID string `json:"property_id"`
+	// CheckInDate and CheckOutDate are the dates of the stay. Format: 2025-01-01T00:00:00Z
+	CheckInDate  time.Time `json:"check_in_date"`
+	CheckOutDate time.Time `json:"check_out_date"`
+	// Nights is the price of each night of the stay.
+	Nights []NightPrice `json:"nights"`
+	// Lines are the items that compose the total price.
+	Lines []QuoteLine `json:"lines"`
+	// TotalPriceCents is the total price of the stay in the minor unit of the currency. See Total.
+	TotalPriceCents int64 `json:"total_price_cents"`
+	// Currency is the currency of the quote. It is always the currency of the property.
+	Currency string `json:"currency"`
+	// InstantBook is whether booking the stay confirms it right away or sends a request to the host.
+	InstantBook bool `json:"instant_book"`
+}
+
+// Total returns the total price of the stay.
+func (q Quote) Total() Money {
+	return Money{Amount: q.TotalPriceCents, Currency: q.Currency}
+}
+
+// QuoteRequest gathers everything needed to price a stay.
+type QuoteRequest struct {
+	// Property is the property being booked.
+	Property Property
+	// Rules are the pricing rules of the property.
+	Rules []PricingRule
+	// TaxRules are the tax rules to charge. Only the ones that apply to the jurisdiction of the property are used.
+	TaxRules []TaxRule
+	// ServiceFeePercent is the fee of the platform. See QuoteLineServiceFee.
+	ServiceFeePercent int
+	// Promotion is the promo code of the guest. It must be checked with Promotion.AppliesTo before pricing the stay. Optional.
+	Promotion *Promotion
+	// CheckInDate and CheckOutDate are the dates of the stay. They must be in UTC at midnight.
+	CheckInDate  time.Time
+	CheckOutDate time.Time
+	// Guests is who is coming to the stay. It must be checked with Property.CheckGuests before pricing the stay.
+	Guests Guests
+}
+
+// Nights returns the number of nights between the check-in and check-out dates.
+func Nights(checkIn, checkOut time.Time) int {
+	return int(checkOut.Sub(checkIn) / (24 * time.Hour))
+}
+
+// NightlyRate returns the price in cents of the night that starts at date and the rule used to price it.
+// When more than one rule applies, the one with the highest priority is used. If no rule applies, the property
+// price is used and the returned rule is nil.
+func NightlyRate(property Property, rules []PricingRule, date time.Time) (int64, *PricingRule) {
+	var best *PricingRule
+	for i := range rules {
+		if !rules[i].AppliesTo(date) {
+			continue
+		}
+		if best == nil || rules[i].outranks(*best) {
+			best = &rules[i]
+		}
+	}
+
+	if best == nil {
+		return property.PricePerNightCents, nil
+	}
+	return best.Price(property.PricePerNightCents), best
+}
+
+// NewQuote computes the price of a stay. Each night is priced individually and the total is the sum of all lines.
+// The lines come in order: the stay itself, its discounts, the cleaning fee, the service fee and the taxes. The fees
+// and the taxes are charged on the lines before them. The long stay discount comes before the promotion, so the
+// promotion is taken from the discounted stay.
+// It returns an error wrapping ErrAmountOverflow if the prices are too large to be added up.
+func NewQuote(req QuoteRequest) (Quote, error) {
+	nights := Nights(req.CheckInDate, req.CheckOutDate)
+	if nights < 1 {
+		return Quote{}, ErrInvalidStay
+	}
+
+	quote := Quote{
+		PropertyID:   req.Property.ID.String(),
+		CheckInDate:  req.CheckInDate,
+		CheckOutDate: req.CheckOutDate,
+		Nights:       make([]NightPrice, 0, nights),
+		Currency:     req.Property.Currency,
+		InstantBook:  req.Property.InstantBook,
+	}
+
+	subtotal := Money{Currency: quote.Currency}
+	for date := req.CheckInDate; date.Before(req.CheckOutDate); date = date.AddDate(0, 0, 1) {
+		price, rule := NightlyRate(req.Property, req.Rules, date)
+		night := NightPrice{Date: date, PriceCents: price}
+		if rule != nil {
+			night.PricingRuleID = rule.ID
+		}
+		quote.Nights = append(quote.Nights, night)
+
+		var err error
+		subtotal, err = subtotal.Add(Money{Amount: price, Currency: quote.Currency})
+		if err != nil {
+			return Quote{}, err
+		}
+	}
+
+	quote.Lines = append(quote.Lines, QuoteLine{
+		Kind:        QuoteLineNightly,
+		Description: fmt.Sprintf("%d nights", nights),
+		AmountCents: subtotal.Amount,
+	})
+
+	if extra := req.Property.ExtraGuests(req.Guests); extra > 0 && req.Property.ExtraGuestFeeCents > 0 {
+		fee, err := Money{Amount: req.Property.ExtraGuestFeeCents, Currency: quote.Currency}.Mul(int64(extra * nights))
+		if err != nil {
+			return Quote{}, err
+		}
+		quote.Lines = append(quote.Lines, QuoteLine{
+			Kind:        QuoteLineExtraGuests,
+			Description: fmt.Sprintf("%d extra guests x %d nights", extra, nights),
+			AmountCents: fee.Amount,
+		})
+	}
+
+	if err := quote.addLongStayDiscount(req.Property, nights); err != nil {
+		return Quote{}, err
+	}
+
+	if req.Promotion != nil {
+		if err := quote.addDiscount(*req.Promotion); err != nil {
+			return Quote{}, err
+		}
+	}
+
+	if req.Property.CleaningFeeCents > 0 {
+		quote.Lines = append(quote.Lines, QuoteLine{
+			Kind:        QuoteLineCleaningFee,
+			Description: "Cleaning fee",
+			AmountCents: req.Property.CleaningFeeCents,
+		})
+	}
+
+	if err := quote.addFeesAndTaxes(req); err != nil {
+		return Quote{}, err
+	}
+
+	total, err := quote.sum()
+	if err != nil {
+		return Quote{}, err
+	}
+	quote.TotalPriceCents = total.Amount
+
+	return quote, nil
+}
+
+// addLongStayDiscount appends the weekly or monthly discount of the property, taken from the sum of the current lines.
+func (q *Quote) addLongStayDiscount(property Property, nights int) error {
+	name, percent := property.LongStayDiscount(nights)
+	if percent == 0 {
+		return nil
+	}
+
+	stay, err := q.sum()
+	if err != nil {
+		return err
+	}
+
+	discount, err := stay.Percent(percent)
+	if err != nil {
+		return err
+	}
+
+	if discount.IsZero() {
+		return nil
+	}
+
+	q.Lines = append(q.Lines, QuoteLine{
+		Kind:        QuoteLineDiscount,
+		Description: fmt.Sprintf("%s discount (%d%%)", name, percent),
+		AmountCents: -discount.Amount,
+	})
+	return nil
+}
+
+// addDiscount appends the discount of the promotion, taken from the sum of the current lines.
+func (q *Quote) addDiscount(promotion Promotion) error {
+	stay, err := q.sum()
+	if err != nil {
+		return err
+	}
+
+	discount, err := promotion.Discount(stay)
+	if err != nil {
+		return err
+	}
+
+	if discount.IsZero() {
+		return nil
+	}
+
+	q.Lines = append(q.Lines, QuoteLine{
+		Kind:        QuoteLineDiscount,
+		Description: promotion.description(),
+		AmountCents: -discount.Amount,
+		SourceID:    promotion.ID,
+	})
+	return nil
+}
+
+// addFeesAndTaxes appends the service fee and the taxes of the stay, both charged on the sum of the current lines.
+func (q *Quote) addFeesAndTaxes(req QuoteRequest) error {
+	taxable, err := q.sum()
+	if err != nil {
+		return err
+	}
+
+	if req.ServiceFeePercent > 0 {
+		fee, err := taxable.Percent(req.ServiceFeePercent)
+		if err != nil {
+			return err
+		}
+		q.Lines = append(q.Lines, QuoteLine{
+			Kind:        QuoteLineServiceFee,
+			Description: fmt.Sprintf("Service fee (%d%%)", req.ServiceFeePercent),
+			AmountCents: fee.Amount,
+		})
+	}
+
+	for _, rule := range req.TaxRules {
+		if !rule.AppliesTo(req.Property.Jurisdiction) {
+			continue
+		}
+		tax, err := taxable.BasisPoints(rule.RateBasisPoints)
+		if err != nil {
+			return err
+		}
+		q.Lines = append(q.Lines, QuoteLine{
+			Kind:        QuoteLineTax,
+			Description: fmt.Sprintf("%s (%s)", rule.Name, formatBasisPoints(rule.RateBasisPoints)),
+			AmountCents: tax.Amount,
+			SourceID:    rule.ID,
+		})
+	}
+
+	return nil
+}
+
+// sum returns the sum of the lines of the quote.
+func (q Quote) sum() (Money, error) {
+	total := Money{Currency: q.Currency}
+	for _, line := range q.Lines {
+		var err error
+		total, err = total.Add(Money{Amount: line.AmountCents, Currency: q.Currency})
+		if err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,153 @@
+package reserv
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrPricingRuleNotFound is returned when a pricing rule doesn't exist for the property.
+var ErrPricingRuleNotFound = errors.New("pricing rule not found")
+
+// ErrInvalidPricingRule is returned when a pricing rule has invalid fields.
+var ErrInvalidPricingRule = errors.New("invalid pricing rule")
+
+// MaxAdjustmentPercent is the highest percentage adjustment of a pricing rule. It avoids typos like 1000 instead of 100.
+const MaxAdjustmentPercent = 500
+
+// Weekdays is a set of days of the week. It is stored as a bitmask where Sunday is the bit 0.
+type Weekdays []time.Weekday
+
+// Contains reports whether day is in the set. An empty set contains every day.
+func (w Weekdays) Contains(day time.Weekday) bool {
+	return len(w) == 0 || slices.Contains(w, day)
+}
+
+// Validate checks that every day is between Sunday and Saturday and appears only once.
+func (w Weekdays) Validate() error {
+	for i, day := range w {
+		if day < time.Sunday || day > time.Saturday {
+			return errors.New("must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		if slices.Contains(w[:i], day) {
+			return fmt.Errorf("duplicated weekday %d", day)
+		}
+	}
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (w Weekdays) Value() (driver.Value, error) {
+	var mask int64
+	for _, day := range w {
+		mask |= 1 << day
+	}
+	return mask, nil
+}
+
+// Scan implements sql.Scanner.
+func (w *Weekdays) Scan(src any) error {
+	mask, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("unsupported type for weekdays: %T", src)
+	}
+
+	days := Weekdays{}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if mask&(1<<day) != 0 {
+			days = append(days, day)
+		}
+	}
+	*w = days
+	return nil
+}
+
+// PricingRule changes the nightly price of a property on some nights. Example: weekends, holidays and high season.
+// A rule applies to a night when the night is inside its date range and its weekdays. When more than one rule applies,
+// only the one with the highest priority is used.
+type PricingRule struct {
+	// ID is the unique identifier for the rule. It is generated by the database.
+	ID string `json:"id" db:"id"`
+	// PropertyID is the id of the property. Required.
+	PropertyID string `json:"property_id" db:"property_id"`
+	// Name is a label for the rule. Example: "Weekends". Required.
+	Name string `json:"name" db:"name"`
+	// StartDate and EndDate limit the nights where the rule applies. Both are inclusive and optional.
+	// Format: 2025-01-01T00:00:00Z
+	StartDate *time.Time `json:"start_date" db:"start_date"`
+	EndDate   *time.Time `json:"end_date" db:"end_date"`
+	// Weekdays limits the nights where the rule applies, where Sunday is 0 and Saturday is 6. Empty means every day.
+	Weekdays Weekdays `json:"weekdays" db:"weekdays"`
+	// Priority decides which rule is used when more than one applies. The highest wins.
+	Priority int `json:"priority" db:"priority"`
+	// PriceCents replaces the price of the night, in cents. Exactly one of PriceCents and AdjustmentPercent must be set.
+	PriceCents *int64 `json:"price_cents" db:"price_cents"`
+	// AdjustmentPercent changes the price of the night by a percentage of the property price. Example: 20 for +20%, -10 for -10%.
+	AdjustmentPercent *int `json:"adjustment_percent" db:"adjustment_percent"`
+	// CreatedAt is the timestamp when the rule was created.
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// UpdatedAt is the timestamp when the rule was updated.
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Validate checks the fields of the rule. The returned error wraps ErrInvalidPricingRule.
+func (r PricingRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPricingRule)
+	}
+
+	if (r.PriceCents == nil) == (r.AdjustmentPercent == nil) {
+		return fmt.Errorf("%w: exactly one of price_cents and adjustment_percent is required", ErrInvalidPricingRule)
+	}
+
+	if r.PriceCents != nil && *r.PriceCents <= 0 {
+		return fmt.Errorf("%w: price_cents must be positive", ErrInvalidPricingRule)
+	}
+
+	if r.AdjustmentPercent != nil && (*r.AdjustmentPercent <= -100 || *r.AdjustmentPercent > MaxAdjustmentPercent) {
+		return fmt.Errorf("%w: adjustment_percent must be greater than -100 and at most %d", ErrInvalidPricingRule, MaxAdjustmentPercent)
+	}
+
+	if r.StartDate != nil && r.EndDate != nil && r.EndDate.Before(*r.StartDate) {
+		return fmt.Errorf("%w: end_date must not be before start_date", ErrInvalidPricingRule)
+	}
+
+	if err := r.Weekdays.Validate(); err != nil {
+		return fmt.Errorf("%w: weekdays %v", ErrInvalidPricingRule, err)
+	}
+
+	return nil
+}
+
+// AppliesTo reports whether the rule applies to the night that starts at date.
+func (r PricingRule) AppliesTo(date time.Time) bool {
+	if r.StartDate != nil && date.Before(*r.StartDate) {
+		return false
+	}
+	if r.EndDate != nil && date.After(*r.EndDate) {
+		return false
+	}
+	return r.Weekdays.Contains(date.Weekday())
+}
+
+// Price returns the price in cents of a night with the rule applied to the base price. Percentage adjustments are
+// rounded half up to the nearest cent.
+func (r PricingRule) Price(base int64) int64 {
+	if r.PriceCents != nil {
+		return *r.PriceCents
+	}
+
+	adjusted := base * int64(100+*r.AdjustmentPercent)
+	return (adjusted + 50) / 100
+}
+
+// outranks reports whether the rule r wins over other when both apply to the same night. Ties are broken by the
+// creation time, the newest first, so the result never depends on the order of the rules.
+func (r PricingRule) outranks(other PricingRule) bool {
+	if r.Priority != other.Priority {
+		return r.Priority > other.Priority
+	}
+	return r.CreatedAt.After(other.CreatedAt)
+}
@@ -0,0 +1,110 @@
+package reserv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestPricingRule_Validate(t *testing.T) {
+	jan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		rule    PricingRule
+		wantErr bool
+	}{
+		{name: "price override", rule: PricingRule{Name: "Weekends", Weekdays: Weekdays{time.Friday, time.Saturday}, PriceCents: ptr(int64(15000))}},
+		{name: "percentage adjustment", rule: PricingRule{Name: "High season", StartDate: &jan, EndDate: ptr(jan.AddDate(0, 1, 0)), AdjustmentPercent: ptr(20)}},
+		{name: "discount", rule: PricingRule{Name: "Low season", AdjustmentPercent: ptr(-30)}},
+		{name: "missing name", rule: PricingRule{PriceCents: ptr(int64(15000))}, wantErr: true},
+		{name: "missing price", rule: PricingRule{Name: "Weekends"}, wantErr: true},
+		{name: "price and adjustment", rule: PricingRule{Name: "Weekends", PriceCents: ptr(int64(15000)), AdjustmentPercent: ptr(20)}, wantErr: true},
+		{name: "zero price", rule: PricingRule{Name: "Weekends", PriceCents: ptr(int64(0))}, wantErr: true},
+		{name: "free nights", rule: PricingRule{Name: "Weekends", AdjustmentPercent: ptr(-100)}, wantErr: true},
+		{name: "too high adjustment", rule: PricingRule{Name: "Weekends", AdjustmentPercent: ptr(MaxAdjustmentPercent + 1)}, wantErr: true},
+		{name: "end before start", rule: PricingRule{Name: "Holidays", StartDate: &jan, EndDate: ptr(jan.AddDate(0, 0, -1)), AdjustmentPercent: ptr(20)}, wantErr: true},
+		{name: "invalid weekday", rule: PricingRule{Name: "Weekends", Weekdays: Weekdays{7}, AdjustmentPercent: ptr(20)}, wantErr: true},
+		{name: "duplicated weekday", rule: PricingRule{Name: "Weekends", Weekdays: Weekdays{time.Friday, time.Friday}, AdjustmentPercent: ptr(20)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidPricingRule)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPricingRule_Price(t *testing.T) {
+	require.Equal(t, int64(15000), PricingRule{PriceCents: ptr(int64(15000))}.Price(10000))
+	require.Equal(t, int64(12000), PricingRule{AdjustmentPercent: ptr(20)}.Price(10000))
+	require.Equal(t, int64(7000), PricingRule{AdjustmentPercent: ptr(-30)}.Price(10000))
+	// 999 * 1.15 = 1148.85, rounded half up to 1149.
+	require.Equal(t, int64(1149), PricingRule{AdjustmentPercent: ptr(15)}.Price(999))
+	// 5 * 0.9 = 4.5, rounded half up to 5.
+	require.Equal(t, int64(5), PricingRule{AdjustmentPercent: ptr(-10)}.Price(5))
+}
+
+func TestWeekdays_ValueScan(t *testing.T) {
+	days := Weekdays{time.Saturday, time.Sunday}
+	value, err := days.Value()
+	require.NoError(t, err)
+	require.Equal(t, int64(1<<0|1<<6), value)
+
+	var got Weekdays
+	require.NoError(t, got.Scan(value))
+	require.Equal(t, Weekdays{time.Sunday, time.Saturday}, got)
+
+	require.NoError(t, got.Scan(int64(0)))
+	require.Empty(t, got)
+	require.True(t, got.Contains(time.Wednesday))
+}
+
+func TestNightlyRate(t *testing.T) {
+	property := Property{PricePerNightCents: 10000, Currency: "USD"}
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rules := []PricingRule{
+		{ID: "weekends", Weekdays: Weekdays{time.Friday, time.Saturday}, AdjustmentPercent: ptr(25), CreatedAt: created},
+		{
+			ID:         "new-year",
+			StartDate:  ptr(time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)),
+			EndDate:    ptr(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+			Priority:   10,
+			PriceCents: ptr(int64(30000)),
+			CreatedAt:  created,
+		},
+		// Same priority of weekends, but newer, so it wins on the nights where both apply.
+		{ID: "saturdays", Weekdays: Weekdays{time.Saturday}, AdjustmentPercent: ptr(50), CreatedAt: created.AddDate(0, 0, 1)},
+	}
+
+	tests := []struct {
+		date     time.Time
+		wantCent int64
+		wantRule string
+	}{
+		{date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), wantCent: 30000, wantRule: "new-year"},
+		{date: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), wantCent: 10000},
+		{date: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), wantCent: 12500, wantRule: "weekends"},
+		{date: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC), wantCent: 15000, wantRule: "saturdays"},
+	}
+
+	for _, tt := range tests {
+		price, rule := NightlyRate(property, rules, tt.date)
+		require.Equal(t, tt.wantCent, price, tt.date)
+		if tt.wantRule == "" {
+			require.Nil(t, rule)
+			continue
+		}
+		require.Equal(t, tt.wantRule, rule.ID)
+	}
+}
@@ -0,0 +1,200 @@
+package reserv
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNights(t *testing.T) {
+	checkIn := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, 0, Nights(checkIn, checkIn))
+	require.Equal(t, 1, Nights(checkIn, checkIn.AddDate(0, 0, 1)))
+	require.Equal(t, 31, Nights(checkIn, checkIn.AddDate(0, 1, 0)))
+	require.Equal(t, -1, Nights(checkIn, checkIn.AddDate(0, 0, -1)))
+}
+
+func TestNewQuote(t *testing.T) {
+	property := Property{
+		ID:                 uuid.New(),
+		PricePerNightCents: 10000,
+		Currency:           "USD",
+	}
+
+	quote, err := NewQuote(QuoteRequest{
+		Property:     property,
+		CheckInDate:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.Equal(t, property.ID.String(), quote.PropertyID)
+	require.Equal(t, "USD", quote.Currency)
+	require.Equal(t, int64(30000), quote.TotalPriceCents)
+	require.Len(t, quote.Nights, 3)
+	require.Equal(t, time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), quote.Nights[2].Date)
+	require.Equal(t, []QuoteLine{{Kind: QuoteLineNightly, Description: "3 nights", AmountCents: 30000}}, quote.Lines)
+}
+
+func TestNewQuote_InvalidStay(t *testing.T) {
+	property := Property{PricePerNightCents: 10000, Currency: "USD"}
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := NewQuote(QuoteRequest{Property: property, CheckInDate: day, CheckOutDate: day})
+	require.ErrorIs(t, err, ErrInvalidStay)
+
+	_, err = NewQuote(QuoteRequest{Property: property, CheckInDate: day, CheckOutDate: day.AddDate(0, 0, -2)})
+	require.ErrorIs(t, err, ErrInvalidStay)
+}
+
+func TestNewQuote_Overflow(t *testing.T) {
+	property := Property{PricePerNightCents: math.MaxInt64 / 2, Currency: "USD"}
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := NewQuote(QuoteRequest{Property: property, CheckInDate: day, CheckOutDate: day.AddDate(0, 0, 3)})
+	require.ErrorIs(t, err, ErrAmountOverflow)
+}
+
+func TestNewQuote_PricingRules(t *testing.T) {
+	property := Property{PricePerNightCents: 10000, Currency: "USD"}
+	weekends := PricingRule{ID: "weekends", Weekdays: Weekdays{time.Friday, time.Saturday}, PriceCents: ptr(int64(15000))}
+
+	// Thursday to Sunday: a weekday night and two weekend nights.
+	quote, err := NewQuote(QuoteRequest{
+		Property:     property,
+		Rules:        []PricingRule{weekends},
+		CheckInDate:  time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(40000), quote.TotalPriceCents)
+	require.Equal(t, []NightPrice{
+		{Date: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), PriceCents: 10000},
+		{Date: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), PriceCents: 15000, PricingRuleID: "weekends"},
+		{Date: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC), PriceCents: 15000, PricingRuleID: "weekends"},
+	}, quote.Nights)
+}
+
+func TestNewQuote_ExtraGuests(t *testing.T) {
+	property := Property{PricePerNightCents: 10000, Currency: "USD", MaxGuests: 6, GuestsIncluded: 2, ExtraGuestFeeCents: 2500}
+	req := QuoteRequest{
+		Property:     property,
+		CheckInDate:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC),
+		Guests:       Guests{Adults: 2, Children: 2, Infants: 1},
+	}
+
+	// Infants are not charged, so 2 extra guests pay 3 nights each.
+	quote, err := NewQuote(req)
+	require.NoError(t, err)
+	require.Equal(t, int64(45000), quote.TotalPriceCents)
+	require.Equal(t, QuoteLine{Kind: QuoteLineExtraGuests, Description: "2 extra guests x 3 nights", AmountCents: 15000}, quote.Lines[1])
+
+	req.Guests = Guests{Adults: 2}
+	quote, err = NewQuote(req)
+	require.NoError(t, err)
+	require.Equal(t, int64(30000), quote.TotalPriceCents)
+	require.Len(t, quote.Lines, 1)
+}
+
+func TestNewQuote_FeesAndTaxes(t *testing.T) {
+	property := Property{PricePerNightCents: 10000, Currency: "USD", CleaningFeeCents: 5000, Jurisdiction: "US-NY-NYC"}
+	req := QuoteRequest{
+		Property: property,
+		TaxRules: []TaxRule{
+			{ID: "ny", Jurisdiction: "US-NY", Name: "NY sales tax", RateBasisPoints: 400},
+			{ID: "nyc", Jurisdiction: "US-NY-NYC", Name: "NYC occupancy tax", RateBasisPoints: 575},
+			{ID: "ca", Jurisdiction: "US-CA", Name: "CA occupancy tax", RateBasisPoints: 1000},
+		},
+		ServiceFeePercent: 10,
+		CheckInDate:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate:      time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC),
+	}
+
+	// The fee and the taxes are charged on the 3 nights and the cleaning fee, 350.00.
+	quote, err := NewQuote(req)
+	require.NoError(t, err)
+	require.Equal(t, []QuoteLine{
+		{Kind: QuoteLineNightly, Description: "3 nights", AmountCents: 30000},
+		{Kind: QuoteLineCleaningFee, Description: "Cleaning fee", AmountCents: 5000},
+		{Kind: QuoteLineServiceFee, Description: "Service fee (10%)", AmountCents: 3500},
+		{Kind: QuoteLineTax, Description: "NY sales tax (4%)", AmountCents: 1400, SourceID: "ny"},
+		{Kind: QuoteLineTax, Description: "NYC occupancy tax (5.75%)", AmountCents: 2012, SourceID: "nyc"},
+	}, quote.Lines)
+	require.Equal(t, int64(41912), quote.TotalPriceCents)
+
+	// Properties without a jurisdiction are not taxed.
+	req.Property.Jurisdiction = ""
+	quote, err = NewQuote(req)
+	require.NoError(t, err)
+	require.Len(t, quote.Lines, 3)
+	require.Equal(t, int64(38500), quote.TotalPriceCents)
+}
+
+func TestNewQuote_Promotion(t *testing.T) {
+	property := Property{PricePerNightCents: 10000, Currency: "USD", CleaningFeeCents: 5000}
+	req := QuoteRequest{
+		Property:          property,
+		Promotion:         &Promotion{ID: "summer", Code: "SUMMER25", PercentOff: ptr(25)},
+		ServiceFeePercent: 10,
+		CheckInDate:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate:      time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC),
+	}
+
+	// The discount is taken from the stay only, and the service fee is charged on the discounted price.
+	quote, err := NewQuote(req)
+	require.NoError(t, err)
+	require.Equal(t, []QuoteLine{
+		{Kind: QuoteLineNightly, Description: "3 nights", AmountCents: 30000},
+		{Kind: QuoteLineDiscount, Description: "Promo code SUMMER25 (25%)", AmountCents: -7500, SourceID: "summer"},
+		{Kind: QuoteLineCleaningFee, Description: "Cleaning fee", AmountCents: 5000},
+		{Kind: QuoteLineServiceFee, Description: "Service fee (10%)", AmountCents: 2750},
+	}, quote.Lines)
+	require.Equal(t, int64(30250), quote.TotalPriceCents)
+
+	req.Promotion = &Promotion{ID: "welcome", Code: "WELCOME", AmountOffCents: ptr(int64(50000)), Currency: "USD"}
+	quote, err = NewQuote(req)
+	require.NoError(t, err)
+	require.Equal(t, QuoteLine{Kind: QuoteLineDiscount, Description: "Promo code WELCOME", AmountCents: -30000, SourceID: "welcome"}, quote.Lines[1])
+	require.Equal(t, int64(5500), quote.TotalPriceCents)
+}
+
+func TestNewQuote_LongStayDiscount(t *testing.T) {
+	property := Property{PricePerNightCents: 10000, Currency: "USD", CleaningFeeCents: 5000, WeeklyDiscountPercent: 10, MonthlyDiscountPercent: 25}
+	req := QuoteRequest{
+		Property:     property,
+		CheckInDate:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		CheckOutDate: time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	quote, err := NewQuote(req)
+	require.NoError(t, err)
+	require.Equal(t, []QuoteLine{
+		{Kind: QuoteLineNightly, Description: "7 nights", AmountCents: 70000},
+		{Kind: QuoteLineDiscount, Description: "Weekly discount (10%)", AmountCents: -7000},
+		{Kind: QuoteLineCleaningFee, Description: "Cleaning fee", AmountCents: 5000},
+	}, quote.Lines)
+	require.Equal(t, int64(68000), quote.TotalPriceCents)
+
+	// The monthly discount replaces the weekly one, and the promotion is taken from the discounted stay.
+	req.CheckOutDate = time.Date(2025, 1, 29, 0, 0, 0, 0, time.UTC)
+	req.Promotion = &Promotion{ID: "nomad", Code: "NOMAD", PercentOff: ptr(10)}
+	quote, err = NewQuote(req)
+	require.NoError(t, err)
+	require.Equal(t, []QuoteLine{
+		{Kind: QuoteLineNightly, Description: "28 nights", AmountCents: 280000},
+		{Kind: QuoteLineDiscount, Description: "Monthly discount (25%)", AmountCents: -70000},
+		{Kind: QuoteLineDiscount, Description: "Promo code NOMAD (10%)", AmountCents: -21000, SourceID: "nomad"},
+		{Kind: QuoteLineCleaningFee, Description: "Cleaning fee", AmountCents: 5000},
+	}, quote.Lines)
+	require.Equal(t, int64(194000), quote.TotalPriceCents)
+
+	// Shorter stays have no discount line.
+	req.CheckOutDate = time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)
+	req.Promotion = nil
+	quote, err = NewQuote(req)
+	require.NoError(t, err)
+	require.Len(t, quote.Lines, 2)
+}
@@ -0,0 +1,148 @@
+package reserv
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidPromotion is returned when a promotion has invalid fields.
+	ErrInvalidPromotion = errors.New("invalid promotion")
+	// ErrPromotionNotFound is returned when a promotion or promo code doesn't exist.
+	ErrPromotionNotFound = errors.New("promotion not found")
+	// ErrPromoCodeExists is returned when another promotion already uses the code.
+	ErrPromoCodeExists = errors.New("promo code already exists")
+	// ErrPromotionNotApplicable is returned when a promotion can't be used for a stay, like a stay shorter than its
+	// minimum nights.
+	ErrPromotionNotApplicable = errors.New("promo code doesn't apply to the stay")
+	// ErrPromotionUnavailable is returned when a promotion expired or reached its usage limit.
+	ErrPromotionUnavailable = errors.New("promo code is no longer available")
+)
+
+// promoCodePattern matches codes like "SUMMER25" or "WELCOME-BACK": 3 to 32 upper case letters, digits, hyphens or underscores.
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// NormalizePromoCode returns the code as it is stored. Guests can type the codes in any case.
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Promotion is a promo code that gives a discount on the stay. The discount is taken from the nightly price and the
+// extra guests fee, before the cleaning fee, the service fee and the taxes.
+type Promotion struct {
+	// ID is the unique identifier for the promotion. It is generated by the database.
+	ID string `json:"id" db:"id"`
+	// Code is what the guests type to get the discount. It is stored in upper case. Example: "SUMMER25". Required.
+	Code string `json:"code" db:"code"`
+	// PercentOff is the discount as a percentage of the stay. Exactly one of PercentOff and AmountOffCents must be set.
+	PercentOff *int `json:"percent_off" db:"percent_off"`
+	// AmountOffCents is a fixed discount in the minor unit of Currency. It is never more than the stay itself.
+	AmountOffCents *int64 `json:"amount_off_cents" db:"amount_off_cents"`
+	// Currency is the ISO 4217 code of AmountOffCents. Fixed discounts only apply to properties in that currency.
+	Currency string `json:"currency,omitempty" db:"currency"`
+	// ExpiresAt is when the code stops being accepted. Nil means it never expires.
+	ExpiresAt *time.Time `json:"expires_at" db:"expires_at"`
+	// MaxRedemptions is how many bookings can use the code. 0 means there is no limit.
+	MaxRedemptions int `json:"max_redemptions" db:"max_redemptions"`
+	// Redemptions is how many bookings already used the code. Declined booking requests don't count.
+	Redemptions int `json:"redemptions" db:"redemptions"`
+	// MinNights is the minimum number of nights of the stay. 0 means any stay.
+	MinNights int `json:"min_nights" db:"min_nights"`
+	// PropertyIDs and HostIDs restrict the code to some properties or to all the properties of some hosts. The code
+	// applies when the property or its host is listed. When both are empty, the code applies to every property.
+	PropertyIDs []string `json:"property_ids" db:"-"`
+	HostIDs     []string `json:"host_ids" db:"-"`
+	// CreatedAt is the timestamp when the promotion was created.
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// UpdatedAt is the timestamp when the promotion was updated.
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Validate checks the fields of the promotion. The returned error wraps ErrInvalidPromotion.
+func (p Promotion) Validate() error {
+	if !promoCodePattern.MatchString(p.Code) {
+		return fmt.Errorf("%w: code must have 3 to 32 upper case letters, digits, hyphens or underscores", ErrInvalidPromotion)
+	}
+
+	if (p.PercentOff == nil) == (p.AmountOffCents == nil) {
+		return fmt.Errorf("%w: exactly one of percent_off and amount_off_cents is required", ErrInvalidPromotion)
+	}
+
+	if p.PercentOff != nil && (*p.PercentOff < 1 || *p.PercentOff > 100) {
+		return fmt.Errorf("%w: percent_off must be between 1 and 100", ErrInvalidPromotion)
+	}
+
+	if p.AmountOffCents != nil {
+		if *p.AmountOffCents <= 0 {
+			return fmt.Errorf("%w: amount_off_cents must be positive", ErrInvalidPromotion)
+		}
+		if err := ValidateCurrency(p.Currency); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPromotion, err)
+		}
+	}
+
+	if p.MaxRedemptions < 0 {
+		return fmt.Errorf("%w: max_redemptions must not be negative", ErrInvalidPromotion)
+	}
+
+	if p.MinNights < 0 {
+		return fmt.Errorf("%w: min_nights must not be negative", ErrInvalidPromotion)
+	}
+
+	return nil
+}
+
+// Available checks that the code can still be redeemed at now. The returned error wraps ErrPromotionUnavailable.
+// The usage limit is enforced again when the booking is stored, since other guests may redeem the code meanwhile.
+func (p Promotion) Available(now time.Time) error {
+	if p.ExpiresAt != nil && !now.Before(*p.ExpiresAt) {
+		return fmt.Errorf("%w: the code expired", ErrPromotionUnavailable)
+	}
+
+	if p.MaxRedemptions > 0 && p.Redemptions >= p.MaxRedemptions {
+		return fmt.Errorf("%w: the code reached its usage limit", ErrPromotionUnavailable)
+	}
+
+	return nil
+}
+
+// AppliesTo checks that the promotion can be used for a stay of nights in the property. The returned error wraps
+// ErrPromotionNotApplicable.
+func (p Promotion) AppliesTo(property Property, nights int) error {
+	if len(p.PropertyIDs) > 0 || len(p.HostIDs) > 0 {
+		if !slices.Contains(p.PropertyIDs, property.ID.String()) && !slices.Contains(p.HostIDs, property.HostID) {
+			return fmt.Errorf("%w: the code is not valid for this property", ErrPromotionNotApplicable)
+		}
+	}
+
+	if nights < p.MinNights {
+		return fmt.Errorf("%w: the code requires at least %d nights", ErrPromotionNotApplicable, p.MinNights)
+	}
+
+	if p.AmountOffCents != nil && p.Currency != property.Currency {
+		return fmt.Errorf("%w: the code is only valid for prices in %s", ErrPromotionNotApplicable, p.Currency)
+	}
+
+	return nil
+}
+
+// Discount returns how much the promotion takes from the stay, as a positive amount. Percentages are rounded toward
+// zero and fixed amounts are limited to the stay, so the discount is never more than the stay itself.
+func (p Promotion) Discount(stay Money) (Money, error) {
+	if p.PercentOff != nil {
+		return stay.Percent(*p.PercentOff)
+	}
+	return Money{Amount: min(*p.AmountOffCents, stay.Amount), Currency: stay.Currency}, nil
+}
+
+// description is the description of the discount line of the promotion. Example: "Promo code SUMMER25 (25%)".
+func (p Promotion) description() string {
+	if p.PercentOff != nil {
+		return fmt.Sprintf("Promo code %s (%d%%)", p.Code, *p.PercentOff)
+	}
+	return "Promo code " + p.Code
+}
@@ -0,0 +1,84 @@
+package reserv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePromoCode(t *testing.T) {
+	require.Equal(t, "SUMMER25", NormalizePromoCode(" summer25 "))
+}
+
+func TestPromotion_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion Promotion
+		wantErr   bool
+	}{
+		{name: "percent", promotion: Promotion{Code: "SUMMER25", PercentOff: ptr(25)}},
+		{name: "fixed amount", promotion: Promotion{Code: "WELCOME", AmountOffCents: ptr(int64(2000)), Currency: "USD"}},
+		{name: "invalid code", promotion: Promotion{Code: "summer 25", PercentOff: ptr(25)}, wantErr: true},
+		{name: "short code", promotion: Promotion{Code: "AB", PercentOff: ptr(25)}, wantErr: true},
+		{name: "no discount", promotion: Promotion{Code: "SUMMER25"}, wantErr: true},
+		{name: "both discounts", promotion: Promotion{Code: "SUMMER25", PercentOff: ptr(25), AmountOffCents: ptr(int64(2000)), Currency: "USD"}, wantErr: true},
+		{name: "percent above 100", promotion: Promotion{Code: "SUMMER25", PercentOff: ptr(101)}, wantErr: true},
+		{name: "zero amount", promotion: Promotion{Code: "WELCOME", AmountOffCents: ptr(int64(0)), Currency: "USD"}, wantErr: true},
+		{name: "amount without currency", promotion: Promotion{Code: "WELCOME", AmountOffCents: ptr(int64(2000))}, wantErr: true},
+		{name: "negative max redemptions", promotion: Promotion{Code: "SUMMER25", PercentOff: ptr(25), MaxRedemptions: -1}, wantErr: true},
+		{name: "negative min nights", promotion: Promotion{Code: "SUMMER25", PercentOff: ptr(25), MinNights: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.promotion.Validate()
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidPromotion)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPromotion_Available(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, Promotion{}.Available(now))
+	require.NoError(t, Promotion{ExpiresAt: ptr(now.Add(time.Second)), MaxRedemptions: 2, Redemptions: 1}.Available(now))
+	require.ErrorIs(t, Promotion{ExpiresAt: &now}.Available(now), ErrPromotionUnavailable)
+	require.ErrorIs(t, Promotion{MaxRedemptions: 2, Redemptions: 2}.Available(now), ErrPromotionUnavailable)
+}
+
+func TestPromotion_AppliesTo(t *testing.T) {
+	property := Property{ID: uuid.New(), HostID: "host_1", Currency: "USD"}
+
+	require.NoError(t, Promotion{PercentOff: ptr(10)}.AppliesTo(property, 1))
+	require.NoError(t, Promotion{PercentOff: ptr(10), PropertyIDs: []string{property.ID.String()}}.AppliesTo(property, 1))
+	require.NoError(t, Promotion{PercentOff: ptr(10), PropertyIDs: []string{uuid.NewString()}, HostIDs: []string{"host_1"}}.AppliesTo(property, 1))
+	require.NoError(t, Promotion{AmountOffCents: ptr(int64(1000)), Currency: "USD", MinNights: 3}.AppliesTo(property, 3))
+
+	require.ErrorIs(t, Promotion{PercentOff: ptr(10), PropertyIDs: []string{uuid.NewString()}}.AppliesTo(property, 1), ErrPromotionNotApplicable)
+	require.ErrorIs(t, Promotion{PercentOff: ptr(10), HostIDs: []string{"host_2"}}.AppliesTo(property, 1), ErrPromotionNotApplicable)
+	require.ErrorIs(t, Promotion{PercentOff: ptr(10), MinNights: 3}.AppliesTo(property, 2), ErrPromotionNotApplicable)
+	require.ErrorIs(t, Promotion{AmountOffCents: ptr(int64(1000)), Currency: "BRL"}.AppliesTo(property, 1), ErrPromotionNotApplicable)
+}
+
+func TestPromotion_Discount(t *testing.T) {
+	stay := Money{Amount: 30050, Currency: "USD"}
+
+	discount, err := Promotion{PercentOff: ptr(15)}.Discount(stay)
+	require.NoError(t, err)
+	require.Equal(t, Money{Amount: 4507, Currency: "USD"}, discount)
+
+	discount, err = Promotion{AmountOffCents: ptr(int64(2000)), Currency: "USD"}.Discount(stay)
+	require.NoError(t, err)
+	require.Equal(t, Money{Amount: 2000, Currency: "USD"}, discount)
+
+	// A fixed discount larger than the stay makes it free, but never negative.
+	discount, err = Promotion{AmountOffCents: ptr(int64(50000)), Currency: "USD"}.Discount(stay)
+	require.NoError(t, err)
+	require.Equal(t, stay, discount)
+}
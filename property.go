@@ -1,11 +1,15 @@
 package reserv
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrPropertyHasPayments is returned when a property can't be deleted because its bookings have payments in progress or charged.
+var ErrPropertyHasPayments = errors.New("property has payments")
+
 // Amenity represents a property amenity
 type Amenity struct {
 	// ID is the unique identifier for the amenity. Required.
@@ -32,14 +36,42 @@ type Property struct {
 	Title string `json:"title" db:"title"`
 	// Description is the description of the property. Required.
 	Description string `json:"description" db:"description"`
-	// PricePerNightCents is the price per night in cents. Required.
+	// PricePerNightCents is the price per night in the minor unit of the currency, like cents for USD. Required.
 	PricePerNightCents int64 `json:"price_per_night_cents" db:"price_per_night_cents"`
-	// Currency is the currency of the property. Example: "USD", "BRL". Required.
+	// Currency is the ISO 4217 code of the currency of the property. Example: "USD", "BRL". Required.
 	Currency string `json:"currency" db:"currency"`
+	// MaxGuests is the maximum number of adults and children the property accepts. Required.
+	MaxGuests int `json:"max_guests" db:"max_guests"`
+	// Bedrooms, Beds and Bathrooms describe the rooms of the property.
+	Bedrooms  int `json:"bedrooms" db:"bedrooms"`
+	Beds      int `json:"beds" db:"beds"`
+	Bathrooms int `json:"bathrooms" db:"bathrooms"`
+	// MaxPets is the maximum number of pets the property accepts. 0 means pets are not allowed.
+	MaxPets int `json:"max_pets" db:"max_pets"`
+	// GuestsIncluded is the number of guests covered by the nightly price.
+	GuestsIncluded int `json:"guests_included" db:"guests_included"`
+	// ExtraGuestFeeCents is the price per night in cents of each guest above GuestsIncluded.
+	ExtraGuestFeeCents int64 `json:"extra_guest_fee_cents" db:"extra_guest_fee_cents"`
+	// CleaningFeeCents is the price in the minor unit of the currency charged once per stay for cleaning the property.
+	CleaningFeeCents int64 `json:"cleaning_fee_cents" db:"cleaning_fee_cents"`
+	// Jurisdiction is the tax jurisdiction of the property. Example: "US-NY-NYC". The taxes of the jurisdiction and of
+	// its parents, like "US-NY", are added to the stays. Empty means the stays are not taxed.
+	Jurisdiction string `json:"jurisdiction" db:"jurisdiction"`
+	// WeeklyDiscountPercent and MonthlyDiscountPercent discount the stays of at least 7 and 28 nights. Example: 10 for
+	// 10% off. 0 means no discount. See LongStayDiscount.
+	WeeklyDiscountPercent  int `json:"weekly_discount_percent" db:"weekly_discount_percent"`
+	MonthlyDiscountPercent int `json:"monthly_discount_percent" db:"monthly_discount_percent"`
+	// InstantBook is whether the bookings are confirmed right away. When it is off, bookings are requests that the host
+	// must approve.
+	InstantBook bool `json:"instant_book" db:"instant_book"`
 	// Amenities is the list of amenities for the property. Example: ["wifi", "pool"].
 	Amenities []Amenity `json:"amenities" db:"-"`
 	// Images is the list of images for the property.
 	Images []PropertyImage `json:"images" db:"-"`
+	// DisplayPrice is the price converted to the currency requested by the guest. It is only set when a currency is requested.
+	DisplayPrice *DisplayPrice `json:"display_price,omitempty" db:"-"`
+	// Rating summarizes the reviews of the property. It is only set in the lists of properties.
+	Rating *PropertyRating `json:"rating,omitempty" db:"-"`
 	// CreatedAt is the timestamp when the property was created. Optional.
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	// UpdatedAt is the timestamp when the property was updated. Required.
@@ -0,0 +1,115 @@
+package reserv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidReview is returned when a review has ratings out of range or a comment too long.
+var ErrInvalidReview = errors.New("invalid review")
+
+// ErrReviewExists is returned when the booking already has a review.
+var ErrReviewExists = errors.New("booking already has a review")
+
+// ErrReviewNotAllowed is returned when a booking can't be reviewed, like before its check-out or after a cancellation.
+var ErrReviewNotAllowed = errors.New("booking can't be reviewed")
+
+const (
+	// MinRating and MaxRating are the range of the star ratings of the reviews and of their categories.
+	MinRating = 1
+	MaxRating = 5
+	// MaxReviewCommentLength is the longest comment of a review, in characters.
+	MaxReviewCommentLength = 5000
+)
+
+// ReviewScores are the star ratings of the categories of a stay, from MinRating to MaxRating.
+type ReviewScores struct {
+	Cleanliness   int `json:"cleanliness" db:"cleanliness"`
+	Accuracy      int `json:"accuracy" db:"accuracy"`
+	CheckIn       int `json:"check_in" db:"check_in"`
+	Communication int `json:"communication" db:"communication"`
+	Location      int `json:"location" db:"location"`
+	Value         int `json:"value" db:"value"`
+}
+
+// Review is the review of a stay by the guest of the booking, after the check-out. Each booking has at most one review.
+type Review struct {
+	ID         string `json:"id" db:"id"`
+	BookingID  string `json:"booking_id" db:"booking_id"`
+	PropertyID string `json:"property_id" db:"property_id"`
+	GuestID    string `json:"guest_id" db:"guest_id"`
+	// Rating is the overall star rating of the stay, from MinRating to MaxRating.
+	Rating int `json:"rating" db:"rating"`
+	ReviewScores
+	Comment   string    `json:"comment" db:"comment"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Cursor returns the position of the review in the lists of reviews, ordered by creation.
+func (r Review) Cursor() Cursor {
+	return Cursor{Time: r.CreatedAt, ID: r.ID}
+}
+
+// Validate checks that the rating and the scores of the categories are within MinRating and MaxRating, and that the
+// comment is at most MaxReviewCommentLength characters long. The returned error wraps ErrInvalidReview.
+func (r Review) Validate() error {
+	for _, score := range []struct {
+		name  string
+		value int
+	}{
+		{"rating", r.Rating},
+		{"cleanliness", r.Cleanliness},
+		{"accuracy", r.Accuracy},
+		{"check_in", r.CheckIn},
+		{"communication", r.Communication},
+		{"location", r.Location},
+		{"value", r.Value},
+	} {
+		if score.value < MinRating || score.value > MaxRating {
+			return fmt.Errorf("%w: %s must be between %d and %d", ErrInvalidReview, score.name, MinRating, MaxRating)
+		}
+	}
+
+	if utf8.RuneCountInString(strings.TrimSpace(r.Comment)) > MaxReviewCommentLength {
+		return fmt.Errorf("%w: the comment is longer than %d characters", ErrInvalidReview, MaxReviewCommentLength)
+	}
+
+	return nil
+}
+
+// CheckReviewable checks that the guest of a booking can review it at now: the guest stayed, so the booking was
+// confirmed, checked in or completed, and the check-out date already came. Confirmed bookings count as stayed, since
+// the hosts don't always check their guests in. The returned error wraps ErrReviewNotAllowed.
+func CheckReviewable(booking Booking, now time.Time) error {
+	if booking.Status != BookingStatusConfirmed && booking.Status != BookingStatusCheckedIn && booking.Status != BookingStatusCompleted {
+		return fmt.Errorf("%w: a %s booking can't be reviewed", ErrReviewNotAllowed, booking.Status)
+	}
+
+	if now.Before(booking.CheckOutDate) {
+		return fmt.Errorf("%w: the stay can be reviewed from the check-out date, %s", ErrReviewNotAllowed, booking.CheckOutDate.Format(time.DateOnly))
+	}
+
+	return nil
+}
+
+// PropertyRating is the summary of the reviews of a property: how many there are and the averages of their ratings.
+// The averages are 0 when there are no reviews.
+type PropertyRating struct {
+	Count   int     `json:"count"`
+	Average float64 `json:"average"`
+	// Categories are the averages of the scores of each category.
+	Categories RatingAverages `json:"categories"`
+}
+
+// RatingAverages are the averages of the scores of each category of the reviews.
+type RatingAverages struct {
+	Cleanliness   float64 `json:"cleanliness"`
+	Accuracy      float64 `json:"accuracy"`
+	CheckIn       float64 `json:"check_in"`
+	Communication float64 `json:"communication"`
+	Location      float64 `json:"location"`
+	Value         float64 `json:"value"`
+}
@@ -0,0 +1,65 @@
+package reserv
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReview_Validate(t *testing.T) {
+	valid := Review{
+		Rating:       5,
+		ReviewScores: ReviewScores{Cleanliness: 5, Accuracy: 4, CheckIn: 5, Communication: 5, Location: 3, Value: 4},
+		Comment:      "Great stay",
+	}
+	require.NoError(t, valid.Validate())
+
+	// The comment is optional, and its length is counted in characters, not bytes.
+	review := valid
+	review.Comment = strings.Repeat("é", MaxReviewCommentLength)
+	require.NoError(t, review.Validate())
+	review.Comment = ""
+	require.NoError(t, review.Validate())
+
+	for name, change := range map[string]func(*Review){
+		"no rating":           func(r *Review) { r.Rating = 0 },
+		"rating above max":    func(r *Review) { r.Rating = 6 },
+		"no cleanliness":      func(r *Review) { r.Cleanliness = 0 },
+		"accuracy below min":  func(r *Review) { r.Accuracy = -1 },
+		"check-in above max":  func(r *Review) { r.CheckIn = 6 },
+		"no communication":    func(r *Review) { r.Communication = 0 },
+		"no location":         func(r *Review) { r.Location = 0 },
+		"value above max":     func(r *Review) { r.Value = 10 },
+		"comment is too long": func(r *Review) { r.Comment = strings.Repeat("a", MaxReviewCommentLength+1) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			review := valid
+			change(&review)
+			require.ErrorIs(t, review.Validate(), ErrInvalidReview)
+		})
+	}
+}
+
+func TestCheckReviewable(t *testing.T) {
+	checkOut := time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC)
+	booking := Booking{Status: BookingStatusCompleted, CheckInDate: checkOut.AddDate(0, 0, -3), CheckOutDate: checkOut}
+
+	require.NoError(t, CheckReviewable(booking, checkOut))
+	require.NoError(t, CheckReviewable(booking, checkOut.AddDate(0, 1, 0)))
+	require.ErrorIs(t, CheckReviewable(booking, checkOut.Add(-time.Hour)), ErrReviewNotAllowed)
+
+	booking.Status = BookingStatusCheckedIn
+	require.NoError(t, CheckReviewable(booking, checkOut.Add(10*time.Hour)))
+
+	// The host never checked the guest in.
+	booking.Status = BookingStatusConfirmed
+	require.NoError(t, CheckReviewable(booking, checkOut))
+	require.ErrorIs(t, CheckReviewable(booking, checkOut.Add(-time.Hour)), ErrReviewNotAllowed)
+
+	for _, status := range []BookingStatus{BookingStatusPending, BookingStatusCancelledByGuest, BookingStatusNoShow, BookingStatusDeclined} {
+		booking.Status = status
+		require.ErrorIs(t, CheckReviewable(booking, checkOut.AddDate(0, 0, 1)), ErrReviewNotAllowed, status)
+	}
+}
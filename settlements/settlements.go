@@ -0,0 +1,158 @@
+// Package settlements captures or voids the authorized payments once their bookings are decided. The payments are
+// settled right away by the handlers, and the worker retries the ones left authorized by a failure.
+package settlements
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/perebaj/reserv"
+)
+
+//go:generate mockgen -source settlements.go -destination ../mock/settlements.go -package mock
+
+// PaymentUpdater stores the changes of the payments.
+type PaymentUpdater interface {
+	// UpdatePayment stores the status of a payment still in the status from and posts entry to the ledger, when not nil
+	UpdatePayment(ctx context.Context, payment reserv.Payment, from reserv.PaymentStatus, entry *reserv.JournalEntry) error
+}
+
+// SettlementRepository gathers the methods needed to settle the payments.
+type SettlementRepository interface {
+	PaymentUpdater
+	// UnsettledPayments returns the authorized payments of the bookings that are not pending anymore, updated before updatedBefore
+	UnsettledPayments(ctx context.Context, updatedBefore time.Time) ([]reserv.Payment, error)
+	// GetBooking gets a booking by its id
+	GetBooking(ctx context.Context, id string) (int, reserv.Booking, error)
+	// GetProperty gets a property by its id
+	GetProperty(ctx context.Context, id string) (int, reserv.Property, error)
+}
+
+// SettlementProvider is the part of the payment providers that moves the money of the authorized payments. Capturing or
+// voiding a payment again must not move its money twice.
+type SettlementProvider interface {
+	// Capture charges an authorized payment
+	Capture(ctx context.Context, paymentID string, amountCents int64) error
+	// Refund gives amountCents of a captured payment back to the guest, or releases an authorized payment
+	Refund(ctx context.Context, paymentID string, amountCents int64) error
+}
+
+// Capture charges an authorized payment and stores it as captured, posting it to the ledger with the host share
+// credited to hostID. If it fails, the payment stays authorized and is captured again by the worker. Payments whose
+// amount differs from the booking price are refused with reserv.ErrPaymentAmountMismatch, so the guest is never
+// charged a stale price.
+func Capture(ctx context.Context, repo PaymentUpdater, provider SettlementProvider, payment reserv.Payment, booking reserv.Booking, hostID string) error {
+	if payment.AmountCents != booking.TotalPriceCents {
+		return fmt.Errorf("%w: payment of %d cents for a booking of %d cents", reserv.ErrPaymentAmountMismatch, payment.AmountCents, booking.TotalPriceCents)
+	}
+
+	if err := provider.Capture(ctx, payment.ProviderPaymentID, payment.AmountCents); err != nil {
+		return err
+	}
+
+	payment.Status = reserv.PaymentStatusCaptured
+	payment.UpdatedAt = time.Now().UTC()
+	entry := reserv.PaymentEntry(payment, booking, hostID, payment.UpdatedAt)
+	return repo.UpdatePayment(ctx, payment, reserv.PaymentStatusAuthorized, &entry)
+}
+
+// Void releases an authorized payment without charging the guest and stores it as voided. Nothing was charged, so
+// nothing is posted to the ledger. If it fails, the payment stays authorized and is voided again by the worker.
+func Void(ctx context.Context, repo PaymentUpdater, provider SettlementProvider, payment reserv.Payment) error {
+	if err := provider.Refund(ctx, payment.ProviderPaymentID, payment.AmountCents); err != nil {
+		return err
+	}
+
+	payment.Status = reserv.PaymentStatusVoided
+	payment.UpdatedAt = time.Now().UTC()
+	return repo.UpdatePayment(ctx, payment, reserv.PaymentStatusAuthorized, nil)
+}
+
+// Worker periodically settles the authorized payments of the decided bookings.
+type Worker struct {
+	repo     SettlementRepository
+	provider SettlementProvider
+	interval time.Duration
+}
+
+// NewWorker creates a worker that settles the payments every interval.
+func NewWorker(repo SettlementRepository, provider SettlementProvider, interval time.Duration) *Worker {
+	return &Worker{repo: repo, provider: provider, interval: interval}
+}
+
+// Run settles the payments until ctx is done. It is blocking, so it must run on its own goroutine.
+func (w *Worker) Run(ctx context.Context) {
+	slog.Info("starting payment settlement worker", "interval", w.interval)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.Settle(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.Info("stopping payment settlement worker")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Settle captures the authorized payments of the bookings that block their dates and voids the ones of the cancelled
+// and declined bookings. Only the payments untouched for an interval are settled, leaving the recent ones to the
+// handlers. Payments that fail stay authorized and are settled again on the next run.
+func (w *Worker) Settle(ctx context.Context) {
+	payments, err := w.repo.UnsettledPayments(ctx, time.Now().UTC().Add(-w.interval))
+	if err != nil {
+		slog.Error("failed to get unsettled payments", "error", err)
+		return
+	}
+
+	for _, payment := range payments {
+		if ctx.Err() != nil {
+			return
+		}
+		w.settle(ctx, payment)
+	}
+}
+
+// settle captures or voids an authorized payment, depending on the status of its booking.
+func (w *Worker) settle(ctx context.Context, payment reserv.Payment) {
+	affected, booking, err := w.repo.GetBooking(ctx, payment.BookingID)
+	if err != nil {
+		slog.Error("failed to get booking", "error", err, "payment_id", payment.ID, "booking_id", payment.BookingID)
+		return
+	}
+
+	// Requests waiting for the host are settled when the host answers them.
+	if affected == 0 || booking.Status == reserv.BookingStatusPending {
+		return
+	}
+
+	if !booking.Status.BlocksDates() {
+		if err := Void(ctx, w.repo, w.provider, payment); err != nil {
+			slog.Error("failed to void payment", "error", err, "payment_id", payment.ID, "booking_id", booking.ID)
+			return
+		}
+		slog.Info("payment voided", "payment_id", payment.ID, "booking_id", booking.ID, "booking_status", booking.Status)
+		return
+	}
+
+	affected, property, err := w.repo.GetProperty(ctx, booking.PropertyID)
+	if err != nil {
+		slog.Error("failed to get property", "error", err, "payment_id", payment.ID, "property_id", booking.PropertyID)
+		return
+	}
+	if affected == 0 {
+		slog.Error("property of the booking not found", "payment_id", payment.ID, "property_id", booking.PropertyID)
+		return
+	}
+
+	if err := Capture(ctx, w.repo, w.provider, payment, booking, property.HostID); err != nil {
+		slog.Error("failed to capture payment", "error", err, "payment_id", payment.ID, "booking_id", booking.ID)
+		return
+	}
+	slog.Info("payment captured", "payment_id", payment.ID, "booking_id", booking.ID, "amount_cents", payment.AmountCents)
+}
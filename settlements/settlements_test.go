@@ -0,0 +1,136 @@
+package settlements_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/perebaj/reserv"
+	"github.com/perebaj/reserv/mock"
+	"github.com/perebaj/reserv/payments"
+	"github.com/perebaj/reserv/settlements"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// authorize creates a payment authorized in the fake provider.
+func authorize(t *testing.T, fake *payments.Fake, id, bookingID string) reserv.Payment {
+	intent, err := fake.CreateIntent(context.Background(), reserv.PaymentIntentRequest{BookingID: bookingID, AmountCents: 30000, Currency: "USD"})
+	require.NoError(t, err)
+	require.NoError(t, fake.Authorize(context.Background(), intent.ID))
+	return reserv.Payment{
+		ID:                id,
+		BookingID:         bookingID,
+		Provider:          payments.FakeProviderName,
+		ProviderPaymentID: intent.ID,
+		Status:            reserv.PaymentStatusAuthorized,
+		AmountCents:       30000,
+		Currency:          "USD",
+	}
+}
+
+func TestWorker_Settle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fake := payments.NewFake("whsec_test")
+	confirmed := authorize(t, fake, "payment-1", "booking-1")
+	declined := authorize(t, fake, "payment-2", "booking-2")
+	pending := authorize(t, fake, "payment-3", "booking-3")
+
+	lineItems := []reserv.QuoteLine{
+		{Kind: reserv.QuoteLineNightly, Description: "3 nights", AmountCents: 27000},
+		{Kind: reserv.QuoteLineServiceFee, Description: "Service fee (11%)", AmountCents: 3000},
+	}
+
+	repo := mock.NewMockSettlementRepository(ctrl)
+	repo.EXPECT().UnsettledPayments(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, updatedBefore time.Time) ([]reserv.Payment, error) {
+		require.WithinDuration(t, time.Now().Add(-10*time.Minute), updatedBefore, time.Minute)
+		return []reserv.Payment{confirmed, declined, pending}, nil
+	})
+	repo.EXPECT().GetBooking(gomock.Any(), "booking-1").
+		Return(1, reserv.Booking{ID: "booking-1", PropertyID: "property-1", Status: reserv.BookingStatusConfirmed, TotalPriceCents: 30000, Currency: "USD", LineItems: lineItems}, nil)
+	repo.EXPECT().GetBooking(gomock.Any(), "booking-2").
+		Return(1, reserv.Booking{ID: "booking-2", PropertyID: "property-1", Status: reserv.BookingStatusDeclined}, nil)
+	// The request is still waiting for the host, so its payment is left authorized.
+	repo.EXPECT().GetBooking(gomock.Any(), "booking-3").
+		Return(1, reserv.Booking{ID: "booking-3", PropertyID: "property-1", Status: reserv.BookingStatusPending}, nil)
+	repo.EXPECT().GetProperty(gomock.Any(), "property-1").Return(1, reserv.Property{HostID: "host-1"}, nil)
+
+	updated := map[string]reserv.PaymentStatus{}
+	repo.EXPECT().UpdatePayment(gomock.Any(), gomock.Any(), reserv.PaymentStatusAuthorized, gomock.Any()).
+		DoAndReturn(func(_ context.Context, payment reserv.Payment, _ reserv.PaymentStatus, entry *reserv.JournalEntry) error {
+			updated[payment.ID] = payment.Status
+			if payment.Status == reserv.PaymentStatusCaptured {
+				require.NotNil(t, entry)
+				require.NoError(t, entry.Validate())
+				require.Equal(t, reserv.JournalEntryPayment, entry.Kind)
+			} else {
+				require.Nil(t, entry)
+			}
+			return nil
+		}).Times(2)
+
+	settlements.NewWorker(repo, fake, 10*time.Minute).Settle(context.Background())
+
+	require.Equal(t, map[string]reserv.PaymentStatus{
+		"payment-1": reserv.PaymentStatusCaptured,
+		"payment-2": reserv.PaymentStatusVoided,
+	}, updated)
+
+	for id, want := range map[string]reserv.PaymentStatus{
+		confirmed.ProviderPaymentID: reserv.PaymentStatusCaptured,
+		declined.ProviderPaymentID:  reserv.PaymentStatusVoided,
+		pending.ProviderPaymentID:   reserv.PaymentStatusAuthorized,
+	} {
+		status, _, err := fake.Status(id)
+		require.NoError(t, err)
+		require.Equal(t, want, status)
+	}
+}
+
+func TestCapture_AmountMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The booking was re-priced after the payment was authorized, so nothing is charged nor stored.
+	repo := mock.NewMockSettlementRepository(ctrl)
+	provider := mock.NewMockSettlementProvider(ctrl)
+
+	payment := reserv.Payment{ID: "payment-1", BookingID: "booking-1", ProviderPaymentID: "pi_1", Status: reserv.PaymentStatusAuthorized, AmountCents: 30000, Currency: "USD"}
+	booking := reserv.Booking{ID: "booking-1", Status: reserv.BookingStatusConfirmed, TotalPriceCents: 40000, Currency: "USD"}
+
+	err := settlements.Capture(context.Background(), repo, provider, payment, booking, "host-1")
+	require.ErrorIs(t, err, reserv.ErrPaymentAmountMismatch)
+}
+
+func TestWorker_SettleFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	unsettled := []reserv.Payment{
+		{ID: "payment-1", BookingID: "booking-1", ProviderPaymentID: "pi_1", Status: reserv.PaymentStatusAuthorized, AmountCents: 30000, Currency: "USD"},
+		{ID: "payment-2", BookingID: "booking-2", ProviderPaymentID: "pi_2", Status: reserv.PaymentStatusAuthorized, AmountCents: 30000, Currency: "USD"},
+		{ID: "payment-3", BookingID: "booking-3", ProviderPaymentID: "pi_3", Status: reserv.PaymentStatusAuthorized, AmountCents: 30000, Currency: "USD"},
+	}
+
+	repo := mock.NewMockSettlementRepository(ctrl)
+	repo.EXPECT().UnsettledPayments(gomock.Any(), gomock.Any()).Return(unsettled, nil)
+	repo.EXPECT().GetBooking(gomock.Any(), "booking-1").Return(0, reserv.Booking{}, errors.New("connection refused"))
+	repo.EXPECT().GetBooking(gomock.Any(), "booking-2").Return(1, reserv.Booking{ID: "booking-2", Status: reserv.BookingStatusCancelledByHost}, nil)
+	repo.EXPECT().GetBooking(gomock.Any(), "booking-3").Return(1, reserv.Booking{ID: "booking-3", Status: reserv.BookingStatusCancelledByGuest}, nil)
+
+	// A failed void leaves the payment authorized and the next payments are still settled.
+	provider := mock.NewMockSettlementProvider(ctrl)
+	provider.EXPECT().Refund(gomock.Any(), "pi_2", int64(30000)).Return(errors.New("provider unavailable"))
+	provider.EXPECT().Refund(gomock.Any(), "pi_3", int64(30000)).Return(nil)
+	repo.EXPECT().UpdatePayment(gomock.Any(), gomock.Any(), reserv.PaymentStatusAuthorized, nil).
+		DoAndReturn(func(_ context.Context, payment reserv.Payment, _ reserv.PaymentStatus, _ *reserv.JournalEntry) error {
+			require.Equal(t, "payment-3", payment.ID)
+			require.Equal(t, reserv.PaymentStatusVoided, payment.Status)
+			return nil
+		})
+
+	settlements.NewWorker(repo, provider, time.Minute).Settle(context.Background())
+}